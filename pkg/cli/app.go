@@ -15,11 +15,15 @@
 package cli
 
 import (
+	"context"
+	"errors"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
 	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/indexmanager"
 	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/server"
 	"github.com/rs/zerolog"
 	"github.com/urfave/cli/v2"
 	"os"
+	"runtime"
 	"time"
 )
 
@@ -29,6 +33,25 @@ type App struct {
 	serverConfig server.Config
 	debug        bool
 	dataPath     string
+	// expirySweepInterval is the interval between deletions of expired
+	// vectors.
+	expirySweepInterval time.Duration
+	// efTuningInterval is the interval between automatic tunings of the
+	// "ef" parameter of the indices.
+	efTuningInterval time.Duration
+	efTuning         indexmanager.EfTuningOptions
+	// loadOptions are the options of the indices loaded at startup.
+	loadOptions hnswgo.LoadOptions
+	// loadWorkers is the number of indices loaded in parallel at startup.
+	loadWorkers int
+	// quarantine enables the quarantine of the indices which fail to load.
+	quarantine bool
+	// lazy enables the lazy loading of the indices, which are unloaded
+	// as configured by lazyOptions, checked at each evictionInterval.
+	lazy             bool
+	lazyOptions      indexmanager.LazyOptions
+	memoryBudgetMiB  int64
+	evictionInterval time.Duration
 }
 
 // NewApp returns a new App object.
@@ -44,11 +67,25 @@ func NewApp() *App {
 	}
 	app.Flags = app.cliFlags()
 	app.Action = app.runAction
+	app.Commands = []*cli.Command{
+		app.importCommand(),
+		app.exportCommand(),
+		app.verifyCommand(),
+		app.inspectCommand(),
+		app.indicesCommand(),
+		app.createCommand(),
+		app.deleteCommand(),
+		app.flushCommand(),
+		app.setEfCommand(),
+		app.searchCommand(),
+		app.insertCommand(),
+		app.describeCommand(),
+	}
 	return app
 }
 
 func (app *App) cliFlags() []cli.Flag {
-	return []cli.Flag{
+	flags := []cli.Flag{
 		&cli.StringFlag{
 			Name:        "address",
 			Value:       "0.0.0.0:19530",
@@ -79,6 +116,102 @@ func (app *App) cliFlags() []cli.Flag {
 			Usage:       "path to the indices folder",
 			Destination: &app.dataPath,
 		},
+		&cli.BoolFlag{
+			Name:        "mmap",
+			Value:       false,
+			Usage:       "memory-map the indices loaded at startup, instead of reading them into memory",
+			Destination: &app.loadOptions.Mmap,
+		},
+		&cli.BoolFlag{
+			Name:        "read-only",
+			Value:       false,
+			Usage:       "load the indices read-only and memory-mapped, rejecting all changes",
+			Destination: &app.loadOptions.ReadOnly,
+		},
+		&cli.IntFlag{
+			Name:        "load-workers",
+			Value:       0,
+			Usage:       "number of indices loaded in parallel at startup (0 = number of CPUs)",
+			Destination: &app.loadWorkers,
+		},
+		&cli.BoolFlag{
+			Name:        "quarantine",
+			Value:       false,
+			Usage:       "move the indices which fail to load to the .quarantine dir, instead of trying to load them at each start, and keep the previous snapshot of the indices to repair them (taking about twice the disk space)",
+			Destination: &app.quarantine,
+		},
+		&cli.BoolFlag{
+			Name:        "lazy",
+			Value:       false,
+			Usage:       "load each index on first access, unloading idle indices as set by --memory-budget and --idle-timeout",
+			Destination: &app.lazy,
+		},
+		&cli.Int64Flag{
+			Name:        "memory-budget",
+			Value:       0,
+			Usage:       "maximum memory of the loaded indices in MiB, unloading the least recently used ones, with --lazy (0 = unlimited)",
+			Destination: &app.memoryBudgetMiB,
+		},
+		&cli.DurationFlag{
+			Name:        "idle-timeout",
+			Value:       0,
+			Usage:       "time after which indices which are not accessed are unloaded, with --lazy (0 = never)",
+			Destination: &app.lazyOptions.IdleTimeout,
+		},
+		&cli.DurationFlag{
+			Name:        "eviction-interval",
+			Value:       time.Minute,
+			Usage:       "interval between checks of the indices to be unloaded, with --lazy",
+			Destination: &app.evictionInterval,
+		},
+		&cli.DurationFlag{
+			Name:        "expiry-sweep-interval",
+			Value:       time.Minute,
+			Usage:       "interval between deletions of expired vectors (0 = never)",
+			Destination: &app.expirySweepInterval,
+		},
+		&cli.DurationFlag{
+			Name:        "ef-tuning-interval",
+			Value:       0,
+			Usage:       "interval between automatic tunings of the ef parameter of HNSW indices (0 = never)",
+			Destination: &app.efTuningInterval,
+		},
+		&cli.Float64Flag{
+			Name:        "ef-tuning-recall",
+			Value:       0.95,
+			Usage:       "target recall@k of automatic ef tunings",
+			Destination: &app.efTuning.TargetRecall,
+		},
+		&cli.IntFlag{
+			Name:        "ef-tuning-k",
+			Value:       10,
+			Usage:       "number of nearest neighbors searched by automatic ef tunings",
+			Destination: &app.efTuning.K,
+		},
+		&cli.IntFlag{
+			Name:        "ef-tuning-sample-size",
+			Value:       100,
+			Usage:       "number of stored vectors used as queries by automatic ef tunings",
+			Destination: &app.efTuning.SampleSize,
+		},
+		&cli.IntFlag{
+			Name:        "ef-tuning-max-ef",
+			Value:       1000,
+			Usage:       "maximum ef which can be chosen by automatic ef tunings",
+			Destination: &app.efTuning.MaxEf,
+		},
+		&cli.IntFlag{
+			Name:        "insert-workers",
+			Value:       0,
+			Usage:       "number of workers inserting vectors for each streaming call (0 = number of CPUs)",
+			Destination: &app.serverConfig.InsertWorkers,
+		},
+		&cli.IntFlag{
+			Name:        "insert-batch-size",
+			Value:       1024,
+			Usage:       "number of vectors inserted at once by streaming calls",
+			Destination: &app.serverConfig.InsertBatchSize,
+		},
 		&cli.BoolFlag{
 			Name:        "debug",
 			Value:       false,
@@ -86,6 +219,68 @@ func (app *App) cliFlags() []cli.Flag {
 			Destination: &app.debug,
 		},
 	}
+	flags = append(flags,
+		&cli.DurationFlag{
+			Name:        "search-max-duration",
+			Usage:       "maximum duration of search requests (0 = unlimited)",
+			Destination: &app.serverConfig.Deadlines.Search,
+		},
+		&cli.DurationFlag{
+			Name:        "write-max-duration",
+			Usage:       "maximum duration of write requests (0 = unlimited)",
+			Destination: &app.serverConfig.Deadlines.Write,
+		},
+		&cli.DurationFlag{
+			Name:        "admin-max-duration",
+			Usage:       "maximum duration of admin requests (0 = unlimited)",
+			Destination: &app.serverConfig.Deadlines.Admin,
+		},
+	)
+	flags = append(flags, admissionFlags("search", &app.serverConfig.Admission.Search)...)
+	flags = append(flags, admissionFlags("write", &app.serverConfig.Admission.Write)...)
+	flags = append(flags, admissionFlags("admin", &app.serverConfig.Admission.Admin)...)
+	return flags
+}
+
+// admissionFlags returns the admission control flags for a single RPC class.
+func admissionFlags(class string, limits *server.ClassLimits) []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:        class + "-max-concurrent",
+			Usage:       "maximum number of concurrent " + class + " requests (0 = unlimited)",
+			Destination: &limits.MaxConcurrent,
+		},
+		&cli.Float64Flag{
+			Name:        class + "-rate",
+			Usage:       "maximum " + class + " requests per second (0 = unlimited)",
+			Destination: &limits.Rate,
+		},
+		&cli.IntFlag{
+			Name:        class + "-burst",
+			Usage:       "burst size for --" + class + "-rate (0 = same as rate)",
+			Destination: &limits.Burst,
+		},
+		&cli.Float64Flag{
+			Name:        class + "-client-rate",
+			Usage:       "maximum " + class + " requests per second for each client (0 = unlimited)",
+			Destination: &limits.ClientRate,
+		},
+		&cli.IntFlag{
+			Name:        class + "-client-burst",
+			Usage:       "burst size for --" + class + "-client-rate (0 = same as rate)",
+			Destination: &limits.ClientBurst,
+		},
+		&cli.Float64Flag{
+			Name:        class + "-index-rate",
+			Usage:       "maximum " + class + " requests per second for each index (0 = unlimited)",
+			Destination: &limits.IndexRate,
+		},
+		&cli.IntFlag{
+			Name:        class + "-index-burst",
+			Usage:       "burst size for --" + class + "-index-rate (0 = same as rate)",
+			Destination: &limits.IndexBurst,
+		},
+	}
 }
 
 func (app *App) runAction(*cli.Context) (err error) {
@@ -97,14 +292,59 @@ func (app *App) runAction(*cli.Context) (err error) {
 		}
 	}()
 
+	// Unless they are loaded lazily, the indices are only discovered before
+	// the server starts listening, and loaded in background afterwards.
+	// Indices which fail to load do not prevent the others from being used.
 	indexManager := indexmanager.New(app.dataPath, logger)
-	err = indexManager.LoadIndices()
-	if err != nil {
+	if app.quarantine {
+		indexManager.EnableQuarantine()
+	}
+	if app.lazy {
+		app.lazyOptions.MemoryBudget = app.memoryBudgetMiB << 20
+		err = indexManager.LoadIndicesLazily(app.loadOptions, app.lazyOptions)
+		if errors.Is(err, indexmanager.ErrIndicesFailed) {
+			logger.Err(err).Send()
+		} else if err != nil {
+			return err
+		}
+	} else if err = indexManager.DiscoverIndices(app.loadOptions); err != nil {
 		return err
 	}
 
+	if app.lazy && app.evictionInterval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go indexManager.RunEvictor(ctx, app.evictionInterval)
+	}
+
+	if app.expirySweepInterval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go indexManager.RunExpirySweeper(ctx, app.expirySweepInterval)
+	}
+	if app.efTuningInterval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go indexManager.RunEfTuner(ctx, app.efTuningInterval, app.efTuning)
+	}
+
 	srv := server.New(app.serverConfig, indexManager, logger)
-	return srv.Run()
+	listener, err := srv.Listen()
+	if err != nil {
+		return err
+	}
+	if !app.lazy {
+		workers := app.loadWorkers
+		if workers <= 0 {
+			workers = runtime.NumCPU()
+		}
+		go func() {
+			if err := indexManager.LoadDiscoveredIndices(workers); err != nil {
+				logger.Err(err).Send()
+			}
+		}()
+	}
+	return srv.Serve(listener)
 }
 
 func (app *App) newLogger() zerolog.Logger {
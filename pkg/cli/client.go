@@ -0,0 +1,194 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/grpcapi"
+	"github.com/urfave/cli/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// Output formats of the client commands.
+const (
+	tableOutput = "table"
+	jsonOutput  = "json"
+)
+
+// clientOptions provides the connection and output settings shared by the
+// client commands, which call a running server.
+type clientOptions struct {
+	address string
+	tls     bool
+	tlsCA   string
+	token   string
+	timeout time.Duration
+	output  string
+}
+
+// flags returns the command line flags of the client options.
+func (opts *clientOptions) flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "address",
+			Value:       "localhost:19530",
+			Usage:       "server address and port",
+			EnvVars:     []string{"HNSW_GRPC_SERVER_ADDRESS"},
+			Destination: &opts.address,
+		},
+		&cli.BoolFlag{
+			Name:        "tls",
+			Usage:       "whether to use TLS",
+			Destination: &opts.tls,
+		},
+		&cli.StringFlag{
+			Name:        "tls-ca",
+			Usage:       "file of the CA certificates verifying the server, with --tls (default: system certificates)",
+			Destination: &opts.tlsCA,
+		},
+		&cli.StringFlag{
+			Name:        "token",
+			Usage:       "bearer token sent in the authorization metadata of each request, with --tls",
+			EnvVars:     []string{"HNSW_GRPC_SERVER_TOKEN"},
+			Destination: &opts.token,
+		},
+		&cli.DurationFlag{
+			Name:        "timeout",
+			Usage:       "maximum duration of the request (0 = unlimited)",
+			Destination: &opts.timeout,
+		},
+		&cli.StringFlag{
+			Name:        "output",
+			Aliases:     []string{"o"},
+			Value:       tableOutput,
+			Usage:       "output format: table or json",
+			Destination: &opts.output,
+		},
+	}
+}
+
+func (opts *clientOptions) validate() error {
+	if opts.output != tableOutput && opts.output != jsonOutput {
+		return fmt.Errorf("invalid output format %#v", opts.output)
+	}
+	if opts.token != "" && !opts.tls {
+		return fmt.Errorf("--token requires --tls, not to send the token in clear text")
+	}
+	if opts.tlsCA != "" && !opts.tls {
+		return fmt.Errorf("--tls-ca requires --tls")
+	}
+	if opts.timeout < 0 {
+		return fmt.Errorf("--timeout must not be negative")
+	}
+	return nil
+}
+
+// withClient connects to the server, and calls fn with a client of the
+// server and the context of the requests, which is canceled on interrupt
+// and once the timeout elapses.
+func (opts *clientOptions) withClient(c *cli.Context, fn func(context.Context, grpcapi.ServerClient) error) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	dialOptions := []grpc.DialOption{grpc.WithInsecure()}
+	if opts.tls {
+		creds := credentials.NewTLS(&tls.Config{})
+		if opts.tlsCA != "" {
+			var err error
+			creds, err = credentials.NewClientTLSFromFile(opts.tlsCA, "")
+			if err != nil {
+				return fmt.Errorf("failed to read TLS CA certs: %w", err)
+			}
+		}
+		dialOptions = []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	}
+	if opts.token != "" {
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(bearerToken(opts.token)))
+	}
+
+	conn, err := grpc.Dial(opts.address, dialOptions...)
+	if err != nil {
+		return fmt.Errorf("error connecting to %#v: %w", opts.address, err)
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+	return fn(ctx, grpcapi.NewServerClient(conn))
+}
+
+// writeJSON writes the messages in the JSON mapping of Protocol Buffers,
+// one per line.
+func writeJSON(w io.Writer, messages ...proto.Message) error {
+	for _, m := range messages {
+		data, err := protojson.Marshal(m)
+		if err != nil {
+			return err
+		}
+		if _, err = fmt.Fprintf(w, "%s\n", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTable writes the rows aligned in columns, below the header, if any.
+func writeTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if len(header) > 0 {
+		if _, err := fmt.Fprintln(tw, strings.Join(header, "\t")); err != nil {
+			return err
+		}
+	}
+	for _, row := range rows {
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// bearerToken provides the authorization metadata of each request. The
+// server itself does not authenticate requests: the token is meant for the
+// proxies in front of it.
+type bearerToken string
+
+// GetRequestMetadata implements credentials.PerRPCCredentials.
+func (t bearerToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity implements credentials.PerRPCCredentials.
+func (t bearerToken) RequireTransportSecurity() bool {
+	return true
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli_test
+
+import (
+	"bytes"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/cli"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/indexmanager"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/server"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net"
+	"testing"
+)
+
+func TestClientOptions(t *testing.T) {
+	t.Parallel()
+	address := startServer(t)
+
+	out, err := runClient(t, "indices", "--address", address, "--output", "yaml")
+	assert.EqualError(t, err, "invalid output format \"yaml\"")
+	assert.Empty(t, out)
+
+	_, err = runClient(t, "indices", "--address", address, "--token", "foo")
+	assert.EqualError(t, err, "--token requires --tls, not to send the token in clear text")
+
+	_, err = runClient(t, "indices", "--address", address, "--tls-ca", "ca.crt")
+	assert.EqualError(t, err, "--tls-ca requires --tls")
+
+	_, err = runClient(t, "indices", "--address", address, "--tls", "--tls-ca", "missing.crt")
+	assert.Error(t, err)
+
+	// The server does not use TLS.
+	_, err = runClient(t, "indices", "--address", address, "--tls", "--token", "foo", "--timeout", "1s")
+	assert.Error(t, err)
+
+	_, err = runClient(t, "indices", "--address", address, "foo")
+	assert.Error(t, err)
+
+	out, err = runClient(t, "indices", "--address", address, "-o", "json")
+	require.NoError(t, err)
+	assert.Equal(t, "{}\n", out)
+}
+
+// startServer runs a server, on a new data path, until the test is
+// complete. It returns the address of the server.
+func startServer(t *testing.T) string {
+	t.Helper()
+	im := indexmanager.New(t.TempDir(), zerolog.Nop())
+	require.NoError(t, im.LoadIndices())
+	srv := server.New(server.Config{Address: "127.0.0.1:0"}, im, zerolog.Nop())
+	listener, err := srv.Listen()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+	return listener.Addr().(*net.TCPAddr).String()
+}
+
+// runClient runs a client command, returning its output.
+func runClient(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	app := cli.NewApp()
+	out := &bytes.Buffer{}
+	app.Writer = out
+	err := app.Run(append([]string{"hnsw-grpc-server"}, args...))
+	return out.String(), err
+}
@@ -0,0 +1,184 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/indexmanager"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/osutils"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/vecfile"
+	"github.com/urfave/cli/v2"
+	"os"
+	"os/signal"
+	"path"
+	"syscall"
+	"time"
+)
+
+// exportOptions provides the settings of the export command.
+type exportOptions struct {
+	dataPath     string
+	name         string
+	format       string
+	idColumn     string
+	vectorColumn string
+	idsFile      string
+}
+
+func (app *App) exportCommand() *cli.Command {
+	opts := &exportOptions{}
+	return &cli.Command{
+		Name:      "export",
+		Usage:     "write all the vectors of an index to a file, without running the server",
+		ArgsUsage: "FILE",
+		Description: "Vectors which are not marked deleted are written to a .npy, .fvecs or\n" +
+			".jsonl file, which can be imported back with the import command.\n" +
+			"The index must not be in use by a running server. For cosine\n" +
+			"spaces, the exported vectors are normalized.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "data",
+				Value:       "./hnsw-grpc-server-data",
+				Usage:       "path to the indices folder",
+				Destination: &opts.dataPath,
+			},
+			&cli.StringFlag{
+				Name:        "name",
+				Required:    true,
+				Usage:       "name of the index",
+				Destination: &opts.name,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Usage:       "format of the output file: npy, fvecs or jsonl (default: from file extension)",
+				Destination: &opts.format,
+			},
+			&cli.StringFlag{
+				Name:        "id-column",
+				Usage:       "JSONL key of the vector IDs (default: \"id\")",
+				Destination: &opts.idColumn,
+			},
+			&cli.StringFlag{
+				Name:        "vector-column",
+				Usage:       "JSONL key of the vectors (default: \"vector\")",
+				Destination: &opts.vectorColumn,
+			},
+			&cli.StringFlag{
+				Name:        "ids",
+				Usage:       "1-dimensional .npy file where the IDs of the vectors of a .npy or .fvecs file are written",
+				Destination: &opts.idsFile,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return app.exportAction(c, opts)
+		},
+	}
+}
+
+func (app *App) exportAction(c *cli.Context, opts *exportOptions) (err error) {
+	logger := app.newLogger().With().Str("index", opts.name).Logger()
+
+	defer func() {
+		if err != nil {
+			logger.Err(err).Send()
+		}
+	}()
+
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("exactly one output file is expected")
+	}
+	output := c.Args().First()
+
+	if !indexmanager.IsValidIndexName(opts.name) {
+		return fmt.Errorf("invalid index name %#v", opts.name)
+	}
+	format, err := opts.fileFormat(output)
+	if err != nil {
+		return err
+	}
+	if format == vecfile.JSONL && opts.idsFile != "" {
+		return fmt.Errorf("--ids cannot be used with jsonl files: IDs are written to each line")
+	}
+	if format != vecfile.JSONL && opts.idsFile == "" {
+		logger.Warn().Msg("--ids is not set: vector IDs will not be exported")
+	}
+
+	dir := path.Join(opts.dataPath, opts.name)
+	exists, err := osutils.DirExists(dir)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("index dir %#v not found", dir)
+	}
+
+	index, err := hnswgo.Load(dir, logger)
+	if err != nil {
+		return err
+	}
+
+	w, err := vecfile.Create(output, format, vecfile.Options{
+		IDColumn:     opts.idColumn,
+		VectorColumn: opts.vectorColumn,
+		IDsFile:      opts.idsFile,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if e := w.Close(); e != nil && err == nil {
+			err = e
+		}
+		if err != nil {
+			_ = os.Remove(output)
+			if opts.idsFile != "" {
+				_ = os.Remove(opts.idsFile)
+			}
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	startTime := time.Now()
+	ids := index.IDs()
+	logger.Info().Msgf("exporting %d vectors...", len(ids))
+
+	exported := 0
+	for _, id := range ids {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		vector, found := index.GetVector(id)
+		if !found {
+			continue
+		}
+		if err = w.Write(vecfile.Record{Vector: vector, ID: id, HasID: true}); err != nil {
+			return err
+		}
+		exported++
+	}
+
+	logger.Info().Msgf("%d vectors exported to %#v in %s", exported, output, time.Since(startTime).Round(time.Millisecond))
+	return nil
+}
+
+func (opts *exportOptions) fileFormat(name string) (vecfile.Format, error) {
+	if opts.format != "" {
+		return vecfile.FormatFromString(opts.format)
+	}
+	return vecfile.FormatFromFilename(name)
+}
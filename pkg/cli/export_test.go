@@ -0,0 +1,122 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli_test
+
+import (
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/cli"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/indexmanager"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path"
+	"testing"
+)
+
+func TestExportCommand(t *testing.T) {
+	t.Parallel()
+
+	input := writeFile(t, "vectors.csv", "id,x,y,z\n"+
+		"10,1,0,0\n"+
+		"20,0,1,0\n"+
+		"30,0,0,1\n")
+
+	newIndex := func(t *testing.T) string {
+		dataPath := t.TempDir()
+		err := cli.NewApp().Run([]string{"hnsw-grpc-server", "import",
+			"--data", dataPath, "--name", "foo", "--id-column", "id", input})
+		require.NoError(t, err)
+
+		// Deleted vectors are not exported
+		im := indexmanager.New(dataPath, zerolog.Nop())
+		require.NoError(t, im.LoadIndices())
+		index, ok := im.GetIndex("foo")
+		require.True(t, ok)
+		require.NoError(t, index.MarkDelete(20))
+		return dataPath
+	}
+
+	assertReimported := func(t *testing.T, dataPath string, args ...string) {
+		args = append([]string{"hnsw-grpc-server", "import", "--data", dataPath, "--name", "bar"}, args...)
+		require.NoError(t, cli.NewApp().Run(args))
+
+		im := indexmanager.New(dataPath, zerolog.Nop())
+		require.NoError(t, im.LoadIndices())
+		index, ok := im.GetIndex("bar")
+		require.True(t, ok)
+		assert.Equal(t, []uint32{10, 30}, index.IDs())
+		vector, found := index.GetVector(30)
+		require.True(t, found)
+		assert.Equal(t, []float32{0, 0, 1}, vector)
+	}
+
+	t.Run("JSONL", func(t *testing.T) {
+		t.Parallel()
+		dataPath := newIndex(t)
+		output := path.Join(t.TempDir(), "vectors.jsonl")
+
+		err := cli.NewApp().Run([]string{"hnsw-grpc-server", "export",
+			"--data", dataPath, "--name", "foo", output})
+		require.NoError(t, err)
+
+		assertReimported(t, dataPath, output)
+	})
+
+	t.Run("NPY with IDs", func(t *testing.T) {
+		t.Parallel()
+		dataPath := newIndex(t)
+		dir := t.TempDir()
+		output := path.Join(dir, "vectors.npy")
+		ids := path.Join(dir, "ids.npy")
+
+		err := cli.NewApp().Run([]string{"hnsw-grpc-server", "export",
+			"--data", dataPath, "--name", "foo", "--ids", ids, output})
+		require.NoError(t, err)
+
+		assertReimported(t, dataPath, "--ids", ids, output)
+	})
+
+	t.Run("fvecs with IDs", func(t *testing.T) {
+		t.Parallel()
+		dataPath := newIndex(t)
+		dir := t.TempDir()
+		output := path.Join(dir, "vectors.out")
+		ids := path.Join(dir, "ids.npy")
+
+		err := cli.NewApp().Run([]string{"hnsw-grpc-server", "export",
+			"--data", dataPath, "--name", "foo", "--format", "fvecs", "--ids", ids, output})
+		require.NoError(t, err)
+
+		assertReimported(t, dataPath, "--format", "fvecs", "--ids", ids, output)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		dataPath := newIndex(t)
+		dir := t.TempDir()
+
+		for _, args := range [][]string{
+			{"--name", "foo"},
+			{"--name", "foo", path.Join(dir, "a.jsonl"), path.Join(dir, "b.jsonl")},
+			{"--name", "bar", path.Join(dir, "a.jsonl")},
+			{"--name", "foo!", path.Join(dir, "a.jsonl")},
+			{"--name", "foo", path.Join(dir, "a.csv")},
+			{"--name", "foo", path.Join(dir, "a")},
+			{"--name", "foo", "--ids", path.Join(dir, "ids.npy"), path.Join(dir, "a.jsonl")},
+		} {
+			args = append([]string{"hnsw-grpc-server", "export", "--data", dataPath}, args...)
+			assert.Error(t, cli.NewApp().Run(args), args)
+		}
+	})
+}
@@ -0,0 +1,553 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/indexmanager"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/osutils"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/vecfile"
+	"github.com/rs/zerolog"
+	"github.com/urfave/cli/v2"
+	"io"
+	"os"
+	"os/signal"
+	"path"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// importBatchSize is the amount of vectors read and inserted at once by the
+// import command.
+const importBatchSize = 10000
+
+// importProgressInterval is the minimum interval between progress logs of
+// the import command.
+const importProgressInterval = 10 * time.Second
+
+// importOptions provides the settings of the import command.
+type importOptions struct {
+	dataPath       string
+	name           string
+	format         string
+	space          string
+	indexType      string
+	storage        string
+	trainingSize   int
+	pqSubs         int
+	pqBits         int
+	rerank         bool
+	maxElements    int
+	m              int
+	efConstruction int
+	seed           int
+	autoID         bool
+	replaceDeleted bool
+	defaultTTL     time.Duration
+	idColumn       string
+	vectorColumn   string
+	payloadColumns cli.StringSlice
+	idsFile        string
+	workers        int
+}
+
+func (app *App) importCommand() *cli.Command {
+	opts := &importOptions{}
+	return &cli.Command{
+		Name:      "import",
+		Usage:     "build a new index from vector files, without running the server",
+		ArgsUsage: "FILE...",
+		Description: "Vectors are read from .npy, .fvecs, .bvecs, .csv, .parquet or .jsonl\n" +
+			"files, and inserted with all available cores, skipping the write-ahead\n" +
+			"log. The index is written to the data folder only once it is complete,\n" +
+			"and it is loaded by the server at the next start.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "data",
+				Value:       "./hnsw-grpc-server-data",
+				Usage:       "path to the indices folder",
+				Destination: &opts.dataPath,
+			},
+			&cli.StringFlag{
+				Name:        "name",
+				Required:    true,
+				Usage:       "name of the new index",
+				Destination: &opts.name,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Usage:       "format of the input files: npy, fvecs, bvecs, csv, parquet or jsonl (default: from file extension)",
+				Destination: &opts.format,
+			},
+			&cli.StringFlag{
+				Name:        "space",
+				Value:       "l2",
+				Usage:       "vector space: l2, l1, ip, cosine, or hamming for binary vectors",
+				Destination: &opts.space,
+			},
+			&cli.StringFlag{
+				Name:        "index-type",
+				Value:       "hnsw",
+				Usage:       "index type: hnsw, or flat for exact searches on small collections",
+				Destination: &opts.indexType,
+			},
+			&cli.StringFlag{
+				Name:        "storage",
+				Value:       "float32",
+				Usage:       "format of the stored vectors: float32, or (hnsw only) float16, bfloat16, sq8 for scalar quantization or pq for product quantization",
+				Destination: &opts.storage,
+			},
+			&cli.IntFlag{
+				Name:        "training-size",
+				Value:       0,
+				Usage:       "number of vectors on which the sq8 or pq quantization is trained (0 = 1000 for sq8, 10000 for pq)",
+				Destination: &opts.trainingSize,
+			},
+			&cli.IntFlag{
+				Name:        "pq-subquantizers",
+				Usage:       "number of sub-vectors of pq indices, dividing the dimension",
+				Destination: &opts.pqSubs,
+			},
+			&cli.IntFlag{
+				Name:        "pq-bits",
+				Value:       hnswgo.DefaultPQBits,
+				Usage:       "size of the codes of pq indices, from 1 to 8",
+				Destination: &opts.pqBits,
+			},
+			&cli.BoolFlag{
+				Name:        "rerank",
+				Usage:       "keep the full-precision vectors of sq8 or pq indices on disk, to re-rank the search results",
+				Destination: &opts.rerank,
+			},
+			&cli.IntFlag{
+				Name:        "max-elements",
+				Value:       0,
+				Usage:       "maximum number of elements of the index (0 = number of imported vectors)",
+				Destination: &opts.maxElements,
+			},
+			&cli.IntFlag{
+				Name:        "m",
+				Value:       16,
+				Usage:       "HNSW \"M\" parameter",
+				Destination: &opts.m,
+			},
+			&cli.IntFlag{
+				Name:        "ef-construction",
+				Value:       200,
+				Usage:       "HNSW \"efConstruction\" parameter",
+				Destination: &opts.efConstruction,
+			},
+			&cli.IntFlag{
+				Name:        "seed",
+				Value:       100,
+				Usage:       "random seed",
+				Destination: &opts.seed,
+			},
+			&cli.BoolFlag{
+				Name:        "auto-id",
+				Usage:       "generate vector IDs, as for indices created with auto_id",
+				Destination: &opts.autoID,
+			},
+			&cli.BoolFlag{
+				Name:        "allow-replace-deleted",
+				Usage:       "let new vectors replace the vectors marked deleted, as for indices created with allow_replace_deleted",
+				Destination: &opts.replaceDeleted,
+			},
+			&cli.DurationFlag{
+				Name:        "default-ttl",
+				Usage:       "time to live of the vectors, from the import time (0 = no expiration)",
+				Destination: &opts.defaultTTL,
+			},
+			&cli.StringFlag{
+				Name:        "id-column",
+				Usage:       "CSV or Parquet column, or JSONL key, containing the vector IDs (default: \"id\" for JSONL)",
+				Destination: &opts.idColumn,
+			},
+			&cli.StringFlag{
+				Name:        "vector-column",
+				Usage:       "CSV or Parquet column, or JSONL key, containing whole vectors (default: all other CSV columns; \"vector\" otherwise)",
+				Destination: &opts.vectorColumn,
+			},
+			&cli.StringSliceFlag{
+				Name:        "payload-column",
+				Usage:       "CSV column to be skipped, since payloads are not stored (can be repeated)",
+				Destination: &opts.payloadColumns,
+			},
+			&cli.StringFlag{
+				Name:        "ids",
+				Usage:       "1-dimensional .npy array of IDs for the vectors of a .npy, .fvecs or .bvecs file",
+				Destination: &opts.idsFile,
+			},
+			&cli.IntFlag{
+				Name:        "workers",
+				Value:       0,
+				Usage:       "number of inserting workers (0 = number of CPUs)",
+				Destination: &opts.workers,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return app.importAction(c, opts)
+		},
+	}
+}
+
+func (app *App) importAction(c *cli.Context, opts *importOptions) (err error) {
+	logger := app.newLogger().With().Str("index", opts.name).Logger()
+
+	defer func() {
+		if err != nil {
+			logger.Err(err).Send()
+		}
+	}()
+
+	files := c.Args().Slice()
+	if len(files) == 0 {
+		return fmt.Errorf("no input files")
+	}
+	if err = opts.validate(files); err != nil {
+		return err
+	}
+
+	readers, err := opts.openFiles(files)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, r := range readers {
+			if e := r.Close(); e != nil {
+				logger.Warn().Err(e).Msg("error closing input file")
+			}
+		}
+	}()
+
+	total := 0
+	for _, r := range readers {
+		n, err := r.Len()
+		if err != nil {
+			return err
+		}
+		total += n
+	}
+	if total == 0 {
+		return fmt.Errorf("no vectors found in the input files")
+	}
+	if opts.maxElements == 0 {
+		opts.maxElements = total
+	}
+	if opts.maxElements < total {
+		return fmt.Errorf("--max-elements (%d) is less than the number of vectors to import (%d)", opts.maxElements, total)
+	}
+
+	dir := path.Join(opts.dataPath, opts.name)
+	exists, err := osutils.DirExists(dir)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("index dir %#v already exists", dir)
+	}
+	if err = os.MkdirAll(opts.dataPath, 0777); err != nil {
+		return fmt.Errorf("error creating dir %#v: %w", opts.dataPath, err)
+	}
+
+	// The index is built in a hidden dir, which is ignored when loading
+	// indices, and renamed only once it is complete.
+	tmpDir := path.Join(opts.dataPath, "."+opts.name+".import")
+	if err = os.RemoveAll(tmpDir); err != nil {
+		return fmt.Errorf("error removing dir %#v: %w", tmpDir, err)
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if e := os.RemoveAll(tmpDir); e != nil {
+			logger.Warn().Err(e).Msgf("error removing dir %#v", tmpDir)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger.Info().Msgf("importing %d vectors from %d files...", total, len(files))
+	startTime := time.Now()
+
+	im := &importer{opts: opts, dir: tmpDir, total: total, logger: logger}
+	index, err := im.run(ctx, readers)
+	if err != nil {
+		return err
+	}
+
+	logger.Info().Msg("saving index...")
+	if err = index.Save(ctx); err != nil {
+		return fmt.Errorf("error saving index: %w", err)
+	}
+	if err = os.Rename(tmpDir, dir); err != nil {
+		return fmt.Errorf("error moving index dir %#v to %#v: %w", tmpDir, dir, err)
+	}
+
+	logger.Info().Msgf("%d vectors imported to %#v in %s", im.imported, dir, time.Since(startTime).Round(time.Millisecond))
+	return nil
+}
+
+func (opts *importOptions) validate(files []string) error {
+	if !indexmanager.IsValidIndexName(opts.name) {
+		return fmt.Errorf("invalid index name %#v", opts.name)
+	}
+	if opts.autoID && (opts.idColumn != "" || opts.idsFile != "") {
+		return fmt.Errorf("IDs cannot be imported with --auto-id")
+	}
+	if opts.defaultTTL < 0 {
+		return fmt.Errorf("--default-ttl must not be negative")
+	}
+	indexType, err := hnswgo.IndexTypeFromString(opts.indexType)
+	if err != nil {
+		return err
+	}
+	storage, err := hnswgo.StorageTypeFromString(opts.storage)
+	if err != nil {
+		return err
+	}
+	if storage != hnswgo.Float32Storage && indexType != hnswgo.HNSWIndex {
+		return fmt.Errorf("--storage %s is only supported by hnsw indices", storage)
+	}
+	quantized := storage == hnswgo.SQ8Storage || storage == hnswgo.PQStorage
+	if opts.rerank && !quantized {
+		return fmt.Errorf("--rerank requires --storage sq8 or pq")
+	}
+	if opts.trainingSize < 0 {
+		return fmt.Errorf("--training-size must not be negative")
+	}
+	if storage == hnswgo.PQStorage && opts.pqSubs <= 0 {
+		return fmt.Errorf("--storage pq requires a positive --pq-subquantizers")
+	}
+	if opts.pqBits < 1 || opts.pqBits > 8 {
+		return fmt.Errorf("--pq-bits must be between 1 and 8")
+	}
+	if opts.idsFile != "" && len(files) > 1 {
+		return fmt.Errorf("--ids can only be used with a single input file")
+	}
+	return nil
+}
+
+func (opts *importOptions) spaceType() (hnswgo.SpaceType, error) {
+	return hnswgo.SpaceTypeFromString(opts.space)
+}
+
+func (opts *importOptions) openFiles(files []string) ([]vecfile.Reader, error) {
+	if _, err := opts.spaceType(); err != nil {
+		return nil, err
+	}
+
+	readerOpts := vecfile.Options{
+		IDColumn:       opts.idColumn,
+		VectorColumn:   opts.vectorColumn,
+		PayloadColumns: opts.payloadColumns.Value(),
+		IDsFile:        opts.idsFile,
+	}
+
+	readers := make([]vecfile.Reader, 0, len(files))
+	for _, name := range files {
+		r, err := opts.openFile(name, readerOpts)
+		if err != nil {
+			for _, r := range readers {
+				_ = r.Close()
+			}
+			return nil, err
+		}
+		readers = append(readers, r)
+	}
+	return readers, nil
+}
+
+func (opts *importOptions) openFile(name string, readerOpts vecfile.Options) (vecfile.Reader, error) {
+	format, err := opts.fileFormat(name)
+	if err != nil {
+		return nil, err
+	}
+	return vecfile.Open(name, format, readerOpts)
+}
+
+func (opts *importOptions) fileFormat(name string) (vecfile.Format, error) {
+	if opts.format != "" {
+		return vecfile.FormatFromString(opts.format)
+	}
+	return vecfile.FormatFromFilename(name)
+}
+
+// importer reads vectors in batches, inserting each batch while the next
+// one is being read.
+type importer struct {
+	opts     *importOptions
+	dir      string
+	total    int
+	logger   zerolog.Logger
+	index    *hnswgo.HNSW
+	imported int
+	lastLog  time.Time
+}
+
+type importBatch struct {
+	vectors [][]float32
+	ids     []uint32
+	err     error
+}
+
+func (im *importer) run(ctx context.Context, readers []vecfile.Reader) (*hnswgo.HNSW, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batches := make(chan importBatch, 1)
+	go im.read(ctx, readers, batches)
+	// The reading goroutine is stopped, and waited for, before returning,
+	// since the readers are closed afterwards.
+	defer func() {
+		cancel()
+		for range batches {
+		}
+	}()
+
+	workers := im.opts.workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	im.lastLog = time.Now()
+
+	for batch := range batches {
+		if batch.err != nil {
+			return nil, batch.err
+		}
+		if err := im.insert(ctx, batch, workers); err != nil {
+			return nil, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if im.index == nil {
+		return nil, fmt.Errorf("no vectors found in the input files")
+	}
+	return im.index, nil
+}
+
+func (im *importer) read(ctx context.Context, readers []vecfile.Reader, batches chan<- importBatch) {
+	defer close(batches)
+
+	send := func(batch importBatch) bool {
+		select {
+		case batches <- batch:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	batch := importBatch{}
+	n := 0
+	for _, r := range readers {
+		for ; ; n++ {
+			rec, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err == nil && !rec.HasID && !im.opts.autoID {
+				err = fmt.Errorf("vector %d has no ID: use --id-column or --ids, or enable --auto-id", n)
+			}
+			if err != nil {
+				send(importBatch{err: err})
+				return
+			}
+			// With auto-ID, any ID found in the files is ignored.
+			batch.vectors = append(batch.vectors, rec.Vector)
+			batch.ids = append(batch.ids, rec.ID)
+			if len(batch.vectors) == importBatchSize {
+				if !send(batch) {
+					return
+				}
+				batch = importBatch{}
+			}
+		}
+	}
+	if len(batch.vectors) > 0 {
+		send(batch)
+	}
+}
+
+func (im *importer) insert(ctx context.Context, batch importBatch, workers int) (err error) {
+	if im.index == nil {
+		im.index, err = im.newIndex(len(batch.vectors[0]))
+		if err != nil {
+			return err
+		}
+	}
+
+	if im.opts.autoID {
+		_, err = im.index.ImportPointsAutoID(ctx, batch.vectors, workers)
+	} else {
+		err = im.index.ImportPoints(ctx, batch.vectors, batch.ids, workers)
+	}
+	if err != nil {
+		return fmt.Errorf("error importing vectors %d-%d: %w", im.imported, im.imported+len(batch.vectors)-1, err)
+	}
+
+	im.imported += len(batch.vectors)
+	if time.Since(im.lastLog) >= importProgressInterval {
+		im.logger.Info().Msgf("%d/%d vectors imported (%.1f%%)",
+			im.imported, im.total, float64(im.imported)*100/float64(im.total))
+		im.lastLog = time.Now()
+	}
+	return nil
+}
+
+func (im *importer) newIndex(dim int) (*hnswgo.HNSW, error) {
+	spaceType, err := im.opts.spaceType()
+	if err != nil {
+		return nil, err
+	}
+	indexType, err := hnswgo.IndexTypeFromString(im.opts.indexType)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := hnswgo.StorageTypeFromString(im.opts.storage)
+	if err != nil {
+		return nil, err
+	}
+	config := hnswgo.Config{
+		SpaceType:           spaceType,
+		Dim:                 dim,
+		MaxElements:         im.opts.maxElements,
+		M:                   im.opts.m,
+		EfConstruction:      im.opts.efConstruction,
+		RandSeed:            im.opts.seed,
+		AutoIDEnabled:       im.opts.autoID,
+		AllowReplaceDeleted: im.opts.replaceDeleted,
+		IndexType:           indexType,
+		Storage:             storage,
+		TrainingSize:        im.opts.trainingSize,
+		PQSubquantizers:     im.opts.pqSubs,
+		PQBits:              im.opts.pqBits,
+		Rerank:              im.opts.rerank,
+		DefaultTTL:          im.opts.defaultTTL,
+	}
+	// The number of PQ sub-quantizers must divide the dimension, which is
+	// only known now.
+	if err = config.ValidateStorage(); err != nil {
+		return nil, err
+	}
+	im.logger.Info().Msgf("creating index with dimension %d", dim)
+	return hnswgo.New(im.dir, config, im.logger), nil
+}
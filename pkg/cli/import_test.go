@@ -0,0 +1,207 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli_test
+
+import (
+	"fmt"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/cli"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/indexmanager"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportCommand(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CSV with IDs", func(t *testing.T) {
+		t.Parallel()
+		dataPath := path.Join(t.TempDir(), "data")
+		input := writeFile(t, "vectors.csv", "id,title,vector\n"+
+			"10,foo,\"[1, 0, 0]\"\n"+
+			"20,bar,\"[0, 1, 0]\"\n"+
+			"30,baz,\"[0, 0, 1]\"\n")
+
+		err := cli.NewApp().Run([]string{"hnsw-grpc-server", "import",
+			"--data", dataPath, "--name", "foo", "--space", "ip",
+			"--id-column", "id", "--vector-column", "vector", input})
+		require.NoError(t, err)
+
+		im := indexmanager.New(dataPath, zerolog.Nop())
+		require.NoError(t, im.LoadIndices())
+		assert.Equal(t, []string{"foo"}, im.IndicesNames())
+
+		index, ok := im.GetIndex("foo")
+		require.True(t, ok)
+		assert.Equal(t, 3, index.Config().Dim)
+		assert.Equal(t, 3, index.Config().MaxElements)
+		results := index.SearchKNN([]float32{0, 1, 0}, 1)
+		require.Len(t, results, 1)
+		assert.Equal(t, uint32(20), results[0].ID)
+	})
+
+	t.Run("half-precision storage", func(t *testing.T) {
+		t.Parallel()
+		dataPath := path.Join(t.TempDir(), "data")
+		input := writeFile(t, "vectors.csv", "id,x,y,z\n10,1,0,0\n20,0,1,0\n30,0,0,1\n")
+
+		err := cli.NewApp().Run([]string{"hnsw-grpc-server", "import",
+			"--data", dataPath, "--name", "foo", "--id-column", "id", "--storage", "bfloat16", input})
+		require.NoError(t, err)
+
+		im := indexmanager.New(dataPath, zerolog.Nop())
+		require.NoError(t, im.LoadIndices())
+		index, ok := im.GetIndex("foo")
+		require.True(t, ok)
+		assert.Equal(t, hnswgo.BFloat16Storage, index.Config().Storage)
+		results := index.SearchKNN([]float32{0, 1, 0}, 1)
+		require.Len(t, results, 1)
+		assert.Equal(t, uint32(20), results[0].ID)
+	})
+
+	t.Run("SQ8 storage", func(t *testing.T) {
+		t.Parallel()
+		dataPath := path.Join(t.TempDir(), "data")
+		input := writeFile(t, "vectors.csv", "id,x,y,z\n10,1,0,0\n20,0,1,0\n30,0,0,1\n")
+
+		err := cli.NewApp().Run([]string{"hnsw-grpc-server", "import",
+			"--data", dataPath, "--name", "foo", "--id-column", "id",
+			"--storage", "sq8", "--training-size", "2", "--rerank", input})
+		require.NoError(t, err)
+
+		im := indexmanager.New(dataPath, zerolog.Nop())
+		require.NoError(t, im.LoadIndices())
+		index, ok := im.GetIndex("foo")
+		require.True(t, ok)
+		assert.Equal(t, hnswgo.SQ8Storage, index.Config().Storage)
+		assert.Equal(t, 2, index.Config().TrainingSize)
+		assert.True(t, index.Config().Rerank)
+		results := index.SearchKNN([]float32{0, 1, 0}, 1)
+		require.Len(t, results, 1)
+		assert.Equal(t, uint32(20), results[0].ID)
+		assert.Equal(t, float32(0), results[0].Distance)
+	})
+
+	t.Run("PQ storage", func(t *testing.T) {
+		t.Parallel()
+		dataPath := path.Join(t.TempDir(), "data")
+		input := writeFile(t, "vectors.csv", "id,x,y,z\n10,1,0,0\n20,0,1,0\n30,0,0,1\n")
+
+		err := cli.NewApp().Run([]string{"hnsw-grpc-server", "import",
+			"--data", dataPath, "--name", "foo", "--id-column", "id", "--storage", "pq",
+			"--pq-subquantizers", "3", "--pq-bits", "2", "--training-size", "3", "--rerank", input})
+		require.NoError(t, err)
+
+		im := indexmanager.New(dataPath, zerolog.Nop())
+		require.NoError(t, im.LoadIndices())
+		index, ok := im.GetIndex("foo")
+		require.True(t, ok)
+		assert.Equal(t, hnswgo.PQStorage, index.Config().Storage)
+		assert.Equal(t, 3, index.Config().PQSubquantizers)
+		assert.Equal(t, 2, index.Config().PQBits)
+		results := index.SearchKNN([]float32{0, 1, 0}, 1)
+		require.Len(t, results, 1)
+		assert.Equal(t, uint32(20), results[0].ID)
+		assert.Equal(t, float32(0), results[0].Distance)
+	})
+
+	t.Run("auto-ID from multiple files", func(t *testing.T) {
+		t.Parallel()
+		dataPath := t.TempDir()
+		input1 := writeFile(t, "a.csv", "x,y\n1,0\n0,1\n")
+		input2 := writeFile(t, "b.csv", "x,y\n1,1\n")
+
+		err := cli.NewApp().Run([]string{"hnsw-grpc-server", "import",
+			"--data", dataPath, "--name", "foo", "--auto-id", "--max-elements", "10",
+			"--allow-replace-deleted", "--default-ttl", "1h", "--index-type", "flat", input1, input2})
+		require.NoError(t, err)
+
+		im := indexmanager.New(dataPath, zerolog.Nop())
+		require.NoError(t, im.LoadIndices())
+		index, ok := im.GetIndex("foo")
+		require.True(t, ok)
+		assert.Equal(t, 10, index.Config().MaxElements)
+		assert.Equal(t, hnswgo.FlatIndex, index.Config().IndexType)
+		assert.True(t, index.Config().AllowReplaceDeleted)
+		assert.Equal(t, time.Hour, index.Config().DefaultTTL)
+		assert.Equal(t, 3, index.ExpiryStats().Pending)
+
+		id, err := index.AddPointAutoID([]float32{2, 2})
+		require.NoError(t, err)
+		assert.Equal(t, uint32(4), id)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		dataPath := t.TempDir()
+		valid := writeFile(t, "valid.csv", "id,x,y\n1,1,0\n")
+		invalid := writeFile(t, "invalid.csv", "id,x,y\n1,1,0\n2,1,foo\n")
+		require.NoError(t, os.Mkdir(path.Join(dataPath, "existing"), 0777))
+
+		for _, args := range [][]string{
+			{"--name", "foo", valid},
+			{"--name", "foo", "--auto-id", "--id-column", "id", valid},
+			{"--name", "foo", "--id-column", "id", "--max-elements", "1", valid, valid},
+			{"--name", "foo", "--id-column", "id", "--space", "foo", valid},
+			{"--name", "foo", "--id-column", "id", "--default-ttl", "-1h", valid},
+			{"--name", "foo", "--id-column", "id", "--index-type", "foo", valid},
+			{"--name", "foo", "--id-column", "id", "--storage", "foo", valid},
+			{"--name", "foo", "--id-column", "id", "--storage", "sq8", "--index-type", "flat", valid},
+			{"--name", "foo", "--id-column", "id", "--rerank", valid},
+			{"--name", "foo", "--id-column", "id", "--storage", "pq", valid},
+			{"--name", "foo", "--id-column", "id", "--storage", "float16", "--index-type", "flat", valid},
+			{"--name", "foo", "--id-column", "id", "--storage", "bfloat16", "--rerank", valid},
+			{"--name", "foo", "--id-column", "id", "--storage", "pq", "--pq-subquantizers", "3", valid},
+			{"--name", "foo", "--id-column", "id", "--storage", "pq", "--pq-subquantizers", "2", "--pq-bits", "9", valid},
+			{"--name", "foo", "--id-column", "id", invalid},
+			{"--name", "foo", "--id-column", "id", "--format", "npy", valid},
+			{"--name", "foo!", "--id-column", "id", valid},
+			{"--name", "existing", "--id-column", "id", valid},
+			{"--name", "foo", "--id-column", "id"},
+		} {
+			args = append([]string{"hnsw-grpc-server", "import", "--data", dataPath}, args...)
+			assert.Error(t, cli.NewApp().Run(args), args)
+		}
+
+		// The insertion of the first batch fails while the next one is read.
+		var lines strings.Builder
+		lines.WriteString(`{"id": 1, "vector": [1, 0]}` + "\n")
+		for i := 2; i <= 20001; i++ {
+			fmt.Fprintf(&lines, `{"id": %d, "vector": [1, 0, 0]}`+"\n", i)
+		}
+		mixed := writeFile(t, "mixed.jsonl", lines.String())
+		err := cli.NewApp().Run([]string{"hnsw-grpc-server", "import", "--data", dataPath, "--name", "foo", mixed})
+		assert.Error(t, err)
+
+		// No partial index is left behind
+		files, err := os.ReadDir(dataPath)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		assert.Equal(t, "existing", files[0].Name())
+	})
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	name = path.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(name, []byte(content), 0666))
+	return name
+}
@@ -0,0 +1,408 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/grpcapi"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/urfave/cli/v2"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (app *App) indicesCommand() *cli.Command {
+	opts := &clientOptions{}
+	return &cli.Command{
+		Name:  "indices",
+		Usage: "list the indices of a running server",
+		Flags: opts.flags(),
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() != 0 {
+				return fmt.Errorf("no arguments are expected")
+			}
+			return opts.withClient(c, func(ctx context.Context, client grpcapi.ServerClient) error {
+				reply, err := client.Indices(ctx, &emptypb.Empty{})
+				if err != nil {
+					return err
+				}
+				if opts.output == jsonOutput {
+					return writeJSON(c.App.Writer, reply)
+				}
+				names := append([]string(nil), reply.GetIndices()...)
+				sort.Strings(names)
+				rows := make([][]string, len(names))
+				for i, name := range names {
+					rows[i] = []string{name}
+				}
+				return writeTable(c.App.Writer, []string{"NAME"}, rows)
+			})
+		},
+	}
+}
+
+// createOptions provides the settings of the create command.
+type createOptions struct {
+	clientOptions
+	space          string
+	indexType      string
+	storage        string
+	trainingSize   int
+	pqSubs         int
+	pqBits         int
+	rerank         bool
+	dim            int
+	maxElements    int
+	m              int
+	efConstruction int
+	seed           int
+	autoID         bool
+	replaceDeleted bool
+	defaultTTL     time.Duration
+}
+
+func (app *App) createCommand() *cli.Command {
+	opts := &createOptions{}
+	return &cli.Command{
+		Name:      "create",
+		Usage:     "create a new index on a running server",
+		ArgsUsage: "NAME",
+		Flags: append(opts.flags(),
+			&cli.IntFlag{
+				Name:        "dim",
+				Required:    true,
+				Usage:       "dimension of the vectors (number of bits, for hamming spaces)",
+				Destination: &opts.dim,
+			},
+			&cli.IntFlag{
+				Name:        "max-elements",
+				Required:    true,
+				Usage:       "maximum number of elements of the index",
+				Destination: &opts.maxElements,
+			},
+			&cli.StringFlag{
+				Name:        "space",
+				Value:       "l2",
+				Usage:       "vector space: l2, l1, ip, cosine, or hamming for binary vectors",
+				Destination: &opts.space,
+			},
+			&cli.StringFlag{
+				Name:        "index-type",
+				Value:       "hnsw",
+				Usage:       "index type: hnsw, or flat for exact searches on small collections",
+				Destination: &opts.indexType,
+			},
+			&cli.StringFlag{
+				Name:        "storage",
+				Value:       "float32",
+				Usage:       "format of the stored vectors: float32, or (hnsw only) float16, bfloat16, sq8 for scalar quantization or pq for product quantization",
+				Destination: &opts.storage,
+			},
+			&cli.IntFlag{
+				Name:        "training-size",
+				Value:       0,
+				Usage:       "number of vectors on which the sq8 or pq quantization is trained (0 = 1000 for sq8, 10000 for pq)",
+				Destination: &opts.trainingSize,
+			},
+			&cli.IntFlag{
+				Name:        "pq-subquantizers",
+				Usage:       "number of sub-vectors of pq indices, dividing the dimension",
+				Destination: &opts.pqSubs,
+			},
+			&cli.IntFlag{
+				Name:        "pq-bits",
+				Value:       hnswgo.DefaultPQBits,
+				Usage:       "size of the codes of pq indices, from 1 to 8",
+				Destination: &opts.pqBits,
+			},
+			&cli.BoolFlag{
+				Name:        "rerank",
+				Usage:       "keep the full-precision vectors of sq8 or pq indices on disk, to re-rank the search results",
+				Destination: &opts.rerank,
+			},
+			&cli.IntFlag{
+				Name:        "m",
+				Value:       16,
+				Usage:       "HNSW \"M\" parameter",
+				Destination: &opts.m,
+			},
+			&cli.IntFlag{
+				Name:        "ef-construction",
+				Value:       200,
+				Usage:       "HNSW \"efConstruction\" parameter",
+				Destination: &opts.efConstruction,
+			},
+			&cli.IntFlag{
+				Name:        "seed",
+				Value:       100,
+				Usage:       "random seed",
+				Destination: &opts.seed,
+			},
+			&cli.BoolFlag{
+				Name:        "auto-id",
+				Usage:       "generate the IDs of the inserted vectors",
+				Destination: &opts.autoID,
+			},
+			&cli.BoolFlag{
+				Name:        "allow-replace-deleted",
+				Usage:       "let new vectors replace the vectors marked deleted",
+				Destination: &opts.replaceDeleted,
+			},
+			&cli.DurationFlag{
+				Name:        "default-ttl",
+				Usage:       "time to live of the vectors, unless set otherwise on insertion (0 = no expiration)",
+				Destination: &opts.defaultTTL,
+			},
+		),
+		Action: func(c *cli.Context) error {
+			return app.createAction(c, opts)
+		},
+	}
+}
+
+func (app *App) createAction(c *cli.Context, opts *createOptions) error {
+	args, err := exactArgs(c, "NAME")
+	if err != nil {
+		return err
+	}
+	spaceType, err := protoEnum("space type", grpcapi.CreateIndexRequest_SpaceType_value, opts.space)
+	if err != nil {
+		return err
+	}
+	indexType, err := protoEnum("index type", grpcapi.CreateIndexRequest_IndexType_value, opts.indexType)
+	if err != nil {
+		return err
+	}
+	storageType, err := protoEnum("storage type", grpcapi.CreateIndexRequest_StorageType_value, opts.storage)
+	if err != nil {
+		return err
+	}
+	if opts.defaultTTL < 0 {
+		return fmt.Errorf("--default-ttl must not be negative")
+	}
+
+	req := &grpcapi.CreateIndexRequest{
+		IndexName:           args[0],
+		Dim:                 int32(opts.dim),
+		EfConstruction:      int32(opts.efConstruction),
+		M:                   int32(opts.m),
+		MaxElements:         int32(opts.maxElements),
+		Seed:                int32(opts.seed),
+		SpaceType:           grpcapi.CreateIndexRequest_SpaceType(spaceType),
+		AutoId:              opts.autoID,
+		AllowReplaceDeleted: opts.replaceDeleted,
+		DefaultTtlSeconds:   int64(opts.defaultTTL / time.Second),
+		IndexType:           grpcapi.CreateIndexRequest_IndexType(indexType),
+		StorageType:         grpcapi.CreateIndexRequest_StorageType(storageType),
+		TrainingSize:        int32(opts.trainingSize),
+		Rerank:              opts.rerank,
+		PqSubquantizers:     int32(opts.pqSubs),
+		PqBits:              int32(opts.pqBits),
+	}
+	return opts.withClient(c, func(ctx context.Context, client grpcapi.ServerClient) error {
+		reply, err := client.CreateIndex(ctx, req)
+		if err != nil {
+			return err
+		}
+		return opts.writeEmpty(c, reply)
+	})
+}
+
+func (app *App) deleteCommand() *cli.Command {
+	opts := &clientOptions{}
+	return &cli.Command{
+		Name:      "delete",
+		Usage:     "delete an index of a running server, with all its files",
+		ArgsUsage: "NAME",
+		Flags:     opts.flags(),
+		Action: func(c *cli.Context) error {
+			args, err := exactArgs(c, "NAME")
+			if err != nil {
+				return err
+			}
+			return opts.withClient(c, func(ctx context.Context, client grpcapi.ServerClient) error {
+				reply, err := client.DeleteIndex(ctx, &grpcapi.DeleteIndexRequest{IndexName: args[0]})
+				if err != nil {
+					return err
+				}
+				return opts.writeEmpty(c, reply)
+			})
+		},
+	}
+}
+
+func (app *App) flushCommand() *cli.Command {
+	opts := &clientOptions{}
+	return &cli.Command{
+		Name:      "flush",
+		Usage:     "save an index of a running server to its files",
+		ArgsUsage: "NAME",
+		Flags:     opts.flags(),
+		Action: func(c *cli.Context) error {
+			args, err := exactArgs(c, "NAME")
+			if err != nil {
+				return err
+			}
+			return opts.withClient(c, func(ctx context.Context, client grpcapi.ServerClient) error {
+				reply, err := client.FlushIndex(ctx, &grpcapi.FlushRequest{IndexName: args[0]})
+				if err != nil {
+					return err
+				}
+				return opts.writeEmpty(c, reply)
+			})
+		},
+	}
+}
+
+func (app *App) setEfCommand() *cli.Command {
+	opts := &clientOptions{}
+	return &cli.Command{
+		Name:      "set-ef",
+		Usage:     "set the \"ef\" parameter of an index of a running server",
+		ArgsUsage: "NAME EF",
+		Flags:     opts.flags(),
+		Action: func(c *cli.Context) error {
+			args, err := exactArgs(c, "NAME", "EF")
+			if err != nil {
+				return err
+			}
+			ef, err := strconv.ParseInt(args[1], 10, 32)
+			if err != nil || ef <= 0 {
+				return fmt.Errorf("invalid ef %#v: it must be a positive integer", args[1])
+			}
+			return opts.withClient(c, func(ctx context.Context, client grpcapi.ServerClient) error {
+				reply, err := client.SetEf(ctx, &grpcapi.SetEfRequest{IndexName: args[0], Value: int32(ef)})
+				if err != nil {
+					return err
+				}
+				return opts.writeEmpty(c, reply)
+			})
+		},
+	}
+}
+
+func (app *App) describeCommand() *cli.Command {
+	opts := &clientOptions{}
+	return &cli.Command{
+		Name:      "describe",
+		Usage:     "describe the configuration and the statistics of an index of a running server",
+		ArgsUsage: "NAME",
+		Flags:     opts.flags(),
+		Action: func(c *cli.Context) error {
+			args, err := exactArgs(c, "NAME")
+			if err != nil {
+				return err
+			}
+			return opts.withClient(c, func(ctx context.Context, client grpcapi.ServerClient) error {
+				reply, err := client.DescribeIndex(ctx, &grpcapi.DescribeIndexRequest{IndexName: args[0]})
+				if err != nil {
+					return err
+				}
+				if opts.output == jsonOutput {
+					return writeJSON(c.App.Writer, reply)
+				}
+				return writeTable(c.App.Writer, nil, describeRows(reply))
+			})
+		},
+	}
+}
+
+// describeRows returns the fields of the reply of DescribeIndex, as pairs
+// of name and value.
+func describeRows(r *grpcapi.DescribeIndexReply) [][]string {
+	rows := [][]string{
+		{"name", r.GetIndexName()},
+		{"status", enumName(r.GetStatus())},
+	}
+	if r.GetStatus() != grpcapi.IndexStatus_READY {
+		if r.GetLoadError() != "" {
+			rows = append(rows, []string{"load error", r.GetLoadError()})
+		}
+		return rows
+	}
+
+	add := func(name string, value interface{}) {
+		rows = append(rows, []string{name, fmt.Sprint(value)})
+	}
+	add("index type", enumName(r.GetIndexType()))
+	add("space type", enumName(r.GetSpaceType()))
+	add("storage", enumName(r.GetStorageType()))
+	add("dim", r.GetDim())
+	add("size", r.GetSize())
+	add("max elements", r.GetMaxElements())
+	add("M", r.GetM())
+	add("ef construction", r.GetEfConstruction())
+	add("seed", r.GetSeed())
+	add("auto ID", r.GetAutoId())
+	add("allow replace deleted", r.GetAllowReplaceDeleted())
+	switch r.GetStorageType() {
+	case grpcapi.CreateIndexRequest_SQ8, grpcapi.CreateIndexRequest_PQ:
+		add("training size", r.GetTrainingSize())
+		add("rerank", r.GetRerank())
+	}
+	if r.GetStorageType() == grpcapi.CreateIndexRequest_PQ {
+		add("PQ subquantizers", r.GetPqSubquantizers())
+		add("PQ bits", r.GetPqBits())
+	}
+	if ttl := r.GetDefaultTtlSeconds(); ttl > 0 {
+		add("default TTL", time.Duration(ttl)*time.Second)
+	}
+	add("resident", r.GetResident())
+	expiry := r.GetExpiry()
+	add("pending expirations", expiry.GetPending())
+	if next := expiry.GetNextExpiration(); next > 0 {
+		add("next expiration", time.Unix(next, 0).UTC().Format(time.RFC3339))
+	}
+	add("expired", expiry.GetExpired())
+	return rows
+}
+
+// writeEmpty writes the empty reply of a request, which is only shown as
+// JSON.
+func (opts *clientOptions) writeEmpty(c *cli.Context, reply *emptypb.Empty) error {
+	if opts.output == jsonOutput {
+		return writeJSON(c.App.Writer, reply)
+	}
+	return nil
+}
+
+// exactArgs returns the arguments of the command, failing unless they are
+// as many as names.
+func exactArgs(c *cli.Context, names ...string) ([]string, error) {
+	if c.Args().Len() != len(names) {
+		return nil, fmt.Errorf("expected arguments: %s", strings.Join(names, " "))
+	}
+	return c.Args().Slice(), nil
+}
+
+// protoEnum returns the value of the Protocol Buffers enum with the given
+// name, which is case-insensitive.
+func protoEnum(kind string, values map[string]int32, name string) (int32, error) {
+	value, ok := values[strings.ToUpper(name)]
+	if !ok {
+		return 0, fmt.Errorf("invalid %s %#v", kind, name)
+	}
+	return value, nil
+}
+
+// enumName returns the name of a Protocol Buffers enum value in lower case,
+// as accepted by the command line flags.
+func enumName(value fmt.Stringer) string {
+	return strings.ToLower(value.String())
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli_test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestIndexCommands(t *testing.T) {
+	t.Parallel()
+	address := startServer(t)
+	run := func(args ...string) (string, error) {
+		return runClient(t, append(args[:1:1], append([]string{"--address", address}, args[1:]...)...)...)
+	}
+
+	out, err := run("create", "--dim", "3", "--max-elements", "10", "--space", "cosine",
+		"--auto-id", "--default-ttl", "1h", "foo")
+	require.NoError(t, err)
+	assert.Empty(t, out)
+	out, err = run("create", "--dim", "4", "--max-elements", "10", "--storage", "sq8", "-o", "json", "bar")
+	require.NoError(t, err)
+	assert.Equal(t, "{}\n", out)
+
+	_, err = run("create", "--dim", "3", "--max-elements", "10", "--space", "foo", "baz")
+	assert.EqualError(t, err, "invalid space type \"foo\"")
+	_, err = run("create", "--dim", "3", "--max-elements", "10", "--storage", "pq", "baz")
+	assert.Error(t, err)
+	_, err = run("create", "--dim", "3", "--max-elements", "10", "foo")
+	assert.Error(t, err)
+	_, err = run("create", "--dim", "3", "--max-elements", "10")
+	assert.EqualError(t, err, "expected arguments: NAME")
+
+	out, err = run("indices")
+	require.NoError(t, err)
+	assert.Equal(t, "NAME\nbar\nfoo\n", out)
+
+	out, err = run("describe", "foo")
+	require.NoError(t, err)
+	assert.Contains(t, out, "name                   foo\n")
+	assert.Contains(t, out, "status                 ready\n")
+	assert.Contains(t, out, "space type             cosine\n")
+	assert.Contains(t, out, "default TTL            1h0m0s\n")
+	assert.NotContains(t, out, "training size")
+
+	out, err = run("describe", "-o", "json", "bar")
+	require.NoError(t, err)
+	var description map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &description))
+	assert.Equal(t, "bar", description["indexName"])
+	assert.Equal(t, "SQ8", description["storageType"])
+	assert.Equal(t, float64(4), description["dim"])
+
+	_, err = run("set-ef", "foo", "42")
+	assert.NoError(t, err)
+	_, err = run("set-ef", "foo", "0")
+	assert.Error(t, err)
+	_, err = run("set-ef", "baz", "42")
+	assert.Error(t, err)
+
+	_, err = run("flush", "foo")
+	assert.NoError(t, err)
+	_, err = run("flush", "baz")
+	assert.Error(t, err)
+
+	_, err = run("delete", "bar")
+	assert.NoError(t, err)
+	_, err = run("delete", "bar")
+	assert.Error(t, err)
+	out, err = run("indices", "-o", "json")
+	require.NoError(t, err)
+	var indices map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &indices))
+	assert.Equal(t, []interface{}{"foo"}, indices["indices"])
+}
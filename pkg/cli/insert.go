@@ -0,0 +1,269 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/grpcapi"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/vecfile"
+	"github.com/urfave/cli/v2"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"time"
+)
+
+// insertOptions provides the settings of the insert command.
+type insertOptions struct {
+	clientOptions
+	format         string
+	idColumn       string
+	vectorColumn   string
+	payloadColumns cli.StringSlice
+	idsFile        string
+	ttl            time.Duration
+}
+
+func (app *App) insertCommand() *cli.Command {
+	opts := &insertOptions{}
+	return &cli.Command{
+		Name:      "insert",
+		Usage:     "insert the vectors of files in an index of a running server",
+		ArgsUsage: "NAME FILE...",
+		Description: "Vectors are read from .npy, .fvecs, .bvecs, .csv, .parquet or .jsonl\n" +
+			"files, as by the import command, and sent to the server in a single\n" +
+			"stream, which is saved once all the vectors are inserted. For\n" +
+			"indices with auto-ID, any ID found in the files is ignored, and the\n" +
+			"generated IDs are part of the JSON output. If a file cannot be read,\n" +
+			"the stream is aborted, and the number of vectors already sent is\n" +
+			"reported: some of them might have been inserted anyway.",
+		Flags: append(opts.flags(),
+			&cli.StringFlag{
+				Name:        "format",
+				Usage:       "format of the input files: npy, fvecs, bvecs, csv, parquet or jsonl (default: from file extension)",
+				Destination: &opts.format,
+			},
+			&cli.StringFlag{
+				Name:        "id-column",
+				Usage:       "CSV or Parquet column, or JSONL key, containing the vector IDs (default: \"id\" for JSONL)",
+				Destination: &opts.idColumn,
+			},
+			&cli.StringFlag{
+				Name:        "vector-column",
+				Usage:       "CSV or Parquet column, or JSONL key, containing whole vectors (default: all other CSV columns; \"vector\" otherwise)",
+				Destination: &opts.vectorColumn,
+			},
+			&cli.StringSliceFlag{
+				Name:        "payload-column",
+				Usage:       "CSV column to be skipped, since payloads are not stored (can be repeated)",
+				Destination: &opts.payloadColumns,
+			},
+			&cli.StringFlag{
+				Name:        "ids",
+				Usage:       "1-dimensional .npy array of IDs for the vectors of a .npy, .fvecs or .bvecs file",
+				Destination: &opts.idsFile,
+			},
+			&cli.DurationFlag{
+				Name:        "ttl",
+				Usage:       "time to live of the vectors (0 = default TTL of the index, negative = no expiration)",
+				Destination: &opts.ttl,
+			},
+		),
+		Action: func(c *cli.Context) error {
+			return app.insertAction(c, opts)
+		},
+	}
+}
+
+func (app *App) insertAction(c *cli.Context, opts *insertOptions) error {
+	if c.Args().Len() < 2 {
+		return fmt.Errorf("expected arguments: NAME FILE...")
+	}
+	name := c.Args().First()
+	files := c.Args().Tail()
+	if opts.idsFile != "" && len(files) > 1 {
+		return fmt.Errorf("--ids can only be used with a single input file")
+	}
+	if opts.ttl > 0 && opts.ttl < time.Second {
+		return fmt.Errorf("--ttl must be at least 1s")
+	}
+
+	readers, err := opts.openFiles(files)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, r := range readers {
+			_ = r.Close()
+		}
+	}()
+
+	return opts.withClient(c, func(ctx context.Context, client grpcapi.ServerClient) error {
+		description, err := client.DescribeIndex(ctx, &grpcapi.DescribeIndexRequest{IndexName: name})
+		if err != nil {
+			return err
+		}
+		if status := description.GetStatus(); status != grpcapi.IndexStatus_READY {
+			return fmt.Errorf("index %#v is not ready: its status is %s", name, enumName(status))
+		}
+
+		var reply proto.Message
+		var inserted int
+		if description.GetAutoId() {
+			reply, inserted, err = opts.insertAutoID(ctx, client, name, readers)
+		} else {
+			reply, inserted, err = opts.insertWithIDs(ctx, client, name, readers)
+		}
+		if err != nil {
+			return err
+		}
+
+		if opts.output == jsonOutput {
+			return writeJSON(c.App.Writer, reply)
+		}
+		_, err = fmt.Fprintf(c.App.Writer, "%d vectors inserted\n", inserted)
+		return err
+	})
+}
+
+func (opts *insertOptions) openFiles(files []string) ([]vecfile.Reader, error) {
+	readerOpts := vecfile.Options{
+		IDColumn:       opts.idColumn,
+		VectorColumn:   opts.vectorColumn,
+		PayloadColumns: opts.payloadColumns.Value(),
+		IDsFile:        opts.idsFile,
+	}
+
+	readers := make([]vecfile.Reader, 0, len(files))
+	for _, name := range files {
+		format, err := vecfile.FormatFromFilename(name)
+		if opts.format != "" {
+			format, err = vecfile.FormatFromString(opts.format)
+		}
+		var r vecfile.Reader
+		if err == nil {
+			r, err = vecfile.Open(name, format, readerOpts)
+		}
+		if err != nil {
+			for _, r := range readers {
+				_ = r.Close()
+			}
+			return nil, err
+		}
+		readers = append(readers, r)
+	}
+	return readers, nil
+}
+
+// ttlSeconds returns the TTL of the inserted vectors, as sent to the server.
+func (opts *insertOptions) ttlSeconds() int64 {
+	if opts.ttl < 0 {
+		return -1
+	}
+	return int64(opts.ttl / time.Second)
+}
+
+// insertAutoID streams the vectors to an index with auto-ID, returning the
+// reply and the number of vectors sent.
+func (opts *insertOptions) insertAutoID(
+	ctx context.Context,
+	client grpcapi.ServerClient,
+	name string,
+	readers []vecfile.Reader,
+) (proto.Message, int, error) {
+	// The stream is aborted by canceling the context: closing it would
+	// make the server insert the vectors sent so far.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := client.InsertVectors(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	n, err := readRecords(readers, func(n int, rec vecfile.Record) error {
+		return stream.Send(&grpcapi.InsertVectorRequest{
+			IndexName:  name,
+			Vector:     &grpcapi.Vector{Value: rec.Vector},
+			TtlSeconds: opts.ttlSeconds(),
+		})
+	})
+	if err != nil && err != io.EOF {
+		cancel()
+		return nil, 0, fmt.Errorf("insertion aborted after sending %d vectors: %w", n, err)
+	}
+	// On io.EOF, the stream was closed by the server, and its error is
+	// returned by CloseAndRecv.
+	reply, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, 0, err
+	}
+	return reply, n, nil
+}
+
+// insertWithIDs streams the vectors, with their IDs, to an index without
+// auto-ID, returning the reply and the number of vectors sent.
+func (opts *insertOptions) insertWithIDs(
+	ctx context.Context,
+	client grpcapi.ServerClient,
+	name string,
+	readers []vecfile.Reader,
+) (proto.Message, int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := client.InsertVectorsWithIds(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	n, err := readRecords(readers, func(n int, rec vecfile.Record) error {
+		if !rec.HasID {
+			return fmt.Errorf("vector %d has no ID: use --id-column or --ids, since index %#v has auto-ID disabled", n, name)
+		}
+		return stream.Send(&grpcapi.InsertVectorWithIdRequest{
+			IndexName:  name,
+			Id:         int32(rec.ID),
+			Vector:     &grpcapi.Vector{Value: rec.Vector},
+			TtlSeconds: opts.ttlSeconds(),
+		})
+	})
+	if err != nil && err != io.EOF {
+		cancel()
+		return nil, 0, fmt.Errorf("insertion aborted after sending %d vectors: %w", n, err)
+	}
+	reply, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, 0, err
+	}
+	return reply, n, nil
+}
+
+// readRecords calls fn with each record of the readers, numbered from
+// zero, until it fails. It returns the number of records processed.
+func readRecords(readers []vecfile.Reader, fn func(int, vecfile.Record) error) (int, error) {
+	n := 0
+	for _, r := range readers {
+		for ; ; n++ {
+			rec, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return n, err
+			}
+			if err = fn(n, rec); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
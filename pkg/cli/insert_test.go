@@ -0,0 +1,87 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli_test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestInsertCommand(t *testing.T) {
+	t.Parallel()
+	address := startServer(t)
+
+	csvFile := writeFile(t, "vectors.csv", "id,x,y,z\n"+
+		"10,1,0,0\n"+
+		"20,0,1,0\n")
+	jsonlFile := writeFile(t, "vectors.jsonl", `{"vector": [0, 0, 1]}`+"\n")
+
+	_, err := runClient(t, "create", "--address", address, "--dim", "3", "--max-elements", "10", "foo")
+	require.NoError(t, err)
+	_, err = runClient(t, "create", "--address", address, "--dim", "3", "--max-elements", "10", "--auto-id", "bar")
+	require.NoError(t, err)
+
+	t.Run("with IDs", func(t *testing.T) {
+		out, err := runClient(t, "insert", "--address", address, "--id-column", "id", "foo", csvFile)
+		require.NoError(t, err)
+		assert.Equal(t, "2 vectors inserted\n", out)
+
+		_, err = runClient(t, "insert", "--address", address, "foo", jsonlFile)
+		assert.EqualError(t, err, "insertion aborted after sending 0 vectors: "+
+			"vector 0 has no ID: use --id-column or --ids, since index \"foo\" has auto-ID disabled")
+
+		// The vectors sent before an invalid one are not inserted.
+		invalid := writeFile(t, "invalid.csv", "id,x,y,z\n30,1,0,0\n40,0,1,0\n50,0,0,foo\n")
+		_, err = runClient(t, "insert", "--address", address, "--id-column", "id", "foo", invalid)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "insertion aborted after sending 2 vectors: ")
+
+		out, err = runClient(t, "describe", "--address", address, "foo")
+		require.NoError(t, err)
+		assert.Contains(t, out, "size                   2\n")
+	})
+
+	t.Run("with auto-ID", func(t *testing.T) {
+		// The IDs in the files are ignored.
+		out, err := runClient(t, "insert", "--address", address, "-o", "json", "--ttl", "1h",
+			"--id-column", "id", "bar", csvFile, jsonlFile)
+		require.NoError(t, err)
+		var reply map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(out), &reply))
+		assert.Equal(t, []interface{}{"1", "2", "3"}, reply["ids"])
+
+		out, err = runClient(t, "describe", "--address", address, "bar")
+		require.NoError(t, err)
+		assert.Contains(t, out, "pending expirations    3\n")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := runClient(t, "insert", "--address", address, "baz", csvFile)
+		assert.Error(t, err)
+		_, err = runClient(t, "insert", "--address", address, "foo")
+		assert.EqualError(t, err, "expected arguments: NAME FILE...")
+		_, err = runClient(t, "insert", "--address", address, "foo", "vectors.txt")
+		assert.Error(t, err)
+		_, err = runClient(t, "insert", "--address", address, "--ttl", "1ms", "foo", csvFile)
+		assert.EqualError(t, err, "--ttl must be at least 1s")
+
+		// The server rejects vectors of the wrong dimension.
+		wrongDim := writeFile(t, "wrong.csv", "id,x,y\n30,1,0\n")
+		_, err = runClient(t, "insert", "--address", address, "--id-column", "id", "foo", wrongDim)
+		assert.Error(t, err)
+	})
+}
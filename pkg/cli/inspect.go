@@ -0,0 +1,309 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/urfave/cli/v2"
+	"io"
+	"sort"
+)
+
+// inspectOptions provides the settings of the inspect command.
+type inspectOptions struct {
+	json bool
+}
+
+func (app *App) inspectCommand() *cli.Command {
+	opts := &inspectOptions{}
+	return &cli.Command{
+		Name:      "inspect",
+		Usage:     "describe the files of an index, without running the server",
+		ArgsUsage: "DIR",
+		Description: "The state of the index in DIR is decoded, and printed along with the\n" +
+			"header of its index file, the number of entries of its write-ahead\n" +
+			"log by type, and the size of its files, including the temporary\n" +
+			"files left by an interrupted saving. Only the headers of the files\n" +
+			"are read, so it is fast even for large indices.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "json",
+				Usage:       "write the description as JSON",
+				Destination: &opts.json,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return app.inspectAction(c, opts)
+		},
+	}
+}
+
+func (app *App) inspectAction(c *cli.Context, opts *inspectOptions) error {
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("exactly one index dir is expected")
+	}
+	in, err := hnswgo.Inspect(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	if opts.json {
+		encoder := json.NewEncoder(c.App.Writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(newInspectReport(in))
+	}
+	return writeInspection(c.App.Writer, in)
+}
+
+// inspectReport is the JSON output of the inspect command. Keys are in
+// lowerCamelCase, as in the JSON output of the verify command.
+type inspectReport struct {
+	State       *inspectState  `json:"state,omitempty"`
+	StateError  string         `json:"stateError,omitempty"`
+	Header      *inspectHeader `json:"header,omitempty"`
+	HeaderError string         `json:"headerError,omitempty"`
+	Log         inspectLog     `json:"log"`
+	Files       []inspectFile  `json:"files"`
+}
+
+type inspectState struct {
+	IndexType           string `json:"indexType"`
+	SpaceType           string `json:"spaceType"`
+	Storage             string `json:"storage"`
+	Dim                 int    `json:"dim"`
+	MaxElements         int    `json:"maxElements"`
+	M                   int    `json:"m"`
+	EfConstruction      int    `json:"efConstruction"`
+	RandSeed            int    `json:"randSeed"`
+	AutoID              bool   `json:"autoId"`
+	AllowReplaceDeleted bool   `json:"allowReplaceDeleted"`
+	TrainingSize        int    `json:"trainingSize,omitempty"`
+	PQSubquantizers     int    `json:"pqSubquantizers,omitempty"`
+	PQBits              int    `json:"pqBits,omitempty"`
+	Rerank              bool   `json:"rerank,omitempty"`
+	DefaultTTL          string `json:"defaultTtl,omitempty"`
+	LastAutoID          uint32 `json:"lastAutoId"`
+	Ef                  int    `json:"ef"`
+	NativeCosine        bool   `json:"nativeCosine"`
+	Expirations         int    `json:"expirations"`
+}
+
+type inspectHeader struct {
+	File           string `json:"file"`
+	Format         string `json:"format"`
+	Elements       uint64 `json:"elements"`
+	Dim            uint64 `json:"dim,omitempty"`
+	MaxElements    uint64 `json:"maxElements,omitempty"`
+	M              uint64 `json:"m,omitempty"`
+	MaxM           uint64 `json:"maxM,omitempty"`
+	MaxM0          uint64 `json:"maxM0,omitempty"`
+	EfConstruction uint64 `json:"efConstruction,omitempty"`
+	MaxLevel       int32  `json:"maxLevel"`
+	EntryPoint     uint32 `json:"entryPoint"`
+}
+
+type inspectLog struct {
+	Entries   map[string]int `json:"entries"`
+	Size      int64          `json:"size"`
+	ValidSize int64          `json:"validSize"`
+	Error     string         `json:"error,omitempty"`
+}
+
+type inspectFile struct {
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	Leftover bool   `json:"leftover,omitempty"`
+}
+
+func newInspectReport(in *hnswgo.Inspection) *inspectReport {
+	r := &inspectReport{
+		Log: inspectLog{
+			Entries:   in.LogEntries,
+			Size:      in.Log.Size,
+			ValidSize: in.Log.ValidSize,
+		},
+		Files: make([]inspectFile, len(in.Files)),
+	}
+	if in.Log.Err != nil {
+		r.Log.Error = in.Log.Err.Error()
+	}
+	for i, file := range in.Files {
+		r.Files[i] = inspectFile{Name: file.Name, Size: file.Size, Leftover: file.Leftover}
+	}
+
+	if in.StateErr != nil {
+		r.StateError = in.StateErr.Error()
+	}
+	if s := in.State; s != nil {
+		r.State = &inspectState{
+			IndexType:           string(indexType(s.Config)),
+			SpaceType:           string(s.SpaceType),
+			Storage:             string(storageType(s.Config)),
+			Dim:                 s.Dim,
+			MaxElements:         s.MaxElements,
+			M:                   s.M,
+			EfConstruction:      s.EfConstruction,
+			RandSeed:            s.RandSeed,
+			AutoID:              s.AutoIDEnabled,
+			AllowReplaceDeleted: s.AllowReplaceDeleted,
+			LastAutoID:          s.LastAutoID,
+			Ef:                  s.Ef,
+			NativeCosine:        s.NativeCosine,
+			Expirations:         s.Expirations,
+		}
+		switch s.Storage {
+		case hnswgo.SQ8Storage, hnswgo.PQStorage:
+			r.State.TrainingSize = s.TrainingSize
+			r.State.Rerank = s.Rerank
+		}
+		if s.Storage == hnswgo.PQStorage {
+			r.State.PQSubquantizers = s.PQSubquantizers
+			r.State.PQBits = s.PQBits
+		}
+		if s.DefaultTTL > 0 {
+			r.State.DefaultTTL = s.DefaultTTL.String()
+		}
+	}
+
+	if in.HeaderErr != nil {
+		r.HeaderError = in.HeaderErr.Error()
+	}
+	if h := in.Header; h != nil {
+		r.Header = &inspectHeader{
+			File:           h.File,
+			Format:         h.Format,
+			Elements:       h.Elements,
+			Dim:            h.Dim,
+			MaxElements:    h.MaxElements,
+			M:              h.M,
+			MaxM:           h.MaxM,
+			MaxM0:          h.MaxM0,
+			EfConstruction: h.EfConstruction,
+			MaxLevel:       h.MaxLevel,
+			EntryPoint:     h.EntryPoint,
+		}
+	}
+	return r
+}
+
+// indexType returns the index type of the configuration, replacing the
+// zero value with its meaning.
+func indexType(config hnswgo.Config) hnswgo.IndexType {
+	if config.IndexType == "" {
+		return hnswgo.HNSWIndex
+	}
+	return config.IndexType
+}
+
+// storageType returns the storage type of the configuration, replacing the
+// zero value with its meaning.
+func storageType(config hnswgo.Config) hnswgo.StorageType {
+	if config.Storage == "" {
+		return hnswgo.Float32Storage
+	}
+	return config.Storage
+}
+
+// writeInspection writes the output of the inspect command in plain text.
+func writeInspection(w io.Writer, in *hnswgo.Inspection) error {
+	var lines []string
+	add := func(format string, a ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, a...))
+	}
+
+	if s := in.State; s != nil {
+		add("state:")
+		add("  index type: %s", indexType(s.Config))
+		add("  space type: %s", s.SpaceType)
+		add("  storage: %s", storageType(s.Config))
+		add("  dim: %d", s.Dim)
+		add("  max elements: %d", s.MaxElements)
+		add("  M: %d", s.M)
+		add("  ef construction: %d", s.EfConstruction)
+		add("  rand seed: %d", s.RandSeed)
+		add("  auto ID: %t", s.AutoIDEnabled)
+		add("  last auto ID: %d", s.LastAutoID)
+		add("  allow replace deleted: %t", s.AllowReplaceDeleted)
+		switch s.Storage {
+		case hnswgo.SQ8Storage, hnswgo.PQStorage:
+			add("  training size: %d", s.TrainingSize)
+			add("  rerank: %t", s.Rerank)
+		}
+		if s.Storage == hnswgo.PQStorage {
+			add("  PQ subquantizers: %d", s.PQSubquantizers)
+			add("  PQ bits: %d", s.PQBits)
+		}
+		if s.DefaultTTL > 0 {
+			add("  default TTL: %s", s.DefaultTTL)
+		}
+		if s.Ef > 0 {
+			add("  ef: %d", s.Ef)
+		} else {
+			add("  ef: default")
+		}
+		add("  native cosine: %t", s.NativeCosine)
+		add("  expirations: %d", s.Expirations)
+	} else {
+		add("state: %v", in.StateErr)
+	}
+
+	switch h := in.Header; {
+	case h != nil:
+		add("header (%s, %s format):", h.File, h.Format)
+		add("  elements: %d", h.Elements)
+		if h.Format == hnswgo.FlatFormat {
+			add("  dim: %d", h.Dim)
+			break
+		}
+		add("  max elements: %d", h.MaxElements)
+		add("  M: %d (max links: %d, %d at level 0)", h.M, h.MaxM, h.MaxM0)
+		add("  ef construction: %d", h.EfConstruction)
+		add("  max level: %d", h.MaxLevel)
+		add("  entry point: %d", h.EntryPoint)
+	case in.HeaderErr != nil:
+		add("header: %v", in.HeaderErr)
+	}
+
+	add("log: %d bytes", in.Log.Size)
+	types := make([]string, 0, len(in.LogEntries))
+	for t := range in.LogEntries {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		add("  %s: %d", t, in.LogEntries[t])
+	}
+	if in.Log.Err != nil {
+		add("  error: %v (%d bytes not decoded)", in.Log.Err, in.Log.Size-in.Log.ValidSize)
+	}
+
+	add("files:")
+	for _, file := range in.Files {
+		if file.Leftover {
+			add("  %s: %d bytes (leftover)", file.Name, file.Size)
+		} else {
+			add("  %s: %d bytes", file.Name, file.Size)
+		}
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/cli"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/indexmanager"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestInspectCommand(t *testing.T) {
+	t.Parallel()
+
+	input := writeFile(t, "vectors.csv", "id,x,y,z\n"+
+		"10,1,0,0\n"+
+		"20,0,1,0\n"+
+		"30,0,0,1\n")
+	dataPath := t.TempDir()
+	err := cli.NewApp().Run([]string{"hnsw-grpc-server", "import",
+		"--data", dataPath, "--name", "foo", "--id-column", "id", input})
+	require.NoError(t, err)
+	dir := path.Join(dataPath, "foo")
+
+	im := indexmanager.New(dataPath, zerolog.Nop())
+	require.NoError(t, im.LoadIndices())
+	index, ok := im.GetIndex("foo")
+	require.True(t, ok)
+	require.NoError(t, index.MarkDelete(10))
+	require.NoError(t, index.MarkDelete(20))
+	require.NoError(t, os.WriteFile(path.Join(dir, "state.tmp"), []byte("foo"), 0666))
+
+	inspect := func(args ...string) (string, error) {
+		app := cli.NewApp()
+		out := &bytes.Buffer{}
+		app.Writer = out
+		err := app.Run(append([]string{"hnsw-grpc-server", "inspect"}, args...))
+		return out.String(), err
+	}
+
+	out, err := inspect(dir)
+	require.NoError(t, err)
+	assert.Contains(t, out, "state:\n  index type: hnsw\n")
+	assert.Contains(t, out, "  dim: 3\n")
+	assert.Contains(t, out, "header (index, hnsw-mapped format):\n  elements: 3\n")
+	assert.Contains(t, out, "\n  DeletionMark: 2\n")
+	assert.Contains(t, out, "\n  state.tmp: 3 bytes (leftover)\n")
+
+	out, err = inspect("--json", dir)
+	require.NoError(t, err)
+	var report struct {
+		State struct {
+			Dim        int    `json:"dim"`
+			SpaceType  string `json:"spaceType"`
+			LastAutoID uint32 `json:"lastAutoId"`
+		} `json:"state"`
+		Header struct {
+			Format   string `json:"format"`
+			Elements uint64 `json:"elements"`
+		} `json:"header"`
+		Log struct {
+			Entries map[string]int `json:"entries"`
+		} `json:"log"`
+		Files []struct {
+			Name     string `json:"name"`
+			Leftover bool   `json:"leftover"`
+		} `json:"files"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &report))
+	assert.Equal(t, 3, report.State.Dim)
+	assert.Equal(t, "hnsw-mapped", report.Header.Format)
+	assert.Equal(t, uint64(3), report.Header.Elements)
+	assert.Equal(t, map[string]int{"DeletionMark": 2}, report.Log.Entries)
+	require.Len(t, report.Files, 4)
+	assert.Equal(t, "state.tmp", report.Files[3].Name)
+	assert.True(t, report.Files[3].Leftover)
+
+	_, err = inspect(path.Join(dataPath, "bar"))
+	assert.Error(t, err)
+	_, err = inspect()
+	assert.Error(t, err)
+}
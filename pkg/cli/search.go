@@ -0,0 +1,237 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/grpcapi"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/vecfile"
+	"github.com/urfave/cli/v2"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// searchOptions provides the settings of the search command.
+type searchOptions struct {
+	clientOptions
+	vector string
+	query  string
+	k      int
+	exact  bool
+}
+
+func (app *App) searchCommand() *cli.Command {
+	opts := &searchOptions{}
+	return &cli.Command{
+		Name:      "search",
+		Usage:     "search the nearest neighbors of vectors in an index of a running server",
+		ArgsUsage: "NAME",
+		Description: "The query vectors are given inline with --vector, or read from the\n" +
+			"--query file: a .json file containing a vector or an array of vectors,\n" +
+			"or any file accepted by the import command, such as a 2-dimensional\n" +
+			".npy array with one query per row.",
+		Flags: append(opts.flags(),
+			&cli.StringFlag{
+				Name:        "vector",
+				Usage:       "query vector, as a JSON array of numbers",
+				Destination: &opts.vector,
+			},
+			&cli.StringFlag{
+				Name:        "query",
+				Usage:       "file of the query vectors",
+				Destination: &opts.query,
+			},
+			&cli.IntFlag{
+				Name:        "k",
+				Value:       10,
+				Usage:       "number of nearest neighbors searched for each query",
+				Destination: &opts.k,
+			},
+			&cli.BoolFlag{
+				Name:        "exact",
+				Usage:       "compare the queries with all the stored vectors, instead of traversing the graph",
+				Destination: &opts.exact,
+			},
+		),
+		Action: func(c *cli.Context) error {
+			return app.searchAction(c, opts)
+		},
+	}
+}
+
+func (app *App) searchAction(c *cli.Context, opts *searchOptions) error {
+	args, err := exactArgs(c, "NAME")
+	if err != nil {
+		return err
+	}
+	if opts.k <= 0 {
+		return fmt.Errorf("--k must be positive")
+	}
+	queries, err := opts.queries()
+	if err != nil {
+		return err
+	}
+
+	return opts.withClient(c, func(ctx context.Context, client grpcapi.ServerClient) error {
+		replies := make([]*grpcapi.SearchKNNReply, len(queries))
+		for i, query := range queries {
+			replies[i], err = client.SearchKNN(ctx, &grpcapi.SearchRequest{
+				IndexName: args[0],
+				Vector:    &grpcapi.Vector{Value: query},
+				K:         int32(opts.k),
+				Exact:     opts.exact,
+			})
+			if err != nil {
+				if len(queries) > 1 {
+					return fmt.Errorf("error searching query %d: %w", i, err)
+				}
+				return err
+			}
+		}
+
+		if opts.output == jsonOutput {
+			messages := make([]proto.Message, len(replies))
+			for i, reply := range replies {
+				messages[i] = reply
+			}
+			return writeJSON(c.App.Writer, messages...)
+		}
+		header, rows := searchTable(replies)
+		return writeTable(c.App.Writer, header, rows)
+	})
+}
+
+// queries returns the query vectors, given inline or read from file.
+func (opts *searchOptions) queries() ([][]float32, error) {
+	switch {
+	case opts.vector != "" && opts.query != "":
+		return nil, fmt.Errorf("--vector and --query cannot be used together")
+	case opts.vector != "":
+		var vector []float32
+		if err := json.Unmarshal([]byte(opts.vector), &vector); err != nil {
+			return nil, fmt.Errorf("invalid --vector: %w", err)
+		}
+		return [][]float32{vector}, nil
+	case opts.query != "":
+		queries, err := readQueries(opts.query)
+		if err != nil {
+			return nil, err
+		}
+		if len(queries) == 0 {
+			return nil, fmt.Errorf("no vectors found in file %#v", opts.query)
+		}
+		return queries, nil
+	default:
+		return nil, fmt.Errorf("either --vector or --query is required")
+	}
+}
+
+// readQueries reads the query vectors from a JSON file, containing either
+// a single vector or an array of vectors, or from any vector file.
+func readQueries(name string) ([][]float32, error) {
+	if !strings.EqualFold(filepath.Ext(name), ".json") {
+		return readVectorFile(name)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %#v: %w", name, err)
+	}
+	var vector []float32
+	if err = json.Unmarshal(data, &vector); err == nil {
+		return [][]float32{vector}, nil
+	}
+	var vectors [][]float32
+	if err = json.Unmarshal(data, &vectors); err != nil {
+		return nil, fmt.Errorf("error reading file %#v: expected a vector or an array of vectors: %w", name, err)
+	}
+	return vectors, nil
+}
+
+// readVectorFile reads all the vectors of a file in one of the formats of
+// the vecfile package, inferred from its extension.
+func readVectorFile(name string) (_ [][]float32, err error) {
+	format, err := vecfile.FormatFromFilename(name)
+	if err != nil {
+		return nil, err
+	}
+	r, err := vecfile.Open(name, format, vecfile.Options{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if e := r.Close(); e != nil && err == nil {
+			err = e
+		}
+	}()
+
+	var vectors [][]float32
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return vectors, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, rec.Vector)
+	}
+}
+
+// searchTable returns the header and the rows of the table of the hits of
+// the searches. The query column is only shown for multiple queries, and
+// the similarity column for the spaces where it is set.
+func searchTable(replies []*grpcapi.SearchKNNReply) ([]string, [][]string) {
+	multiple := len(replies) > 1
+	similarity := false
+	for _, reply := range replies {
+		for _, hit := range reply.GetHits() {
+			similarity = similarity || hit.Similarity != nil
+		}
+	}
+
+	header := []string{"ID", "DISTANCE"}
+	if multiple {
+		header = append([]string{"QUERY"}, header...)
+	}
+	if similarity {
+		header = append(header, "SIMILARITY")
+	}
+
+	var rows [][]string
+	for i, reply := range replies {
+		for _, hit := range reply.GetHits() {
+			row := []string{hit.GetId(), formatFloat(hit.GetDistance())}
+			if multiple {
+				row = append([]string{strconv.Itoa(i)}, row...)
+			}
+			if similarity {
+				row = append(row, formatFloat(hit.GetSimilarity()))
+			}
+			rows = append(rows, row)
+		}
+	}
+	return header, rows
+}
+
+func formatFloat(f float32) string {
+	return strconv.FormatFloat(float64(f), 'g', -1, 32)
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli_test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestSearchCommand(t *testing.T) {
+	t.Parallel()
+	address := startServer(t)
+
+	_, err := runClient(t, "create", "--address", address, "--dim", "3", "--max-elements", "10", "foo")
+	require.NoError(t, err)
+	_, err = runClient(t, "create", "--address", address, "--dim", "3", "--max-elements", "10", "--space", "ip", "bar")
+	require.NoError(t, err)
+	input := writeFile(t, "vectors.csv", "id,x,y,z\n"+
+		"10,1,0,0\n"+
+		"20,0,1,0\n"+
+		"30,0,0,1\n")
+	for _, name := range []string{"foo", "bar"} {
+		_, err = runClient(t, "insert", "--address", address, "--id-column", "id", name, input)
+		require.NoError(t, err)
+	}
+
+	out, err := runClient(t, "search", "--address", address, "--vector", "[1, 0.5, 0]", "--k", "2", "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "ID  DISTANCE\n"+
+		"10  0.25\n"+
+		"20  1.25\n", out)
+
+	out, err = runClient(t, "search", "--address", address, "--vector", "[1, 0.5, 0]", "--k", "1", "--exact", "bar")
+	require.NoError(t, err)
+	assert.Equal(t, "ID  DISTANCE  SIMILARITY\n"+
+		"10  0         1\n", out)
+
+	queries := writeFile(t, "queries.json", "[[0, 0, 1], [0, 1, 0]]")
+	out, err = runClient(t, "search", "--address", address, "--query", queries, "--k", "1", "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "QUERY  ID  DISTANCE\n"+
+		"0      30  0\n"+
+		"1      20  0\n", out)
+
+	query := writeFile(t, "query.json", "[0, 1, 0]")
+	out, err = runClient(t, "search", "--address", address, "--query", query, "--k", "1", "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "ID  DISTANCE\n20  0\n", out)
+
+	jsonlQueries := writeFile(t, "queries.jsonl", `{"vector": [1, 0, 0]}`+"\n"+`{"vector": [0, 0, 1]}`+"\n")
+	out, err = runClient(t, "search", "--address", address, "--query", jsonlQueries, "--k", "1", "-o", "json", "foo")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 2)
+	var reply struct {
+		Hits []struct {
+			ID string `json:"id"`
+		} `json:"hits"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &reply))
+	require.Len(t, reply.Hits, 1)
+	assert.Equal(t, "30", reply.Hits[0].ID)
+
+	_, err = runClient(t, "search", "--address", address, "foo")
+	assert.EqualError(t, err, "either --vector or --query is required")
+	_, err = runClient(t, "search", "--address", address, "--vector", "[1]", "--query", query, "foo")
+	assert.Error(t, err)
+	_, err = runClient(t, "search", "--address", address, "--vector", "foo", "foo")
+	assert.Error(t, err)
+	_, err = runClient(t, "search", "--address", address, "--vector", "[1, 0]", "foo")
+	assert.Error(t, err)
+	_, err = runClient(t, "search", "--address", address, "--vector", "[1, 0, 0]", "--k", "0", "foo")
+	assert.Error(t, err)
+	_, err = runClient(t, "search", "--address", address, "--vector", "[1, 0, 0]", "baz")
+	assert.Error(t, err)
+}
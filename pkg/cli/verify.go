@@ -0,0 +1,132 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/server"
+	"github.com/urfave/cli/v2"
+	"google.golang.org/protobuf/encoding/protojson"
+	"io"
+)
+
+// verifyOptions provides the settings of the verify command.
+type verifyOptions struct {
+	repair bool
+	json   bool
+}
+
+func (app *App) verifyCommand() *cli.Command {
+	opts := &verifyOptions{}
+	return &cli.Command{
+		Name:      "verify",
+		Usage:     "verify the integrity of an index, without running the server",
+		ArgsUsage: "DIR",
+		Description: "The state of the index in DIR is decoded, its data structure is\n" +
+			"loaded, the links of its graph and the IDs of its elements are\n" +
+			"checked, and its write-ahead log is decoded. The report is written\n" +
+			"to the standard output, and the command fails if any problem is\n" +
+			"found. The index must not be in use by a running server.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "repair",
+				Usage:       "discard the corrupted end of the write-ahead log, if any",
+				Destination: &opts.repair,
+			},
+			&cli.BoolFlag{
+				Name:        "json",
+				Usage:       "write the report as JSON, in the format of the VerifyIndex reply",
+				Destination: &opts.json,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return app.verifyAction(c, opts)
+		},
+	}
+}
+
+func (app *App) verifyAction(c *cli.Context, opts *verifyOptions) (err error) {
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("exactly one index dir is expected")
+	}
+	dir := c.Args().First()
+	logger := app.newLogger().With().Str("dir", dir).Logger()
+
+	report, err := hnswgo.Verify(dir, opts.repair, logger)
+	if err != nil {
+		return err
+	}
+
+	if opts.json {
+		data, err := protojson.Marshal(server.NewVerifyIndexReply(report))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(c.App.Writer, "%s\n", data)
+		if err != nil {
+			return err
+		}
+	} else if err = writeVerifyReport(c.App.Writer, report); err != nil {
+		return err
+	}
+
+	if problems := report.Problems(); len(problems) > 0 {
+		return fmt.Errorf("index dir %#v has %d problems", dir, len(problems))
+	}
+	return nil
+}
+
+// writeVerifyReport writes the report of the verify command in plain text.
+func writeVerifyReport(w io.Writer, report *hnswgo.VerifyReport) error {
+	var lines []string
+	switch {
+	case report.StateErr != nil:
+		lines = append(lines, "state: failed")
+	case report.IndexErr != nil:
+		lines = append(lines, "state: ok", "index: failed")
+	default:
+		lines = append(lines, "state: ok", "index: ok")
+	}
+	if g := report.Graph; g != nil {
+		lines = append(lines, fmt.Sprintf("graph: %d elements (%d deleted), %d links",
+			g.Elements, g.Deleted, g.Connections))
+	}
+	lines = append(lines, fmt.Sprintf("log: %d entries, %d of %d bytes decoded",
+		report.Log.Entries, report.Log.ValidSize, report.Log.Size))
+
+	for _, section := range []struct {
+		title string
+		items []string
+	}{
+		{"problems", report.Problems()},
+		{"warnings", report.Warnings()},
+	} {
+		if len(section.items) == 0 {
+			continue
+		}
+		lines = append(lines, section.title+":")
+		for _, item := range section.items {
+			lines = append(lines, "  "+item)
+		}
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
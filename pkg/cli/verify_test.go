@@ -0,0 +1,87 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/cli"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/indexmanager"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestVerifyCommand(t *testing.T) {
+	t.Parallel()
+
+	input := writeFile(t, "vectors.csv", "id,x,y,z\n"+
+		"10,1,0,0\n"+
+		"20,0,1,0\n"+
+		"30,0,0,1\n")
+	dataPath := t.TempDir()
+	err := cli.NewApp().Run([]string{"hnsw-grpc-server", "import",
+		"--data", dataPath, "--name", "foo", "--id-column", "id", input})
+	require.NoError(t, err)
+	dir := path.Join(dataPath, "foo")
+
+	verify := func(args ...string) (string, error) {
+		app := cli.NewApp()
+		out := &bytes.Buffer{}
+		app.Writer = out
+		err := app.Run(append([]string{"hnsw-grpc-server", "verify"}, args...))
+		return out.String(), err
+	}
+
+	out, err := verify(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "state: ok\n"+
+		"index: ok\n"+
+		"graph: 3 elements (0 deleted), 6 links\n"+
+		"log: 0 entries, 0 of 0 bytes decoded\n", out)
+
+	// The last entry of the log is only partially written.
+	im := indexmanager.New(dataPath, zerolog.Nop())
+	require.NoError(t, im.LoadIndices())
+	index, ok := im.GetIndex("foo")
+	require.True(t, ok)
+	require.NoError(t, index.MarkDelete(10))
+	require.NoError(t, index.MarkDelete(20))
+	logFile := path.Join(dir, "log")
+	info, err := os.Stat(logFile)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(logFile, info.Size()-3))
+
+	out, err = verify(dir)
+	assert.Error(t, err)
+	assert.Contains(t, out, "log: 1 entries")
+	assert.Contains(t, out, "problems:\n  log: ")
+
+	out, err = verify("--repair", "--json", dir)
+	require.NoError(t, err)
+	var reply map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &reply))
+	assert.Equal(t, true, reply["ok"])
+	assert.Equal(t, true, reply["log"].(map[string]interface{})["repaired"])
+	assert.Len(t, reply["warnings"], 1)
+
+	_, err = verify(path.Join(dataPath, "bar"))
+	assert.Error(t, err)
+	_, err = verify()
+	assert.Error(t, err)
+}
@@ -21,6 +21,63 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// IndexStatus is the loading status of an index. The indices are loaded in
+// background once the server starts.
+type IndexStatus int32
+
+const (
+	// READY indices can be used.
+	IndexStatus_READY IndexStatus = 0
+	// LOADING indices are not loaded yet: the requests using them fail with
+	// the UNAVAILABLE code.
+	IndexStatus_LOADING IndexStatus = 1
+	// FAILED indices could not be loaded. Their files can be checked with
+	// VerifyIndex, and they can be loaded again with RetryIndex, optionally
+	// repairing them from their previous snapshot, or deleted.
+	IndexStatus_FAILED IndexStatus = 2
+)
+
+// Enum value maps for IndexStatus.
+var (
+	IndexStatus_name = map[int32]string{
+		0: "READY",
+		1: "LOADING",
+		2: "FAILED",
+	}
+	IndexStatus_value = map[string]int32{
+		"READY":   0,
+		"LOADING": 1,
+		"FAILED":  2,
+	}
+)
+
+func (x IndexStatus) Enum() *IndexStatus {
+	p := new(IndexStatus)
+	*p = x
+	return p
+}
+
+func (x IndexStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (IndexStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_hnswservice_proto_enumTypes[0].Descriptor()
+}
+
+func (IndexStatus) Type() protoreflect.EnumType {
+	return &file_hnswservice_proto_enumTypes[0]
+}
+
+func (x IndexStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use IndexStatus.Descriptor instead.
+func (IndexStatus) EnumDescriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{0}
+}
+
 // SpaceType is the vector space.
 type CreateIndexRequest_SpaceType int32
 
@@ -28,6 +85,15 @@ const (
 	CreateIndexRequest_L2     CreateIndexRequest_SpaceType = 0
 	CreateIndexRequest_IP     CreateIndexRequest_SpaceType = 1
 	CreateIndexRequest_COSINE CreateIndexRequest_SpaceType = 2
+	// HAMMING compares binary vectors, whose components are 0 or 1, by the
+	// number of differing bits. The dimension is the number of bits. It
+	// only supports the FLOAT32 storage type, and stores the vectors
+	// bit-packed regardless.
+	CreateIndexRequest_HAMMING CreateIndexRequest_SpaceType = 3
+	// L1 compares vectors by the Manhattan distance, the sum of the
+	// absolute differences of the components. It only supports the FLOAT32
+	// storage type.
+	CreateIndexRequest_L1 CreateIndexRequest_SpaceType = 4
 )
 
 // Enum value maps for CreateIndexRequest_SpaceType.
@@ -36,11 +102,15 @@ var (
 		0: "L2",
 		1: "IP",
 		2: "COSINE",
+		3: "HAMMING",
+		4: "L1",
 	}
 	CreateIndexRequest_SpaceType_value = map[string]int32{
-		"L2":     0,
-		"IP":     1,
-		"COSINE": 2,
+		"L2":      0,
+		"IP":      1,
+		"COSINE":  2,
+		"HAMMING": 3,
+		"L1":      4,
 	}
 )
 
@@ -55,11 +125,11 @@ func (x CreateIndexRequest_SpaceType) String() string {
 }
 
 func (CreateIndexRequest_SpaceType) Descriptor() protoreflect.EnumDescriptor {
-	return file_hnswservice_proto_enumTypes[0].Descriptor()
+	return file_hnswservice_proto_enumTypes[1].Descriptor()
 }
 
 func (CreateIndexRequest_SpaceType) Type() protoreflect.EnumType {
-	return &file_hnswservice_proto_enumTypes[0]
+	return &file_hnswservice_proto_enumTypes[1]
 }
 
 func (x CreateIndexRequest_SpaceType) Number() protoreflect.EnumNumber {
@@ -71,12 +141,198 @@ func (CreateIndexRequest_SpaceType) EnumDescriptor() ([]byte, []int) {
 	return file_hnswservice_proto_rawDescGZIP(), []int{0, 0}
 }
 
+// IndexType is the data structure of the index.
+type CreateIndexRequest_IndexType int32
+
+const (
+	// HNSW performs approximate searches on a HNSW graph.
+	CreateIndexRequest_HNSW CreateIndexRequest_IndexType = 0
+	// FLAT compares each query with all the stored vectors, performing
+	// exact searches. It is meant for small collections, where building a
+	// graph is not worth it. The m, efConstruction and seed fields are
+	// ignored.
+	CreateIndexRequest_FLAT CreateIndexRequest_IndexType = 1
+)
+
+// Enum value maps for CreateIndexRequest_IndexType.
+var (
+	CreateIndexRequest_IndexType_name = map[int32]string{
+		0: "HNSW",
+		1: "FLAT",
+	}
+	CreateIndexRequest_IndexType_value = map[string]int32{
+		"HNSW": 0,
+		"FLAT": 1,
+	}
+)
+
+func (x CreateIndexRequest_IndexType) Enum() *CreateIndexRequest_IndexType {
+	p := new(CreateIndexRequest_IndexType)
+	*p = x
+	return p
+}
+
+func (x CreateIndexRequest_IndexType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CreateIndexRequest_IndexType) Descriptor() protoreflect.EnumDescriptor {
+	return file_hnswservice_proto_enumTypes[2].Descriptor()
+}
+
+func (CreateIndexRequest_IndexType) Type() protoreflect.EnumType {
+	return &file_hnswservice_proto_enumTypes[2]
+}
+
+func (x CreateIndexRequest_IndexType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CreateIndexRequest_IndexType.Descriptor instead.
+func (CreateIndexRequest_IndexType) EnumDescriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{0, 1}
+}
+
+// StorageType is the format of the stored vectors.
+type CreateIndexRequest_StorageType int32
+
+const (
+	// FLOAT32 stores the vectors in full precision.
+	CreateIndexRequest_FLOAT32 CreateIndexRequest_StorageType = 0
+	// SQ8 stores one byte per dimension, with a scalar quantization of the
+	// range of values found in the first training_size vectors, which are
+	// stored in full precision until then. It is only supported by HNSW
+	// indices.
+	CreateIndexRequest_SQ8 CreateIndexRequest_StorageType = 1
+	// PQ splits the vectors into pq_subquantizers sub-vectors, each one
+	// stored as the pq_bits code of the nearest centroid of its subspace.
+	// The centroids are found by k-means on the first training_size
+	// vectors, which are stored in full precision until then. It is only
+	// supported by HNSW indices.
+	CreateIndexRequest_PQ CreateIndexRequest_StorageType = 2
+	// FLOAT16 stores the vectors as IEEE 754 half-precision values, in two
+	// bytes per dimension. It is only supported by HNSW indices.
+	CreateIndexRequest_FLOAT16 CreateIndexRequest_StorageType = 3
+	// BFLOAT16 stores the vectors as bfloat16 values, in two bytes per
+	// dimension. It is only supported by HNSW indices.
+	CreateIndexRequest_BFLOAT16 CreateIndexRequest_StorageType = 4
+)
+
+// Enum value maps for CreateIndexRequest_StorageType.
+var (
+	CreateIndexRequest_StorageType_name = map[int32]string{
+		0: "FLOAT32",
+		1: "SQ8",
+		2: "PQ",
+		3: "FLOAT16",
+		4: "BFLOAT16",
+	}
+	CreateIndexRequest_StorageType_value = map[string]int32{
+		"FLOAT32":  0,
+		"SQ8":      1,
+		"PQ":       2,
+		"FLOAT16":  3,
+		"BFLOAT16": 4,
+	}
+)
+
+func (x CreateIndexRequest_StorageType) Enum() *CreateIndexRequest_StorageType {
+	p := new(CreateIndexRequest_StorageType)
+	*p = x
+	return p
+}
+
+func (x CreateIndexRequest_StorageType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CreateIndexRequest_StorageType) Descriptor() protoreflect.EnumDescriptor {
+	return file_hnswservice_proto_enumTypes[3].Descriptor()
+}
+
+func (CreateIndexRequest_StorageType) Type() protoreflect.EnumType {
+	return &file_hnswservice_proto_enumTypes[3]
+}
+
+func (x CreateIndexRequest_StorageType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CreateIndexRequest_StorageType.Descriptor instead.
+func (CreateIndexRequest_StorageType) EnumDescriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{0, 2}
+}
+
+// Encoding is the format of the components of data.
+type Vector_Encoding int32
+
+const (
+	// FLOAT32 encodes each component in 4 bytes.
+	Vector_FLOAT32 Vector_Encoding = 0
+	// FLOAT16 encodes each component as an IEEE 754 half-precision value,
+	// in 2 bytes.
+	Vector_FLOAT16 Vector_Encoding = 1
+	// BFLOAT16 encodes each component as the upper 2 bytes of its float32
+	// value.
+	Vector_BFLOAT16 Vector_Encoding = 2
+	// BINARY encodes each component in 1 bit, set if it is not zero. Bits
+	// are packed most significant first, as by numpy.packbits: the
+	// component i is the bit 7 - i % 8 of the byte i / 8. The unused bits
+	// of the last byte are ignored.
+	Vector_BINARY Vector_Encoding = 3
+)
+
+// Enum value maps for Vector_Encoding.
+var (
+	Vector_Encoding_name = map[int32]string{
+		0: "FLOAT32",
+		1: "FLOAT16",
+		2: "BFLOAT16",
+		3: "BINARY",
+	}
+	Vector_Encoding_value = map[string]int32{
+		"FLOAT32":  0,
+		"FLOAT16":  1,
+		"BFLOAT16": 2,
+		"BINARY":   3,
+	}
+)
+
+func (x Vector_Encoding) Enum() *Vector_Encoding {
+	p := new(Vector_Encoding)
+	*p = x
+	return p
+}
+
+func (x Vector_Encoding) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Vector_Encoding) Descriptor() protoreflect.EnumDescriptor {
+	return file_hnswservice_proto_enumTypes[4].Descriptor()
+}
+
+func (Vector_Encoding) Type() protoreflect.EnumType {
+	return &file_hnswservice_proto_enumTypes[4]
+}
+
+func (x Vector_Encoding) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Vector_Encoding.Descriptor instead.
+func (Vector_Encoding) EnumDescriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{4, 0}
+}
+
 type CreateIndexRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	IndexName      string                       `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	IndexName string `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	// Dim is the number of components of the vectors (of bits, in HAMMING
+	// spaces).
 	Dim            int32                        `protobuf:"varint,2,opt,name=dim,proto3" json:"dim,omitempty"`
 	EfConstruction int32                        `protobuf:"varint,3,opt,name=efConstruction,proto3" json:"efConstruction,omitempty"`
 	M              int32                        `protobuf:"varint,4,opt,name=m,proto3" json:"m,omitempty"`
@@ -84,6 +340,28 @@ type CreateIndexRequest struct {
 	Seed           int32                        `protobuf:"varint,6,opt,name=seed,proto3" json:"seed,omitempty"`
 	SpaceType      CreateIndexRequest_SpaceType `protobuf:"varint,7,opt,name=space_type,json=spaceType,proto3,enum=grpcapi.CreateIndexRequest_SpaceType" json:"space_type,omitempty"`
 	AutoId         bool                         `protobuf:"varint,8,opt,name=auto_id,json=autoId,proto3" json:"auto_id,omitempty"`
+	// AllowReplaceDeleted makes new vectors replace the vectors marked
+	// deleted, instead of taking new slots, so that the index capacity
+	// (max_elements) is not consumed by deletions.
+	AllowReplaceDeleted bool `protobuf:"varint,9,opt,name=allow_replace_deleted,json=allowReplaceDeleted,proto3" json:"allow_replace_deleted,omitempty"`
+	// DefaultTtlSeconds is the time to live of new vectors, unless set
+	// otherwise on insertion. Zero means no expiration.
+	DefaultTtlSeconds int64                          `protobuf:"varint,10,opt,name=default_ttl_seconds,json=defaultTtlSeconds,proto3" json:"default_ttl_seconds,omitempty"`
+	IndexType         CreateIndexRequest_IndexType   `protobuf:"varint,11,opt,name=index_type,json=indexType,proto3,enum=grpcapi.CreateIndexRequest_IndexType" json:"index_type,omitempty"`
+	StorageType       CreateIndexRequest_StorageType `protobuf:"varint,12,opt,name=storage_type,json=storageType,proto3,enum=grpcapi.CreateIndexRequest_StorageType" json:"storage_type,omitempty"`
+	// TrainingSize is the number of vectors on which the quantization of SQ8
+	// and PQ indices is trained. It defaults to 1000 for SQ8, and to 10000
+	// for PQ.
+	TrainingSize int32 `protobuf:"varint,13,opt,name=training_size,json=trainingSize,proto3" json:"training_size,omitempty"`
+	// Rerank keeps the full-precision vectors of SQ8 and PQ indices on disk,
+	// and uses them to re-rank the search results.
+	Rerank bool `protobuf:"varint,14,opt,name=rerank,proto3" json:"rerank,omitempty"`
+	// PqSubquantizers is the number of sub-vectors of PQ indices. It must
+	// divide dim.
+	PqSubquantizers int32 `protobuf:"varint,15,opt,name=pq_subquantizers,json=pqSubquantizers,proto3" json:"pq_subquantizers,omitempty"`
+	// PqBits is the size of the codes of PQ indices, from 1 to 8. It
+	// defaults to 8.
+	PqBits int32 `protobuf:"varint,16,opt,name=pq_bits,json=pqBits,proto3" json:"pq_bits,omitempty"`
 }
 
 func (x *CreateIndexRequest) Reset() {
@@ -174,6 +452,62 @@ func (x *CreateIndexRequest) GetAutoId() bool {
 	return false
 }
 
+func (x *CreateIndexRequest) GetAllowReplaceDeleted() bool {
+	if x != nil {
+		return x.AllowReplaceDeleted
+	}
+	return false
+}
+
+func (x *CreateIndexRequest) GetDefaultTtlSeconds() int64 {
+	if x != nil {
+		return x.DefaultTtlSeconds
+	}
+	return 0
+}
+
+func (x *CreateIndexRequest) GetIndexType() CreateIndexRequest_IndexType {
+	if x != nil {
+		return x.IndexType
+	}
+	return CreateIndexRequest_HNSW
+}
+
+func (x *CreateIndexRequest) GetStorageType() CreateIndexRequest_StorageType {
+	if x != nil {
+		return x.StorageType
+	}
+	return CreateIndexRequest_FLOAT32
+}
+
+func (x *CreateIndexRequest) GetTrainingSize() int32 {
+	if x != nil {
+		return x.TrainingSize
+	}
+	return 0
+}
+
+func (x *CreateIndexRequest) GetRerank() bool {
+	if x != nil {
+		return x.Rerank
+	}
+	return false
+}
+
+func (x *CreateIndexRequest) GetPqSubquantizers() int32 {
+	if x != nil {
+		return x.PqSubquantizers
+	}
+	return 0
+}
+
+func (x *CreateIndexRequest) GetPqBits() int32 {
+	if x != nil {
+		return x.PqBits
+	}
+	return 0
+}
+
 type InsertVectorRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -181,6 +515,9 @@ type InsertVectorRequest struct {
 
 	IndexName string  `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
 	Vector    *Vector `protobuf:"bytes,2,opt,name=vector,proto3" json:"vector,omitempty"`
+	// TtlSeconds is the time to live of the vector. Zero means the default
+	// TTL of the index, while a negative value means no expiration.
+	TtlSeconds int64 `protobuf:"varint,3,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
 }
 
 func (x *InsertVectorRequest) Reset() {
@@ -229,6 +566,13 @@ func (x *InsertVectorRequest) GetVector() *Vector {
 	return nil
 }
 
+func (x *InsertVectorRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
 type InsertVectorWithIdRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -237,6 +581,9 @@ type InsertVectorWithIdRequest struct {
 	IndexName string  `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
 	Id        int32   `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
 	Vector    *Vector `protobuf:"bytes,3,opt,name=vector,proto3" json:"vector,omitempty"`
+	// TtlSeconds is the time to live of the vector. Zero means the default
+	// TTL of the index, while a negative value means no expiration.
+	TtlSeconds int64 `protobuf:"varint,4,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
 }
 
 func (x *InsertVectorWithIdRequest) Reset() {
@@ -292,6 +639,13 @@ func (x *InsertVectorWithIdRequest) GetVector() *Vector {
 	return nil
 }
 
+func (x *InsertVectorWithIdRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
 type SearchRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -300,6 +654,10 @@ type SearchRequest struct {
 	IndexName string  `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
 	Vector    *Vector `protobuf:"bytes,2,opt,name=vector,proto3" json:"vector,omitempty"`
 	K         int32   `protobuf:"varint,3,opt,name=k,proto3" json:"k,omitempty"`
+	// Exact makes the search compare the query with all the stored vectors,
+	// returning the true nearest neighbors, instead of traversing the graph.
+	// It is much slower, and mostly meant for evaluation.
+	Exact bool `protobuf:"varint,4,opt,name=exact,proto3" json:"exact,omitempty"`
 }
 
 func (x *SearchRequest) Reset() {
@@ -355,12 +713,24 @@ func (x *SearchRequest) GetK() int32 {
 	return 0
 }
 
+func (x *SearchRequest) GetExact() bool {
+	if x != nil {
+		return x.Exact
+	}
+	return false
+}
+
 type Vector struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	Value []float32 `protobuf:"fixed32,1,rep,packed,name=value,proto3" json:"value,omitempty"`
+	// Data, if set, holds the vector instead of value: its components are
+	// encoded as set by encoding, in little-endian order. Binary vectors
+	// (see CreateIndexRequest.SpaceType.HAMMING) are best sent as BINARY.
+	Data     []byte          `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Encoding Vector_Encoding `protobuf:"varint,3,opt,name=encoding,proto3,enum=grpcapi.Vector_Encoding" json:"encoding,omitempty"`
 }
 
 func (x *Vector) Reset() {
@@ -402,6 +772,20 @@ func (x *Vector) GetValue() []float32 {
 	return nil
 }
 
+func (x *Vector) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Vector) GetEncoding() Vector_Encoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return Vector_FLOAT32
+}
+
 type DeleteIndexRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -814,8 +1198,17 @@ type Hit struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id       string  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`               // the id of the stored vector
-	Distance float32 `protobuf:"fixed32,2,opt,name=distance,proto3" json:"distance,omitempty"` // the distance between the stored vector and the query vector
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // the id of the stored vector
+	// Distance is the distance between the stored vector and the query vector,
+	// depending on the space type: the squared Euclidean distance for L2, one
+	// minus the inner product for IP and COSINE (on the normalized vectors),
+	// the number of differing bits for HAMMING, and the Manhattan distance
+	// for L1. Smaller is closer in every space.
+	Distance float32 `protobuf:"fixed32,2,opt,name=distance,proto3" json:"distance,omitempty"`
+	// Similarity is the inner product of the stored vector and the query
+	// vector (the cosine of their angle, for COSINE), set only for the IP
+	// and COSINE space types. Greater is closer.
+	Similarity *float32 `protobuf:"fixed32,3,opt,name=similarity,proto3,oneof" json:"similarity,omitempty"`
 }
 
 func (x *Hit) Reset() {
@@ -864,6 +1257,13 @@ func (x *Hit) GetDistance() float32 {
 	return 0
 }
 
+func (x *Hit) GetSimilarity() float32 {
+	if x != nil && x.Similarity != nil {
+		return *x.Similarity
+	}
+	return 0
+}
+
 type SetEfRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -919,225 +1319,2543 @@ func (x *SetEfRequest) GetValue() int32 {
 	return 0
 }
 
-var File_hnswservice_proto protoreflect.FileDescriptor
+type ExportIndexRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-var file_hnswservice_proto_rawDesc = []byte{
-	0x0a, 0x11, 0x68, 0x6e, 0x73, 0x77, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x12, 0x07, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x1a, 0x1b, 0x67, 0x6f,
-	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d,
-	0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xba, 0x02, 0x0a, 0x12, 0x43, 0x72,
-	0x65, 0x61, 0x74, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x12,
-	0x10, 0x0a, 0x03, 0x64, 0x69, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x64, 0x69,
-	0x6d, 0x12, 0x26, 0x0a, 0x0e, 0x65, 0x66, 0x43, 0x6f, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74,
-	0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x65, 0x66, 0x43, 0x6f, 0x6e,
-	0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0c, 0x0a, 0x01, 0x6d, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x05, 0x52, 0x01, 0x6d, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x61, 0x78, 0x5f, 0x65,
-	0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x6d,
-	0x61, 0x78, 0x45, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65,
-	0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x65, 0x65, 0x64, 0x12, 0x44,
-	0x0a, 0x0a, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x07, 0x20, 0x01,
-	0x28, 0x0e, 0x32, 0x25, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x72, 0x65,
-	0x61, 0x74, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e,
-	0x53, 0x70, 0x61, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x09, 0x73, 0x70, 0x61, 0x63, 0x65,
-	0x54, 0x79, 0x70, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x61, 0x75, 0x74, 0x6f, 0x5f, 0x69, 0x64, 0x18,
-	0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x61, 0x75, 0x74, 0x6f, 0x49, 0x64, 0x22, 0x27, 0x0a,
-	0x09, 0x53, 0x70, 0x61, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x06, 0x0a, 0x02, 0x4c, 0x32,
-	0x10, 0x00, 0x12, 0x06, 0x0a, 0x02, 0x49, 0x50, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x43, 0x4f,
-	0x53, 0x49, 0x4e, 0x45, 0x10, 0x02, 0x22, 0x5d, 0x0a, 0x13, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74,
-	0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a,
-	0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x09, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x27, 0x0a, 0x06,
-	0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x67,
-	0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x06, 0x76,
-	0x65, 0x63, 0x74, 0x6f, 0x72, 0x22, 0x73, 0x0a, 0x19, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56,
-	0x65, 0x63, 0x74, 0x6f, 0x72, 0x57, 0x69, 0x74, 0x68, 0x49, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d,
-	0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69,
-	0x64, 0x12, 0x27, 0x0a, 0x06, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x0f, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x56, 0x65, 0x63, 0x74,
-	0x6f, 0x72, 0x52, 0x06, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x22, 0x65, 0x0a, 0x0d, 0x53, 0x65,
-	0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x69,
-	0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x09, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x27, 0x0a, 0x06, 0x76, 0x65,
-	0x63, 0x74, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x67, 0x72, 0x70,
-	0x63, 0x61, 0x70, 0x69, 0x2e, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x06, 0x76, 0x65, 0x63,
-	0x74, 0x6f, 0x72, 0x12, 0x0c, 0x0a, 0x01, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x01,
-	0x6b, 0x22, 0x1e, 0x0a, 0x06, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x03, 0x28, 0x02, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x22, 0x33, 0x0a, 0x12, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78,
-	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x64,
-	0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x28, 0x0a, 0x0c, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65,
-	0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65,
-	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73,
-	0x22, 0x2d, 0x0a, 0x0c, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x22,
-	0x37, 0x0a, 0x11, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52,
-	0x65, 0x70, 0x6c, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x03, 0x52, 0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x22, 0x2d, 0x0a, 0x17, 0x49, 0x6e, 0x73, 0x65,
-	0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x57, 0x69, 0x74, 0x68, 0x49, 0x64, 0x52, 0x65,
-	0x70, 0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x03, 0x52, 0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x22, 0x3a, 0x0a, 0x12, 0x49, 0x6e, 0x73, 0x65, 0x72,
-	0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x10, 0x0a,
-	0x03, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x03, 0x69, 0x64, 0x73, 0x12,
-	0x12, 0x0a, 0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74,
-	0x6f, 0x6f, 0x6b, 0x22, 0x2f, 0x0a, 0x19, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63,
-	0x74, 0x6f, 0x72, 0x73, 0x57, 0x69, 0x74, 0x68, 0x49, 0x64, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79,
-	0x12, 0x12, 0x0a, 0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04,
-	0x74, 0x6f, 0x6f, 0x6b, 0x22, 0x46, 0x0a, 0x0e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x4b, 0x4e,
-	0x4e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x20, 0x0a, 0x04, 0x68, 0x69, 0x74, 0x73, 0x18, 0x01,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x48,
-	0x69, 0x74, 0x52, 0x04, 0x68, 0x69, 0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x6f, 0x6f, 0x6b,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x22, 0x31, 0x0a, 0x03,
-	0x48, 0x69, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x02, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x02, 0x52, 0x08, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x22,
-	0x43, 0x0a, 0x0c, 0x53, 0x65, 0x74, 0x45, 0x66, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x14,
-	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x32, 0xe7, 0x05, 0x0a, 0x06, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x12,
-	0x44, 0x0a, 0x0b, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1b,
-	0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x49,
-	0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f,
-	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d,
-	0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x44, 0x0a, 0x0b, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x49,
-	0x6e, 0x64, 0x65, 0x78, 0x12, 0x1b, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x44,
-	0x65, 0x6c, 0x65, 0x74, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x4a, 0x0a, 0x0c, 0x49,
-	0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x1c, 0x2e, 0x67, 0x72,
-	0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63, 0x74,
-	0x6f, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x67, 0x72, 0x70, 0x63,
-	0x61, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72,
-	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x4e, 0x0a, 0x0d, 0x49, 0x6e, 0x73, 0x65, 0x72,
-	0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x12, 0x1c, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61,
-	0x70, 0x69, 0x2e, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69,
-	0x2e, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x52, 0x65,
-	0x70, 0x6c, 0x79, 0x22, 0x00, 0x28, 0x01, 0x12, 0x5c, 0x0a, 0x12, 0x49, 0x6e, 0x73, 0x65, 0x72,
-	0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x57, 0x69, 0x74, 0x68, 0x49, 0x64, 0x12, 0x22, 0x2e,
-	0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65,
-	0x63, 0x74, 0x6f, 0x72, 0x57, 0x69, 0x74, 0x68, 0x49, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x20, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x73, 0x65,
-	0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x57, 0x69, 0x74, 0x68, 0x49, 0x64, 0x52, 0x65,
-	0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x62, 0x0a, 0x14, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56,
-	0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x57, 0x69, 0x74, 0x68, 0x49, 0x64, 0x73, 0x12, 0x22, 0x2e,
-	0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65,
-	0x63, 0x74, 0x6f, 0x72, 0x57, 0x69, 0x74, 0x68, 0x49, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x22, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x73, 0x65,
-	0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x57, 0x69, 0x74, 0x68, 0x49, 0x64, 0x73,
-	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x28, 0x01, 0x12, 0x3e, 0x0a, 0x09, 0x53, 0x65, 0x61,
-	0x72, 0x63, 0x68, 0x4b, 0x4e, 0x4e, 0x12, 0x16, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69,
-	0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17,
-	0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x4b,
-	0x4e, 0x4e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x3d, 0x0a, 0x0a, 0x46, 0x6c, 0x75,
-	0x73, 0x68, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x15, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70,
-	0x69, 0x2e, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16,
-	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
-	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x3a, 0x0a, 0x07, 0x49, 0x6e, 0x64, 0x69,
-	0x63, 0x65, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x15, 0x2e, 0x67, 0x72,
-	0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x52, 0x65, 0x70,
-	0x6c, 0x79, 0x22, 0x00, 0x12, 0x38, 0x0a, 0x05, 0x53, 0x65, 0x74, 0x45, 0x66, 0x12, 0x15, 0x2e,
-	0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x53, 0x65, 0x74, 0x45, 0x66, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x42, 0x41,
-	0x5a, 0x3f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x53, 0x70, 0x65,
-	0x63, 0x69, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x6c, 0x69,
-	0x73, 0x74, 0x2f, 0x68, 0x6e, 0x73, 0x77, 0x67, 0x2d, 0x67, 0x72, 0x70, 0x63, 0x2d, 0x73, 0x65,
-	0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70,
-	0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	IndexName string `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	// Encoding is the encoding of the exported vectors. FLOAT16, BFLOAT16
+	// and BINARY vectors are sent in the data field of Vector; FLOAT32 ones
+	// in value.
+	Encoding Vector_Encoding `protobuf:"varint,2,opt,name=encoding,proto3,enum=grpcapi.Vector_Encoding" json:"encoding,omitempty"`
 }
 
-var (
-	file_hnswservice_proto_rawDescOnce sync.Once
-	file_hnswservice_proto_rawDescData = file_hnswservice_proto_rawDesc
-)
+func (x *ExportIndexRequest) Reset() {
+	*x = ExportIndexRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
 
-func file_hnswservice_proto_rawDescGZIP() []byte {
-	file_hnswservice_proto_rawDescOnce.Do(func() {
-		file_hnswservice_proto_rawDescData = protoimpl.X.CompressGZIP(file_hnswservice_proto_rawDescData)
-	})
-	return file_hnswservice_proto_rawDescData
+func (x *ExportIndexRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var file_hnswservice_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_hnswservice_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
-var file_hnswservice_proto_goTypes = []interface{}{
-	(CreateIndexRequest_SpaceType)(0), // 0: grpcapi.CreateIndexRequest.SpaceType
-	(*CreateIndexRequest)(nil),        // 1: grpcapi.CreateIndexRequest
-	(*InsertVectorRequest)(nil),       // 2: grpcapi.InsertVectorRequest
-	(*InsertVectorWithIdRequest)(nil), // 3: grpcapi.InsertVectorWithIdRequest
-	(*SearchRequest)(nil),             // 4: grpcapi.SearchRequest
-	(*Vector)(nil),                    // 5: grpcapi.Vector
-	(*DeleteIndexRequest)(nil),        // 6: grpcapi.DeleteIndexRequest
-	(*IndicesReply)(nil),              // 7: grpcapi.IndicesReply
-	(*FlushRequest)(nil),              // 8: grpcapi.FlushRequest
-	(*InsertVectorReply)(nil),         // 9: grpcapi.InsertVectorReply
-	(*InsertVectorWithIdReply)(nil),   // 10: grpcapi.InsertVectorWithIdReply
-	(*InsertVectorsReply)(nil),        // 11: grpcapi.InsertVectorsReply
-	(*InsertVectorsWithIdsReply)(nil), // 12: grpcapi.InsertVectorsWithIdsReply
-	(*SearchKNNReply)(nil),            // 13: grpcapi.SearchKNNReply
-	(*Hit)(nil),                       // 14: grpcapi.Hit
-	(*SetEfRequest)(nil),              // 15: grpcapi.SetEfRequest
-	(*emptypb.Empty)(nil),             // 16: google.protobuf.Empty
+func (*ExportIndexRequest) ProtoMessage() {}
+
+func (x *ExportIndexRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
-var file_hnswservice_proto_depIdxs = []int32{
-	0,  // 0: grpcapi.CreateIndexRequest.space_type:type_name -> grpcapi.CreateIndexRequest.SpaceType
-	5,  // 1: grpcapi.InsertVectorRequest.vector:type_name -> grpcapi.Vector
-	5,  // 2: grpcapi.InsertVectorWithIdRequest.vector:type_name -> grpcapi.Vector
-	5,  // 3: grpcapi.SearchRequest.vector:type_name -> grpcapi.Vector
-	14, // 4: grpcapi.SearchKNNReply.hits:type_name -> grpcapi.Hit
-	1,  // 5: grpcapi.Server.CreateIndex:input_type -> grpcapi.CreateIndexRequest
-	6,  // 6: grpcapi.Server.DeleteIndex:input_type -> grpcapi.DeleteIndexRequest
-	2,  // 7: grpcapi.Server.InsertVector:input_type -> grpcapi.InsertVectorRequest
-	2,  // 8: grpcapi.Server.InsertVectors:input_type -> grpcapi.InsertVectorRequest
-	3,  // 9: grpcapi.Server.InsertVectorWithId:input_type -> grpcapi.InsertVectorWithIdRequest
-	3,  // 10: grpcapi.Server.InsertVectorsWithIds:input_type -> grpcapi.InsertVectorWithIdRequest
-	4,  // 11: grpcapi.Server.SearchKNN:input_type -> grpcapi.SearchRequest
-	8,  // 12: grpcapi.Server.FlushIndex:input_type -> grpcapi.FlushRequest
-	16, // 13: grpcapi.Server.Indices:input_type -> google.protobuf.Empty
-	15, // 14: grpcapi.Server.SetEf:input_type -> grpcapi.SetEfRequest
-	16, // 15: grpcapi.Server.CreateIndex:output_type -> google.protobuf.Empty
-	16, // 16: grpcapi.Server.DeleteIndex:output_type -> google.protobuf.Empty
-	9,  // 17: grpcapi.Server.InsertVector:output_type -> grpcapi.InsertVectorReply
-	11, // 18: grpcapi.Server.InsertVectors:output_type -> grpcapi.InsertVectorsReply
-	10, // 19: grpcapi.Server.InsertVectorWithId:output_type -> grpcapi.InsertVectorWithIdReply
-	12, // 20: grpcapi.Server.InsertVectorsWithIds:output_type -> grpcapi.InsertVectorsWithIdsReply
-	13, // 21: grpcapi.Server.SearchKNN:output_type -> grpcapi.SearchKNNReply
-	16, // 22: grpcapi.Server.FlushIndex:output_type -> google.protobuf.Empty
-	7,  // 23: grpcapi.Server.Indices:output_type -> grpcapi.IndicesReply
-	16, // 24: grpcapi.Server.SetEf:output_type -> google.protobuf.Empty
-	15, // [15:25] is the sub-list for method output_type
-	5,  // [5:15] is the sub-list for method input_type
-	5,  // [5:5] is the sub-list for extension type_name
-	5,  // [5:5] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+
+// Deprecated: Use ExportIndexRequest.ProtoReflect.Descriptor instead.
+func (*ExportIndexRequest) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{15}
 }
 
-func init() { file_hnswservice_proto_init() }
-func file_hnswservice_proto_init() {
-	if File_hnswservice_proto != nil {
-		return
+func (x *ExportIndexRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_hnswservice_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateIndexRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+	return ""
+}
+
+func (x *ExportIndexRequest) GetEncoding() Vector_Encoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return Vector_FLOAT32
+}
+
+type ExportIndexReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // the id of the stored vector
+	// Vector is the stored vector. For cosine spaces, it is normalized.
+	Vector *Vector `protobuf:"bytes,2,opt,name=vector,proto3" json:"vector,omitempty"`
+}
+
+func (x *ExportIndexReply) Reset() {
+	*x = ExportIndexReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportIndexReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportIndexReply) ProtoMessage() {}
+
+func (x *ExportIndexReply) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportIndexReply.ProtoReflect.Descriptor instead.
+func (*ExportIndexReply) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ExportIndexReply) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ExportIndexReply) GetVector() *Vector {
+	if x != nil {
+		return x.Vector
+	}
+	return nil
+}
+
+type GetVectorsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IndexName string  `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	Ids       []int32 `protobuf:"varint,2,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	// Encoding is the encoding of the returned vectors, as for
+	// ExportIndexRequest.
+	Encoding Vector_Encoding `protobuf:"varint,3,opt,name=encoding,proto3,enum=grpcapi.Vector_Encoding" json:"encoding,omitempty"`
+	// Original makes COSINE indices return the vectors as they were inserted,
+	// instead of normalized. It requires an HNSW index with FLOAT32 storage,
+	// which stores the original norms of the vectors; it is an error for the
+	// other COSINE indices, and it has no effect for the other space types.
+	Original bool `protobuf:"varint,4,opt,name=original,proto3" json:"original,omitempty"`
+}
+
+func (x *GetVectorsRequest) Reset() {
+	*x = GetVectorsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetVectorsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVectorsRequest) ProtoMessage() {}
+
+func (x *GetVectorsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVectorsRequest.ProtoReflect.Descriptor instead.
+func (*GetVectorsRequest) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetVectorsRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *GetVectorsRequest) GetIds() []int32 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *GetVectorsRequest) GetEncoding() Vector_Encoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return Vector_FLOAT32
+}
+
+func (x *GetVectorsRequest) GetOriginal() bool {
+	if x != nil {
+		return x.Original
+	}
+	return false
+}
+
+type GetVectorsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Vectors are the requested vectors which are found, in the same order.
+	Vectors []*StoredVector `protobuf:"bytes,1,rep,name=vectors,proto3" json:"vectors,omitempty"`
+	// NotFoundIds are the requested IDs which are unknown, or marked deleted.
+	NotFoundIds []string `protobuf:"bytes,2,rep,name=not_found_ids,json=notFoundIds,proto3" json:"not_found_ids,omitempty"`
+	// Took is the number of milliseconds it took the server to execute the request.
+	Took int64 `protobuf:"varint,3,opt,name=took,proto3" json:"took,omitempty"`
+}
+
+func (x *GetVectorsReply) Reset() {
+	*x = GetVectorsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetVectorsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVectorsReply) ProtoMessage() {}
+
+func (x *GetVectorsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVectorsReply.ProtoReflect.Descriptor instead.
+func (*GetVectorsReply) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetVectorsReply) GetVectors() []*StoredVector {
+	if x != nil {
+		return x.Vectors
+	}
+	return nil
+}
+
+func (x *GetVectorsReply) GetNotFoundIds() []string {
+	if x != nil {
+		return x.NotFoundIds
+	}
+	return nil
+}
+
+func (x *GetVectorsReply) GetTook() int64 {
+	if x != nil {
+		return x.Took
+	}
+	return 0
+}
+
+type StoredVector struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id     string  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // the id of the stored vector
+	Vector *Vector `protobuf:"bytes,2,opt,name=vector,proto3" json:"vector,omitempty"`
+}
+
+func (x *StoredVector) Reset() {
+	*x = StoredVector{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StoredVector) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoredVector) ProtoMessage() {}
+
+func (x *StoredVector) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoredVector.ProtoReflect.Descriptor instead.
+func (*StoredVector) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *StoredVector) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *StoredVector) GetVector() *Vector {
+	if x != nil {
+		return x.Vector
+	}
+	return nil
+}
+
+type CompactIndexRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IndexName string `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	// Wait makes the call return only once the compaction is complete.
+	// Otherwise, the compaction is started in background.
+	Wait bool `protobuf:"varint,2,opt,name=wait,proto3" json:"wait,omitempty"`
+}
+
+func (x *CompactIndexRequest) Reset() {
+	*x = CompactIndexRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompactIndexRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompactIndexRequest) ProtoMessage() {}
+
+func (x *CompactIndexRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompactIndexRequest.ProtoReflect.Descriptor instead.
+func (*CompactIndexRequest) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *CompactIndexRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *CompactIndexRequest) GetWait() bool {
+	if x != nil {
+		return x.Wait
+	}
+	return false
+}
+
+type DeleteVectorsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IndexName string  `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	Ids       []int32 `protobuf:"varint,2,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+}
+
+func (x *DeleteVectorsRequest) Reset() {
+	*x = DeleteVectorsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteVectorsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteVectorsRequest) ProtoMessage() {}
+
+func (x *DeleteVectorsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteVectorsRequest.ProtoReflect.Descriptor instead.
+func (*DeleteVectorsRequest) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DeleteVectorsRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *DeleteVectorsRequest) GetIds() []int32 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type DeleteVectorsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Took is the number of milliseconds it took the server to execute the request.
+	Took int64 `protobuf:"varint,2,opt,name=took,proto3" json:"took,omitempty"`
+}
+
+func (x *DeleteVectorsReply) Reset() {
+	*x = DeleteVectorsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteVectorsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteVectorsReply) ProtoMessage() {}
+
+func (x *DeleteVectorsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteVectorsReply.ProtoReflect.Descriptor instead.
+func (*DeleteVectorsReply) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DeleteVectorsReply) GetTook() int64 {
+	if x != nil {
+		return x.Took
+	}
+	return 0
+}
+
+type UndeleteVectorsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IndexName string  `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	Ids       []int32 `protobuf:"varint,2,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+}
+
+func (x *UndeleteVectorsRequest) Reset() {
+	*x = UndeleteVectorsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UndeleteVectorsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndeleteVectorsRequest) ProtoMessage() {}
+
+func (x *UndeleteVectorsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndeleteVectorsRequest.ProtoReflect.Descriptor instead.
+func (*UndeleteVectorsRequest) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *UndeleteVectorsRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *UndeleteVectorsRequest) GetIds() []int32 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type UndeleteVectorsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// NotFoundIds are the requested IDs which cannot be restored: they are
+	// unknown, or their vectors were removed by a compaction, or replaced by
+	// new vectors.
+	NotFoundIds []string `protobuf:"bytes,1,rep,name=not_found_ids,json=notFoundIds,proto3" json:"not_found_ids,omitempty"`
+	// Took is the number of milliseconds it took the server to execute the request.
+	Took int64 `protobuf:"varint,2,opt,name=took,proto3" json:"took,omitempty"`
+}
+
+func (x *UndeleteVectorsReply) Reset() {
+	*x = UndeleteVectorsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UndeleteVectorsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndeleteVectorsReply) ProtoMessage() {}
+
+func (x *UndeleteVectorsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndeleteVectorsReply.ProtoReflect.Descriptor instead.
+func (*UndeleteVectorsReply) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *UndeleteVectorsReply) GetNotFoundIds() []string {
+	if x != nil {
+		return x.NotFoundIds
+	}
+	return nil
+}
+
+func (x *UndeleteVectorsReply) GetTook() int64 {
+	if x != nil {
+		return x.Took
+	}
+	return 0
+}
+
+type DescribeIndexRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IndexName string `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+}
+
+func (x *DescribeIndexRequest) Reset() {
+	*x = DescribeIndexRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DescribeIndexRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DescribeIndexRequest) ProtoMessage() {}
+
+func (x *DescribeIndexRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DescribeIndexRequest.ProtoReflect.Descriptor instead.
+func (*DescribeIndexRequest) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *DescribeIndexRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+type DescribeIndexReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IndexName           string                       `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	Dim                 int32                        `protobuf:"varint,2,opt,name=dim,proto3" json:"dim,omitempty"`
+	EfConstruction      int32                        `protobuf:"varint,3,opt,name=efConstruction,proto3" json:"efConstruction,omitempty"`
+	M                   int32                        `protobuf:"varint,4,opt,name=m,proto3" json:"m,omitempty"`
+	MaxElements         int32                        `protobuf:"varint,5,opt,name=max_elements,json=maxElements,proto3" json:"max_elements,omitempty"`
+	Seed                int32                        `protobuf:"varint,6,opt,name=seed,proto3" json:"seed,omitempty"`
+	SpaceType           CreateIndexRequest_SpaceType `protobuf:"varint,7,opt,name=space_type,json=spaceType,proto3,enum=grpcapi.CreateIndexRequest_SpaceType" json:"space_type,omitempty"`
+	AutoId              bool                         `protobuf:"varint,8,opt,name=auto_id,json=autoId,proto3" json:"auto_id,omitempty"`
+	AllowReplaceDeleted bool                         `protobuf:"varint,9,opt,name=allow_replace_deleted,json=allowReplaceDeleted,proto3" json:"allow_replace_deleted,omitempty"`
+	DefaultTtlSeconds   int64                        `protobuf:"varint,10,opt,name=default_ttl_seconds,json=defaultTtlSeconds,proto3" json:"default_ttl_seconds,omitempty"`
+	// Size is the number of vectors which are not marked deleted.
+	Size            int64                          `protobuf:"varint,11,opt,name=size,proto3" json:"size,omitempty"`
+	Expiry          *ExpiryStats                   `protobuf:"bytes,12,opt,name=expiry,proto3" json:"expiry,omitempty"`
+	IndexType       CreateIndexRequest_IndexType   `protobuf:"varint,13,opt,name=index_type,json=indexType,proto3,enum=grpcapi.CreateIndexRequest_IndexType" json:"index_type,omitempty"`
+	StorageType     CreateIndexRequest_StorageType `protobuf:"varint,14,opt,name=storage_type,json=storageType,proto3,enum=grpcapi.CreateIndexRequest_StorageType" json:"storage_type,omitempty"`
+	TrainingSize    int32                          `protobuf:"varint,15,opt,name=training_size,json=trainingSize,proto3" json:"training_size,omitempty"`
+	Rerank          bool                           `protobuf:"varint,16,opt,name=rerank,proto3" json:"rerank,omitempty"`
+	PqSubquantizers int32                          `protobuf:"varint,17,opt,name=pq_subquantizers,json=pqSubquantizers,proto3" json:"pq_subquantizers,omitempty"`
+	PqBits          int32                          `protobuf:"varint,18,opt,name=pq_bits,json=pqBits,proto3" json:"pq_bits,omitempty"`
+	// Resident reports whether the index was loaded in memory when the request
+	// was received. With lazy loading, indices which are not resident are
+	// loaded by the request.
+	Resident bool `protobuf:"varint,19,opt,name=resident,proto3" json:"resident,omitempty"`
+	// Status is the loading status of the index. Unless it is READY, only the
+	// index name, the status and the load error are set.
+	Status IndexStatus `protobuf:"varint,20,opt,name=status,proto3,enum=grpcapi.IndexStatus" json:"status,omitempty"`
+	// LoadError is the error which made the loading fail, if the status is
+	// FAILED.
+	LoadError string `protobuf:"bytes,21,opt,name=load_error,json=loadError,proto3" json:"load_error,omitempty"`
+}
+
+func (x *DescribeIndexReply) Reset() {
+	*x = DescribeIndexReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DescribeIndexReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DescribeIndexReply) ProtoMessage() {}
+
+func (x *DescribeIndexReply) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DescribeIndexReply.ProtoReflect.Descriptor instead.
+func (*DescribeIndexReply) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *DescribeIndexReply) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *DescribeIndexReply) GetDim() int32 {
+	if x != nil {
+		return x.Dim
+	}
+	return 0
+}
+
+func (x *DescribeIndexReply) GetEfConstruction() int32 {
+	if x != nil {
+		return x.EfConstruction
+	}
+	return 0
+}
+
+func (x *DescribeIndexReply) GetM() int32 {
+	if x != nil {
+		return x.M
+	}
+	return 0
+}
+
+func (x *DescribeIndexReply) GetMaxElements() int32 {
+	if x != nil {
+		return x.MaxElements
+	}
+	return 0
+}
+
+func (x *DescribeIndexReply) GetSeed() int32 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *DescribeIndexReply) GetSpaceType() CreateIndexRequest_SpaceType {
+	if x != nil {
+		return x.SpaceType
+	}
+	return CreateIndexRequest_L2
+}
+
+func (x *DescribeIndexReply) GetAutoId() bool {
+	if x != nil {
+		return x.AutoId
+	}
+	return false
+}
+
+func (x *DescribeIndexReply) GetAllowReplaceDeleted() bool {
+	if x != nil {
+		return x.AllowReplaceDeleted
+	}
+	return false
+}
+
+func (x *DescribeIndexReply) GetDefaultTtlSeconds() int64 {
+	if x != nil {
+		return x.DefaultTtlSeconds
+	}
+	return 0
+}
+
+func (x *DescribeIndexReply) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *DescribeIndexReply) GetExpiry() *ExpiryStats {
+	if x != nil {
+		return x.Expiry
+	}
+	return nil
+}
+
+func (x *DescribeIndexReply) GetIndexType() CreateIndexRequest_IndexType {
+	if x != nil {
+		return x.IndexType
+	}
+	return CreateIndexRequest_HNSW
+}
+
+func (x *DescribeIndexReply) GetStorageType() CreateIndexRequest_StorageType {
+	if x != nil {
+		return x.StorageType
+	}
+	return CreateIndexRequest_FLOAT32
+}
+
+func (x *DescribeIndexReply) GetTrainingSize() int32 {
+	if x != nil {
+		return x.TrainingSize
+	}
+	return 0
+}
+
+func (x *DescribeIndexReply) GetRerank() bool {
+	if x != nil {
+		return x.Rerank
+	}
+	return false
+}
+
+func (x *DescribeIndexReply) GetPqSubquantizers() int32 {
+	if x != nil {
+		return x.PqSubquantizers
+	}
+	return 0
+}
+
+func (x *DescribeIndexReply) GetPqBits() int32 {
+	if x != nil {
+		return x.PqBits
+	}
+	return 0
+}
+
+func (x *DescribeIndexReply) GetResident() bool {
+	if x != nil {
+		return x.Resident
+	}
+	return false
+}
+
+func (x *DescribeIndexReply) GetStatus() IndexStatus {
+	if x != nil {
+		return x.Status
+	}
+	return IndexStatus_READY
+}
+
+func (x *DescribeIndexReply) GetLoadError() string {
+	if x != nil {
+		return x.LoadError
+	}
+	return ""
+}
+
+type ExpiryStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Pending is the number of vectors which are set to expire.
+	Pending int64 `protobuf:"varint,1,opt,name=pending,proto3" json:"pending,omitempty"`
+	// NextExpiration is the earliest expiration time of the pending vectors,
+	// as Unix time in seconds, or zero if there are none.
+	NextExpiration int64 `protobuf:"varint,2,opt,name=next_expiration,json=nextExpiration,proto3" json:"next_expiration,omitempty"`
+	// Expired is the number of vectors marked deleted because expired, since
+	// the index was loaded.
+	Expired int64 `protobuf:"varint,3,opt,name=expired,proto3" json:"expired,omitempty"`
+}
+
+func (x *ExpiryStats) Reset() {
+	*x = ExpiryStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExpiryStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpiryStats) ProtoMessage() {}
+
+func (x *ExpiryStats) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpiryStats.ProtoReflect.Descriptor instead.
+func (*ExpiryStats) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ExpiryStats) GetPending() int64 {
+	if x != nil {
+		return x.Pending
+	}
+	return 0
+}
+
+func (x *ExpiryStats) GetNextExpiration() int64 {
+	if x != nil {
+		return x.NextExpiration
+	}
+	return 0
+}
+
+func (x *ExpiryStats) GetExpired() int64 {
+	if x != nil {
+		return x.Expired
+	}
+	return 0
+}
+
+type EvaluateRecallRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IndexName string `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	// K is the number of nearest neighbors searched for each query.
+	K int32 `protobuf:"varint,2,opt,name=k,proto3" json:"k,omitempty"`
+	// SampleSize is the number of stored vectors, chosen at random, used as
+	// queries when no queries are given. It defaults to 100.
+	SampleSize int32     `protobuf:"varint,3,opt,name=sample_size,json=sampleSize,proto3" json:"sample_size,omitempty"`
+	Queries    []*Vector `protobuf:"bytes,4,rep,name=queries,proto3" json:"queries,omitempty"`
+}
+
+func (x *EvaluateRecallRequest) Reset() {
+	*x = EvaluateRecallRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EvaluateRecallRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvaluateRecallRequest) ProtoMessage() {}
+
+func (x *EvaluateRecallRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvaluateRecallRequest.ProtoReflect.Descriptor instead.
+func (*EvaluateRecallRequest) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *EvaluateRecallRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *EvaluateRecallRequest) GetK() int32 {
+	if x != nil {
+		return x.K
+	}
+	return 0
+}
+
+func (x *EvaluateRecallRequest) GetSampleSize() int32 {
+	if x != nil {
+		return x.SampleSize
+	}
+	return 0
+}
+
+func (x *EvaluateRecallRequest) GetQueries() []*Vector {
+	if x != nil {
+		return x.Queries
+	}
+	return nil
+}
+
+type EvaluateRecallReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Recall is the mean fraction of the exact k nearest neighbors which are
+	// also found by the approximate search (recall@k).
+	Recall float32 `protobuf:"fixed32,1,opt,name=recall,proto3" json:"recall,omitempty"`
+	// Queries is the number of queries evaluated.
+	Queries int32 `protobuf:"varint,2,opt,name=queries,proto3" json:"queries,omitempty"`
+	// Ef is the `ef` parameter used by the approximate searches.
+	Ef int32 `protobuf:"varint,3,opt,name=ef,proto3" json:"ef,omitempty"`
+	// ApproximateLatency is the mean number of milliseconds taken by the
+	// approximate searches.
+	ApproximateLatency float32 `protobuf:"fixed32,4,opt,name=approximate_latency,json=approximateLatency,proto3" json:"approximate_latency,omitempty"`
+	// ExactLatency is the mean number of milliseconds taken by the exact
+	// searches.
+	ExactLatency float32 `protobuf:"fixed32,5,opt,name=exact_latency,json=exactLatency,proto3" json:"exact_latency,omitempty"`
+	// Took is the number of milliseconds it took the server to execute the request.
+	Took int64 `protobuf:"varint,6,opt,name=took,proto3" json:"took,omitempty"`
+}
+
+func (x *EvaluateRecallReply) Reset() {
+	*x = EvaluateRecallReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EvaluateRecallReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvaluateRecallReply) ProtoMessage() {}
+
+func (x *EvaluateRecallReply) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvaluateRecallReply.ProtoReflect.Descriptor instead.
+func (*EvaluateRecallReply) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *EvaluateRecallReply) GetRecall() float32 {
+	if x != nil {
+		return x.Recall
+	}
+	return 0
+}
+
+func (x *EvaluateRecallReply) GetQueries() int32 {
+	if x != nil {
+		return x.Queries
+	}
+	return 0
+}
+
+func (x *EvaluateRecallReply) GetEf() int32 {
+	if x != nil {
+		return x.Ef
+	}
+	return 0
+}
+
+func (x *EvaluateRecallReply) GetApproximateLatency() float32 {
+	if x != nil {
+		return x.ApproximateLatency
+	}
+	return 0
+}
+
+func (x *EvaluateRecallReply) GetExactLatency() float32 {
+	if x != nil {
+		return x.ExactLatency
+	}
+	return 0
+}
+
+func (x *EvaluateRecallReply) GetTook() int64 {
+	if x != nil {
+		return x.Took
+	}
+	return 0
+}
+
+type AutoTuneEfRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IndexName string `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	// TargetRecall is the recall@k to be met, in (0, 1].
+	TargetRecall float32 `protobuf:"fixed32,2,opt,name=target_recall,json=targetRecall,proto3" json:"target_recall,omitempty"`
+	// K is the number of nearest neighbors searched for each query.
+	K int32 `protobuf:"varint,3,opt,name=k,proto3" json:"k,omitempty"`
+	// SampleSize is the number of stored vectors, chosen at random, used as
+	// queries when no queries are given. It defaults to 100.
+	SampleSize int32     `protobuf:"varint,4,opt,name=sample_size,json=sampleSize,proto3" json:"sample_size,omitempty"`
+	Queries    []*Vector `protobuf:"bytes,5,rep,name=queries,proto3" json:"queries,omitempty"`
+	// MaxEf is the maximum `ef` parameter which can be chosen. It defaults
+	// to 1000.
+	MaxEf int32 `protobuf:"varint,6,opt,name=max_ef,json=maxEf,proto3" json:"max_ef,omitempty"`
+}
+
+func (x *AutoTuneEfRequest) Reset() {
+	*x = AutoTuneEfRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AutoTuneEfRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AutoTuneEfRequest) ProtoMessage() {}
+
+func (x *AutoTuneEfRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AutoTuneEfRequest.ProtoReflect.Descriptor instead.
+func (*AutoTuneEfRequest) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *AutoTuneEfRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *AutoTuneEfRequest) GetTargetRecall() float32 {
+	if x != nil {
+		return x.TargetRecall
+	}
+	return 0
+}
+
+func (x *AutoTuneEfRequest) GetK() int32 {
+	if x != nil {
+		return x.K
+	}
+	return 0
+}
+
+func (x *AutoTuneEfRequest) GetSampleSize() int32 {
+	if x != nil {
+		return x.SampleSize
+	}
+	return 0
+}
+
+func (x *AutoTuneEfRequest) GetQueries() []*Vector {
+	if x != nil {
+		return x.Queries
+	}
+	return nil
+}
+
+func (x *AutoTuneEfRequest) GetMaxEf() int32 {
+	if x != nil {
+		return x.MaxEf
+	}
+	return 0
+}
+
+type AutoTuneEfReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Ef is the chosen `ef` parameter, which is set on the index. If the
+	// target recall cannot be met, it is max_ef.
+	Ef int32 `protobuf:"varint,1,opt,name=ef,proto3" json:"ef,omitempty"`
+	// Recall is the recall@k measured with the chosen `ef`.
+	Recall        float32 `protobuf:"fixed32,2,opt,name=recall,proto3" json:"recall,omitempty"`
+	TargetReached bool    `protobuf:"varint,3,opt,name=target_reached,json=targetReached,proto3" json:"target_reached,omitempty"`
+	// Measurements are taken for each `ef` evaluated, in ascending order.
+	Measurements []*RecallMeasurement `protobuf:"bytes,4,rep,name=measurements,proto3" json:"measurements,omitempty"`
+	// ExactLatency is the mean number of milliseconds taken by the exact
+	// searches.
+	ExactLatency float32 `protobuf:"fixed32,5,opt,name=exact_latency,json=exactLatency,proto3" json:"exact_latency,omitempty"`
+	// Took is the number of milliseconds it took the server to execute the request.
+	Took int64 `protobuf:"varint,6,opt,name=took,proto3" json:"took,omitempty"`
+}
+
+func (x *AutoTuneEfReply) Reset() {
+	*x = AutoTuneEfReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AutoTuneEfReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AutoTuneEfReply) ProtoMessage() {}
+
+func (x *AutoTuneEfReply) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AutoTuneEfReply.ProtoReflect.Descriptor instead.
+func (*AutoTuneEfReply) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *AutoTuneEfReply) GetEf() int32 {
+	if x != nil {
+		return x.Ef
+	}
+	return 0
+}
+
+func (x *AutoTuneEfReply) GetRecall() float32 {
+	if x != nil {
+		return x.Recall
+	}
+	return 0
+}
+
+func (x *AutoTuneEfReply) GetTargetReached() bool {
+	if x != nil {
+		return x.TargetReached
+	}
+	return false
+}
+
+func (x *AutoTuneEfReply) GetMeasurements() []*RecallMeasurement {
+	if x != nil {
+		return x.Measurements
+	}
+	return nil
+}
+
+func (x *AutoTuneEfReply) GetExactLatency() float32 {
+	if x != nil {
+		return x.ExactLatency
+	}
+	return 0
+}
+
+func (x *AutoTuneEfReply) GetTook() int64 {
+	if x != nil {
+		return x.Took
+	}
+	return 0
+}
+
+type RecallMeasurement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ef     int32   `protobuf:"varint,1,opt,name=ef,proto3" json:"ef,omitempty"`
+	Recall float32 `protobuf:"fixed32,2,opt,name=recall,proto3" json:"recall,omitempty"`
+	// ApproximateLatency is the mean number of milliseconds taken by the
+	// approximate searches.
+	ApproximateLatency float32 `protobuf:"fixed32,3,opt,name=approximate_latency,json=approximateLatency,proto3" json:"approximate_latency,omitempty"`
+}
+
+func (x *RecallMeasurement) Reset() {
+	*x = RecallMeasurement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecallMeasurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecallMeasurement) ProtoMessage() {}
+
+func (x *RecallMeasurement) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecallMeasurement.ProtoReflect.Descriptor instead.
+func (*RecallMeasurement) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *RecallMeasurement) GetEf() int32 {
+	if x != nil {
+		return x.Ef
+	}
+	return 0
+}
+
+func (x *RecallMeasurement) GetRecall() float32 {
+	if x != nil {
+		return x.Recall
+	}
+	return 0
+}
+
+func (x *RecallMeasurement) GetApproximateLatency() float32 {
+	if x != nil {
+		return x.ApproximateLatency
+	}
+	return 0
+}
+
+type FailedIndicesReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Indices []*FailedIndex `protobuf:"bytes,1,rep,name=indices,proto3" json:"indices,omitempty"`
+}
+
+func (x *FailedIndicesReply) Reset() {
+	*x = FailedIndicesReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FailedIndicesReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FailedIndicesReply) ProtoMessage() {}
+
+func (x *FailedIndicesReply) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FailedIndicesReply.ProtoReflect.Descriptor instead.
+func (*FailedIndicesReply) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *FailedIndicesReply) GetIndices() []*FailedIndex {
+	if x != nil {
+		return x.Indices
+	}
+	return nil
+}
+
+type FailedIndex struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IndexName string `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	// Error is the error which made the loading fail.
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Quarantined reports whether the index dir was moved to the `.quarantine`
+	// dir, so that it is no longer loaded at startup.
+	Quarantined bool `protobuf:"varint,3,opt,name=quarantined,proto3" json:"quarantined,omitempty"`
+}
+
+func (x *FailedIndex) Reset() {
+	*x = FailedIndex{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FailedIndex) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FailedIndex) ProtoMessage() {}
+
+func (x *FailedIndex) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FailedIndex.ProtoReflect.Descriptor instead.
+func (*FailedIndex) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *FailedIndex) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *FailedIndex) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *FailedIndex) GetQuarantined() bool {
+	if x != nil {
+		return x.Quarantined
+	}
+	return false
+}
+
+type RetryIndexRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IndexName string `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	// Repair replaces the last snapshot of the index with the previous one,
+	// kept when the index was last saved, before loading it. The operations
+	// performed since the previous snapshot are replayed from the write-ahead
+	// log.
+	Repair bool `protobuf:"varint,2,opt,name=repair,proto3" json:"repair,omitempty"`
+}
+
+func (x *RetryIndexRequest) Reset() {
+	*x = RetryIndexRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RetryIndexRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryIndexRequest) ProtoMessage() {}
+
+func (x *RetryIndexRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryIndexRequest.ProtoReflect.Descriptor instead.
+func (*RetryIndexRequest) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *RetryIndexRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *RetryIndexRequest) GetRepair() bool {
+	if x != nil {
+		return x.Repair
+	}
+	return false
+}
+
+type VerifyIndexRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IndexName string `protobuf:"bytes,1,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	// Repair discards the corrupted end of the write-ahead log, if any, so
+	// that the entries appended to it afterwards are not lost. Loaded indices
+	// are saved instead, emptying the log.
+	Repair bool `protobuf:"varint,2,opt,name=repair,proto3" json:"repair,omitempty"`
+}
+
+func (x *VerifyIndexRequest) Reset() {
+	*x = VerifyIndexRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerifyIndexRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyIndexRequest) ProtoMessage() {}
+
+func (x *VerifyIndexRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyIndexRequest.ProtoReflect.Descriptor instead.
+func (*VerifyIndexRequest) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *VerifyIndexRequest) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+func (x *VerifyIndexRequest) GetRepair() bool {
+	if x != nil {
+		return x.Repair
+	}
+	return false
+}
+
+type VerifyIndexReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Ok reports whether no problem was found, or all of them were repaired.
+	Ok bool `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	// Problems describe the problems found, which prevent the index from
+	// being loaded, or make it behave incorrectly or lose data.
+	Problems []string `protobuf:"bytes,2,rep,name=problems,proto3" json:"problems,omitempty"`
+	// Warnings describe the findings which are not problems, such as the
+	// repairs performed.
+	Warnings []string `protobuf:"bytes,3,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// StateError is the error decoding the state of the index, if any.
+	StateError string `protobuf:"bytes,4,opt,name=state_error,json=stateError,proto3" json:"state_error,omitempty"`
+	// IndexError is the error loading the data structure of the index, if any.
+	IndexError string `protobuf:"bytes,5,opt,name=index_error,json=indexError,proto3" json:"index_error,omitempty"`
+	// Graph is not set for flat indices, for quantized indices which are not
+	// trained yet, and for indices which cannot be loaded.
+	Graph *GraphCheck `protobuf:"bytes,6,opt,name=graph,proto3" json:"graph,omitempty"`
+	Log   *LogCheck   `protobuf:"bytes,7,opt,name=log,proto3" json:"log,omitempty"`
+	// Took is the number of milliseconds it took the server to execute the request.
+	Took int64 `protobuf:"varint,8,opt,name=took,proto3" json:"took,omitempty"`
+}
+
+func (x *VerifyIndexReply) Reset() {
+	*x = VerifyIndexReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerifyIndexReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyIndexReply) ProtoMessage() {}
+
+func (x *VerifyIndexReply) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyIndexReply.ProtoReflect.Descriptor instead.
+func (*VerifyIndexReply) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *VerifyIndexReply) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *VerifyIndexReply) GetProblems() []string {
+	if x != nil {
+		return x.Problems
+	}
+	return nil
+}
+
+func (x *VerifyIndexReply) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *VerifyIndexReply) GetStateError() string {
+	if x != nil {
+		return x.StateError
+	}
+	return ""
+}
+
+func (x *VerifyIndexReply) GetIndexError() string {
+	if x != nil {
+		return x.IndexError
+	}
+	return ""
+}
+
+func (x *VerifyIndexReply) GetGraph() *GraphCheck {
+	if x != nil {
+		return x.Graph
+	}
+	return nil
+}
+
+func (x *VerifyIndexReply) GetLog() *LogCheck {
+	if x != nil {
+		return x.Log
+	}
+	return nil
+}
+
+func (x *VerifyIndexReply) GetTook() int64 {
+	if x != nil {
+		return x.Took
+	}
+	return 0
+}
+
+type GraphCheck struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Elements is the number of elements, including the ones marked deleted.
+	Elements uint64 `protobuf:"varint,1,opt,name=elements,proto3" json:"elements,omitempty"`
+	Deleted  uint64 `protobuf:"varint,2,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	// Connections is the number of links between the elements, at all levels.
+	Connections uint64 `protobuf:"varint,3,opt,name=connections,proto3" json:"connections,omitempty"`
+	// InvalidLinks is the number of links to elements which do not exist.
+	InvalidLinks   uint64 `protobuf:"varint,4,opt,name=invalid_links,json=invalidLinks,proto3" json:"invalid_links,omitempty"`
+	SelfLinks      uint64 `protobuf:"varint,5,opt,name=self_links,json=selfLinks,proto3" json:"self_links,omitempty"`
+	DuplicateLinks uint64 `protobuf:"varint,6,opt,name=duplicate_links,json=duplicateLinks,proto3" json:"duplicate_links,omitempty"`
+	// OversizedLists is the number of link lists longer than allowed by `m`.
+	OversizedLists uint64 `protobuf:"varint,7,opt,name=oversized_lists,json=oversizedLists,proto3" json:"oversized_lists,omitempty"`
+	// InvalidLevels is the number of elements above the top level of the graph.
+	InvalidLevels     uint64 `protobuf:"varint,8,opt,name=invalid_levels,json=invalidLevels,proto3" json:"invalid_levels,omitempty"`
+	InvalidEntryPoint bool   `protobuf:"varint,9,opt,name=invalid_entry_point,json=invalidEntryPoint,proto3" json:"invalid_entry_point,omitempty"`
+	// InconsistentLabels is the number of elements whose ID is not mapped to
+	// them, plus the number of IDs mapped to elements with another ID.
+	InconsistentLabels uint64 `protobuf:"varint,10,opt,name=inconsistent_labels,json=inconsistentLabels,proto3" json:"inconsistent_labels,omitempty"`
+	// Unreachable is the number of elements, besides the entry point, which
+	// are not linked by any other element.
+	Unreachable uint64 `protobuf:"varint,11,opt,name=unreachable,proto3" json:"unreachable,omitempty"`
+}
+
+func (x *GraphCheck) Reset() {
+	*x = GraphCheck{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GraphCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GraphCheck) ProtoMessage() {}
+
+func (x *GraphCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GraphCheck.ProtoReflect.Descriptor instead.
+func (*GraphCheck) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *GraphCheck) GetElements() uint64 {
+	if x != nil {
+		return x.Elements
+	}
+	return 0
+}
+
+func (x *GraphCheck) GetDeleted() uint64 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+func (x *GraphCheck) GetConnections() uint64 {
+	if x != nil {
+		return x.Connections
+	}
+	return 0
+}
+
+func (x *GraphCheck) GetInvalidLinks() uint64 {
+	if x != nil {
+		return x.InvalidLinks
+	}
+	return 0
+}
+
+func (x *GraphCheck) GetSelfLinks() uint64 {
+	if x != nil {
+		return x.SelfLinks
+	}
+	return 0
+}
+
+func (x *GraphCheck) GetDuplicateLinks() uint64 {
+	if x != nil {
+		return x.DuplicateLinks
+	}
+	return 0
+}
+
+func (x *GraphCheck) GetOversizedLists() uint64 {
+	if x != nil {
+		return x.OversizedLists
+	}
+	return 0
+}
+
+func (x *GraphCheck) GetInvalidLevels() uint64 {
+	if x != nil {
+		return x.InvalidLevels
+	}
+	return 0
+}
+
+func (x *GraphCheck) GetInvalidEntryPoint() bool {
+	if x != nil {
+		return x.InvalidEntryPoint
+	}
+	return false
+}
+
+func (x *GraphCheck) GetInconsistentLabels() uint64 {
+	if x != nil {
+		return x.InconsistentLabels
+	}
+	return 0
+}
+
+func (x *GraphCheck) GetUnreachable() uint64 {
+	if x != nil {
+		return x.Unreachable
+	}
+	return 0
+}
+
+type LogCheck struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Entries is the number of entries decoded.
+	Entries int64 `protobuf:"varint,1,opt,name=entries,proto3" json:"entries,omitempty"`
+	// Size is the size of the log file, in bytes.
+	Size int64 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	// ValidSize is the size of the leading part of the log file which was
+	// decoded.
+	ValidSize int64 `protobuf:"varint,3,opt,name=valid_size,json=validSize,proto3" json:"valid_size,omitempty"`
+	// Error is the error which stopped decoding at valid_size, if any.
+	Error string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	// Repaired reports whether the corrupted part of the log was discarded.
+	Repaired bool `protobuf:"varint,5,opt,name=repaired,proto3" json:"repaired,omitempty"`
+}
+
+func (x *LogCheck) Reset() {
+	*x = LogCheck{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hnswservice_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogCheck) ProtoMessage() {}
+
+func (x *LogCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_hnswservice_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogCheck.ProtoReflect.Descriptor instead.
+func (*LogCheck) Descriptor() ([]byte, []int) {
+	return file_hnswservice_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *LogCheck) GetEntries() int64 {
+	if x != nil {
+		return x.Entries
+	}
+	return 0
+}
+
+func (x *LogCheck) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *LogCheck) GetValidSize() int64 {
+	if x != nil {
+		return x.ValidSize
+	}
+	return 0
+}
+
+func (x *LogCheck) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *LogCheck) GetRepaired() bool {
+	if x != nil {
+		return x.Repaired
+	}
+	return false
+}
+
+var File_hnswservice_proto protoreflect.FileDescriptor
+
+var file_hnswservice_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x68, 0x6e, 0x73, 0x77, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x07, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x1a, 0x1b, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d,
+	0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xaf, 0x06, 0x0a, 0x12, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x12,
+	0x10, 0x0a, 0x03, 0x64, 0x69, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x64, 0x69,
+	0x6d, 0x12, 0x26, 0x0a, 0x0e, 0x65, 0x66, 0x43, 0x6f, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x65, 0x66, 0x43, 0x6f, 0x6e,
+	0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0c, 0x0a, 0x01, 0x6d, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x01, 0x6d, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x61, 0x78, 0x5f, 0x65,
+	0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x6d,
+	0x61, 0x78, 0x45, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65,
+	0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x65, 0x65, 0x64, 0x12, 0x44,
+	0x0a, 0x0a, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x25, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e,
+	0x53, 0x70, 0x61, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x09, 0x73, 0x70, 0x61, 0x63, 0x65,
+	0x54, 0x79, 0x70, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x61, 0x75, 0x74, 0x6f, 0x5f, 0x69, 0x64, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x61, 0x75, 0x74, 0x6f, 0x49, 0x64, 0x12, 0x32, 0x0a,
+	0x15, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x5f, 0x64,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x13, 0x61, 0x6c,
+	0x6c, 0x6f, 0x77, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x64, 0x12, 0x2e, 0x0a, 0x13, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x74, 0x74, 0x6c,
+	0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x11,
+	0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x54, 0x74, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64,
+	0x73, 0x12, 0x44, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x25, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x2e, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x54, 0x79, 0x70, 0x65, 0x52, 0x09, 0x69, 0x6e,
+	0x64, 0x65, 0x78, 0x54, 0x79, 0x70, 0x65, 0x12, 0x4a, 0x0a, 0x0c, 0x73, 0x74, 0x6f, 0x72, 0x61,
+	0x67, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x27, 0x2e,
+	0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x49, 0x6e,
+	0x64, 0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61,
+	0x67, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0b, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x5f,
+	0x73, 0x69, 0x7a, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x74, 0x72, 0x61, 0x69,
+	0x6e, 0x69, 0x6e, 0x67, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x72, 0x61,
+	0x6e, 0x6b, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x72, 0x65, 0x72, 0x61, 0x6e, 0x6b,
+	0x12, 0x29, 0x0a, 0x10, 0x70, 0x71, 0x5f, 0x73, 0x75, 0x62, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69,
+	0x7a, 0x65, 0x72, 0x73, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x70, 0x71, 0x53, 0x75,
+	0x62, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69, 0x7a, 0x65, 0x72, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x70,
+	0x71, 0x5f, 0x62, 0x69, 0x74, 0x73, 0x18, 0x10, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x70, 0x71,
+	0x42, 0x69, 0x74, 0x73, 0x22, 0x3c, 0x0a, 0x09, 0x53, 0x70, 0x61, 0x63, 0x65, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x06, 0x0a, 0x02, 0x4c, 0x32, 0x10, 0x00, 0x12, 0x06, 0x0a, 0x02, 0x49, 0x50, 0x10,
+	0x01, 0x12, 0x0a, 0x0a, 0x06, 0x43, 0x4f, 0x53, 0x49, 0x4e, 0x45, 0x10, 0x02, 0x12, 0x0b, 0x0a,
+	0x07, 0x48, 0x41, 0x4d, 0x4d, 0x49, 0x4e, 0x47, 0x10, 0x03, 0x12, 0x06, 0x0a, 0x02, 0x4c, 0x31,
+	0x10, 0x04, 0x22, 0x1f, 0x0a, 0x09, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x08, 0x0a, 0x04, 0x48, 0x4e, 0x53, 0x57, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x46, 0x4c, 0x41,
+	0x54, 0x10, 0x01, 0x22, 0x46, 0x0a, 0x0b, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x54, 0x79,
+	0x70, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x46, 0x4c, 0x4f, 0x41, 0x54, 0x33, 0x32, 0x10, 0x00, 0x12,
+	0x07, 0x0a, 0x03, 0x53, 0x51, 0x38, 0x10, 0x01, 0x12, 0x06, 0x0a, 0x02, 0x50, 0x51, 0x10, 0x02,
+	0x12, 0x0b, 0x0a, 0x07, 0x46, 0x4c, 0x4f, 0x41, 0x54, 0x31, 0x36, 0x10, 0x03, 0x12, 0x0c, 0x0a,
+	0x08, 0x42, 0x46, 0x4c, 0x4f, 0x41, 0x54, 0x31, 0x36, 0x10, 0x04, 0x22, 0x7e, 0x0a, 0x13, 0x49,
+	0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d,
+	0x65, 0x12, 0x27, 0x0a, 0x06, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0f, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x56, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x52, 0x06, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x74,
+	0x6c, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0a, 0x74, 0x74, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x22, 0x94, 0x01, 0x0a, 0x19,
+	0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x57, 0x69, 0x74, 0x68,
+	0x49, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x27, 0x0a, 0x06, 0x76, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61,
+	0x70, 0x69, 0x2e, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x06, 0x76, 0x65, 0x63, 0x74, 0x6f,
+	0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x74, 0x6c, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74, 0x74, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e,
+	0x64, 0x73, 0x22, 0x7b, 0x0a, 0x0d, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61,
+	0x6d, 0x65, 0x12, 0x27, 0x0a, 0x06, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x56, 0x65, 0x63,
+	0x74, 0x6f, 0x72, 0x52, 0x06, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x0c, 0x0a, 0x01, 0x6b,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x01, 0x6b, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x78, 0x61,
+	0x63, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x65, 0x78, 0x61, 0x63, 0x74, 0x22,
+	0xa8, 0x01, 0x0a, 0x06, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x03, 0x28, 0x02, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04,
+	0x64, 0x61, 0x74, 0x61, 0x12, 0x34, 0x0a, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69,
+	0x2e, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67,
+	0x52, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x22, 0x3e, 0x0a, 0x08, 0x45, 0x6e,
+	0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x0b, 0x0a, 0x07, 0x46, 0x4c, 0x4f, 0x41, 0x54, 0x33,
+	0x32, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x46, 0x4c, 0x4f, 0x41, 0x54, 0x31, 0x36, 0x10, 0x01,
+	0x12, 0x0c, 0x0a, 0x08, 0x42, 0x46, 0x4c, 0x4f, 0x41, 0x54, 0x31, 0x36, 0x10, 0x02, 0x12, 0x0a,
+	0x0a, 0x06, 0x42, 0x49, 0x4e, 0x41, 0x52, 0x59, 0x10, 0x03, 0x22, 0x33, 0x0a, 0x12, 0x44, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x22,
+	0x28, 0x0a, 0x0c, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12,
+	0x18, 0x0a, 0x07, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x07, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x22, 0x2d, 0x0a, 0x0c, 0x46, 0x6c, 0x75,
+	0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x37, 0x0a, 0x11, 0x49, 0x6e, 0x73, 0x65,
+	0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a,
+	0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x6f, 0x6f,
+	0x6b, 0x22, 0x2d, 0x0a, 0x17, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f,
+	0x72, 0x57, 0x69, 0x74, 0x68, 0x49, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x6f, 0x6f, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x6f, 0x6f, 0x6b,
+	0x22, 0x3a, 0x0a, 0x12, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x03, 0x69, 0x64, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x6f, 0x6f, 0x6b,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x22, 0x2f, 0x0a, 0x19,
+	0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x57, 0x69, 0x74,
+	0x68, 0x49, 0x64, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x6f, 0x6f,
+	0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x22, 0x46, 0x0a,
+	0x0e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x4b, 0x4e, 0x4e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12,
+	0x20, 0x0a, 0x04, 0x68, 0x69, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e,
+	0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x48, 0x69, 0x74, 0x52, 0x04, 0x68, 0x69, 0x74,
+	0x73, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x22, 0x65, 0x0a, 0x03, 0x48, 0x69, 0x74, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08,
+	0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x02, 0x52, 0x08,
+	0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x23, 0x0a, 0x0a, 0x73, 0x69, 0x6d, 0x69,
+	0x6c, 0x61, 0x72, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x02, 0x48, 0x00, 0x52, 0x0a,
+	0x73, 0x69, 0x6d, 0x69, 0x6c, 0x61, 0x72, 0x69, 0x74, 0x79, 0x88, 0x01, 0x01, 0x42, 0x0d, 0x0a,
+	0x0b, 0x5f, 0x73, 0x69, 0x6d, 0x69, 0x6c, 0x61, 0x72, 0x69, 0x74, 0x79, 0x22, 0x43, 0x0a, 0x0c,
+	0x53, 0x65, 0x74, 0x45, 0x66, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a,
+	0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x22, 0x69, 0x0a, 0x12, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x34, 0x0a, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69,
+	0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61,
+	0x70, 0x69, 0x2e, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69,
+	0x6e, 0x67, 0x52, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x22, 0x4b, 0x0a, 0x10,
+	0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64,
+	0x12, 0x27, 0x0a, 0x06, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0f, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x56, 0x65, 0x63, 0x74, 0x6f,
+	0x72, 0x52, 0x06, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x22, 0x96, 0x01, 0x0a, 0x11, 0x47, 0x65,
+	0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x10,
+	0x0a, 0x03, 0x69, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x05, 0x52, 0x03, 0x69, 0x64, 0x73,
+	0x12, 0x34, 0x0a, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x18, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x56, 0x65, 0x63,
+	0x74, 0x6f, 0x72, 0x2e, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x65, 0x6e,
+	0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x1a, 0x0a, 0x08, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e,
+	0x61, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e,
+	0x61, 0x6c, 0x22, 0x7a, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2f, 0x0a, 0x07, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69,
+	0x2e, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x07, 0x76,
+	0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x12, 0x22, 0x0a, 0x0d, 0x6e, 0x6f, 0x74, 0x5f, 0x66, 0x6f,
+	0x75, 0x6e, 0x64, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x6e,
+	0x6f, 0x74, 0x46, 0x6f, 0x75, 0x6e, 0x64, 0x49, 0x64, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x6f,
+	0x6f, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x22, 0x47,
+	0x0a, 0x0c, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x0e,
+	0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x27,
+	0x0a, 0x06, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f,
+	0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52,
+	0x06, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x22, 0x48, 0x0a, 0x13, 0x43, 0x6f, 0x6d, 0x70, 0x61,
+	0x63, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d,
+	0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x77, 0x61, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x77, 0x61, 0x69,
+	0x74, 0x22, 0x47, 0x0a, 0x14, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x56, 0x65, 0x63, 0x74, 0x6f,
+	0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x64, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x05, 0x52, 0x03, 0x69, 0x64, 0x73, 0x22, 0x28, 0x0a, 0x12, 0x44, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x12, 0x0a, 0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04,
+	0x74, 0x6f, 0x6f, 0x6b, 0x22, 0x49, 0x0a, 0x16, 0x55, 0x6e, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d,
+	0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x10, 0x0a,
+	0x03, 0x69, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x05, 0x52, 0x03, 0x69, 0x64, 0x73, 0x22,
+	0x4e, 0x0a, 0x14, 0x55, 0x6e, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x56, 0x65, 0x63, 0x74, 0x6f,
+	0x72, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x22, 0x0a, 0x0d, 0x6e, 0x6f, 0x74, 0x5f, 0x66,
+	0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b,
+	0x6e, 0x6f, 0x74, 0x46, 0x6f, 0x75, 0x6e, 0x64, 0x49, 0x64, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x74,
+	0x6f, 0x6f, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x22,
+	0x35, 0x0a, 0x14, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0xb3, 0x06, 0x0a, 0x12, 0x44, 0x65, 0x73, 0x63, 0x72,
+	0x69, 0x62, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1d, 0x0a,
+	0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03,
+	0x64, 0x69, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x64, 0x69, 0x6d, 0x12, 0x26,
+	0x0a, 0x0e, 0x65, 0x66, 0x43, 0x6f, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x65, 0x66, 0x43, 0x6f, 0x6e, 0x73, 0x74, 0x72,
+	0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0c, 0x0a, 0x01, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x01, 0x6d, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x61, 0x78, 0x5f, 0x65, 0x6c, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x45,
+	0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x65, 0x64, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x65, 0x65, 0x64, 0x12, 0x44, 0x0a, 0x0a, 0x73,
+	0x70, 0x61, 0x63, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x25, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x70, 0x61,
+	0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x09, 0x73, 0x70, 0x61, 0x63, 0x65, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x17, 0x0a, 0x07, 0x61, 0x75, 0x74, 0x6f, 0x5f, 0x69, 0x64, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x06, 0x61, 0x75, 0x74, 0x6f, 0x49, 0x64, 0x12, 0x32, 0x0a, 0x15, 0x61, 0x6c,
+	0x6c, 0x6f, 0x77, 0x5f, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x5f, 0x64, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x13, 0x61, 0x6c, 0x6c, 0x6f, 0x77,
+	0x52, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x12, 0x2e,
+	0x0a, 0x13, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x74, 0x74, 0x6c, 0x5f, 0x73, 0x65,
+	0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x11, 0x64, 0x65, 0x66,
+	0x61, 0x75, 0x6c, 0x74, 0x54, 0x74, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x12,
+	0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69,
+	0x7a, 0x65, 0x12, 0x2c, 0x0a, 0x06, 0x65, 0x78, 0x70, 0x69, 0x72, 0x79, 0x18, 0x0c, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x45, 0x78, 0x70,
+	0x69, 0x72, 0x79, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x06, 0x65, 0x78, 0x70, 0x69, 0x72, 0x79,
+	0x12, 0x44, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x0d,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x25, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x2e, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x54, 0x79, 0x70, 0x65, 0x52, 0x09, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x54, 0x79, 0x70, 0x65, 0x12, 0x4a, 0x0a, 0x0c, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67,
+	0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x27, 0x2e, 0x67,
+	0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x49, 0x6e, 0x64,
+	0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67,
+	0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0b, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x54, 0x79,
+	0x70, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x73,
+	0x69, 0x7a, 0x65, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x74, 0x72, 0x61, 0x69, 0x6e,
+	0x69, 0x6e, 0x67, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x72, 0x61, 0x6e,
+	0x6b, 0x18, 0x10, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x72, 0x65, 0x72, 0x61, 0x6e, 0x6b, 0x12,
+	0x29, 0x0a, 0x10, 0x70, 0x71, 0x5f, 0x73, 0x75, 0x62, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69, 0x7a,
+	0x65, 0x72, 0x73, 0x18, 0x11, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x70, 0x71, 0x53, 0x75, 0x62,
+	0x71, 0x75, 0x61, 0x6e, 0x74, 0x69, 0x7a, 0x65, 0x72, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x70, 0x71,
+	0x5f, 0x62, 0x69, 0x74, 0x73, 0x18, 0x12, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x70, 0x71, 0x42,
+	0x69, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x18,
+	0x13, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x73, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x12,
+	0x2c, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x14, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1d, 0x0a,
+	0x0a, 0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x15, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x6c, 0x6f, 0x61, 0x64, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x6a, 0x0a, 0x0b,
+	0x45, 0x78, 0x70, 0x69, 0x72, 0x79, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x70,
+	0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x70, 0x65,
+	0x6e, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x27, 0x0a, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x65, 0x78,
+	0x70, 0x69, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e,
+	0x6e, 0x65, 0x78, 0x74, 0x45, 0x78, 0x70, 0x69, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18,
+	0x0a, 0x07, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x07, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x64, 0x22, 0x90, 0x01, 0x0a, 0x15, 0x45, 0x76, 0x61,
+	0x6c, 0x75, 0x61, 0x74, 0x65, 0x52, 0x65, 0x63, 0x61, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d,
+	0x65, 0x12, 0x0c, 0x0a, 0x01, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x01, 0x6b, 0x12,
+	0x1f, 0x0a, 0x0b, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65,
+	0x12, 0x29, 0x0a, 0x07, 0x71, 0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x0f, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x56, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x52, 0x07, 0x71, 0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x22, 0xc1, 0x01, 0x0a, 0x13,
+	0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x65, 0x52, 0x65, 0x63, 0x61, 0x6c, 0x6c, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x63, 0x61, 0x6c, 0x6c, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x02, 0x52, 0x06, 0x72, 0x65, 0x63, 0x61, 0x6c, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x71,
+	0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x71, 0x75,
+	0x65, 0x72, 0x69, 0x65, 0x73, 0x12, 0x0e, 0x0a, 0x02, 0x65, 0x66, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x02, 0x65, 0x66, 0x12, 0x2f, 0x0a, 0x13, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x78, 0x69,
+	0x6d, 0x61, 0x74, 0x65, 0x5f, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x02, 0x52, 0x12, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x78, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x4c,
+	0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x23, 0x0a, 0x0d, 0x65, 0x78, 0x61, 0x63, 0x74, 0x5f,
+	0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x02, 0x52, 0x0c, 0x65,
+	0x78, 0x61, 0x63, 0x74, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x74,
+	0x6f, 0x6f, 0x6b, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x22,
+	0xc8, 0x01, 0x0a, 0x11, 0x41, 0x75, 0x74, 0x6f, 0x54, 0x75, 0x6e, 0x65, 0x45, 0x66, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x4e, 0x61, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x5f, 0x72,
+	0x65, 0x63, 0x61, 0x6c, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x02, 0x52, 0x0c, 0x74, 0x61, 0x72,
+	0x67, 0x65, 0x74, 0x52, 0x65, 0x63, 0x61, 0x6c, 0x6c, 0x12, 0x0c, 0x0a, 0x01, 0x6b, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x01, 0x6b, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x61, 0x6d, 0x70, 0x6c,
+	0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x61,
+	0x6d, 0x70, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x29, 0x0a, 0x07, 0x71, 0x75, 0x65, 0x72,
+	0x69, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x67, 0x72, 0x70, 0x63,
+	0x61, 0x70, 0x69, 0x2e, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x07, 0x71, 0x75, 0x65, 0x72,
+	0x69, 0x65, 0x73, 0x12, 0x15, 0x0a, 0x06, 0x6d, 0x61, 0x78, 0x5f, 0x65, 0x66, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x05, 0x6d, 0x61, 0x78, 0x45, 0x66, 0x22, 0xd9, 0x01, 0x0a, 0x0f, 0x41,
+	0x75, 0x74, 0x6f, 0x54, 0x75, 0x6e, 0x65, 0x45, 0x66, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x0e,
+	0x0a, 0x02, 0x65, 0x66, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x65, 0x66, 0x12, 0x16,
+	0x0a, 0x06, 0x72, 0x65, 0x63, 0x61, 0x6c, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x02, 0x52, 0x06,
+	0x72, 0x65, 0x63, 0x61, 0x6c, 0x6c, 0x12, 0x25, 0x0a, 0x0e, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74,
+	0x5f, 0x72, 0x65, 0x61, 0x63, 0x68, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d,
+	0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x52, 0x65, 0x61, 0x63, 0x68, 0x65, 0x64, 0x12, 0x3e, 0x0a,
+	0x0c, 0x6d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65,
+	0x63, 0x61, 0x6c, 0x6c, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52,
+	0x0c, 0x6d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x23, 0x0a,
+	0x0d, 0x65, 0x78, 0x61, 0x63, 0x74, 0x5f, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x02, 0x52, 0x0c, 0x65, 0x78, 0x61, 0x63, 0x74, 0x4c, 0x61, 0x74, 0x65, 0x6e,
+	0x63, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x22, 0x6c, 0x0a, 0x11, 0x52, 0x65, 0x63, 0x61, 0x6c, 0x6c,
+	0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x65,
+	0x66, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x65, 0x66, 0x12, 0x16, 0x0a, 0x06, 0x72,
+	0x65, 0x63, 0x61, 0x6c, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x02, 0x52, 0x06, 0x72, 0x65, 0x63,
+	0x61, 0x6c, 0x6c, 0x12, 0x2f, 0x0a, 0x13, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x78, 0x69, 0x6d, 0x61,
+	0x74, 0x65, 0x5f, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x02,
+	0x52, 0x12, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x78, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x4c, 0x61, 0x74,
+	0x65, 0x6e, 0x63, 0x79, 0x22, 0x44, 0x0a, 0x12, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x49, 0x6e,
+	0x64, 0x69, 0x63, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2e, 0x0a, 0x07, 0x69, 0x6e,
+	0x64, 0x69, 0x63, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x72,
+	0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x49, 0x6e, 0x64, 0x65,
+	0x78, 0x52, 0x07, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x22, 0x64, 0x0a, 0x0b, 0x46, 0x61,
+	0x69, 0x6c, 0x65, 0x64, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x20,
+	0x0a, 0x0b, 0x71, 0x75, 0x61, 0x72, 0x61, 0x6e, 0x74, 0x69, 0x6e, 0x65, 0x64, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0b, 0x71, 0x75, 0x61, 0x72, 0x61, 0x6e, 0x74, 0x69, 0x6e, 0x65, 0x64,
+	0x22, 0x4a, 0x0a, 0x11, 0x52, 0x65, 0x74, 0x72, 0x79, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x4e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x70, 0x61, 0x69, 0x72, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x72, 0x65, 0x70, 0x61, 0x69, 0x72, 0x22, 0x4b, 0x0a, 0x12,
+	0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4e, 0x61, 0x6d,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x70, 0x61, 0x69, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x06, 0x72, 0x65, 0x70, 0x61, 0x69, 0x72, 0x22, 0x80, 0x02, 0x0a, 0x10, 0x56, 0x65,
+	0x72, 0x69, 0x66, 0x79, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x0e,
+	0x0a, 0x02, 0x6f, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x02, 0x6f, 0x6b, 0x12, 0x1a,
+	0x0a, 0x08, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x08, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x77, 0x61,
+	0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x77, 0x61,
+	0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x74, 0x61, 0x74, 0x65, 0x5f,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x69, 0x6e,
+	0x64, 0x65, 0x78, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x29, 0x0a, 0x05, 0x67, 0x72, 0x61, 0x70,
+	0x68, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70,
+	0x69, 0x2e, 0x47, 0x72, 0x61, 0x70, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x05, 0x67, 0x72,
+	0x61, 0x70, 0x68, 0x12, 0x23, 0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x11, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x6f, 0x67, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x52, 0x03, 0x6c, 0x6f, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x6f, 0x6f, 0x6b,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x6f, 0x6f, 0x6b, 0x22, 0xa4, 0x03, 0x0a,
+	0x0a, 0x47, 0x72, 0x61, 0x70, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x65,
+	0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x65,
+	0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x64, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x5f, 0x6c,
+	0x69, 0x6e, 0x6b, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x69, 0x6e, 0x76, 0x61,
+	0x6c, 0x69, 0x64, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x6c, 0x66,
+	0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x73, 0x65,
+	0x6c, 0x66, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x64, 0x75, 0x70, 0x6c, 0x69,
+	0x63, 0x61, 0x74, 0x65, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x0e, 0x64, 0x75, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x73,
+	0x12, 0x27, 0x0a, 0x0f, 0x6f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x7a, 0x65, 0x64, 0x5f, 0x6c, 0x69,
+	0x73, 0x74, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0e, 0x6f, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x7a, 0x65, 0x64, 0x4c, 0x69, 0x73, 0x74, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x69, 0x6e, 0x76,
+	0x61, 0x6c, 0x69, 0x64, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0d, 0x69, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x73,
+	0x12, 0x2e, 0x0a, 0x13, 0x69, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x5f, 0x65, 0x6e, 0x74, 0x72,
+	0x79, 0x5f, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x69,
+	0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x50, 0x6f, 0x69, 0x6e, 0x74,
+	0x12, 0x2f, 0x0a, 0x13, 0x69, 0x6e, 0x63, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x74,
+	0x5f, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x04, 0x52, 0x12, 0x69,
+	0x6e, 0x63, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x74, 0x4c, 0x61, 0x62, 0x65, 0x6c,
+	0x73, 0x12, 0x20, 0x0a, 0x0b, 0x75, 0x6e, 0x72, 0x65, 0x61, 0x63, 0x68, 0x61, 0x62, 0x6c, 0x65,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x75, 0x6e, 0x72, 0x65, 0x61, 0x63, 0x68, 0x61,
+	0x62, 0x6c, 0x65, 0x22, 0x89, 0x01, 0x0a, 0x08, 0x4c, 0x6f, 0x67, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69,
+	0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x1d,
+	0x0a, 0x0a, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x14, 0x0a,
+	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x70, 0x61, 0x69, 0x72, 0x65, 0x64, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x70, 0x61, 0x69, 0x72, 0x65, 0x64, 0x2a,
+	0x31, 0x0a, 0x0b, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x09,
+	0x0a, 0x05, 0x52, 0x45, 0x41, 0x44, 0x59, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x4c, 0x4f, 0x41,
+	0x44, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44,
+	0x10, 0x02, 0x32, 0xa0, 0x0c, 0x0a, 0x06, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x12, 0x44, 0x0a,
+	0x0b, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1b, 0x2e, 0x67,
+	0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x49, 0x6e, 0x64,
+	0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74,
+	0x79, 0x22, 0x00, 0x12, 0x44, 0x0a, 0x0b, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x49, 0x6e, 0x64,
+	0x65, 0x78, 0x12, 0x1b, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x4a, 0x0a, 0x0c, 0x49, 0x6e, 0x73,
+	0x65, 0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x1c, 0x2e, 0x67, 0x72, 0x70, 0x63,
+	0x61, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70,
+	0x69, 0x2e, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x4e, 0x0a, 0x0d, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56,
+	0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x12, 0x1c, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69,
+	0x2e, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x49,
+	0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x70, 0x6c,
+	0x79, 0x22, 0x00, 0x28, 0x01, 0x12, 0x5c, 0x0a, 0x12, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56,
+	0x65, 0x63, 0x74, 0x6f, 0x72, 0x57, 0x69, 0x74, 0x68, 0x49, 0x64, 0x12, 0x22, 0x2e, 0x67, 0x72,
+	0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x57, 0x69, 0x74, 0x68, 0x49, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x20, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74,
+	0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x57, 0x69, 0x74, 0x68, 0x49, 0x64, 0x52, 0x65, 0x70, 0x6c,
+	0x79, 0x22, 0x00, 0x12, 0x62, 0x0a, 0x14, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63,
+	0x74, 0x6f, 0x72, 0x73, 0x57, 0x69, 0x74, 0x68, 0x49, 0x64, 0x73, 0x12, 0x22, 0x2e, 0x67, 0x72,
+	0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x56, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x57, 0x69, 0x74, 0x68, 0x49, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x22, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74,
+	0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x57, 0x69, 0x74, 0x68, 0x49, 0x64, 0x73, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x22, 0x00, 0x28, 0x01, 0x12, 0x3e, 0x0a, 0x09, 0x53, 0x65, 0x61, 0x72, 0x63,
+	0x68, 0x4b, 0x4e, 0x4e, 0x12, 0x16, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x53,
+	0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x67,
+	0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x4b, 0x4e, 0x4e,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x3d, 0x0a, 0x0a, 0x46, 0x6c, 0x75, 0x73, 0x68,
+	0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x15, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e,
+	0x46, 0x6c, 0x75, 0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45,
+	0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x3a, 0x0a, 0x07, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65,
+	0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x15, 0x2e, 0x67, 0x72, 0x70, 0x63,
+	0x61, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x22, 0x00, 0x12, 0x38, 0x0a, 0x05, 0x53, 0x65, 0x74, 0x45, 0x66, 0x12, 0x15, 0x2e, 0x67, 0x72,
+	0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x53, 0x65, 0x74, 0x45, 0x66, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x44, 0x0a, 0x0a,
+	0x47, 0x65, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x12, 0x1a, 0x2e, 0x67, 0x72, 0x70,
+	0x63, 0x61, 0x70, 0x69, 0x2e, 0x47, 0x65, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69,
+	0x2e, 0x47, 0x65, 0x74, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x22, 0x00, 0x12, 0x49, 0x0a, 0x0b, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x49, 0x6e, 0x64, 0x65,
+	0x78, 0x12, 0x1b, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x45, 0x78, 0x70, 0x6f,
+	0x72, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19,
+	0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x49,
+	0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x30, 0x01, 0x12, 0x46, 0x0a,
+	0x0c, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1c, 0x2e,
+	0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x49,
+	0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x4d, 0x0a, 0x0d, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x56,
+	0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x12, 0x1d, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69,
+	0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e,
+	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x22, 0x00, 0x12, 0x53, 0x0a, 0x0f, 0x55, 0x6e, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x12, 0x1f, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70,
+	0x69, 0x2e, 0x55, 0x6e, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61,
+	0x70, 0x69, 0x2e, 0x55, 0x6e, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x56, 0x65, 0x63, 0x74, 0x6f,
+	0x72, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x4d, 0x0a, 0x0d, 0x44, 0x65, 0x73,
+	0x63, 0x72, 0x69, 0x62, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1d, 0x2e, 0x67, 0x72, 0x70,
+	0x63, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x49, 0x6e, 0x64,
+	0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x67, 0x72, 0x70, 0x63,
+	0x61, 0x70, 0x69, 0x2e, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x49, 0x6e, 0x64, 0x65,
+	0x78, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x50, 0x0a, 0x0e, 0x45, 0x76, 0x61, 0x6c,
+	0x75, 0x61, 0x74, 0x65, 0x52, 0x65, 0x63, 0x61, 0x6c, 0x6c, 0x12, 0x1e, 0x2e, 0x67, 0x72, 0x70,
+	0x63, 0x61, 0x70, 0x69, 0x2e, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x65, 0x52, 0x65, 0x63,
+	0x61, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x67, 0x72, 0x70,
+	0x63, 0x61, 0x70, 0x69, 0x2e, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x65, 0x52, 0x65, 0x63,
+	0x61, 0x6c, 0x6c, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x44, 0x0a, 0x0a, 0x41, 0x75,
+	0x74, 0x6f, 0x54, 0x75, 0x6e, 0x65, 0x45, 0x66, 0x12, 0x1a, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61,
+	0x70, 0x69, 0x2e, 0x41, 0x75, 0x74, 0x6f, 0x54, 0x75, 0x6e, 0x65, 0x45, 0x66, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x41,
+	0x75, 0x74, 0x6f, 0x54, 0x75, 0x6e, 0x65, 0x45, 0x66, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00,
+	0x12, 0x46, 0x0a, 0x0d, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65,
+	0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x1b, 0x2e, 0x67, 0x72, 0x70, 0x63,
+	0x61, 0x70, 0x69, 0x2e, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65,
+	0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x42, 0x0a, 0x0a, 0x52, 0x65, 0x74, 0x72,
+	0x79, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1a, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69,
+	0x2e, 0x52, 0x65, 0x74, 0x72, 0x79, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x47, 0x0a, 0x0b,
+	0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1b, 0x2e, 0x67, 0x72,
+	0x70, 0x63, 0x61, 0x70, 0x69, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x49, 0x6e, 0x64, 0x65,
+	0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x61,
+	0x70, 0x69, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x41, 0x5a, 0x3f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x63, 0x6f, 0x6d, 0x2f, 0x53, 0x70, 0x65, 0x63, 0x69, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x47,
+	0x65, 0x6e, 0x65, 0x72, 0x61, 0x6c, 0x69, 0x73, 0x74, 0x2f, 0x68, 0x6e, 0x73, 0x77, 0x67, 0x2d,
+	0x67, 0x72, 0x70, 0x63, 0x2d, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x70, 0x6b, 0x67,
+	0x2f, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_hnswservice_proto_rawDescOnce sync.Once
+	file_hnswservice_proto_rawDescData = file_hnswservice_proto_rawDesc
+)
+
+func file_hnswservice_proto_rawDescGZIP() []byte {
+	file_hnswservice_proto_rawDescOnce.Do(func() {
+		file_hnswservice_proto_rawDescData = protoimpl.X.CompressGZIP(file_hnswservice_proto_rawDescData)
+	})
+	return file_hnswservice_proto_rawDescData
+}
+
+var file_hnswservice_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_hnswservice_proto_msgTypes = make([]protoimpl.MessageInfo, 40)
+var file_hnswservice_proto_goTypes = []interface{}{
+	(IndexStatus)(0),                    // 0: grpcapi.IndexStatus
+	(CreateIndexRequest_SpaceType)(0),   // 1: grpcapi.CreateIndexRequest.SpaceType
+	(CreateIndexRequest_IndexType)(0),   // 2: grpcapi.CreateIndexRequest.IndexType
+	(CreateIndexRequest_StorageType)(0), // 3: grpcapi.CreateIndexRequest.StorageType
+	(Vector_Encoding)(0),                // 4: grpcapi.Vector.Encoding
+	(*CreateIndexRequest)(nil),          // 5: grpcapi.CreateIndexRequest
+	(*InsertVectorRequest)(nil),         // 6: grpcapi.InsertVectorRequest
+	(*InsertVectorWithIdRequest)(nil),   // 7: grpcapi.InsertVectorWithIdRequest
+	(*SearchRequest)(nil),               // 8: grpcapi.SearchRequest
+	(*Vector)(nil),                      // 9: grpcapi.Vector
+	(*DeleteIndexRequest)(nil),          // 10: grpcapi.DeleteIndexRequest
+	(*IndicesReply)(nil),                // 11: grpcapi.IndicesReply
+	(*FlushRequest)(nil),                // 12: grpcapi.FlushRequest
+	(*InsertVectorReply)(nil),           // 13: grpcapi.InsertVectorReply
+	(*InsertVectorWithIdReply)(nil),     // 14: grpcapi.InsertVectorWithIdReply
+	(*InsertVectorsReply)(nil),          // 15: grpcapi.InsertVectorsReply
+	(*InsertVectorsWithIdsReply)(nil),   // 16: grpcapi.InsertVectorsWithIdsReply
+	(*SearchKNNReply)(nil),              // 17: grpcapi.SearchKNNReply
+	(*Hit)(nil),                         // 18: grpcapi.Hit
+	(*SetEfRequest)(nil),                // 19: grpcapi.SetEfRequest
+	(*ExportIndexRequest)(nil),          // 20: grpcapi.ExportIndexRequest
+	(*ExportIndexReply)(nil),            // 21: grpcapi.ExportIndexReply
+	(*GetVectorsRequest)(nil),           // 22: grpcapi.GetVectorsRequest
+	(*GetVectorsReply)(nil),             // 23: grpcapi.GetVectorsReply
+	(*StoredVector)(nil),                // 24: grpcapi.StoredVector
+	(*CompactIndexRequest)(nil),         // 25: grpcapi.CompactIndexRequest
+	(*DeleteVectorsRequest)(nil),        // 26: grpcapi.DeleteVectorsRequest
+	(*DeleteVectorsReply)(nil),          // 27: grpcapi.DeleteVectorsReply
+	(*UndeleteVectorsRequest)(nil),      // 28: grpcapi.UndeleteVectorsRequest
+	(*UndeleteVectorsReply)(nil),        // 29: grpcapi.UndeleteVectorsReply
+	(*DescribeIndexRequest)(nil),        // 30: grpcapi.DescribeIndexRequest
+	(*DescribeIndexReply)(nil),          // 31: grpcapi.DescribeIndexReply
+	(*ExpiryStats)(nil),                 // 32: grpcapi.ExpiryStats
+	(*EvaluateRecallRequest)(nil),       // 33: grpcapi.EvaluateRecallRequest
+	(*EvaluateRecallReply)(nil),         // 34: grpcapi.EvaluateRecallReply
+	(*AutoTuneEfRequest)(nil),           // 35: grpcapi.AutoTuneEfRequest
+	(*AutoTuneEfReply)(nil),             // 36: grpcapi.AutoTuneEfReply
+	(*RecallMeasurement)(nil),           // 37: grpcapi.RecallMeasurement
+	(*FailedIndicesReply)(nil),          // 38: grpcapi.FailedIndicesReply
+	(*FailedIndex)(nil),                 // 39: grpcapi.FailedIndex
+	(*RetryIndexRequest)(nil),           // 40: grpcapi.RetryIndexRequest
+	(*VerifyIndexRequest)(nil),          // 41: grpcapi.VerifyIndexRequest
+	(*VerifyIndexReply)(nil),            // 42: grpcapi.VerifyIndexReply
+	(*GraphCheck)(nil),                  // 43: grpcapi.GraphCheck
+	(*LogCheck)(nil),                    // 44: grpcapi.LogCheck
+	(*emptypb.Empty)(nil),               // 45: google.protobuf.Empty
+}
+var file_hnswservice_proto_depIdxs = []int32{
+	1,  // 0: grpcapi.CreateIndexRequest.space_type:type_name -> grpcapi.CreateIndexRequest.SpaceType
+	2,  // 1: grpcapi.CreateIndexRequest.index_type:type_name -> grpcapi.CreateIndexRequest.IndexType
+	3,  // 2: grpcapi.CreateIndexRequest.storage_type:type_name -> grpcapi.CreateIndexRequest.StorageType
+	9,  // 3: grpcapi.InsertVectorRequest.vector:type_name -> grpcapi.Vector
+	9,  // 4: grpcapi.InsertVectorWithIdRequest.vector:type_name -> grpcapi.Vector
+	9,  // 5: grpcapi.SearchRequest.vector:type_name -> grpcapi.Vector
+	4,  // 6: grpcapi.Vector.encoding:type_name -> grpcapi.Vector.Encoding
+	18, // 7: grpcapi.SearchKNNReply.hits:type_name -> grpcapi.Hit
+	4,  // 8: grpcapi.ExportIndexRequest.encoding:type_name -> grpcapi.Vector.Encoding
+	9,  // 9: grpcapi.ExportIndexReply.vector:type_name -> grpcapi.Vector
+	4,  // 10: grpcapi.GetVectorsRequest.encoding:type_name -> grpcapi.Vector.Encoding
+	24, // 11: grpcapi.GetVectorsReply.vectors:type_name -> grpcapi.StoredVector
+	9,  // 12: grpcapi.StoredVector.vector:type_name -> grpcapi.Vector
+	1,  // 13: grpcapi.DescribeIndexReply.space_type:type_name -> grpcapi.CreateIndexRequest.SpaceType
+	32, // 14: grpcapi.DescribeIndexReply.expiry:type_name -> grpcapi.ExpiryStats
+	2,  // 15: grpcapi.DescribeIndexReply.index_type:type_name -> grpcapi.CreateIndexRequest.IndexType
+	3,  // 16: grpcapi.DescribeIndexReply.storage_type:type_name -> grpcapi.CreateIndexRequest.StorageType
+	0,  // 17: grpcapi.DescribeIndexReply.status:type_name -> grpcapi.IndexStatus
+	9,  // 18: grpcapi.EvaluateRecallRequest.queries:type_name -> grpcapi.Vector
+	9,  // 19: grpcapi.AutoTuneEfRequest.queries:type_name -> grpcapi.Vector
+	37, // 20: grpcapi.AutoTuneEfReply.measurements:type_name -> grpcapi.RecallMeasurement
+	39, // 21: grpcapi.FailedIndicesReply.indices:type_name -> grpcapi.FailedIndex
+	43, // 22: grpcapi.VerifyIndexReply.graph:type_name -> grpcapi.GraphCheck
+	44, // 23: grpcapi.VerifyIndexReply.log:type_name -> grpcapi.LogCheck
+	5,  // 24: grpcapi.Server.CreateIndex:input_type -> grpcapi.CreateIndexRequest
+	10, // 25: grpcapi.Server.DeleteIndex:input_type -> grpcapi.DeleteIndexRequest
+	6,  // 26: grpcapi.Server.InsertVector:input_type -> grpcapi.InsertVectorRequest
+	6,  // 27: grpcapi.Server.InsertVectors:input_type -> grpcapi.InsertVectorRequest
+	7,  // 28: grpcapi.Server.InsertVectorWithId:input_type -> grpcapi.InsertVectorWithIdRequest
+	7,  // 29: grpcapi.Server.InsertVectorsWithIds:input_type -> grpcapi.InsertVectorWithIdRequest
+	8,  // 30: grpcapi.Server.SearchKNN:input_type -> grpcapi.SearchRequest
+	12, // 31: grpcapi.Server.FlushIndex:input_type -> grpcapi.FlushRequest
+	45, // 32: grpcapi.Server.Indices:input_type -> google.protobuf.Empty
+	19, // 33: grpcapi.Server.SetEf:input_type -> grpcapi.SetEfRequest
+	22, // 34: grpcapi.Server.GetVectors:input_type -> grpcapi.GetVectorsRequest
+	20, // 35: grpcapi.Server.ExportIndex:input_type -> grpcapi.ExportIndexRequest
+	25, // 36: grpcapi.Server.CompactIndex:input_type -> grpcapi.CompactIndexRequest
+	26, // 37: grpcapi.Server.DeleteVectors:input_type -> grpcapi.DeleteVectorsRequest
+	28, // 38: grpcapi.Server.UndeleteVectors:input_type -> grpcapi.UndeleteVectorsRequest
+	30, // 39: grpcapi.Server.DescribeIndex:input_type -> grpcapi.DescribeIndexRequest
+	33, // 40: grpcapi.Server.EvaluateRecall:input_type -> grpcapi.EvaluateRecallRequest
+	35, // 41: grpcapi.Server.AutoTuneEf:input_type -> grpcapi.AutoTuneEfRequest
+	45, // 42: grpcapi.Server.FailedIndices:input_type -> google.protobuf.Empty
+	40, // 43: grpcapi.Server.RetryIndex:input_type -> grpcapi.RetryIndexRequest
+	41, // 44: grpcapi.Server.VerifyIndex:input_type -> grpcapi.VerifyIndexRequest
+	45, // 45: grpcapi.Server.CreateIndex:output_type -> google.protobuf.Empty
+	45, // 46: grpcapi.Server.DeleteIndex:output_type -> google.protobuf.Empty
+	13, // 47: grpcapi.Server.InsertVector:output_type -> grpcapi.InsertVectorReply
+	15, // 48: grpcapi.Server.InsertVectors:output_type -> grpcapi.InsertVectorsReply
+	14, // 49: grpcapi.Server.InsertVectorWithId:output_type -> grpcapi.InsertVectorWithIdReply
+	16, // 50: grpcapi.Server.InsertVectorsWithIds:output_type -> grpcapi.InsertVectorsWithIdsReply
+	17, // 51: grpcapi.Server.SearchKNN:output_type -> grpcapi.SearchKNNReply
+	45, // 52: grpcapi.Server.FlushIndex:output_type -> google.protobuf.Empty
+	11, // 53: grpcapi.Server.Indices:output_type -> grpcapi.IndicesReply
+	45, // 54: grpcapi.Server.SetEf:output_type -> google.protobuf.Empty
+	23, // 55: grpcapi.Server.GetVectors:output_type -> grpcapi.GetVectorsReply
+	21, // 56: grpcapi.Server.ExportIndex:output_type -> grpcapi.ExportIndexReply
+	45, // 57: grpcapi.Server.CompactIndex:output_type -> google.protobuf.Empty
+	27, // 58: grpcapi.Server.DeleteVectors:output_type -> grpcapi.DeleteVectorsReply
+	29, // 59: grpcapi.Server.UndeleteVectors:output_type -> grpcapi.UndeleteVectorsReply
+	31, // 60: grpcapi.Server.DescribeIndex:output_type -> grpcapi.DescribeIndexReply
+	34, // 61: grpcapi.Server.EvaluateRecall:output_type -> grpcapi.EvaluateRecallReply
+	36, // 62: grpcapi.Server.AutoTuneEf:output_type -> grpcapi.AutoTuneEfReply
+	38, // 63: grpcapi.Server.FailedIndices:output_type -> grpcapi.FailedIndicesReply
+	45, // 64: grpcapi.Server.RetryIndex:output_type -> google.protobuf.Empty
+	42, // 65: grpcapi.Server.VerifyIndex:output_type -> grpcapi.VerifyIndexReply
+	45, // [45:66] is the sub-list for method output_type
+	24, // [24:45] is the sub-list for method input_type
+	24, // [24:24] is the sub-list for extension type_name
+	24, // [24:24] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
+}
+
+func init() { file_hnswservice_proto_init() }
+func file_hnswservice_proto_init() {
+	if File_hnswservice_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_hnswservice_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateIndexRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
 		}
 		file_hnswservice_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*InsertVectorRequest); i {
@@ -1307,14 +4025,315 @@ func file_hnswservice_proto_init() {
 				return nil
 			}
 		}
+		file_hnswservice_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportIndexRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportIndexReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetVectorsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetVectorsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StoredVector); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompactIndexRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteVectorsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteVectorsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UndeleteVectorsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UndeleteVectorsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DescribeIndexRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DescribeIndexReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExpiryStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EvaluateRecallRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EvaluateRecallReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AutoTuneEfRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AutoTuneEfReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecallMeasurement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FailedIndicesReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FailedIndex); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RetryIndexRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyIndexRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyIndexReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GraphCheck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hnswservice_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogCheck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
+	file_hnswservice_proto_msgTypes[13].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_hnswservice_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   15,
+			NumEnums:      5,
+			NumMessages:   40,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
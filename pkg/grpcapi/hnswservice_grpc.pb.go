@@ -39,6 +39,28 @@ type ServerClient interface {
 	Indices(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*IndicesReply, error)
 	// SetEf sets the `ef` parameter for the given index.
 	SetEf(ctx context.Context, in *SetEfRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// GetVectors returns the vectors stored with the given IDs in the given index.
+	GetVectors(ctx context.Context, in *GetVectorsRequest, opts ...grpc.CallOption) (*GetVectorsReply, error)
+	// ExportIndex streams all the vectors of the given index which are not marked deleted, with their IDs.
+	ExportIndex(ctx context.Context, in *ExportIndexRequest, opts ...grpc.CallOption) (Server_ExportIndexClient, error)
+	// CompactIndex rebuilds the given index from the vectors which are not marked deleted, reclaiming their capacity.
+	CompactIndex(ctx context.Context, in *CompactIndexRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// DeleteVectors marks the given vectors deleted, hiding them from search results. Unknown IDs are ignored.
+	DeleteVectors(ctx context.Context, in *DeleteVectorsRequest, opts ...grpc.CallOption) (*DeleteVectorsReply, error)
+	// UndeleteVectors restores the given vectors marked deleted.
+	UndeleteVectors(ctx context.Context, in *UndeleteVectorsRequest, opts ...grpc.CallOption) (*UndeleteVectorsReply, error)
+	// DescribeIndex returns the configuration and the statistics of the given index.
+	DescribeIndex(ctx context.Context, in *DescribeIndexRequest, opts ...grpc.CallOption) (*DescribeIndexReply, error)
+	// EvaluateRecall compares the results of approximate and exact searches on the given index, at its current `ef`.
+	EvaluateRecall(ctx context.Context, in *EvaluateRecallRequest, opts ...grpc.CallOption) (*EvaluateRecallReply, error)
+	// AutoTuneEf sets the smallest `ef` parameter of the given index meeting a target recall, measured as by EvaluateRecall.
+	AutoTuneEf(ctx context.Context, in *AutoTuneEfRequest, opts ...grpc.CallOption) (*AutoTuneEfReply, error)
+	// FailedIndices returns the indices which could not be loaded, with the errors which made the loading fail.
+	FailedIndices(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*FailedIndicesReply, error)
+	// RetryIndex loads again an index which could not be loaded, optionally repairing it from its previous snapshot and write-ahead log.
+	RetryIndex(ctx context.Context, in *RetryIndexRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// VerifyIndex verifies the integrity of the files of the given index, optionally repairing its write-ahead log.
+	VerifyIndex(ctx context.Context, in *VerifyIndexRequest, opts ...grpc.CallOption) (*VerifyIndexReply, error)
 }
 
 type serverClient struct {
@@ -189,6 +211,128 @@ func (c *serverClient) SetEf(ctx context.Context, in *SetEfRequest, opts ...grpc
 	return out, nil
 }
 
+func (c *serverClient) GetVectors(ctx context.Context, in *GetVectorsRequest, opts ...grpc.CallOption) (*GetVectorsReply, error) {
+	out := new(GetVectorsReply)
+	err := c.cc.Invoke(ctx, "/grpcapi.Server/GetVectors", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) ExportIndex(ctx context.Context, in *ExportIndexRequest, opts ...grpc.CallOption) (Server_ExportIndexClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Server_ServiceDesc.Streams[2], "/grpcapi.Server/ExportIndex", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serverExportIndexClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Server_ExportIndexClient interface {
+	Recv() (*ExportIndexReply, error)
+	grpc.ClientStream
+}
+
+type serverExportIndexClient struct {
+	grpc.ClientStream
+}
+
+func (x *serverExportIndexClient) Recv() (*ExportIndexReply, error) {
+	m := new(ExportIndexReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *serverClient) CompactIndex(ctx context.Context, in *CompactIndexRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/grpcapi.Server/CompactIndex", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) DeleteVectors(ctx context.Context, in *DeleteVectorsRequest, opts ...grpc.CallOption) (*DeleteVectorsReply, error) {
+	out := new(DeleteVectorsReply)
+	err := c.cc.Invoke(ctx, "/grpcapi.Server/DeleteVectors", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) UndeleteVectors(ctx context.Context, in *UndeleteVectorsRequest, opts ...grpc.CallOption) (*UndeleteVectorsReply, error) {
+	out := new(UndeleteVectorsReply)
+	err := c.cc.Invoke(ctx, "/grpcapi.Server/UndeleteVectors", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) DescribeIndex(ctx context.Context, in *DescribeIndexRequest, opts ...grpc.CallOption) (*DescribeIndexReply, error) {
+	out := new(DescribeIndexReply)
+	err := c.cc.Invoke(ctx, "/grpcapi.Server/DescribeIndex", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) EvaluateRecall(ctx context.Context, in *EvaluateRecallRequest, opts ...grpc.CallOption) (*EvaluateRecallReply, error) {
+	out := new(EvaluateRecallReply)
+	err := c.cc.Invoke(ctx, "/grpcapi.Server/EvaluateRecall", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) AutoTuneEf(ctx context.Context, in *AutoTuneEfRequest, opts ...grpc.CallOption) (*AutoTuneEfReply, error) {
+	out := new(AutoTuneEfReply)
+	err := c.cc.Invoke(ctx, "/grpcapi.Server/AutoTuneEf", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) FailedIndices(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*FailedIndicesReply, error) {
+	out := new(FailedIndicesReply)
+	err := c.cc.Invoke(ctx, "/grpcapi.Server/FailedIndices", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) RetryIndex(ctx context.Context, in *RetryIndexRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/grpcapi.Server/RetryIndex", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) VerifyIndex(ctx context.Context, in *VerifyIndexRequest, opts ...grpc.CallOption) (*VerifyIndexReply, error) {
+	out := new(VerifyIndexReply)
+	err := c.cc.Invoke(ctx, "/grpcapi.Server/VerifyIndex", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ServerServer is the server API for Server service.
 // All implementations must embed UnimplementedServerServer
 // for forward compatibility
@@ -213,6 +357,28 @@ type ServerServer interface {
 	Indices(context.Context, *emptypb.Empty) (*IndicesReply, error)
 	// SetEf sets the `ef` parameter for the given index.
 	SetEf(context.Context, *SetEfRequest) (*emptypb.Empty, error)
+	// GetVectors returns the vectors stored with the given IDs in the given index.
+	GetVectors(context.Context, *GetVectorsRequest) (*GetVectorsReply, error)
+	// ExportIndex streams all the vectors of the given index which are not marked deleted, with their IDs.
+	ExportIndex(*ExportIndexRequest, Server_ExportIndexServer) error
+	// CompactIndex rebuilds the given index from the vectors which are not marked deleted, reclaiming their capacity.
+	CompactIndex(context.Context, *CompactIndexRequest) (*emptypb.Empty, error)
+	// DeleteVectors marks the given vectors deleted, hiding them from search results. Unknown IDs are ignored.
+	DeleteVectors(context.Context, *DeleteVectorsRequest) (*DeleteVectorsReply, error)
+	// UndeleteVectors restores the given vectors marked deleted.
+	UndeleteVectors(context.Context, *UndeleteVectorsRequest) (*UndeleteVectorsReply, error)
+	// DescribeIndex returns the configuration and the statistics of the given index.
+	DescribeIndex(context.Context, *DescribeIndexRequest) (*DescribeIndexReply, error)
+	// EvaluateRecall compares the results of approximate and exact searches on the given index, at its current `ef`.
+	EvaluateRecall(context.Context, *EvaluateRecallRequest) (*EvaluateRecallReply, error)
+	// AutoTuneEf sets the smallest `ef` parameter of the given index meeting a target recall, measured as by EvaluateRecall.
+	AutoTuneEf(context.Context, *AutoTuneEfRequest) (*AutoTuneEfReply, error)
+	// FailedIndices returns the indices which could not be loaded, with the errors which made the loading fail.
+	FailedIndices(context.Context, *emptypb.Empty) (*FailedIndicesReply, error)
+	// RetryIndex loads again an index which could not be loaded, optionally repairing it from its previous snapshot and write-ahead log.
+	RetryIndex(context.Context, *RetryIndexRequest) (*emptypb.Empty, error)
+	// VerifyIndex verifies the integrity of the files of the given index, optionally repairing its write-ahead log.
+	VerifyIndex(context.Context, *VerifyIndexRequest) (*VerifyIndexReply, error)
 	mustEmbedUnimplementedServerServer()
 }
 
@@ -250,6 +416,39 @@ func (UnimplementedServerServer) Indices(context.Context, *emptypb.Empty) (*Indi
 func (UnimplementedServerServer) SetEf(context.Context, *SetEfRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetEf not implemented")
 }
+func (UnimplementedServerServer) GetVectors(context.Context, *GetVectorsRequest) (*GetVectorsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVectors not implemented")
+}
+func (UnimplementedServerServer) ExportIndex(*ExportIndexRequest, Server_ExportIndexServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportIndex not implemented")
+}
+func (UnimplementedServerServer) CompactIndex(context.Context, *CompactIndexRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompactIndex not implemented")
+}
+func (UnimplementedServerServer) DeleteVectors(context.Context, *DeleteVectorsRequest) (*DeleteVectorsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteVectors not implemented")
+}
+func (UnimplementedServerServer) UndeleteVectors(context.Context, *UndeleteVectorsRequest) (*UndeleteVectorsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UndeleteVectors not implemented")
+}
+func (UnimplementedServerServer) DescribeIndex(context.Context, *DescribeIndexRequest) (*DescribeIndexReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DescribeIndex not implemented")
+}
+func (UnimplementedServerServer) EvaluateRecall(context.Context, *EvaluateRecallRequest) (*EvaluateRecallReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EvaluateRecall not implemented")
+}
+func (UnimplementedServerServer) AutoTuneEf(context.Context, *AutoTuneEfRequest) (*AutoTuneEfReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AutoTuneEf not implemented")
+}
+func (UnimplementedServerServer) FailedIndices(context.Context, *emptypb.Empty) (*FailedIndicesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FailedIndices not implemented")
+}
+func (UnimplementedServerServer) RetryIndex(context.Context, *RetryIndexRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RetryIndex not implemented")
+}
+func (UnimplementedServerServer) VerifyIndex(context.Context, *VerifyIndexRequest) (*VerifyIndexReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyIndex not implemented")
+}
 func (UnimplementedServerServer) mustEmbedUnimplementedServerServer() {}
 
 // UnsafeServerServer may be embedded to opt out of forward compatibility for this service.
@@ -459,6 +658,207 @@ func _Server_SetEf_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Server_GetVectors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVectorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).GetVectors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Server/GetVectors",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).GetVectors(ctx, req.(*GetVectorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_ExportIndex_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportIndexRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServerServer).ExportIndex(m, &serverExportIndexServer{stream})
+}
+
+type Server_ExportIndexServer interface {
+	Send(*ExportIndexReply) error
+	grpc.ServerStream
+}
+
+type serverExportIndexServer struct {
+	grpc.ServerStream
+}
+
+func (x *serverExportIndexServer) Send(m *ExportIndexReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Server_CompactIndex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompactIndexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).CompactIndex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Server/CompactIndex",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).CompactIndex(ctx, req.(*CompactIndexRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_DeleteVectors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteVectorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).DeleteVectors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Server/DeleteVectors",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).DeleteVectors(ctx, req.(*DeleteVectorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_UndeleteVectors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndeleteVectorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).UndeleteVectors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Server/UndeleteVectors",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).UndeleteVectors(ctx, req.(*UndeleteVectorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_DescribeIndex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DescribeIndexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).DescribeIndex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Server/DescribeIndex",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).DescribeIndex(ctx, req.(*DescribeIndexRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_EvaluateRecall_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvaluateRecallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).EvaluateRecall(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Server/EvaluateRecall",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).EvaluateRecall(ctx, req.(*EvaluateRecallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_AutoTuneEf_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AutoTuneEfRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).AutoTuneEf(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Server/AutoTuneEf",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).AutoTuneEf(ctx, req.(*AutoTuneEfRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_FailedIndices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).FailedIndices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Server/FailedIndices",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).FailedIndices(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_RetryIndex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetryIndexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).RetryIndex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Server/RetryIndex",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).RetryIndex(ctx, req.(*RetryIndexRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_VerifyIndex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyIndexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).VerifyIndex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Server/VerifyIndex",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).VerifyIndex(ctx, req.(*VerifyIndexRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Server_ServiceDesc is the grpc.ServiceDesc for Server service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -498,6 +898,46 @@ var Server_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SetEf",
 			Handler:    _Server_SetEf_Handler,
 		},
+		{
+			MethodName: "GetVectors",
+			Handler:    _Server_GetVectors_Handler,
+		},
+		{
+			MethodName: "CompactIndex",
+			Handler:    _Server_CompactIndex_Handler,
+		},
+		{
+			MethodName: "DeleteVectors",
+			Handler:    _Server_DeleteVectors_Handler,
+		},
+		{
+			MethodName: "UndeleteVectors",
+			Handler:    _Server_UndeleteVectors_Handler,
+		},
+		{
+			MethodName: "DescribeIndex",
+			Handler:    _Server_DescribeIndex_Handler,
+		},
+		{
+			MethodName: "EvaluateRecall",
+			Handler:    _Server_EvaluateRecall_Handler,
+		},
+		{
+			MethodName: "AutoTuneEf",
+			Handler:    _Server_AutoTuneEf_Handler,
+		},
+		{
+			MethodName: "FailedIndices",
+			Handler:    _Server_FailedIndices_Handler,
+		},
+		{
+			MethodName: "RetryIndex",
+			Handler:    _Server_RetryIndex_Handler,
+		},
+		{
+			MethodName: "VerifyIndex",
+			Handler:    _Server_VerifyIndex_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -510,6 +950,11 @@ var Server_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Server_InsertVectorsWithIds_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "ExportIndex",
+			Handler:       _Server_ExportIndex_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "hnswservice.proto",
 }
@@ -0,0 +1,241 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo
+
+import (
+	"context"
+	"fmt"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/wal"
+	"os"
+	"path"
+	"sync/atomic"
+)
+
+// compactionBatchSize is the amount of vectors copied at once to the
+// rebuilt index during a compaction.
+const compactionBatchSize = 1024
+
+// Compaction is a rebuilt copy of an index, ready to replace it.
+// It is created by HNSW.Compact, and it must be either committed or
+// aborted.
+type Compaction struct {
+	old  *HNSW
+	new  *HNSW
+	done bool
+}
+
+// Compact rebuilds the index from its elements which are not marked
+// deleted, reclaiming the capacity and the search time taken by deleted
+// elements, which are never reused otherwise.
+//
+// The new index is built by the given amount of workers, and saved in the
+// given dir, while h keeps serving all operations. The write operations
+// performed in the meantime are recorded, to be replayed on the new index
+// by Compaction.Commit. If the context is done before the new index is
+// saved, the compaction is aborted and the context error is returned.
+//
+// Only one compaction at a time can be performed on an index.
+func (h *HNSW) Compact(ctx context.Context, dir string, workers int) (_ *Compaction, err error) {
+	if h.readOnly {
+		return nil, ErrReadOnly
+	}
+	h, unlock, err := h.acquire()
+	if err != nil {
+		return nil, err
+	}
+	h.journalMx.Lock()
+	compacting := h.compacting
+	h.compacting = true
+	h.journalMx.Unlock()
+	ef := h.index.ef()
+	lastAutoID := atomic.LoadUint32(&h.state.LastAutoID)
+	keepPrevious := h.keepPrevious
+	unlock()
+
+	if compacting {
+		return nil, fmt.Errorf("a compaction of the index is already in progress")
+	}
+
+	// The new index keeps the format of the old one (see
+	// hnswState.NativeCosine).
+	c := &Compaction{old: h, new: newHNSW(dir, h.state.Config, h.state.NativeCosine, h.logger)}
+	c.new.keepPrevious = keepPrevious
+	defer func() {
+		if err != nil {
+			c.Abort()
+		}
+	}()
+	c.new.state.LastAutoID = lastAutoID
+	c.new.index.setEf(ef)
+
+	ids := h.IDs()
+	for start := 0; start < len(ids); start += compactionBatchSize {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		end := start + compactionBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		vectors := make([][]float32, 0, end-start)
+		vectorIDs := make([]uint32, 0, end-start)
+		for _, id := range ids[start:end] {
+			// Elements marked deleted in the meantime are not found.
+			if vector, found := h.GetOriginalVector(id); found {
+				vectors = append(vectors, vector)
+				vectorIDs = append(vectorIDs, id)
+			}
+		}
+		err = c.new.addPoints(ctx, vectors, vectorIDs, h.getExpirations(vectorIDs), workers, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = c.new.Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Commit replaces the old index with the rebuilt one, and returns the
+// latter.
+//
+// The old index is locked while the recorded write operations are replayed
+// on the new index, and their dirs are swapped: the old dir is moved to
+// oldDir, and then removed. From now on, all operations on the old HNSW
+// object are forwarded to the new one.
+//
+// If an error occurs, the compaction is aborted, and the old index keeps
+// serving.
+func (c *Compaction) Commit(oldDir string) (*HNSW, error) {
+	if c.done {
+		return nil, fmt.Errorf("the compaction was already committed or aborted")
+	}
+
+	h, n := c.old, c.new
+	h.rwMx.Lock()
+	defer h.rwMx.Unlock()
+
+	h.journalMx.Lock()
+	journal := h.journal
+	h.compacting = false
+	h.journal = nil
+	h.journalMx.Unlock()
+
+	err := c.replay(journal)
+	if err == nil {
+		err = c.swapDirs(oldDir)
+	}
+	if err != nil {
+		c.Abort()
+		return nil, fmt.Errorf("error committing compaction: %w", err)
+	}
+	c.done = true
+
+	n.state.LastAutoID = h.state.LastAutoID
+	n.expired = atomic.LoadUint64(&h.expired)
+	n.pins = h.pins
+	h.successor = n
+	h.index.free()
+	h.index = nil
+	if h.originals != nil {
+		h.originals.close()
+	}
+
+	if err = os.RemoveAll(oldDir); err != nil {
+		n.logger.Warn().Err(err).Msgf("error removing dir %#v", oldDir)
+	}
+	return n, nil
+}
+
+// replay applies the recorded operations to the new index, writing them to
+// its log, since it was already saved.
+//
+// Elements restored during the rebuild might have been marked deleted
+// before it started: in this case, they are missing from the new index,
+// and their vectors are copied from the old one.
+func (c *Compaction) replay(journal []interface{}) error {
+	for _, e := range journal {
+		if u, ok := e.(wal.DeletionUnmark); ok {
+			if _, found := c.new.getVector(u.ID, true); !found {
+				if vector, found := c.old.getOriginalVector(u.ID, true); found {
+					e = wal.PointAddition{Vector: vector, ID: u.ID}
+				}
+			}
+		}
+		if err := c.new.applyLogEntry(e, true); err != nil {
+			return err
+		}
+	}
+	// The "ef" parameter might have been set before the compaction, and
+	// still only be stored in the old log.
+	return c.new.setEf(c.old.index.ef(), true)
+}
+
+func (c *Compaction) swapDirs(oldDir string) error {
+	h, n := c.old, c.new
+	if err := h.log.Close(); err != nil {
+		return err
+	}
+	if err := n.log.Close(); err != nil {
+		return err
+	}
+
+	err := os.Rename(h.dir, oldDir)
+	if err != nil {
+		return fmt.Errorf("error moving dir %#v to %#v: %w", h.dir, oldDir, err)
+	}
+	err = os.Rename(n.dir, h.dir)
+	if err != nil {
+		if e := os.Rename(oldDir, h.dir); e != nil {
+			h.logger.Error().Err(e).Msgf("error moving dir %#v back to %#v", oldDir, h.dir)
+		}
+		return fmt.Errorf("error moving dir %#v to %#v: %w", n.dir, h.dir, err)
+	}
+
+	n.dir = h.dir
+	n.log = wal.NewLog(path.Join(h.dir, "log"))
+	return nil
+}
+
+// Abort discards the rebuilt index, removing its dir, and stops recording
+// the write operations on the old index. It has no effect if the compaction
+// was already committed or aborted.
+func (c *Compaction) Abort() {
+	if c.done {
+		return
+	}
+	c.done = true
+
+	c.old.journalMx.Lock()
+	c.old.compacting = false
+	c.old.journal = nil
+	c.old.journalMx.Unlock()
+
+	if err := c.new.log.Close(); err != nil {
+		c.new.logger.Warn().Err(err).Msg("error closing log of compacted index")
+	}
+	if err := os.RemoveAll(c.new.dir); err != nil {
+		c.new.logger.Warn().Err(err).Msgf("error removing dir %#v", c.new.dir)
+	}
+	c.new.index.free()
+	c.new.index = nil
+	if c.new.originals != nil {
+		c.new.originals.close()
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo_test
+
+import (
+	"context"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/osutils"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path"
+	"testing"
+)
+
+func TestHNSW_Compact(t *testing.T) {
+	t.Parallel()
+
+	t.Run("deleted elements are removed", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		indexDir := path.Join(dir, "index")
+
+		config := makeConfig(hnswgo.L2Space, false)
+		config.MaxElements = 3
+		old := hnswgo.New(indexDir, config, zerolog.Nop())
+		require.NoError(t, old.Save(ctx))
+		require.NoError(t, old.AddPoints(ctx, [][]float32{sampleVectors[0], sampleVectors[1], sampleVectors[0]}, []uint32{1, 2, 3}, 2))
+		require.NoError(t, old.MarkDelete(1))
+		require.NoError(t, old.MarkDelete(3))
+
+		c, err := old.Compact(ctx, path.Join(dir, "new"), 2)
+		require.NoError(t, err)
+		index, err := c.Commit(path.Join(dir, "old"))
+		require.NoError(t, err)
+
+		assert.Equal(t, []uint32{2}, index.IDs())
+		assertDirExists(t, indexDir, true)
+		assertDirExists(t, path.Join(dir, "new"), false)
+		assertDirExists(t, path.Join(dir, "old"), false)
+
+		// The capacity of deleted elements is reclaimed
+		require.NoError(t, index.AddPoints(ctx, [][]float32{sampleVectors[0], sampleVectors[1]}, []uint32{4, 5}, 1))
+		assert.Equal(t, []uint32{2, 4, 5}, index.IDs())
+
+		// The old object forwards all operations to the new one
+		require.NoError(t, old.MarkDelete(5))
+		assert.Equal(t, []uint32{2, 4}, old.IDs())
+		assert.Equal(t, []uint32{2, 4}, index.IDs())
+
+		loaded, err := hnswgo.Load(indexDir, zerolog.Nop())
+		require.NoError(t, err)
+		assert.Equal(t, []uint32{2, 4}, loaded.IDs())
+	})
+
+	t.Run("operations during the rebuild are replayed", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		indexDir := path.Join(dir, "index")
+
+		old := hnswgo.New(indexDir, makeConfig(hnswgo.CosineSpace, true), zerolog.Nop())
+		require.NoError(t, old.Save(ctx))
+		_, err := old.AddPointsAutoID(ctx, [][]float32{sampleVectors[0], sampleVectors[1]}, 1)
+		require.NoError(t, err)
+
+		c, err := old.Compact(ctx, path.Join(dir, "new"), 1)
+		require.NoError(t, err)
+
+		id, err := old.AddPointAutoID(sampleVectors[1])
+		require.NoError(t, err)
+		assert.Equal(t, uint32(3), id)
+		require.NoError(t, old.MarkDelete(1))
+		require.NoError(t, old.SetEf(42))
+
+		index, err := c.Commit(path.Join(dir, "old"))
+		require.NoError(t, err)
+		assert.Equal(t, []uint32{2, 3}, index.IDs())
+
+		id, err = index.AddPointAutoID(sampleVectors[0])
+		require.NoError(t, err)
+		assert.Equal(t, uint32(4), id)
+
+		loaded, err := hnswgo.Load(indexDir, zerolog.Nop())
+		require.NoError(t, err)
+		assert.Equal(t, []uint32{2, 3, 4}, loaded.IDs())
+		id, err = loaded.AddPointAutoID(sampleVectors[0])
+		require.NoError(t, err)
+		assert.Equal(t, uint32(5), id)
+	})
+
+	t.Run("elements restored during the rebuild are copied", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		indexDir := path.Join(dir, "index")
+
+		old := hnswgo.New(indexDir, makeConfig(hnswgo.L2Space, false), zerolog.Nop())
+		require.NoError(t, old.Save(ctx))
+		require.NoError(t, old.AddPoints(ctx, sampleVectors, []uint32{1, 2}, 1))
+		require.NoError(t, old.MarkDelete(1))
+
+		c, err := old.Compact(ctx, path.Join(dir, "new"), 1)
+		require.NoError(t, err)
+
+		found, err := old.UnmarkDelete(1)
+		require.NoError(t, err)
+		assert.True(t, found)
+
+		index, err := c.Commit(path.Join(dir, "old"))
+		require.NoError(t, err)
+		assert.Equal(t, []uint32{1, 2}, index.IDs())
+		vector, found := index.GetVector(1)
+		require.True(t, found)
+		assert.Equal(t, sampleVectors[0], vector)
+
+		loaded, err := hnswgo.Load(indexDir, zerolog.Nop())
+		require.NoError(t, err)
+		assert.Equal(t, []uint32{1, 2}, loaded.IDs())
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		old := hnswgo.New(path.Join(dir, "index"), makeConfig(hnswgo.L2Space, false), zerolog.Nop())
+		require.NoError(t, old.Save(ctx))
+		require.NoError(t, old.AddPoint(sampleVectors[0], 1))
+
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		c, err := old.Compact(canceledCtx, path.Join(dir, "new"), 1)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, c)
+		assertDirExists(t, path.Join(dir, "new"), false)
+
+		// A new compaction can be started
+		c, err = old.Compact(ctx, path.Join(dir, "new"), 1)
+		require.NoError(t, err)
+		c.Abort()
+		assertDirExists(t, path.Join(dir, "new"), false)
+		assert.Equal(t, []uint32{1}, old.IDs())
+	})
+
+	t.Run("one compaction at a time", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		old := hnswgo.New(path.Join(dir, "index"), makeConfig(hnswgo.L2Space, false), zerolog.Nop())
+		c, err := old.Compact(ctx, path.Join(dir, "new1"), 1)
+		require.NoError(t, err)
+		defer c.Abort()
+
+		_, err = old.Compact(ctx, path.Join(dir, "new2"), 1)
+		assert.Error(t, err)
+		assertDirExists(t, path.Join(dir, "new1"), true)
+	})
+
+	t.Run("commit error", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		old := hnswgo.New(path.Join(dir, "index"), makeConfig(hnswgo.L2Space, false), zerolog.Nop())
+		require.NoError(t, old.Save(ctx))
+		c, err := old.Compact(ctx, path.Join(dir, "new"), 1)
+		require.NoError(t, err)
+
+		// The old dir cannot be created
+		index, err := c.Commit(path.Join(dir, "foo", "old"))
+		assert.Error(t, err)
+		assert.Nil(t, index)
+		assertDirExists(t, path.Join(dir, "new"), false)
+		assertDirExists(t, path.Join(dir, "index"), true)
+
+		require.NoError(t, old.AddPoint(sampleVectors[0], 1))
+		assert.Equal(t, []uint32{1}, old.IDs())
+	})
+}
+
+func assertDirExists(t *testing.T, dir string, expected bool) {
+	t.Helper()
+	exists, err := osutils.DirExists(dir)
+	require.NoError(t, err)
+	assert.Equal(t, expected, exists, dir)
+}
@@ -0,0 +1,179 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ExpiryStats provides statistics about the expiration of the elements of
+// an index.
+type ExpiryStats struct {
+	// Pending is the amount of elements which are set to expire.
+	Pending int
+	// NextExpiration is the earliest expiration time of the pending
+	// elements, or the zero time if there are none.
+	NextExpiration time.Time
+	// Expired is the amount of elements marked deleted by DeleteExpired,
+	// since the index was loaded.
+	Expired uint64
+}
+
+// DeleteExpired marks deleted all the elements whose expiration time is not
+// after the given time, writing them to the log at once, and returns their
+// amount.
+//
+// Expiration times are only set on insertion (see AddPointWithTTL): they are
+// removed when the elements are marked deleted, so elements which are then
+// restored (see UnmarkDelete) no longer expire.
+func (h *HNSW) DeleteExpired(now time.Time) (int, error) {
+	if h.readOnly {
+		return 0, ErrReadOnly
+	}
+	h, unlock, err := h.acquire()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	// The lock is held until the elements are marked deleted, so that the
+	// elements added again in the meantime (which first set their new
+	// expiration) are added after the deletion.
+	h.expiryMx.Lock()
+	defer h.expiryMx.Unlock()
+
+	deadline := now.UnixNano()
+	ids := make([]uint32, 0)
+	for id, expiresAt := range h.state.Expirations {
+		if expiresAt <= deadline {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	err = h.markDeletes(ids, true)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		delete(h.state.Expirations, id)
+	}
+	atomic.AddUint64(&h.expired, uint64(len(ids)))
+	return len(ids), nil
+}
+
+// ExpiryStats returns statistics about the expiration of the elements of
+// the index.
+func (h *HNSW) ExpiryStats() ExpiryStats {
+	h, unlock := h.peek()
+	defer unlock()
+
+	h.expiryMx.Lock()
+	defer h.expiryMx.Unlock()
+
+	stats := ExpiryStats{
+		Pending: len(h.state.Expirations),
+		Expired: atomic.LoadUint64(&h.expired),
+	}
+	var next int64
+	for _, expiresAt := range h.state.Expirations {
+		if next == 0 || expiresAt < next {
+			next = expiresAt
+		}
+	}
+	if next != 0 {
+		stats.NextExpiration = time.Unix(0, next)
+	}
+	return stats
+}
+
+// expirationTime returns the expiration time of an element added at the
+// given time, with the given TTL, as Unix time in nanoseconds. A zero TTL
+// means the default TTL of the index. It returns zero if the element does
+// not expire.
+func (h *HNSW) expirationTime(ttl time.Duration, now time.Time) int64 {
+	if ttl == 0 {
+		ttl = h.state.DefaultTTL
+	}
+	if ttl <= 0 {
+		return 0
+	}
+	return now.Add(ttl).UnixNano()
+}
+
+// expirationTimes returns the expiration times of n elements added now,
+// with the given TTLs, or with the default TTL of the index if ttls is nil.
+// It returns nil if no element expires.
+func (h *HNSW) expirationTimes(n int, ttls []time.Duration) ([]int64, error) {
+	if ttls != nil && len(ttls) != n {
+		return nil, fmt.Errorf("mismatching amount of vectors (%d) and TTLs (%d)", n, len(ttls))
+	}
+	if ttls == nil && h.state.DefaultTTL <= 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	expirations := make([]int64, n)
+	for i := range expirations {
+		var ttl time.Duration
+		if ttls != nil {
+			ttl = ttls[i]
+		}
+		expirations[i] = h.expirationTime(ttl, now)
+	}
+	return expirations, nil
+}
+
+// setExpirations sets the expiration times of the elements with the given
+// IDs, removing them if zero, or if expirations is nil.
+func (h *HNSW) setExpirations(ids []uint32, expirations []int64) {
+	h.expiryMx.Lock()
+	defer h.expiryMx.Unlock()
+
+	for i, id := range ids {
+		if expirations == nil || expirations[i] == 0 {
+			delete(h.state.Expirations, id)
+			continue
+		}
+		if h.state.Expirations == nil {
+			h.state.Expirations = make(map[uint32]int64)
+		}
+		h.state.Expirations[id] = expirations[i]
+	}
+}
+
+// getExpirations returns the expiration times of the elements with the
+// given IDs, or nil if none of them expires.
+func (h *HNSW) getExpirations(ids []uint32) []int64 {
+	h.expiryMx.Lock()
+	defer h.expiryMx.Unlock()
+
+	var expirations []int64
+	for i, id := range ids {
+		expiresAt, ok := h.state.Expirations[id]
+		if !ok {
+			continue
+		}
+		if expirations == nil {
+			expirations = make([]int64, len(ids))
+		}
+		expirations[i] = expiresAt
+	}
+	return expirations
+}
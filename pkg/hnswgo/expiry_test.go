@@ -0,0 +1,145 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo_test
+
+import (
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestHNSW_DeleteExpired(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default and custom TTLs", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		config := makeConfig(hnswgo.L2Space, false)
+		config.DefaultTTL = time.Hour
+		hnsw := hnswgo.New(dir, config, zerolog.Nop())
+		start := time.Now()
+
+		require.NoError(t, hnsw.AddPoint(sampleVectors[0], 1))
+		require.NoError(t, hnsw.AddPointWithTTL(sampleVectors[1], 2, time.Minute))
+		require.NoError(t, hnsw.AddPointWithTTL(sampleVectors[1], 3, -1))
+		require.NoError(t, hnsw.AddPointsWithTTL(ctx, sampleVectors, []uint32{4, 5}, []time.Duration{2 * time.Hour, 0}, 2))
+
+		stats := hnsw.ExpiryStats()
+		assert.Equal(t, 4, stats.Pending)
+		assert.WithinDuration(t, start.Add(time.Minute), stats.NextExpiration, 5*time.Second)
+		assert.Zero(t, stats.Expired)
+
+		n, err := hnsw.DeleteExpired(start.Add(30 * time.Second))
+		require.NoError(t, err)
+		assert.Zero(t, n)
+
+		n, err = hnsw.DeleteExpired(start.Add(90 * time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, []uint32{3, 4}, hnsw.IDs())
+
+		stats = hnsw.ExpiryStats()
+		assert.Equal(t, 1, stats.Pending)
+		assert.WithinDuration(t, start.Add(2*time.Hour), stats.NextExpiration, 5*time.Second)
+		assert.Equal(t, uint64(3), stats.Expired)
+	})
+
+	t.Run("deleted and re-added elements", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := hnswgo.New(dir, makeConfig(hnswgo.L2Space, true), zerolog.Nop())
+		start := time.Now()
+
+		id1, err := hnsw.AddPointAutoIDWithTTL(sampleVectors[0], time.Minute)
+		require.NoError(t, err)
+		ids, err := hnsw.AddPointsAutoIDWithTTL(ctx, sampleVectors, []time.Duration{time.Minute, time.Minute}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 3, hnsw.ExpiryStats().Pending)
+
+		// Deleted elements no longer expire, even if restored
+		require.NoError(t, hnsw.MarkDelete(id1))
+		_, err = hnsw.UnmarkDelete(id1)
+		require.NoError(t, err)
+		assert.Equal(t, 2, hnsw.ExpiryStats().Pending)
+
+		n, err := hnsw.DeleteExpired(start.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []uint32{id1}, hnsw.IDs())
+
+		_, err = hnsw.UnmarkDelete(ids[0])
+		require.NoError(t, err)
+		n, err = hnsw.DeleteExpired(start.Add(2 * time.Hour))
+		require.NoError(t, err)
+		assert.Zero(t, n)
+		assert.Equal(t, []uint32{id1, ids[0]}, hnsw.IDs())
+	})
+
+	t.Run("mismatching TTLs", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := hnswgo.New(dir, makeConfig(hnswgo.L2Space, false), zerolog.Nop())
+		err := hnsw.AddPointsWithTTL(ctx, sampleVectors, []uint32{1, 2}, []time.Duration{time.Minute}, 1)
+		assert.Error(t, err)
+		assert.Empty(t, hnsw.IDs())
+	})
+
+	t.Run("expirations are saved and recovered from the log", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		indexDir := path.Join(dir, "index")
+
+		config := makeConfig(hnswgo.L2Space, false)
+		config.DefaultTTL = time.Hour
+		hnsw := hnswgo.New(indexDir, config, zerolog.Nop())
+		require.NoError(t, hnsw.Save(ctx))
+		start := time.Now()
+
+		require.NoError(t, hnsw.AddPointWithTTL(sampleVectors[0], 1, time.Minute))
+		require.NoError(t, hnsw.Save(ctx))
+		require.NoError(t, hnsw.AddPoints(ctx, sampleVectors, []uint32{2, 3}, 1))
+		require.NoError(t, hnsw.MarkDelete(3))
+		n, err := hnsw.DeleteExpired(start.Add(30 * time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		loaded, err := hnswgo.Load(indexDir, zerolog.Nop())
+		require.NoError(t, err)
+		assert.Equal(t, time.Hour, loaded.Config().DefaultTTL)
+		assert.Equal(t, []uint32{2}, loaded.IDs())
+		assert.Equal(t, 1, loaded.ExpiryStats().Pending)
+
+		// Expirations are kept by compactions
+		c, err := loaded.Compact(ctx, path.Join(dir, "new"), 1)
+		require.NoError(t, err)
+		compacted, err := c.Commit(path.Join(dir, "old"))
+		require.NoError(t, err)
+		n, err = compacted.DeleteExpired(start.Add(2 * time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Empty(t, compacted.IDs())
+	})
+}
@@ -0,0 +1,400 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// defaultFlatEf is the initial "ef" parameter of flat indices, which has no
+// effect on them. It is the same as for the native graph.
+const defaultFlatEf = 10
+
+// flatIndex is a vectorIndex which stores the vectors in a contiguous slice,
+// and compares each query with all of them.
+//
+// Its distances are the same as for the native graph: the squared
+// Euclidean distance for L2Space, the Manhattan distance for L1Space, one
+// minus the inner product for IPSpace and CosineSpace, and the number of
+// differing components for HammingSpace.
+type flatIndex struct {
+	mx                  sync.RWMutex
+	dim                 int
+	maxElements         int
+	allowReplaceDeleted bool
+	distance            func(a, b []float32) float32
+	// The element with internal ID i has the vector
+	// data[i*dim : (i+1)*dim] and the ID labels[i].
+	data    []float32
+	labels  []uint32
+	deleted []bool
+	lookup  map[uint32]int
+	// replaceable holds the internal IDs of the elements marked deleted, in
+	// ascending order, when allowReplaceDeleted is set. Like the native
+	// graph, the lowest one is replaced first.
+	replaceable []int
+	efValue     int
+}
+
+var _ vectorIndex = &flatIndex{}
+
+func newFlatIndex(config Config) *flatIndex {
+	f := &flatIndex{
+		dim:                 config.Dim,
+		maxElements:         config.MaxElements,
+		allowReplaceDeleted: config.AllowReplaceDeleted,
+		distance:            spaceDistance(config.SpaceType),
+		lookup:              make(map[uint32]int),
+		efValue:             defaultFlatEf,
+	}
+	return f
+}
+
+// loadFlatIndex reads a flat index written by flatIndex.save.
+func loadFlatIndex(filename string, config Config) (_ *flatIndex, err error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %#v: %w", filename, err)
+	}
+	defer func() {
+		if e := file.Close(); e != nil && err == nil {
+			err = fmt.Errorf("error closing file %#v: %w", filename, e)
+		}
+	}()
+	r := bufio.NewReader(file)
+
+	var header [2]uint64
+	if err = binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("error reading flat index %#v: %w", filename, err)
+	}
+	count, dim := int(header[0]), int(header[1])
+	if dim != config.Dim {
+		return nil, fmt.Errorf("flat index %#v has dimension %d, expected %d", filename, dim, config.Dim)
+	}
+	if count > config.MaxElements {
+		return nil, fmt.Errorf("flat index %#v has %d elements, more than the maximum %d", filename, count, config.MaxElements)
+	}
+
+	f := newFlatIndex(config)
+	f.labels = make([]uint32, count)
+	deleted := make([]uint8, count)
+	f.data = make([]float32, count*dim)
+	for _, v := range []interface{}{f.labels, deleted, f.data} {
+		if err = binary.Read(r, binary.LittleEndian, v); err != nil {
+			return nil, fmt.Errorf("error reading flat index %#v: %w", filename, err)
+		}
+	}
+
+	f.deleted = make([]bool, count)
+	for i, label := range f.labels {
+		f.lookup[label] = i
+		f.deleted[i] = deleted[i] != 0
+		if f.deleted[i] && f.allowReplaceDeleted {
+			f.replaceable = append(f.replaceable, i)
+		}
+	}
+	return f, nil
+}
+
+// save writes the amount of elements and the dimension, followed by all
+// the IDs, the deletion marks and the vectors, in little-endian order.
+func (f *flatIndex) save(name string) (err error) {
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+
+	file, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("error creating file %#v: %w", name, err)
+	}
+	defer func() {
+		if e := file.Close(); e != nil && err == nil {
+			err = fmt.Errorf("error closing file %#v: %w", name, e)
+		}
+	}()
+	w := bufio.NewWriter(file)
+
+	deleted := make([]uint8, len(f.deleted))
+	for i, d := range f.deleted {
+		if d {
+			deleted[i] = 1
+		}
+	}
+	header := [2]uint64{uint64(len(f.labels)), uint64(f.dim)}
+	for _, v := range []interface{}{header, f.labels, deleted, f.data} {
+		if err = binary.Write(w, binary.LittleEndian, v); err != nil {
+			return fmt.Errorf("error writing flat index %#v: %w", name, err)
+		}
+	}
+	if err = w.Flush(); err != nil {
+		return fmt.Errorf("error writing flat index %#v: %w", name, err)
+	}
+	return nil
+}
+
+func (f *flatIndex) free() {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	f.data, f.labels, f.deleted, f.lookup, f.replaceable = nil, nil, nil, nil, nil
+}
+
+func (f *flatIndex) addPoint(vector []float32, id uint32) error {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	if i, ok := f.lookup[id]; ok {
+		copy(f.vector(i), vector)
+		f.setDeleted(i, false)
+		return nil
+	}
+	if len(f.labels) >= f.maxElements {
+		return errTooManyElements
+	}
+	f.lookup[id] = len(f.labels)
+	f.labels = append(f.labels, id)
+	f.deleted = append(f.deleted, false)
+	f.data = append(f.data, vector...)
+	return nil
+}
+
+func (f *flatIndex) reserveDeletedElement(id uint32) int64 {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	if i, ok := f.lookup[id]; ok {
+		f.removeReplaceable(i)
+		return -1
+	}
+	if len(f.replaceable) == 0 {
+		return -1
+	}
+	i := f.replaceable[0]
+	f.replaceable = f.replaceable[1:]
+	return int64(i)
+}
+
+func (f *flatIndex) replaceDeletedElement(vector []float32, id uint32, replaced int64) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	i := int(replaced)
+	if old := f.labels[i]; f.lookup[old] == i {
+		delete(f.lookup, old)
+	}
+	f.labels[i] = id
+	f.lookup[id] = i
+	copy(f.vector(i), vector)
+	f.deleted[i] = false
+}
+
+func (f *flatIndex) markDelete(id uint32) bool {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	i, ok := f.lookup[id]
+	if ok {
+		f.setDeleted(i, true)
+	}
+	return ok
+}
+
+func (f *flatIndex) unmarkDelete(id uint32) bool {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	i, ok := f.lookup[id]
+	if ok {
+		f.setDeleted(i, false)
+	}
+	return ok
+}
+
+// searchKNN always performs an exact search.
+func (f *flatIndex) searchKNN(vector []float32, N int, _ bool) []KNNResult {
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+
+	if N <= 0 {
+		return []KNNResult{}
+	}
+	results := make(resultHeap, 0, N)
+	for i, label := range f.labels {
+		if f.deleted[i] {
+			continue
+		}
+		d := f.distance(vector, f.vector(i))
+		if len(results) < N {
+			heap.Push(&results, KNNResult{ID: label, Distance: d})
+		} else if d < results[0].Distance {
+			results[0] = KNNResult{ID: label, Distance: d}
+			heap.Fix(&results, 0)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Distance < results[j].Distance })
+	return results
+}
+
+// searchKNNWithEf always performs an exact search.
+func (f *flatIndex) searchKNNWithEf(vector []float32, N int, _ int) []KNNResult {
+	return f.searchKNN(vector, N, true)
+}
+
+func (f *flatIndex) ids() []uint32 {
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+
+	var ids []uint32
+	for i, label := range f.labels {
+		if !f.deleted[i] {
+			ids = append(ids, label)
+		}
+	}
+	return ids
+}
+
+func (f *flatIndex) getVector(id uint32, includeDeleted bool) ([]float32, bool) {
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+
+	i, ok := f.lookup[id]
+	if !ok || (f.deleted[i] && !includeDeleted) {
+		return nil, false
+	}
+	vector := make([]float32, f.dim)
+	copy(vector, f.vector(i))
+	return vector, true
+}
+
+func (f *flatIndex) count() int {
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+	return len(f.labels)
+}
+
+func (f *flatIndex) memoryUsage() int64 {
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+	return int64(len(f.data)*4 + len(f.labels)*4 + len(f.deleted))
+}
+
+func (f *flatIndex) setEf(ef int) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	f.efValue = ef
+}
+
+func (f *flatIndex) ef() int {
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+	return f.efValue
+}
+
+// vector returns the vector of the element with the given internal ID,
+// sharing the underlying data.
+func (f *flatIndex) vector(i int) []float32 {
+	return f.data[i*f.dim : (i+1)*f.dim]
+}
+
+// setDeleted sets the deletion mark of the element with the given internal
+// ID, keeping replaceable up to date.
+func (f *flatIndex) setDeleted(i int, deleted bool) {
+	f.deleted[i] = deleted
+	if !f.allowReplaceDeleted {
+		return
+	}
+	if !deleted {
+		f.removeReplaceable(i)
+		return
+	}
+	pos := sort.SearchInts(f.replaceable, i)
+	if pos < len(f.replaceable) && f.replaceable[pos] == i {
+		return
+	}
+	f.replaceable = append(f.replaceable, 0)
+	copy(f.replaceable[pos+1:], f.replaceable[pos:])
+	f.replaceable[pos] = i
+}
+
+func (f *flatIndex) removeReplaceable(i int) {
+	pos := sort.SearchInts(f.replaceable, i)
+	if pos < len(f.replaceable) && f.replaceable[pos] == i {
+		f.replaceable = append(f.replaceable[:pos], f.replaceable[pos+1:]...)
+	}
+}
+
+// spaceDistance returns the distance function of the given space, which is
+// the same as for the native graph.
+func spaceDistance(st SpaceType) func(a, b []float32) float32 {
+	switch st {
+	case L2Space:
+		return l2Distance
+	case L1Space:
+		return l1Distance
+	case HammingSpace:
+		return hammingDistance
+	default:
+		return ipDistance
+	}
+}
+
+func l2Distance(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return sum
+}
+
+func l1Distance(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		d := a[i] - b[i]
+		if d < 0 {
+			d = -d
+		}
+		sum += d
+	}
+	return sum
+}
+
+func ipDistance(a, b []float32) float32 {
+	var dot float32
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return 1 - dot
+}
+
+// resultHeap is a max-heap of search results, by distance, implementing
+// heap.Interface.
+type resultHeap []KNNResult
+
+func (h resultHeap) Len() int            { return len(h) }
+func (h resultHeap) Less(i, j int) bool  { return h[i].Distance > h[j].Distance }
+func (h resultHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *resultHeap) Push(x interface{}) { *h = append(*h, x.(KNNResult)) }
+func (h *resultHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
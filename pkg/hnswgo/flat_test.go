@@ -0,0 +1,158 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo_test
+
+import (
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"path"
+	"testing"
+)
+
+func TestHNSW_FlatIndex(t *testing.T) {
+	t.Parallel()
+
+	makeFlatConfig := func(spaceType hnswgo.SpaceType) hnswgo.Config {
+		config := makeConfig(spaceType, false)
+		config.IndexType = hnswgo.FlatIndex
+		return config
+	}
+
+	t.Run("same results as exact graph searches", func(t *testing.T) {
+		t.Parallel()
+		r := rand.New(rand.NewSource(1))
+		vectors := make([][]float32, 10)
+		ids := make([]uint32, len(vectors))
+		for i := range vectors {
+			vectors[i] = []float32{r.Float32(), r.Float32(), r.Float32(), r.Float32(), r.Float32()}
+			ids[i] = uint32(i * 10)
+		}
+
+		for _, spaceType := range []hnswgo.SpaceType{hnswgo.L2Space, hnswgo.IPSpace, hnswgo.CosineSpace} {
+			dir := createTempDir(t)
+			defer deleteDir(t, dir)
+
+			flat := hnswgo.New(path.Join(dir, "flat"), makeFlatConfig(spaceType), zerolog.Nop())
+			graph := hnswgo.New(path.Join(dir, "graph"), makeConfig(spaceType, false), zerolog.Nop())
+			for _, h := range []*hnswgo.HNSW{flat, graph} {
+				require.NoError(t, h.Save(ctx))
+				require.NoError(t, h.AddPoints(ctx, vectors, ids, 2))
+				require.NoError(t, h.MarkDelete(30))
+			}
+
+			for _, query := range vectors[:3] {
+				expected := graph.SearchKNNExact(query, 4)
+				actual := flat.SearchKNN(query, 4)
+				require.Len(t, actual, len(expected), spaceType)
+				for i := range expected {
+					assert.Equal(t, expected[i].ID, actual[i].ID, spaceType)
+					assert.InDelta(t, expected[i].Distance, actual[i].Distance, 1e-5, spaceType)
+				}
+				assert.Equal(t, actual, flat.SearchKNNExact(query, 4))
+			}
+			assert.Equal(t, graph.IDs(), flat.IDs())
+		}
+	})
+
+	t.Run("elements are updated, deleted and restored", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := hnswgo.New(dir, makeFlatConfig(hnswgo.L2Space), zerolog.Nop())
+		require.NoError(t, hnsw.AddPoint(sampleVectors[0], 1))
+		require.NoError(t, hnsw.AddPoint(sampleVectors[0], 2))
+		require.NoError(t, hnsw.AddPoint(sampleVectors[1], 2))
+		vector, found := hnsw.GetVector(2)
+		require.True(t, found)
+		assert.Equal(t, sampleVectors[1], vector)
+
+		require.NoError(t, hnsw.MarkDelete(1))
+		assert.Equal(t, []uint32{2}, hnsw.IDs())
+		results := hnsw.SearchKNN(sampleVectors[0], 2)
+		require.Len(t, results, 1)
+		assert.Equal(t, uint32(2), results[0].ID)
+
+		found, err := hnsw.UnmarkDelete(1)
+		require.NoError(t, err)
+		assert.True(t, found)
+		found, err = hnsw.UnmarkDelete(3)
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.Equal(t, []uint32{1, 2}, hnsw.IDs())
+		assert.Empty(t, hnsw.SearchKNN(sampleVectors[0], 0))
+	})
+
+	t.Run("deleted elements are replaced", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		config := makeFlatConfig(hnswgo.L2Space)
+		config.MaxElements = 2
+		config.AllowReplaceDeleted = true
+		hnsw := hnswgo.New(dir, config, zerolog.Nop())
+		require.NoError(t, hnsw.Save(ctx))
+		require.NoError(t, hnsw.AddPoints(ctx, sampleVectors, []uint32{1, 2}, 2))
+		require.NoError(t, hnsw.MarkDelete(2))
+		require.NoError(t, hnsw.MarkDelete(1))
+		require.NoError(t, hnsw.AddPoint(sampleVectors[1], 3))
+		require.NoError(t, hnsw.AddPoint(sampleVectors[0], 4))
+		assert.Equal(t, []uint32{3, 4}, hnsw.IDs())
+
+		loaded, err := hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		assert.Equal(t, []uint32{3, 4}, loaded.IDs())
+		assert.Equal(t, hnsw.SearchKNN(sampleVectors[0], 2), loaded.SearchKNN(sampleVectors[0], 2))
+	})
+
+	t.Run("saving, loading and compaction", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		indexDir := path.Join(dir, "index")
+
+		hnsw := hnswgo.New(indexDir, makeFlatConfig(hnswgo.CosineSpace), zerolog.Nop())
+		require.NoError(t, hnsw.Save(ctx))
+		require.NoError(t, hnsw.AddPoints(ctx, sampleVectors, []uint32{1, 2}, 1))
+		require.NoError(t, hnsw.MarkDelete(1))
+		require.NoError(t, hnsw.Save(ctx))
+		require.NoError(t, hnsw.AddPoint(sampleVectors[0], 3))
+
+		loaded, err := hnswgo.Load(indexDir, zerolog.Nop())
+		require.NoError(t, err)
+		assert.Equal(t, hnswgo.FlatIndex, loaded.Config().IndexType)
+		assert.Equal(t, []uint32{2, 3}, loaded.IDs())
+		_, found := loaded.GetVector(1)
+		assert.False(t, found)
+		found, err = loaded.UnmarkDelete(1)
+		require.NoError(t, err)
+		assert.True(t, found)
+		require.NoError(t, loaded.MarkDelete(1))
+
+		c, err := loaded.Compact(ctx, path.Join(dir, "new"), 1)
+		require.NoError(t, err)
+		compacted, err := c.Commit(path.Join(dir, "old"))
+		require.NoError(t, err)
+		assert.Equal(t, hnswgo.FlatIndex, compacted.Config().IndexType)
+		assert.Equal(t, []uint32{2, 3}, compacted.IDs())
+		found, err = compacted.UnmarkDelete(1)
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+}
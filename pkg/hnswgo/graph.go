@@ -0,0 +1,381 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo
+
+// #cgo LDFLAGS: -L${SRCDIR}/hnsw -lm
+// #include <stdlib.h>
+// #include "hnsw_wrapper.h"
+// SPACE newSQ8Space(int dim, float *min, float *scale, char stype);
+// SPACE newPQSpace(int dim, int m, int bits, float *centroids, char stype);
+// SPACE newHalfSpace(int dim, int bfloat16, char stype);
+// SPACE newHammingSpace(int dim);
+// HNSW initHNSW(int dim, unsigned long int max_elements, int M, int ef_construction, int rand_seed, char stype, int allow_replace_deleted, SPACE space);
+// HNSW loadHNSW(char *location, int dim, unsigned long int max_elements, char stype, int allow_replace_deleted, int map, SPACE space, char **error);
+// void saveHNSW(HNSW index, char *location);
+// int isMappedHNSW(HNSW index);
+// void freeHNSW(HNSW index);
+// int addPoint(HNSW index, void *vec, unsigned long int label);
+// long long reserveDeletedElement(HNSW index, unsigned long int label);
+// void replaceDeletedElement(HNSW index, void *vec, unsigned long int label, unsigned int internal_id);
+// int markDelete(HNSW index, unsigned long int label);
+// int unmarkDelete(HNSW index, unsigned long int label);
+// int searchKnn(HNSW index, void *vec, int N, unsigned long int *label, float *dist);
+// int searchKnnWithEf(HNSW index, void *vec, int N, int ef, unsigned long int *label, float *dist);
+// int searchKnnExact(HNSW index, void *vec, int N, unsigned long int *label, float *dist);
+// void setEf(HNSW index, int ef);
+// int getEf(HNSW index);
+// unsigned long int getCurrentCount(HNSW index);
+// unsigned long int getMemoryUsage(HNSW index);
+// void checkHNSW(HNSW index, GraphCheck *check);
+// unsigned long int getLabels(HNSW index, unsigned long int *labels, unsigned long int size);
+// int getDataByLabel(HNSW index, unsigned long int label, void *vec, int include_deleted);
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// graphIndex is a vectorIndex backed by the native hnswlib graph.
+type graphIndex struct {
+	ptr C.HNSW
+	dim int
+	// quantizer is set if the native index stores quantized or
+	// half-precision vectors, which are encoded and decoded by it.
+	quantizer quantizer
+	// normalizes reports whether the native index has a cosine space,
+	// storing each vector normalized, followed by its original norm.
+	normalizes bool
+}
+
+var _ vectorIndex = &graphIndex{}
+
+// newGraphIndex creates a native index, storing the vectors encoded by the
+// quantizer, if given, or float vectors otherwise. If normalizes is true,
+// the float vectors of a cosine space are normalized by the native index.
+func newGraphIndex(config Config, quantizer quantizer, normalizes bool) *graphIndex {
+	return &graphIndex{
+		ptr: C.initHNSW(
+			C.int(config.Dim),
+			C.ulong(config.MaxElements),
+			C.int(config.M),
+			C.int(config.EfConstruction),
+			C.int(config.RandSeed),
+			spaceChar(config, normalizes),
+			cBool(config.AllowReplaceDeleted),
+			newSpace(config, quantizer),
+		),
+		dim:        config.Dim,
+		quantizer:  quantizer,
+		normalizes: normalizes,
+	}
+}
+
+// loadGraphIndex reads a native index, storing the vectors encoded by the
+// quantizer, if given, or float vectors otherwise. If normalizes is true,
+// the float vectors of a cosine space are normalized by the native index.
+// If mmap is true, the vectors and the base layer of the graph are
+// memory-mapped from the file, unless it was saved in the format of
+// upstream hnswlib, which is read into memory.
+func loadGraphIndex(filename string, config Config, quantizer quantizer, normalizes, mmap bool) (*graphIndex, error) {
+	pFilename := C.CString(filename)
+	defer C.free(unsafe.Pointer(pFilename))
+	var cErr *C.char
+	ptr := C.loadHNSW(
+		pFilename,
+		C.int(config.Dim),
+		C.ulong(config.MaxElements),
+		spaceChar(config, normalizes),
+		cBool(config.AllowReplaceDeleted),
+		cBool(mmap),
+		newSpace(config, quantizer),
+		&cErr,
+	)
+	if ptr == nil {
+		defer C.free(unsafe.Pointer(cErr))
+		return nil, fmt.Errorf("cannot load HNSW index file %#v: %s", filename, C.GoString(cErr))
+	}
+	return &graphIndex{
+		ptr:        ptr,
+		dim:        config.Dim,
+		quantizer:  quantizer,
+		normalizes: normalizes,
+	}, nil
+}
+
+// mapped reports whether the native index is memory-mapped from its file.
+func (g *graphIndex) mapped() bool {
+	return C.isMappedHNSW(g.ptr) != 0
+}
+
+func (g *graphIndex) save(name string) error {
+	pName := C.CString(name)
+	defer C.free(unsafe.Pointer(pName))
+	C.saveHNSW(g.ptr, pName)
+	return nil
+}
+
+func (g *graphIndex) free() {
+	C.freeHNSW(g.ptr)
+	g.ptr = nil
+}
+
+func (g *graphIndex) addPoint(vector []float32, id uint32) error {
+	if C.addPoint(g.ptr, g.data(vector), C.ulong(id)) == 0 {
+		return errTooManyElements
+	}
+	return nil
+}
+
+func (g *graphIndex) reserveDeletedElement(id uint32) int64 {
+	return int64(C.reserveDeletedElement(g.ptr, C.ulong(id)))
+}
+
+func (g *graphIndex) replaceDeletedElement(vector []float32, id uint32, replaced int64) {
+	C.replaceDeletedElement(g.ptr, g.data(vector), C.ulong(id), C.uint(replaced))
+}
+
+func (g *graphIndex) markDelete(id uint32) bool {
+	return C.markDelete(g.ptr, C.ulong(id)) != 0
+}
+
+func (g *graphIndex) unmarkDelete(id uint32) bool {
+	return C.unmarkDelete(g.ptr, C.ulong(id)) != 0
+}
+
+func (g *graphIndex) searchKNN(vector []float32, N int, exact bool) []KNNResult {
+	cLabels := make([]C.ulong, N, N)
+	cDistances := make([]C.float, N, N)
+	var numResults int
+	if exact {
+		numResults = int(C.searchKnnExact(
+			g.ptr,
+			g.query(vector),
+			C.int(N),
+			&cLabels[0],
+			&cDistances[0],
+		))
+	} else {
+		numResults = int(C.searchKnn(
+			g.ptr,
+			g.query(vector),
+			C.int(N),
+			&cLabels[0],
+			&cDistances[0],
+		))
+	}
+	return knnResults(cLabels[:numResults], cDistances[:numResults])
+}
+
+func (g *graphIndex) searchKNNWithEf(vector []float32, N int, ef int) []KNNResult {
+	cLabels := make([]C.ulong, N, N)
+	cDistances := make([]C.float, N, N)
+	numResults := int(C.searchKnnWithEf(
+		g.ptr,
+		g.query(vector),
+		C.int(N),
+		C.int(ef),
+		&cLabels[0],
+		&cDistances[0],
+	))
+	return knnResults(cLabels[:numResults], cDistances[:numResults])
+}
+
+func knnResults(cLabels []C.ulong, cDistances []C.float) []KNNResult {
+	results := make([]KNNResult, len(cLabels))
+	for i := range results {
+		results[i] = KNNResult{
+			ID:       uint32(cLabels[i]),
+			Distance: float32(cDistances[i]),
+		}
+	}
+	return results
+}
+
+func (g *graphIndex) ids() []uint32 {
+	count := C.getCurrentCount(g.ptr)
+	if count == 0 {
+		return nil
+	}
+	// Elements added in the meantime are ignored.
+	labels := make([]C.ulong, count)
+	n := int(C.getLabels(g.ptr, &labels[0], count))
+
+	ids := make([]uint32, n)
+	for i := range ids {
+		ids[i] = uint32(labels[i])
+	}
+	return ids
+}
+
+func (g *graphIndex) getVector(id uint32, includeDeleted bool) ([]float32, bool) {
+	if g.quantizer != nil {
+		data := make([]byte, g.quantizer.dataSize())
+		found := C.getDataByLabel(g.ptr, C.ulong(id), unsafe.Pointer(&data[0]), cBool(includeDeleted))
+		if found == 0 {
+			return nil, false
+		}
+		return g.quantizer.decode(data), true
+	}
+
+	vector, _, found := g.getNormalizedVector(id, includeDeleted)
+	return vector, found
+}
+
+// getOriginalVector is the same as getVector, but in a cosine space it
+// returns the vector as it was added, before the native index normalized it.
+func (g *graphIndex) getOriginalVector(id uint32, includeDeleted bool) ([]float32, bool) {
+	vector, norm, found := g.getNormalizedVector(id, includeDeleted)
+	if !found || !g.normalizes {
+		return vector, found
+	}
+	for i := range vector {
+		vector[i] *= norm
+	}
+	return vector, true
+}
+
+// getNormalizedVector returns the float vector of an element, and its
+// original norm in a cosine space.
+func (g *graphIndex) getNormalizedVector(id uint32, includeDeleted bool) (_ []float32, norm float32, _ bool) {
+	size := g.dim
+	if g.normalizes {
+		size++
+	}
+	vector := make([]float32, size)
+	found := C.getDataByLabel(g.ptr, C.ulong(id), unsafe.Pointer(&vector[0]), cBool(includeDeleted))
+	if found == 0 {
+		return nil, 0, false
+	}
+	if g.normalizes {
+		norm = vector[g.dim]
+	}
+	return vector[:g.dim:g.dim], norm, true
+}
+
+// check verifies the consistency of the native graph.
+func (g *graphIndex) check() GraphCheck {
+	var c C.GraphCheck
+	C.checkHNSW(g.ptr, &c)
+	return GraphCheck{
+		Elements:           uint64(c.elements),
+		Deleted:            uint64(c.deleted),
+		Connections:        uint64(c.connections),
+		InvalidLinks:       uint64(c.invalid_links),
+		SelfLinks:          uint64(c.self_links),
+		DuplicateLinks:     uint64(c.duplicate_links),
+		OversizedLists:     uint64(c.oversized_lists),
+		InvalidLevels:      uint64(c.invalid_levels),
+		InvalidEntryPoint:  c.invalid_entry_point != 0,
+		InconsistentLabels: uint64(c.inconsistent_labels),
+		Unreachable:        uint64(c.unreachable),
+	}
+}
+
+func (g *graphIndex) count() int {
+	return int(C.getCurrentCount(g.ptr))
+}
+
+func (g *graphIndex) memoryUsage() int64 {
+	return int64(C.getMemoryUsage(g.ptr))
+}
+
+// data returns the vector in the format stored by the native index.
+func (g *graphIndex) data(vector []float32) unsafe.Pointer {
+	if g.quantizer != nil {
+		data := g.quantizer.encode(vector)
+		return unsafe.Pointer(&data[0])
+	}
+	return unsafe.Pointer(&vector[0])
+}
+
+// query returns the vector in the format searched by the native index.
+func (g *graphIndex) query(vector []float32) unsafe.Pointer {
+	if g.quantizer != nil {
+		data := g.quantizer.query(vector)
+		return unsafe.Pointer(&data[0])
+	}
+	return unsafe.Pointer(&vector[0])
+}
+
+func (g *graphIndex) setEf(ef int) {
+	C.setEf(g.ptr, C.int(ef))
+}
+
+func (g *graphIndex) ef() int {
+	return int(C.getEf(g.ptr))
+}
+
+func (st SpaceType) cChar() C.char {
+	switch st {
+	case IPSpace, CosineSpace:
+		return C.char('i')
+	case L2Space:
+		return C.char('l')
+	case L1Space:
+		return C.char('m')
+	case HammingSpace:
+		return C.char('h')
+	default:
+		panic(fmt.Sprintf("unexpected SpaceType %#v", st))
+	}
+}
+
+// spaceChar returns the native space type of the configuration: the
+// cosine space if the native index normalizes the vectors, or otherwise the
+// space of the stored float vectors (see SpaceType.cChar).
+func spaceChar(config Config, normalizes bool) C.char {
+	if normalizes {
+		return C.char('c')
+	}
+	return config.SpaceType.cChar()
+}
+
+// newSpace returns the native space of the vectors encoded by the
+// quantizer, which is owned by the index using it, or nil for float vectors.
+func newSpace(config Config, quantizer quantizer) C.SPACE {
+	switch q := quantizer.(type) {
+	case nil:
+		return nil
+	case *sq8Quantizer:
+		return C.newSQ8Space(
+			C.int(config.Dim),
+			(*C.float)(unsafe.Pointer(&q.Min[0])),
+			(*C.float)(unsafe.Pointer(&q.Scale[0])),
+			config.SpaceType.cChar(),
+		)
+	case *pqQuantizer:
+		return C.newPQSpace(
+			C.int(config.Dim),
+			C.int(q.m),
+			C.int(q.bits),
+			(*C.float)(unsafe.Pointer(&q.centroids[0])),
+			config.SpaceType.cChar(),
+		)
+	case *halfQuantizer:
+		return C.newHalfSpace(C.int(config.Dim), cBool(q.bfloat16), config.SpaceType.cChar())
+	case *binaryQuantizer:
+		return C.newHammingSpace(C.int(config.Dim))
+	default:
+		panic(fmt.Sprintf("unexpected quantizer %T", quantizer))
+	}
+}
+
+func cBool(b bool) C.int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,148 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo
+
+import (
+	"math"
+	"unsafe"
+)
+
+// Float16ToFloat32 converts an IEEE 754 half-precision (binary16) value to
+// float32. The conversion is exact.
+func Float16ToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h) & 0x3ff
+	switch {
+	case exp == 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	case exp != 0:
+		return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+	case mant == 0:
+		return math.Float32frombits(sign)
+	}
+	// Subnormal values are normalized.
+	exp = 127 - 15 + 1
+	for mant&0x400 == 0 {
+		mant <<= 1
+		exp--
+	}
+	return math.Float32frombits(sign | exp<<23 | (mant&0x3ff)<<13)
+}
+
+// Float32ToFloat16 converts a float32 value to the nearest IEEE 754
+// half-precision (binary16) value, rounding ties to even. Values out of
+// range become infinities.
+func Float32ToFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23&0xff) - 127 + 15
+	mant := bits & 0x7fffff
+
+	switch {
+	case bits&0x7fffffff > 0x7f800000:
+		return sign | 0x7e00
+	case exp >= 0x1f:
+		return sign | 0x7c00
+	case exp <= 0:
+		// Subnormal values, with the implicit leading bit of the mantissa.
+		shift := uint(14 - exp)
+		if shift > 24 {
+			return sign
+		}
+		return sign | uint16(roundShift(mant|0x800000, shift))
+	}
+	// A carry out of the mantissa correctly increments the exponent, up to
+	// infinity.
+	return sign | uint16(uint32(exp)<<10+roundShift(mant, 13))
+}
+
+// roundShift shifts v right, rounding to the nearest value, ties to even.
+func roundShift(v uint32, shift uint) uint32 {
+	rounded := v >> shift
+	rem, half := v&(1<<shift-1), uint32(1)<<(shift-1)
+	if rem > half || rem == half && rounded&1 == 1 {
+		rounded++
+	}
+	return rounded
+}
+
+// BFloat16ToFloat32 converts a bfloat16 value, the upper half of a float32
+// value, to float32. The conversion is exact.
+func BFloat16ToFloat32(h uint16) float32 {
+	return math.Float32frombits(uint32(h) << 16)
+}
+
+// Float32ToBFloat16 converts a float32 value to the nearest bfloat16 value,
+// rounding ties to even.
+func Float32ToBFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	if bits&0x7fffffff > 0x7f800000 {
+		// NaN payloads could be rounded to infinity: keep them quiet NaNs.
+		return uint16(bits>>16) | 0x40
+	}
+	return uint16((bits + 0x7fff + bits>>16&1) >> 16)
+}
+
+// halfQuantizer stores Float16Storage and BFloat16Storage vectors, as two
+// bytes per dimension in native byte order. It needs no training.
+type halfQuantizer struct {
+	dim      int
+	bfloat16 bool
+}
+
+var _ quantizer = &halfQuantizer{}
+
+// newHalfQuantizer returns the quantizer of a Float16Storage or
+// BFloat16Storage configuration.
+func newHalfQuantizer(config Config) *halfQuantizer {
+	return &halfQuantizer{dim: config.Dim, bfloat16: config.Storage == BFloat16Storage}
+}
+
+func (q *halfQuantizer) encode(vector []float32) []byte {
+	data := make([]byte, q.dataSize())
+	values := unsafe.Slice((*uint16)(unsafe.Pointer(&data[0])), q.dim)
+	for i, v := range vector {
+		if q.bfloat16 {
+			values[i] = Float32ToBFloat16(v)
+		} else {
+			values[i] = Float32ToFloat16(v)
+		}
+	}
+	return data
+}
+
+func (q *halfQuantizer) decode(data []byte) []float32 {
+	values := unsafe.Slice((*uint16)(unsafe.Pointer(&data[0])), q.dim)
+	vector := make([]float32, q.dim)
+	for i, v := range values {
+		if q.bfloat16 {
+			vector[i] = BFloat16ToFloat32(v)
+		} else {
+			vector[i] = Float16ToFloat32(v)
+		}
+	}
+	return vector
+}
+
+// query returns the encoded vector: queries are compared in half precision
+// as well.
+func (q *halfQuantizer) query(vector []float32) []byte {
+	return q.encode(vector)
+}
+
+func (q *halfQuantizer) dataSize() int {
+	return 2 * q.dim
+}
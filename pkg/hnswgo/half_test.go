@@ -0,0 +1,156 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo_test
+
+import (
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFloat16(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		value float32
+		bits  uint16
+	}{
+		{0, 0x0000},
+		{float32(math.Copysign(0, -1)), 0x8000},
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{0.5, 0x3800},
+		{65504, 0x7bff},
+		{float32(math.Inf(1)), 0x7c00},
+		{float32(math.Inf(-1)), 0xfc00},
+		{float32(math.Ldexp(1, -14)), 0x0400},
+		{float32(math.Ldexp(1, -24)), 0x0001},
+		{float32(math.Ldexp(1023, -24)), 0x03ff},
+	} {
+		assert.Equal(t, tc.bits, hnswgo.Float32ToFloat16(tc.value), tc.value)
+		assert.Equal(t, tc.value, hnswgo.Float16ToFloat32(tc.bits), tc.bits)
+	}
+
+	t.Run("rounding", func(t *testing.T) {
+		t.Parallel()
+		// Ties are rounded to even.
+		assert.Equal(t, uint16(0x3c00), hnswgo.Float32ToFloat16(1+float32(math.Ldexp(1, -11))))
+		assert.Equal(t, uint16(0x3c02), hnswgo.Float32ToFloat16(1+3*float32(math.Ldexp(1, -11))))
+		assert.Equal(t, uint16(0x3c01), hnswgo.Float32ToFloat16(1+float32(math.Ldexp(1.1, -11))))
+		assert.Equal(t, uint16(0x7c00), hnswgo.Float32ToFloat16(65520))
+		assert.Equal(t, uint16(0x0000), hnswgo.Float32ToFloat16(float32(math.Ldexp(1, -25))))
+		assert.Equal(t, uint16(0x0001), hnswgo.Float32ToFloat16(float32(math.Ldexp(1.5, -25))))
+	})
+
+	t.Run("NaN", func(t *testing.T) {
+		t.Parallel()
+		assert.True(t, math.IsNaN(float64(hnswgo.Float16ToFloat32(hnswgo.Float32ToFloat16(float32(math.NaN()))))))
+	})
+}
+
+func TestBFloat16(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		value float32
+		bits  uint16
+	}{
+		{0, 0x0000},
+		{1, 0x3f80},
+		{-2, 0xc000},
+		{float32(math.Inf(1)), 0x7f80},
+		{float32(math.Ldexp(1, 100)), 0x7180},
+	} {
+		assert.Equal(t, tc.bits, hnswgo.Float32ToBFloat16(tc.value), tc.value)
+		assert.Equal(t, tc.value, hnswgo.BFloat16ToFloat32(tc.bits), tc.bits)
+	}
+
+	// Ties are rounded to even.
+	assert.Equal(t, uint16(0x3f80), hnswgo.Float32ToBFloat16(1+float32(math.Ldexp(1, -8))))
+	assert.Equal(t, uint16(0x3f82), hnswgo.Float32ToBFloat16(1+3*float32(math.Ldexp(1, -8))))
+	assert.True(t, math.IsNaN(float64(hnswgo.BFloat16ToFloat32(hnswgo.Float32ToBFloat16(float32(math.NaN()))))))
+}
+
+func TestHNSW_HalfPrecisionStorage(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	vectors := make([][]float32, 50)
+	ids := make([]uint32, len(vectors))
+	for i := range vectors {
+		vectors[i] = make([]float32, 16)
+		for j := range vectors[i] {
+			vectors[i][j] = r.Float32()
+		}
+		ids[i] = uint32(i + 1)
+	}
+
+	for _, tc := range []struct {
+		storage hnswgo.StorageType
+		// maxError is the maximum relative error of the stored values.
+		maxError float64
+	}{
+		{hnswgo.Float16Storage, 1.0 / 2048},
+		{hnswgo.BFloat16Storage, 1.0 / 256},
+	} {
+		tc := tc
+		t.Run(string(tc.storage), func(t *testing.T) {
+			t.Parallel()
+
+			for _, spaceType := range []hnswgo.SpaceType{hnswgo.L2Space, hnswgo.IPSpace} {
+				dir := createTempDir(t)
+				defer deleteDir(t, dir)
+
+				hnsw := hnswgo.New(dir, hnswgo.Config{
+					SpaceType:      spaceType,
+					Dim:            16,
+					MaxElements:    100,
+					M:              8,
+					EfConstruction: 100,
+					RandSeed:       100,
+					Storage:        tc.storage,
+				}, zerolog.Nop())
+				require.NoError(t, hnsw.AddPoints(ctx, vectors, ids, 2))
+
+				vector, found := hnsw.GetVector(1)
+				require.True(t, found)
+				require.Len(t, vector, 16)
+				for i, v := range vector {
+					assert.InEpsilon(t, vectors[0][i], v, tc.maxError, spaceType)
+				}
+				if spaceType == hnswgo.L2Space {
+					results := hnsw.SearchKNN(vectors[1], 1)
+					require.Len(t, results, 1)
+					assert.Equal(t, ids[1], results[0].ID)
+					assert.Equal(t, float32(0), results[0].Distance)
+				}
+				expected := hnsw.SearchKNNExact(vectors[2], 5)
+				assert.Equal(t, expected, hnsw.SearchKNN(vectors[2], 5), spaceType)
+
+				require.NoError(t, hnsw.Save(ctx))
+				loaded, err := hnswgo.Load(dir, zerolog.Nop())
+				require.NoError(t, err)
+				assert.Equal(t, expected, loaded.SearchKNNExact(vectors[2], 5), spaceType)
+				loadedVector, found := loaded.GetVector(1)
+				require.True(t, found)
+				assert.Equal(t, vector, loadedVector)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo
+
+// binaryQuantizer stores the vectors of HammingSpace indices, packing their
+// components in bytes, least significant bit first. Non-zero components
+// are stored as 1.
+type binaryQuantizer struct {
+	dim int
+}
+
+var _ quantizer = &binaryQuantizer{}
+
+func newBinaryQuantizer(config Config) *binaryQuantizer {
+	return &binaryQuantizer{dim: config.Dim}
+}
+
+func (q *binaryQuantizer) encode(vector []float32) []byte {
+	data := make([]byte, q.dataSize())
+	for i, v := range vector {
+		if v != 0 {
+			data[i>>3] |= 1 << (i & 7)
+		}
+	}
+	return data
+}
+
+func (q *binaryQuantizer) decode(data []byte) []float32 {
+	vector := make([]float32, q.dim)
+	for i := range vector {
+		vector[i] = float32(data[i>>3] >> (i & 7) & 1)
+	}
+	return vector
+}
+
+// query returns the encoded vector, compared bit by bit with the stored
+// ones.
+func (q *binaryQuantizer) query(vector []float32) []byte {
+	return q.encode(vector)
+}
+
+func (q *binaryQuantizer) dataSize() int {
+	return (q.dim + 7) / 8
+}
+
+// hammingDistance returns the number of components which are zero in one
+// vector, and non-zero in the other one.
+func hammingDistance(a, b []float32) float32 {
+	var count float32
+	for i := range a {
+		if (a[i] != 0) != (b[i] != 0) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo_test
+
+import (
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"testing"
+)
+
+func TestHNSW_HammingSpace(t *testing.T) {
+	t.Parallel()
+
+	// More than 64 bits, and not a multiple of 8.
+	const dim = 70
+	r := rand.New(rand.NewSource(1))
+	vectors := make([][]float32, 50)
+	ids := make([]uint32, len(vectors))
+	for i := range vectors {
+		vectors[i] = make([]float32, dim)
+		for j := range vectors[i] {
+			vectors[i][j] = float32(r.Intn(2))
+		}
+		ids[i] = uint32(i + 1)
+	}
+	hamming := func(a, b []float32) float32 {
+		var count float32
+		for i := range a {
+			if a[i] != b[i] {
+				count++
+			}
+		}
+		return count
+	}
+
+	for _, indexType := range []hnswgo.IndexType{hnswgo.HNSWIndex, hnswgo.FlatIndex} {
+		indexType := indexType
+		t.Run(string(indexType), func(t *testing.T) {
+			t.Parallel()
+			dir := createTempDir(t)
+			defer deleteDir(t, dir)
+
+			hnsw := hnswgo.New(dir, hnswgo.Config{
+				SpaceType:      hnswgo.HammingSpace,
+				Dim:            dim,
+				MaxElements:    100,
+				M:              8,
+				EfConstruction: 100,
+				RandSeed:       100,
+				IndexType:      indexType,
+			}, zerolog.Nop())
+			require.NoError(t, hnsw.AddPoints(ctx, vectors, ids, 2))
+
+			invalid := make([]float32, dim)
+			invalid[3] = 0.5
+			assert.Error(t, hnsw.AddPoint(invalid, 100))
+
+			vector, found := hnsw.GetVector(1)
+			require.True(t, found)
+			assert.Equal(t, vectors[0], vector)
+
+			query := append([]float32(nil), vectors[1]...)
+			query[0], query[dim-1] = 1-query[0], 1-query[dim-1]
+			results := hnsw.SearchKNNExact(query, 10)
+			require.Len(t, results, 10)
+			assert.Equal(t, ids[1], results[0].ID)
+			assert.Equal(t, float32(2), results[0].Distance)
+			for _, result := range results {
+				assert.Equal(t, hamming(query, vectors[result.ID-1]), result.Distance)
+			}
+			assert.Equal(t, results[:1], hnsw.SearchKNN(query, 1))
+
+			require.NoError(t, hnsw.Save(ctx))
+			loaded, err := hnswgo.Load(dir, zerolog.Nop())
+			require.NoError(t, err)
+			assert.Equal(t, results, loaded.SearchKNNExact(query, 10))
+		})
+	}
+}
@@ -1,19 +1,9 @@
 package hnswgo
 
-// #cgo LDFLAGS: -L${SRCDIR}/hnsw -lm
-// #include <stdlib.h>
-// #include "hnsw_wrapper.h"
-// HNSW initHNSW(int dim, unsigned long int max_elements, int M, int ef_construction, int rand_seed, char stype);
-// HNSW loadHNSW(char *location, int dim, unsigned long int max_elements, char stype);
-// void saveHNSW(HNSW index, char *location);
-// void addPoint(HNSW index, float *vec, unsigned long int label);
-// void markDelete(HNSW index, unsigned long int label);
-// int searchKnn(HNSW index, float *vec, int N, unsigned long int *label, float *dist);
-// void setEf(HNSW index, int ef);
-import "C"
-
 import (
+	"context"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/osutils"
 	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/wal"
@@ -21,9 +11,10 @@ import (
 	"math"
 	"os"
 	"path"
+	"sort"
 	"sync"
 	"sync/atomic"
-	"unsafe"
+	"time"
 )
 
 // Config provides configuration parameters for HNSW.
@@ -35,25 +26,93 @@ type Config struct {
 	EfConstruction int
 	RandSeed       int
 	AutoIDEnabled  bool
+	// AllowReplaceDeleted makes new elements replace the elements marked
+	// deleted, if any, instead of taking new slots.
+	AllowReplaceDeleted bool
+	// IndexType is the data structure of the index. The zero value means
+	// HNSWIndex. M, EfConstruction and RandSeed only apply to HNSWIndex.
+	IndexType IndexType
+	// Storage is the format of the vectors stored by HNSWIndex indices.
+	// The zero value means Float32Storage.
+	Storage StorageType
+	// TrainingSize is the amount of vectors on which the quantizer of
+	// SQ8Storage and PQStorage indices is trained. Zero means
+	// DefaultSQ8TrainingSize or DefaultPQTrainingSize.
+	TrainingSize int
+	// PQSubquantizers is the number of sub-vectors into which PQStorage
+	// splits the vectors. It must divide Dim.
+	PQSubquantizers int
+	// PQBits is the size of the codes of the sub-vectors of PQStorage,
+	// from 1 to 8. Zero means DefaultPQBits.
+	PQBits int
+	// Rerank keeps the full-precision vectors of SQ8Storage and PQStorage
+	// indices on disk, and uses them to re-rank the search results.
+	Rerank bool
+	// DefaultTTL is the time to live of new elements, unless set otherwise
+	// on insertion. Zero means no expiration.
+	DefaultTTL time.Duration
 }
 
 // SpaceType identifies a space type to be used by HNSW algorithm.
 type SpaceType string
 
+// IndexType identifies the data structure of an index.
+type IndexType string
+
+// StorageType identifies the format of the vectors stored by an index.
+type StorageType string
+
 // HNSW is an interface to HNSW C code.
+//
+// Indices of type FlatIndex are backed by a brute-force Go implementation
+// instead, while sharing all the other features.
 type HNSW struct {
 	dir   string
-	index C.HNSW
+	index vectorIndex
 	state hnswState
+	// originals are the full-precision vectors, if Config.Rerank applies.
+	originals *vectorStore
 	// log is the write-ahead log for all index operations.
 	// It is deleted (i.e. emptied) only after successful saving.
 	log *wal.Log
 	// Most operations lock the mutex for reading, including AddPoint and
 	// AddPointAutoID, since the actual locking of critical parts is
 	// already implemented in the native C++ code.
-	// The only operation which locks for writing is Save.
+	// The only operations which lock for writing are Save and
+	// Compaction.Commit.
 	rwMx   sync.RWMutex
 	logger zerolog.Logger
+	// successor is the index which replaced this one, after a compaction.
+	// If set, all operations are forwarded to it (see acquire). It is
+	// guarded by rwMx, and it never changes once set.
+	successor *HNSW
+	// With AllowReplaceDeleted, replaceMx serializes write operations, from
+	// writing to the log to updating the native index: this way, the
+	// elements replaced by new ones are the same when the log is replayed.
+	replaceMx sync.Mutex
+	// While a compaction is in progress, journal records all the write
+	// operations, as log entries.
+	compacting bool
+	journal    []interface{}
+	journalMx  sync.Mutex
+	// expiryMx guards state.Expirations and expired (see expiry.go).
+	expiryMx sync.Mutex
+	expired  uint64
+	// readOnly is set by LoadOptions.ReadOnly.
+	readOnly bool
+	// mmap is set by LoadOptions.Mmap and LoadOptions.ReadOnly.
+	mmap bool
+	// keepPrevious is set by LoadOptions.KeepPrevious, or by
+	// SetKeepPrevious. It is guarded by rwMx.
+	keepPrevious bool
+	// unloaded reports whether the index has no data structure, either
+	// because it was loaded with LoadOptions.Lazy or because it was
+	// unloaded (see Unload), until it is loaded again by acquire.
+	// It is guarded by rwMx.
+	unloaded bool
+	// pins is the number of operations preventing the index from being
+	// unloaded (see Pin). It is shared with the successor, if any.
+	pins *int32
 }
 
 // hnswState provides serializable configuration settings and other
@@ -61,6 +120,21 @@ type HNSW struct {
 type hnswState struct {
 	Config
 	LastAutoID uint32
+	// Ef is the "ef" parameter of the index when it was saved. Zero means
+	// the initial value of the index.
+	Ef int
+	// Quantizer is the trained quantizer of an SQ8Storage index, if any.
+	// The codebooks of a PQStorage index are saved to their own file.
+	Quantizer *sq8Quantizer
+	// Expirations are the expiration times of the elements which expire, as
+	// Unix times in nanoseconds.
+	Expirations map[uint32]int64
+	// NativeCosine reports whether the vectors of a cosine space are
+	// normalized by the native index, which also stores their original
+	// norms (see Config.normalizesNatively). Otherwise, they are normalized
+	// by HNSW: this is the case of the indices created before the native
+	// cosine space was introduced, which keep their format.
+	NativeCosine bool
 }
 
 const (
@@ -70,10 +144,128 @@ const (
 	CosineSpace SpaceType = "cosine"
 	// L2Space identifies an L2 space.
 	L2Space SpaceType = "l2"
+	// HammingSpace identifies a space of binary vectors, whose components
+	// are 0 or 1, compared by the number of differing components. The
+	// dimension is the number of bits, and vectors are stored bit-packed.
+	HammingSpace SpaceType = "hamming"
+	// L1Space identifies an L1 (Manhattan) space, whose distance is the sum
+	// of the absolute differences of the components.
+	L1Space SpaceType = "l1"
+)
+
+const (
+	// HNSWIndex identifies an index backed by a HNSW graph, performing
+	// approximate searches.
+	HNSWIndex IndexType = "hnsw"
+	// FlatIndex identifies an index which compares each query with all the
+	// stored vectors, performing exact searches. It is meant for small
+	// collections, where building a graph is not worth it.
+	FlatIndex IndexType = "flat"
+)
+
+const (
+	// Float32Storage identifies vectors stored in full precision.
+	Float32Storage StorageType = "float32"
+	// Float16Storage identifies vectors stored as IEEE 754 half-precision
+	// values, taking two bytes per dimension.
+	Float16Storage StorageType = "float16"
+	// BFloat16Storage identifies vectors stored as bfloat16 values, taking
+	// two bytes per dimension, with the range of float32 values but less
+	// precision than Float16Storage.
+	BFloat16Storage StorageType = "bfloat16"
+	// SQ8Storage identifies vectors stored with scalar quantization: each
+	// dimension takes one byte, mapping the range of values found in the
+	// first vectors added (see Config.TrainingSize) to 256 levels.
+	// Until then, the vectors are stored in full precision.
+	SQ8Storage StorageType = "sq8"
+	// PQStorage identifies vectors stored with product quantization: each
+	// vector is split into Config.PQSubquantizers sub-vectors, stored as
+	// the code of the nearest centroid of their subspace, with
+	// Config.PQBits bits. The centroids are found by k-means on the first
+	// vectors added (see Config.TrainingSize). Until then, the vectors are
+	// stored in full precision.
+	PQStorage StorageType = "pq"
 )
 
+// vectorIndex is the data structure storing the vectors of an index, and
+// searching them. Its implementations handle the native operations of HNSW,
+// which is responsible for the write-ahead log, the persistence and all the
+// other features built on top.
+type vectorIndex interface {
+	// save writes the index to the given file.
+	save(name string) error
+	// free releases the resources of the index, which can no longer be used.
+	free()
+	// addPoint adds a new element, or updates the existing element with the
+	// same ID, removing its deletion mark. It returns errTooManyElements if
+	// the index is full.
+	addPoint(vector []float32, id uint32) error
+	// reserveDeletedElement chooses the element marked deleted which a new
+	// element with the given ID will replace, and returns its internal ID,
+	// or -1 if there is none (see HNSW.reserveDeletedElement).
+	reserveDeletedElement(id uint32) int64
+	// replaceDeletedElement replaces the element reserved by
+	// reserveDeletedElement with a new one.
+	replaceDeletedElement(vector []float32, id uint32, replaced int64)
+	// markDelete marks an element deleted, and reports whether it exists.
+	markDelete(id uint32) bool
+	// unmarkDelete removes the deletion mark of an element, and reports
+	// whether it exists.
+	unmarkDelete(id uint32) bool
+	// searchKNN returns the nearest elements which are not marked deleted,
+	// sorted by distance.
+	searchKNN(vector []float32, N int, exact bool) []KNNResult
+	// searchKNNWithEf performs an approximate search with the given "ef"
+	// parameter, instead of the current one.
+	searchKNNWithEf(vector []float32, N int, ef int) []KNNResult
+	// ids returns the IDs of the elements which are not marked deleted, in
+	// no particular order.
+	ids() []uint32
+	// getVector returns the vector of an element.
+	getVector(id uint32, includeDeleted bool) ([]float32, bool)
+	// count returns the number of elements, including the ones marked
+	// deleted.
+	count() int
+	// memoryUsage estimates the memory taken by the elements, in bytes.
+	memoryUsage() int64
+	setEf(ef int)
+	ef() int
+}
+
+// IndexTypeFromString makes an IndexType value from string.
+// Valid string values are: "hnsw" or "flat".
+func IndexTypeFromString(s string) (IndexType, error) {
+	switch s {
+	case "hnsw":
+		return HNSWIndex, nil
+	case "flat":
+		return FlatIndex, nil
+	default:
+		return HNSWIndex, fmt.Errorf("invalid index type %#v", s)
+	}
+}
+
+// StorageTypeFromString makes a StorageType value from string.
+// Valid string values are: "float32", "float16", "bfloat16", "sq8" or "pq".
+func StorageTypeFromString(s string) (StorageType, error) {
+	switch s {
+	case "float32":
+		return Float32Storage, nil
+	case "float16":
+		return Float16Storage, nil
+	case "bfloat16":
+		return BFloat16Storage, nil
+	case "sq8":
+		return SQ8Storage, nil
+	case "pq":
+		return PQStorage, nil
+	default:
+		return Float32Storage, fmt.Errorf("invalid storage type %#v", s)
+	}
+}
+
 // SpaceTypeFromString makes a SpaceType value from string.
-// Valid string values are: "ip", "cosine", or "l2".
+// Valid string values are: "ip", "cosine", "l2", "l1", or "hamming".
 func SpaceTypeFromString(s string) (SpaceType, error) {
 	switch s {
 	case "ip":
@@ -82,69 +274,179 @@ func SpaceTypeFromString(s string) (SpaceType, error) {
 		return CosineSpace, nil
 	case "l2":
 		return L2Space, nil
+	case "l1":
+		return L1Space, nil
+	case "hamming":
+		return HammingSpace, nil
 	default:
 		return IPSpace, fmt.Errorf("invalid space type %#v", s)
 	}
 }
 
-func (st SpaceType) cChar() C.char {
-	switch st {
-	case IPSpace, CosineSpace:
-		return C.char('i')
-	case L2Space:
-		return C.char('l')
+// ValidateStorage returns an error if the storage settings of the
+// configuration are not supported.
+func (c Config) ValidateStorage() error {
+	if c.SpaceType == HammingSpace && c.Storage != "" && c.Storage != Float32Storage {
+		return fmt.Errorf("storage type %#v is not supported by Hamming spaces", c.Storage)
+	}
+	if c.SpaceType == L1Space && c.Storage != "" && c.Storage != Float32Storage {
+		return fmt.Errorf("storage type %#v is not supported by L1 spaces", c.Storage)
+	}
+
+	switch c.Storage {
+	case "", Float32Storage, Float16Storage, BFloat16Storage:
+		if c.Rerank {
+			return fmt.Errorf("re-ranking is only supported by quantized storage types")
+		}
+		if c.IndexType == FlatIndex && c.Storage != "" && c.Storage != Float32Storage {
+			return fmt.Errorf("storage type %#v is only supported by HNSW indices", c.Storage)
+		}
+		return nil
+	case SQ8Storage, PQStorage:
 	default:
-		panic(fmt.Sprintf("unexpected SpaceType %#v", st))
+		return fmt.Errorf("invalid storage type %#v", c.Storage)
 	}
+
+	if c.IndexType == FlatIndex {
+		return fmt.Errorf("storage type %#v is only supported by HNSW indices", c.Storage)
+	}
+	if c.TrainingSize < 0 {
+		return fmt.Errorf("invalid training size %d: it must not be negative", c.TrainingSize)
+	}
+	if c.Storage != PQStorage {
+		return nil
+	}
+	if c.PQSubquantizers <= 0 || c.Dim%c.PQSubquantizers != 0 {
+		return fmt.Errorf("invalid number of PQ sub-quantizers %d: it must divide the dimension %d", c.PQSubquantizers, c.Dim)
+	}
+	if c.PQBits < 0 || c.PQBits > 8 {
+		return fmt.Errorf("invalid PQ bits %d: it must be between 1 and 8", c.PQBits)
+	}
+	return nil
 }
 
 // New creates a new HNSW index.
 func New(dir string, config Config, logger zerolog.Logger) *HNSW {
-	return &HNSW{
-		dir: dir,
-		index: C.initHNSW(
-			C.int(config.Dim),
-			C.ulong(config.MaxElements),
-			C.int(config.M),
-			C.int(config.EfConstruction),
-			C.int(config.RandSeed),
-			config.SpaceType.cChar(),
-		),
+	return newHNSW(dir, config, config.normalizesNatively(), logger)
+}
+
+// newHNSW creates a new HNSW index, setting hnswState.NativeCosine.
+func newHNSW(dir string, config Config, nativeCosine bool, logger zerolog.Logger) *HNSW {
+	h := &HNSW{
+		dir:   dir,
+		index: newVectorIndex(config, nativeCosine),
 		state: hnswState{
-			Config:     config,
-			LastAutoID: 0,
+			Config:       config,
+			LastAutoID:   0,
+			NativeCosine: nativeCosine,
 		},
 		log:    wal.NewLog(path.Join(dir, "log")),
 		rwMx:   sync.RWMutex{},
 		logger: logger,
+		pins:   new(int32),
+	}
+	if config.reranks() {
+		h.originals = newVectorStore(config.Dim)
 	}
+	return h
 }
 
+// LoadOptions are the options of LoadWithOptions.
+type LoadOptions struct {
+	// Mmap memory-maps the vectors and the base layer of the graph of HNSW
+	// indices from the index file, instead of reading them into memory:
+	// they are paged in lazily, when accessed. The pages modified afterwards
+	// are copied to memory, leaving the file unchanged until Save.
+	//
+	// It only applies to index files written by Save since the mapped
+	// format was introduced: older files are read into memory, and they
+	// are converted by the next Save.
+	Mmap bool
+	// ReadOnly opens the index for searching only, implying Mmap: the
+	// pages of the index file are shared with the page cache, without
+	// being copied. The operations which would modify the index, or save
+	// it, return ErrReadOnly, except for SetEf and AutoTuneEf, whose
+	// changes are not persisted.
+	ReadOnly bool
+	// Lazy only reads the state of the index: the rest of its data is
+	// loaded by the first operation which needs it, as for the indices
+	// which are unloaded (see HNSW.Unload).
+	Lazy bool
+	// KeepPrevious makes Save keep the previous snapshot of the index, so
+	// that it can be restored if the last one is damaged (see
+	// RestorePrevious and HNSW.SetKeepPrevious).
+	KeepPrevious bool
+}
+
+// ErrReadOnly is returned by the operations which would modify an index
+// loaded with LoadOptions.ReadOnly.
+var ErrReadOnly = errors.New("the index is read-only")
+
+// errTooManyElements is returned by the additions which would exceed
+// Config.MaxElements.
+var errTooManyElements = errors.New("the number of elements exceeds the specified limit")
+
 // Load loads an HNSW index from file.
 func Load(dir string, logger zerolog.Logger) (*HNSW, error) {
+	return LoadWithOptions(dir, LoadOptions{}, logger)
+}
+
+// LoadWithOptions loads an HNSW index from file, with the given options.
+func LoadWithOptions(dir string, opts LoadOptions, logger zerolog.Logger) (*HNSW, error) {
 	state, err := loadState(dir, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	index, err := loadIndex(dir, state, logger)
+	h := &HNSW{
+		dir:          dir,
+		state:        *state,
+		rwMx:         sync.RWMutex{},
+		logger:       logger,
+		mmap:         opts.Mmap || opts.ReadOnly,
+		readOnly:     opts.ReadOnly,
+		unloaded:     true,
+		pins:         new(int32),
+		keepPrevious: opts.KeepPrevious,
+	}
+	if opts.Lazy {
+		return h, nil
+	}
+	err = h.load()
 	if err != nil {
 		return nil, err
 	}
+	return h, nil
+}
 
-	h := &HNSW{
-		dir:    dir,
-		index:  index,
-		state:  *state,
-		log:    wal.NewLog(path.Join(dir, "log")),
-		rwMx:   sync.RWMutex{},
-		logger: logger,
+// load reads the data structure of the index from its dir, and replays its
+// log, once the state is loaded. It is called at loading, and to load
+// unloaded indices again (see Unload).
+func (h *HNSW) load() (err error) {
+	index, err := loadIndex(h.dir, &h.state, h.mmap, h.logger)
+	if err != nil {
+		return err
+	}
+	if h.state.Ef > 0 {
+		index.setEf(h.state.Ef)
 	}
+	h.index = index
+	h.log = wal.NewLog(path.Join(h.dir, "log"))
+	if h.state.reranks() {
+		h.originals, err = openVectorStore(path.Join(h.dir, "vectors"), h.state.Dim)
+		if err != nil {
+			h.release()
+			return err
+		}
+	}
+	h.unloaded = false
+
 	err = h.loadLog()
 	if err != nil {
-		return nil, err
+		h.release()
+		return err
 	}
-	return h, nil
+	return nil
 }
 
 func loadState(dir string, logger zerolog.Logger) (_ *hnswState, err error) {
@@ -176,14 +478,66 @@ func loadState(dir string, logger zerolog.Logger) (_ *hnswState, err error) {
 	return state, nil
 }
 
-func loadIndex(dir string, state *hnswState, logger zerolog.Logger) (C.HNSW, error) {
-	tmpFilename := path.Join(dir, "index.tmp")
-	tmpExists, err := osutils.FileExists(tmpFilename)
-	if err != nil {
-		return nil, err
+// newVectorIndex creates the data structure of a new index, normalizing
+// the vectors of a cosine space if nativeCosine is true
+// (see hnswState.NativeCosine).
+func newVectorIndex(config Config, nativeCosine bool) vectorIndex {
+	switch {
+	case config.IndexType == FlatIndex:
+		return newFlatIndex(config)
+	case config.quantized():
+		return newQuantizedIndex(config)
+	default:
+		return newGraphIndex(config, config.fixedQuantizer(), nativeCosine)
 	}
-	if tmpExists {
-		logger.Warn().Msg("index.tmp found: the index might not be saved correctly")
+}
+
+// normalizesNatively reports whether the vectors of a cosine space with
+// the configuration can be normalized by the native index: this is the case
+// of HNSW indices storing float32 vectors.
+func (c Config) normalizesNatively() bool {
+	return c.SpaceType == CosineSpace && c.IndexType != FlatIndex && !c.quantized() && c.fixedQuantizer() == nil
+}
+
+// fixedQuantizer returns the quantizer of the configurations whose native
+// format needs no training: Float16Storage, BFloat16Storage and
+// HammingSpace. It returns nil for the other ones.
+func (c Config) fixedQuantizer() quantizer {
+	switch {
+	case c.SpaceType == HammingSpace:
+		return newBinaryQuantizer(c)
+	case c.Storage == Float16Storage || c.Storage == BFloat16Storage:
+		return newHalfQuantizer(c)
+	default:
+		return nil
+	}
+}
+
+// quantized reports whether the configuration describes a quantizedIndex.
+func (c Config) quantized() bool {
+	return (c.Storage == SQ8Storage || c.Storage == PQStorage) && c.IndexType != FlatIndex
+}
+
+// reranks reports whether Rerank applies to the configuration.
+func (c Config) reranks() bool {
+	return c.Rerank && c.quantized()
+}
+
+// loadIndex loads the data structure of the index, memory-mapping the
+// native graph if mmap is true (see LoadOptions.Mmap).
+func loadIndex(dir string, state *hnswState, mmap bool, logger zerolog.Logger) (vectorIndex, error) {
+	for _, name := range []string{"index.tmp", "training.tmp", "codebooks.tmp"} {
+		tmpExists, err := osutils.FileExists(path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		if tmpExists {
+			logger.Warn().Msgf("%s found: the index might not be saved correctly", name)
+		}
+	}
+
+	if state.quantized() {
+		return loadQuantizedIndexFiles(dir, state, mmap)
 	}
 
 	filename := path.Join(dir, "index")
@@ -195,34 +549,69 @@ func loadIndex(dir string, state *hnswState, logger zerolog.Logger) (C.HNSW, err
 		return nil, fmt.Errorf("cannot load HNSW index file %#v: file not found", filename)
 	}
 
-	pFilename := C.CString(filename)
-	defer C.free(unsafe.Pointer(pFilename))
-	index := C.loadHNSW(
-		pFilename,
-		C.int(state.Dim),
-		C.ulong(state.MaxElements),
-		state.SpaceType.cChar(),
-	)
-	return index, nil
+	if state.IndexType == FlatIndex {
+		return loadFlatIndex(filename, state.Config)
+	}
+	graph, err := loadGraphIndex(filename, state.Config, state.fixedQuantizer(), state.NativeCosine, mmap)
+	if err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
+
+// loadQuantizedIndexFiles loads a quantizedIndex from the "index" file, if
+// both the file and the quantizer are saved, or otherwise from the
+// "training" file, which holds the vectors stored until training.
+//
+// The files are renamed in this same order by Save, so that an interrupted
+// saving never pairs a quantizer with vectors it did not encode.
+func loadQuantizedIndexFiles(dir string, state *hnswState, mmap bool) (vectorIndex, error) {
+	var q quantizer
+	switch {
+	case state.Storage == PQStorage:
+		filename := path.Join(dir, "codebooks")
+		exists, err := osutils.FileExists(filename)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			codebooks, err := loadPQQuantizer(filename, state.Config)
+			if err != nil {
+				return nil, err
+			}
+			q = codebooks
+		}
+	case state.Quantizer != nil:
+		q = state.Quantizer
+	}
+
+	if q != nil {
+		filename := path.Join(dir, "index")
+		exists, err := osutils.FileExists(filename)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return loadQuantizedIndex(filename, state.Config, q, mmap)
+		}
+	}
+
+	filename := path.Join(dir, "training")
+	exists, err := osutils.FileExists(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("cannot load HNSW index file %#v: file not found", filename)
+	}
+	return loadQuantizedIndex(filename, state.Config, nil, false)
 }
 
 func (h *HNSW) loadLog() error {
 	var innerErr error
 
 	readErr := h.log.Read(func(e interface{}) error {
-		switch et := e.(type) {
-		case wal.PointAddition:
-			if h.state.AutoIDEnabled && h.state.LastAutoID < et.ID {
-				h.state.LastAutoID = et.ID
-			}
-			innerErr = h.addPoint(et.Vector, et.ID, false)
-		case wal.DeletionMark:
-			C.markDelete(h.index, C.ulong(et.ID))
-		case wal.EfSetting:
-			C.setEf(h.index, C.int(et.Ef))
-		default:
-			innerErr = fmt.Errorf("unexpected log entry %#v", e)
-		}
+		innerErr = h.applyLogEntry(e, false)
 		return innerErr
 	})
 
@@ -245,39 +634,240 @@ func (h *HNSW) loadLog() error {
 	return nil
 }
 
-// Save saves the HNSW index to file.
-func (h *HNSW) Save() error {
+// applyLogEntry performs the operation described by a log entry, optionally
+// writing it to the log.
+func (h *HNSW) applyLogEntry(e interface{}, writeToLog bool) error {
+	switch et := e.(type) {
+	case wal.PointAddition:
+		if h.state.AutoIDEnabled && h.state.LastAutoID < et.ID {
+			h.state.LastAutoID = et.ID
+		}
+		return h.addPoint(et.Vector, et.ID, et.ExpiresAt, writeToLog)
+	case wal.DeletionMark:
+		return h.markDelete(et.ID, writeToLog)
+	case wal.DeletionUnmark:
+		_, err := h.unmarkDelete(et.ID, writeToLog)
+		return err
+	case wal.EfSetting:
+		return h.setEf(et.Ef, writeToLog)
+	default:
+		return fmt.Errorf("unexpected log entry %#v", e)
+	}
+}
+
+// acquire locks the index for reading, and returns the index which must
+// actually perform the operation, with the function unlocking it.
+//
+// It is h itself, unless h was replaced by a compaction: in this case,
+// the operation is forwarded to its successor.
+//
+// If the index is unloaded, it is loaded again first (see Reload), and the
+// loading error, if any, is returned.
+func (h *HNSW) acquire() (*HNSW, func(), error) {
+	h.rwMx.RLock()
+	if s := h.successor; s != nil {
+		h.rwMx.RUnlock()
+		return s.acquire()
+	}
+	if h.unloaded {
+		h.rwMx.RUnlock()
+		if err := h.Reload(); err != nil {
+			return nil, nil, err
+		}
+		return h.acquire()
+	}
+	return h, h.rwMx.RUnlock, nil
+}
+
+// acquireOrLog is the same as acquire, for the operations which cannot
+// return an error: the loading error is logged, and false is returned.
+func (h *HNSW) acquireOrLog() (*HNSW, func(), bool) {
+	a, unlock, err := h.acquire()
+	if err != nil {
+		h.logger.Err(err).Msg("error loading index")
+		return nil, nil, false
+	}
+	return a, unlock, true
+}
+
+// peek is the same as acquire, but it does not load unloaded indices.
+func (h *HNSW) peek() (*HNSW, func()) {
+	h.rwMx.RLock()
+	if s := h.successor; s != nil {
+		h.rwMx.RUnlock()
+		return s.peek()
+	}
+	return h, h.rwMx.RUnlock
+}
+
+// acquireExclusive is the same as peek, but it locks the index for
+// writing.
+func (h *HNSW) acquireExclusive() (*HNSW, func()) {
 	h.rwMx.Lock()
-	defer h.rwMx.Unlock()
+	if s := h.successor; s != nil {
+		h.rwMx.Unlock()
+		return s.acquireExclusive()
+	}
+	return h, h.rwMx.Unlock
+}
+
+// record appends the given log entries to the journal, if a compaction is
+// in progress.
+func (h *HNSW) record(entries ...interface{}) {
+	h.journalMx.Lock()
+	defer h.journalMx.Unlock()
+
+	if h.compacting {
+		h.journal = append(h.journal, entries...)
+	}
+}
+
+// recordPointAdditions is the same as record, for many PointAddition
+// entries. The expirations are optional, as for wal.Log.WritePointAdditions.
+func (h *HNSW) recordPointAdditions(vectors [][]float32, ids []uint32, expirations []int64) {
+	h.journalMx.Lock()
+	defer h.journalMx.Unlock()
+
+	if !h.compacting {
+		return
+	}
+	for i, vector := range vectors {
+		e := wal.PointAddition{Vector: vector, ID: ids[i]}
+		if expirations != nil {
+			e.ExpiresAt = expirations[i]
+		}
+		h.journal = append(h.journal, e)
+	}
+}
+
+// Save saves the HNSW index to file.
+//
+// The context is checked before each saving step: if it is done, saving is
+// interrupted, temporary files are removed, and the context error is
+// returned. The serialization of the native index cannot be interrupted:
+// once it is done, the new files are always committed.
+func (h *HNSW) Save(ctx context.Context) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	h, unlock := h.acquireExclusive()
+	defer unlock()
+	if h.unloaded {
+		// The index was saved when it was unloaded.
+		return nil
+	}
+	return h.save(ctx)
+}
+
+// save is the same as Save, with the index already locked for writing.
+func (h *HNSW) save(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
 	err := ensureDirExists(h.dir)
 	if err != nil {
 		return err
 	}
 
+	// The "ef" parameter is no longer in the log once it is deleted.
+	h.state.Ef = h.index.ef()
+	indexFile := "index"
+	var codebooks *pqQuantizer
+	if q, ok := h.index.(*quantizedIndex); ok {
+		switch quantizer := q.quantizer().(type) {
+		case nil:
+			indexFile = "training"
+		case *sq8Quantizer:
+			h.state.Quantizer = quantizer
+		case *pqQuantizer:
+			codebooks = quantizer
+		}
+	}
+
 	// Create new temporary files: if something goes wrong, the old
 	// files (if any) will not be corrupted.
 	err = h.saveState(path.Join(h.dir, "state.tmp"))
 	if err != nil {
 		return err
 	}
-	h.saveIndex(path.Join(h.dir, "index.tmp"))
+	if err = ctx.Err(); err != nil {
+		h.removeTmpFiles()
+		return err
+	}
+	if codebooks != nil {
+		err = codebooks.save(path.Join(h.dir, "codebooks.tmp"))
+		if err != nil {
+			h.removeTmpFiles()
+			return err
+		}
+	}
+	err = h.index.save(path.Join(h.dir, indexFile+".tmp"))
+	if err != nil {
+		h.removeTmpFiles()
+		return err
+	}
+	if h.originals != nil {
+		// Vectors of elements replaced by new ones are dropped.
+		err = h.originals.write(path.Join(h.dir, "vectors.tmp"), func(id uint32) bool {
+			_, found := h.index.getVector(id, true)
+			return found
+		})
+		if err != nil {
+			h.removeTmpFiles()
+			return err
+		}
+	}
 
 	// Now that the temporary files are successfully created, replace
 	// the old files (if any) with the new ones. After that, we can
 	// finally empty the write-ahead log.
-	return h.moveTmpFilesAndDeleteLog()
+	return h.moveTmpFilesAndDeleteLog(indexFile, codebooks != nil)
 }
 
-func (h *HNSW) moveTmpFilesAndDeleteLog() error {
+// moveTmpFilesAndDeleteLog renames the temporary files written by Save:
+// the native index is saved to indexFile, which is "training" for
+// quantized indices which are not trained yet. The replaced files and the
+// log are kept as the previous snapshot (see RestorePrevious).
+func (h *HNSW) moveTmpFilesAndDeleteLog(indexFile string, codebooks bool) error {
+	if h.keepPrevious {
+		if err := keepPrevious(h.dir); err != nil {
+			h.logger.Warn().Err(err).Msg("error keeping the previous snapshot")
+		}
+	} else if err := removePrevious(h.dir); err != nil {
+		h.logger.Warn().Err(err).Msg("error removing the previous snapshot")
+	}
 	err := os.Rename(path.Join(h.dir, "state.tmp"), path.Join(h.dir, "state"))
 	if err != nil {
 		return err
 	}
-	err = os.Rename(path.Join(h.dir, "index.tmp"), path.Join(h.dir, "index"))
+	if codebooks {
+		err = os.Rename(path.Join(h.dir, "codebooks.tmp"), path.Join(h.dir, "codebooks"))
+		if err != nil {
+			return err
+		}
+	}
+	err = os.Rename(path.Join(h.dir, indexFile+".tmp"), path.Join(h.dir, indexFile))
 	if err != nil {
 		return err
 	}
+	if indexFile == "index" && h.state.quantized() {
+		// The vectors stored until training are no longer needed.
+		err = os.Remove(path.Join(h.dir, "training"))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if h.originals != nil {
+		err = os.Rename(path.Join(h.dir, "vectors.tmp"), path.Join(h.dir, "vectors"))
+		if err != nil {
+			return err
+		}
+		err = h.originals.open(path.Join(h.dir, "vectors"))
+		if err != nil {
+			return err
+		}
+	}
 	err = h.log.Delete()
 	if err != nil {
 		return err
@@ -285,6 +875,15 @@ func (h *HNSW) moveTmpFilesAndDeleteLog() error {
 	return nil
 }
 
+func (h *HNSW) removeTmpFiles() {
+	for _, name := range []string{"state.tmp", "codebooks.tmp", "index.tmp", "training.tmp", "vectors.tmp"} {
+		err := os.Remove(path.Join(h.dir, name))
+		if err != nil && !os.IsNotExist(err) {
+			h.logger.Warn().Err(err).Msgf("error removing temporary file %#v", name)
+		}
+	}
+}
+
 func (h *HNSW) saveState(name string) (err error) {
 	file, err := os.Create(name)
 	if err != nil {
@@ -303,66 +902,553 @@ func (h *HNSW) saveState(name string) (err error) {
 	return nil
 }
 
-func (h *HNSW) saveIndex(name string) {
-	pName := C.CString(name)
-	defer C.free(unsafe.Pointer(pName))
-	C.saveHNSW(h.index, pName)
+// Config returns the configuration of the index.
+func (h *HNSW) Config() Config {
+	return h.state.Config
+}
+
+// ReadOnly reports whether the index was loaded with LoadOptions.ReadOnly.
+func (h *HNSW) ReadOnly() bool {
+	return h.readOnly
 }
 
-// AddPoint adds a new vector to the index.
+// Mapped reports whether the native graph of the index is memory-mapped
+// from its file (see LoadOptions.Mmap). It is false for unloaded indices.
+func (h *HNSW) Mapped() bool {
+	h, unlock := h.peek()
+	defer unlock()
+	if h.unloaded {
+		return false
+	}
+	index := h.index
+	if q, ok := index.(*quantizedIndex); ok {
+		current, unlockCurrent := q.current()
+		defer unlockCurrent()
+		index = current
+	}
+	g, ok := index.(*graphIndex)
+	return ok && g.mapped()
+}
+
+// AddPoint adds a new vector to the index, expiring after the default TTL
+// of the index, if any.
 func (h *HNSW) AddPoint(vector []float32, id uint32) error {
+	return h.AddPointWithTTL(vector, id, 0)
+}
+
+// AddPointWithTTL adds a new vector to the index, expiring after the given
+// time to live. A zero TTL means the default TTL of the index, while a
+// negative TTL means no expiration.
+func (h *HNSW) AddPointWithTTL(vector []float32, id uint32, ttl time.Duration) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
 	if h.state.AutoIDEnabled {
 		return fmt.Errorf("invalid call to HNSW.AddPoint with auto-ID enabled")
 	}
-	return h.addPoint(vector, id, true)
+	if err := h.ValidateVector(vector); err != nil {
+		return err
+	}
+	h, unlock, err := h.acquire()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return h.addPoint(vector, id, h.expirationTime(ttl, time.Now()), true)
 }
 
-// AddPointAutoID adds a new vector to the index.
+// AddPointAutoID adds a new vector to the index, expiring after the default
+// TTL of the index, if any.
 func (h *HNSW) AddPointAutoID(vector []float32) (uint32, error) {
+	return h.AddPointAutoIDWithTTL(vector, 0)
+}
+
+// AddPointAutoIDWithTTL adds a new vector to the index, expiring after the
+// given time to live (see AddPointWithTTL).
+func (h *HNSW) AddPointAutoIDWithTTL(vector []float32, ttl time.Duration) (uint32, error) {
+	if h.readOnly {
+		return 0, ErrReadOnly
+	}
 	if !h.state.AutoIDEnabled {
 		return 0, fmt.Errorf("invalid call to HNSW.AddPointAutoID with auto-ID disabled")
 	}
+	if err := h.ValidateVector(vector); err != nil {
+		return 0, err
+	}
+	h, unlock, err := h.acquire()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
 	id := atomic.AddUint32(&h.state.LastAutoID, 1)
-	err := h.addPoint(vector, id, true)
+	err = h.addPoint(vector, id, h.expirationTime(ttl, time.Now()), true)
 	if err != nil {
 		return 0, err
 	}
 	return id, nil
 }
 
-func (h *HNSW) addPoint(vector []float32, id uint32, writeToLog bool) error {
-	h.rwMx.RLock()
-	defer h.rwMx.RUnlock()
+// addPoint adds a new vector to the index, which must be already acquired
+// (see acquire), unless it is not shared yet. The expiration time is zero
+// if the element does not expire.
+//
+// The vector is not checked with ValidateVector, which the callers adding
+// new vectors must do, so that vectors accepted by previous versions can
+// still be replayed from the log.
+func (h *HNSW) addPoint(vector []float32, id uint32, expiresAt int64, writeToLog bool) error {
+	err := h.validateVector(vector)
+	if err != nil {
+		return err
+	}
+	if err = h.checkCapacity([]uint32{id}); err != nil {
+		return err
+	}
+
+	h.setExpirations([]uint32{id}, []int64{expiresAt})
+
+	if h.state.AllowReplaceDeleted {
+		h.replaceMx.Lock()
+		defer h.replaceMx.Unlock()
+	}
+
+	if writeToLog {
+		err = h.log.WriteExpiringPointAddition(vector, id, expiresAt)
+		if err != nil {
+			return err
+		}
+	}
+	h.record(wal.PointAddition{Vector: vector, ID: id, ExpiresAt: expiresAt})
+
+	err = h.addNativePoint(vector, id, h.reserveDeletedElement(id))
+	h.trainQuantizer()
+	return err
+}
+
+// AddPoints adds new vectors to the index, with the given IDs.
+//
+// All points are first appended to the write-ahead log at once, then they
+// are inserted concurrently by the given amount of workers. If the same ID
+// is given more than once, the last vector wins.
+//
+// The context is only checked before writing to the log: once the points
+// are logged, they are always inserted.
+//
+// The points expire after the default TTL of the index, if any.
+func (h *HNSW) AddPoints(ctx context.Context, vectors [][]float32, ids []uint32, workers int) error {
+	return h.AddPointsWithTTL(ctx, vectors, ids, nil, workers)
+}
+
+// AddPointsWithTTL is the same as AddPoints, with the time to live of each
+// point (see AddPointWithTTL). If ttls is nil, the default TTL of the index
+// is used for all points.
+func (h *HNSW) AddPointsWithTTL(ctx context.Context, vectors [][]float32, ids []uint32, ttls []time.Duration, workers int) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	if h.state.AutoIDEnabled {
+		return fmt.Errorf("invalid call to HNSW.AddPoints with auto-ID enabled")
+	}
+	if len(vectors) != len(ids) {
+		return fmt.Errorf("mismatching amount of vectors (%d) and IDs (%d)", len(vectors), len(ids))
+	}
+	if err := h.validateVectors(vectors); err != nil {
+		return err
+	}
+	expirations, err := h.expirationTimes(len(vectors), ttls)
+	if err != nil {
+		return err
+	}
+	h, unlock, err := h.acquire()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return h.addPoints(ctx, vectors, ids, expirations, workers, true)
+}
+
+// AddPointsAutoID adds new vectors to the index, returning the generated
+// IDs in the same order. See AddPoints for details.
+func (h *HNSW) AddPointsAutoID(ctx context.Context, vectors [][]float32, workers int) ([]uint32, error) {
+	return h.AddPointsAutoIDWithTTL(ctx, vectors, nil, workers)
+}
+
+// AddPointsAutoIDWithTTL is the same as AddPointsAutoID, with the time to
+// live of each point (see AddPointsWithTTL).
+func (h *HNSW) AddPointsAutoIDWithTTL(ctx context.Context, vectors [][]float32, ttls []time.Duration, workers int) ([]uint32, error) {
+	if h.readOnly {
+		return nil, ErrReadOnly
+	}
+	if !h.state.AutoIDEnabled {
+		return nil, fmt.Errorf("invalid call to HNSW.AddPointsAutoID with auto-ID disabled")
+	}
+	expirations, err := h.expirationTimes(len(vectors), ttls)
+	if err != nil {
+		return nil, err
+	}
+	return h.addPointsAutoID(ctx, vectors, expirations, workers, true)
+}
+
+// ImportPoints adds new vectors to the index, with the given IDs, without
+// writing them to the write-ahead log. It is otherwise the same as
+// AddPoints.
+//
+// It is meant for the offline bulk loading of new indices: imported points
+// are lost unless the index is successfully saved afterwards.
+func (h *HNSW) ImportPoints(ctx context.Context, vectors [][]float32, ids []uint32, workers int) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	if h.state.AutoIDEnabled {
+		return fmt.Errorf("invalid call to HNSW.ImportPoints with auto-ID enabled")
+	}
+	if len(vectors) != len(ids) {
+		return fmt.Errorf("mismatching amount of vectors (%d) and IDs (%d)", len(vectors), len(ids))
+	}
+	if err := h.validateVectors(vectors); err != nil {
+		return err
+	}
+	expirations, err := h.expirationTimes(len(vectors), nil)
+	if err != nil {
+		return err
+	}
+	h, unlock, err := h.acquire()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return h.addPoints(ctx, vectors, ids, expirations, workers, false)
+}
+
+// ImportPointsAutoID adds new vectors to the index, without writing them to
+// the write-ahead log, returning the generated IDs in the same order.
+// See ImportPoints for details.
+func (h *HNSW) ImportPointsAutoID(ctx context.Context, vectors [][]float32, workers int) ([]uint32, error) {
+	if h.readOnly {
+		return nil, ErrReadOnly
+	}
+	if !h.state.AutoIDEnabled {
+		return nil, fmt.Errorf("invalid call to HNSW.ImportPointsAutoID with auto-ID disabled")
+	}
+	expirations, err := h.expirationTimes(len(vectors), nil)
+	if err != nil {
+		return nil, err
+	}
+	return h.addPointsAutoID(ctx, vectors, expirations, workers, false)
+}
+
+func (h *HNSW) addPointsAutoID(ctx context.Context, vectors [][]float32, expirations []int64, workers int, writeToLog bool) ([]uint32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := h.validateVectors(vectors); err != nil {
+		return nil, err
+	}
+
+	h, unlock, err := h.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	n := uint32(len(vectors))
+	firstID := atomic.AddUint32(&h.state.LastAutoID, n) - n + 1
+	ids := make([]uint32, n)
+	for i := range ids {
+		ids[i] = firstID + uint32(i)
+	}
+
+	err = h.addPoints(ctx, vectors, ids, expirations, workers, writeToLog)
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// addPoints adds new vectors to the index, which must be already acquired
+// (see acquire), unless it is not shared yet. The expiration times are
+// optional, as for wal.Log.WritePointAdditions. As for addPoint, the
+// vectors are not checked with ValidateVector.
+func (h *HNSW) addPoints(ctx context.Context, vectors [][]float32, ids []uint32, expirations []int64, workers int, writeToLog bool) error {
+	for _, vector := range vectors {
+		if err := h.validateVector(vector); err != nil {
+			return err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := h.checkCapacity(ids); err != nil {
+		return err
+	}
+
+	h.setExpirations(ids, expirations)
+
+	if h.state.AllowReplaceDeleted {
+		h.replaceMx.Lock()
+		defer h.replaceMx.Unlock()
+	}
 
 	if writeToLog {
-		err := h.log.WritePointAddition(vector, id)
+		err := h.log.WritePointAdditions(vectors, ids, expirations)
 		if err != nil {
 			return err
 		}
 	}
+	h.recordPointAdditions(vectors, ids, expirations)
+
+	// Concurrent insertions of the same label are not ordered, so only
+	// the last occurrence of each ID is inserted.
+	lastIndex := make(map[uint32]int, len(ids))
+	for i, id := range ids {
+		lastIndex[id] = i
+	}
+
+	// The elements to be replaced are chosen in the same order as the
+	// points would be inserted one by one, when replaying the log: the
+	// first occurrence of each ID chooses the element, and the following
+	// ones update it.
+	replaced := make(map[uint32]int64, len(ids))
+	for _, id := range ids {
+		if _, ok := replaced[id]; !ok {
+			replaced[id] = h.reserveDeletedElement(id)
+		}
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan int, workers)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := h.addNativePoint(vectors[i], ids[i], replaced[ids[i]]); err != nil {
+					errOnce.Do(func() { firstErr = err })
+				}
+			}
+		}()
+	}
+	for i, id := range ids {
+		if lastIndex[id] == i {
+			jobs <- i
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	h.trainQuantizer()
+	return firstErr
+}
+
+// checkCapacity returns errTooManyElements if adding the elements with the
+// given IDs would exceed Config.MaxElements, before they are written to
+// the log. Elements updating existing ones, or replacing elements marked
+// deleted with AllowReplaceDeleted, need no capacity. Concurrent additions
+// are not accounted for: they can still fail on insertion.
+func (h *HNSW) checkCapacity(ids []uint32) error {
+	count := h.index.count()
+	available := h.state.MaxElements - count
+	if len(ids) <= available {
+		return nil
+	}
+
+	needed := 0
+	seen := make(map[uint32]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		if _, found := h.index.getVector(id, true); !found {
+			needed++
+		}
+	}
+	if h.state.AllowReplaceDeleted {
+		available += count - len(h.index.ids())
+	}
+	if needed > available {
+		return fmt.Errorf("%w: %d new elements, %d available", errTooManyElements, needed, available)
+	}
+	return nil
+}
+
+// addNativePoint adds a vector to the native index, replacing the element
+// with the given internal ID, if it is not negative
+// (see reserveDeletedElement).
+func (h *HNSW) addNativePoint(vector []float32, id uint32, replaced int64) error {
+	vector = h.normalize(vector)
+	if h.originals != nil {
+		h.originals.put(vector, id)
+	}
+	if replaced < 0 {
+		return h.index.addPoint(vector, id)
+	}
+	h.index.replaceDeletedElement(vector, id, replaced)
+	return nil
+}
+
+// trainQuantizer trains the quantizer of an SQ8Storage or PQStorage index,
+// once it has enough vectors (see quantizedIndex.train). It must be called
+// when no element is reserved for replacement.
+func (h *HNSW) trainQuantizer() {
+	if q, ok := h.index.(*quantizedIndex); ok {
+		q.train()
+	}
+}
+
+// reserveDeletedElement chooses the element marked deleted which a new
+// element with the given ID will replace, and returns its internal ID.
+// It returns -1 if no element must be replaced, or if replacement is not
+// allowed.
+func (h *HNSW) reserveDeletedElement(id uint32) int64 {
+	if !h.state.AllowReplaceDeleted {
+		return -1
+	}
+	return h.index.reserveDeletedElement(id)
+}
+
+// ValidateVector returns an error if the vector cannot be stored in the index,
+// or used as a query.
+//
+// Zero vectors are rejected in cosine spaces, unless the index was created
+// before the native cosine space was introduced, and might already store
+// them.
+func (h *HNSW) ValidateVector(vector []float32) error {
+	if err := h.validateVector(vector); err != nil {
+		return err
+	}
+	if h.state.SpaceType == CosineSpace && !h.legacyCosine() && isZeroVector(vector) {
+		return fmt.Errorf("invalid vector: zero vectors cannot be normalized in a cosine space")
+	}
+	return nil
+}
+
+func (h *HNSW) validateVectors(vectors [][]float32) error {
+	for _, vector := range vectors {
+		if err := h.ValidateVector(vector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	if h.state.SpaceType == "cosine" {
-		vector = normalizeVector(vector)
+// validateVector returns an error if the vector does not fit the index at
+// all, regardless of its values.
+func (h *HNSW) validateVector(vector []float32) error {
+	if len(vector) != h.state.Dim {
+		return fmt.Errorf("invalid vector size %d: the index has dimension %d", len(vector), h.state.Dim)
+	}
+	if h.state.SpaceType == HammingSpace {
+		for _, v := range vector {
+			if v != 0 && v != 1 {
+				return fmt.Errorf("invalid binary vector: component %v is not 0 or 1", v)
+			}
+		}
 	}
-	C.addPoint(h.index, (*C.float)(unsafe.Pointer(&vector[0])), C.ulong(id))
 	return nil
 }
 
+// legacyCosine reports whether the index was created before the native
+// cosine space was introduced (see hnswState.NativeCosine).
+func (h *HNSW) legacyCosine() bool {
+	return h.state.normalizesNatively() && !h.state.NativeCosine
+}
+
+func isZeroVector(vector []float32) bool {
+	for _, v := range vector {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // MarkDelete marks an element with the given ID deleted.
 // It does not really change the current graph.
 func (h *HNSW) MarkDelete(id uint32) error {
-	h.rwMx.RLock()
-	defer h.rwMx.RUnlock()
-
-	err := h.log.WriteDeletionMark(id)
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	h, unlock, err := h.acquire()
 	if err != nil {
 		return err
 	}
+	defer unlock()
+	return h.markDelete(id, true)
+}
+
+func (h *HNSW) markDelete(id uint32, writeToLog bool) error {
+	h.setExpirations([]uint32{id}, nil)
+	return h.markDeletes([]uint32{id}, writeToLog)
+}
+
+// markDeletes marks the elements with the given IDs deleted, writing all
+// entries to the log at once. Unlike markDelete, it does not remove their
+// expiration times.
+func (h *HNSW) markDeletes(ids []uint32, writeToLog bool) error {
+	if h.state.AllowReplaceDeleted {
+		h.replaceMx.Lock()
+		defer h.replaceMx.Unlock()
+	}
 
-	C.markDelete(h.index, C.ulong(id))
+	if writeToLog {
+		err := h.log.WriteDeletionMarks(ids)
+		if err != nil {
+			return err
+		}
+	}
+	entries := make([]interface{}, len(ids))
+	for i, id := range ids {
+		entries[i] = wal.DeletionMark{ID: id}
+	}
+	h.record(entries...)
+
+	// Unknown IDs are ignored.
+	for _, id := range ids {
+		h.index.markDelete(id)
+	}
 	return nil
 }
 
+// UnmarkDelete removes the deletion mark from the element with the given ID,
+// restoring it, and reports whether the element exists. Elements which are
+// not marked deleted are left unchanged.
+//
+// Deleted elements can no longer be restored once the index is compacted,
+// or once they are replaced by new elements (see
+// Config.AllowReplaceDeleted).
+func (h *HNSW) UnmarkDelete(id uint32) (bool, error) {
+	if h.readOnly {
+		return false, ErrReadOnly
+	}
+	h, unlock, err := h.acquire()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	return h.unmarkDelete(id, true)
+}
+
+func (h *HNSW) unmarkDelete(id uint32, writeToLog bool) (bool, error) {
+	if h.state.AllowReplaceDeleted {
+		h.replaceMx.Lock()
+		defer h.replaceMx.Unlock()
+	}
+
+	if writeToLog {
+		err := h.log.WriteDeletionUnmark(id)
+		if err != nil {
+			return false, err
+		}
+	}
+	h.record(wal.DeletionUnmark{ID: id})
+
+	return h.index.unmarkDelete(id), nil
+}
+
 // KNNResult is an ID/Distance pair, which is a single result
 // item of HNSW.SearchKNN.
 type KNNResult struct {
@@ -372,47 +1458,171 @@ type KNNResult struct {
 
 // SearchKNN performs KNN search.
 func (h *HNSW) SearchKNN(vector []float32, N int) []KNNResult {
-	h.rwMx.RLock()
-	defer h.rwMx.RUnlock()
+	h, unlock, ok := h.acquireOrLog()
+	if !ok {
+		return nil
+	}
+	defer unlock()
+	return h.searchKNN(vector, N, false)
+}
 
-	if h.state.SpaceType == "cosine" {
-		vector = normalizeVector(vector)
+// SearchKNNExact performs exact KNN search, comparing the vector with all the
+// elements which are not marked deleted, instead of traversing the graph.
+// It is much slower than SearchKNN, but its results are the true nearest
+// neighbors.
+func (h *HNSW) SearchKNNExact(vector []float32, N int) []KNNResult {
+	h, unlock, ok := h.acquireOrLog()
+	if !ok {
+		return nil
 	}
+	defer unlock()
+	return h.searchKNN(vector, N, true)
+}
 
-	cLabels := make([]C.ulong, N, N)
-	cDistances := make([]C.float, N, N)
-	numResults := int(C.searchKnn(
-		h.index,
-		(*C.float)(unsafe.Pointer(&vector[0])),
-		C.int(N),
-		&cLabels[0],
-		&cDistances[0],
-	))
+func (h *HNSW) searchKNN(vector []float32, N int, exact bool) []KNNResult {
+	return h.search(h.normalize(vector), N, exact, 0)
+}
 
-	results := make([]KNNResult, numResults)
-	for i := range results {
-		results[i] = KNNResult{
-			ID:       uint32(cLabels[i]),
-			Distance: float32(cDistances[i]),
-		}
+// search performs a search with a vector which is already normalized by
+// HNSW.normalize, re-ranking the results if Config.Rerank applies. A positive
+// "ef" is used by approximate searches instead of the current one.
+func (h *HNSW) search(vector []float32, N int, exact bool, ef int) []KNNResult {
+	n := N
+	if h.originals != nil {
+		n *= rerankOversampling
+	}
+
+	var results []KNNResult
+	if ef > 0 && !exact {
+		results = h.index.searchKNNWithEf(vector, n, ef)
+	} else {
+		results = h.index.searchKNN(vector, n, exact)
+	}
+
+	if h.originals != nil {
+		results = h.rerank(vector, results, N)
 	}
 	return results
 }
 
-// SetEf sets the "ef" parameter.
-func (h *HNSW) SetEf(ef int) error {
-	h.rwMx.RLock()
-	defer h.rwMx.RUnlock()
+// IDs returns the IDs of all the elements which are not marked deleted,
+// in ascending order.
+func (h *HNSW) IDs() []uint32 {
+	h, unlock, ok := h.acquireOrLog()
+	if !ok {
+		return nil
+	}
+	defer unlock()
+
+	ids := h.index.ids()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
 
-	err := h.log.WriteEfSetting(ef)
+// GetVector returns the vector stored with the given ID, and reports
+// whether it is found. Elements marked deleted are not found.
+//
+// In a cosine space, the returned vector is the normalized one (see
+// GetOriginalVector). With SQ8Storage, it is the one approximated by the
+// quantizer, unless the full-precision vectors are kept (see Config.Rerank).
+func (h *HNSW) GetVector(id uint32) ([]float32, bool) {
+	h, unlock, ok := h.acquireOrLog()
+	if !ok {
+		return nil, false
+	}
+	defer unlock()
+	return h.getVector(id, false)
+}
+
+// getVector returns the vector stored with the given ID, optionally
+// including elements marked deleted, without locking the index.
+func (h *HNSW) getVector(id uint32, includeDeleted bool) ([]float32, bool) {
+	vector, found := h.index.getVector(id, includeDeleted)
+	if !found || h.originals == nil {
+		return vector, found
+	}
+	if original, found := h.originals.get(id); found {
+		return original, true
+	}
+	return vector, true
+}
+
+// GetOriginalVector is the same as GetVector, but in a cosine space it
+// returns the vector as it was added, instead of the normalized one, if the
+// index stores the original norms (see StoresNorms). Otherwise, the
+// returned vector is the normalized one as well.
+func (h *HNSW) GetOriginalVector(id uint32) ([]float32, bool) {
+	h, unlock, ok := h.acquireOrLog()
+	if !ok {
+		return nil, false
+	}
+	defer unlock()
+	return h.getOriginalVector(id, false)
+}
+
+func (h *HNSW) getOriginalVector(id uint32, includeDeleted bool) ([]float32, bool) {
+	if g, ok := h.index.(*graphIndex); ok {
+		return g.getOriginalVector(id, includeDeleted)
+	}
+	return h.getVector(id, includeDeleted)
+}
+
+// StoresNorms reports whether the index stores the original norms of the
+// vectors of a cosine space, which are normalized. They are stored by HNSW
+// indices with Float32Storage, unless they were created before the native
+// cosine space was introduced. It is false for the other space types.
+func (h *HNSW) StoresNorms() bool {
+	return h.state.SpaceType == CosineSpace && h.state.NativeCosine
+}
+
+// SetEf sets the "ef" parameter. It is not written to the log of read-only
+// indices.
+func (h *HNSW) SetEf(ef int) error {
+	h, unlock, err := h.acquire()
 	if err != nil {
 		return err
 	}
+	defer unlock()
+	return h.setEf(ef, !h.readOnly)
+}
 
-	C.setEf(h.index, C.int(ef))
+// Ef returns the current "ef" parameter.
+func (h *HNSW) Ef() int {
+	h, unlock, ok := h.acquireOrLog()
+	if !ok {
+		return 0
+	}
+	defer unlock()
+	return h.ef()
+}
+
+func (h *HNSW) ef() int {
+	return h.index.ef()
+}
+
+func (h *HNSW) setEf(ef int, writeToLog bool) error {
+	if writeToLog {
+		err := h.log.WriteEfSetting(ef)
+		if err != nil {
+			return err
+		}
+	}
+	h.record(wal.EfSetting{Ef: ef})
+
+	h.index.setEf(ef)
 	return nil
 }
 
+// normalize returns the vector normalized in a cosine space, unless the
+// native index normalizes it (see hnswState.NativeCosine). Otherwise, the
+// vector is returned as is.
+func (h *HNSW) normalize(vector []float32) []float32 {
+	if h.state.SpaceType == CosineSpace && !h.state.NativeCosine {
+		return normalizeVector(vector)
+	}
+	return vector
+}
+
 func normalizeVector(vector []float32) []float32 {
 	var norm float32
 	for _, v := range vector {
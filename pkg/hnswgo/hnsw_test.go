@@ -15,13 +15,18 @@
 package hnswgo_test
 
 import (
+	"context"
+	"fmt"
 	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"math"
+	"math/rand"
 	"os"
 	"path"
 	"testing"
+	"time"
 )
 
 var sampleVectors = [][]float32{
@@ -29,6 +34,8 @@ var sampleVectors = [][]float32{
 	{0.9, 0.8, 0.7, 0.6, 0.5},
 }
 
+var ctx = context.Background()
+
 func TestSpaceTypeFromString(t *testing.T) {
 	t.Parallel()
 
@@ -53,6 +60,20 @@ func TestSpaceTypeFromString(t *testing.T) {
 		assert.Equal(t, hnswgo.L2Space, val)
 	})
 
+	t.Run("L1Space", func(t *testing.T) {
+		t.Parallel()
+		val, err := hnswgo.SpaceTypeFromString("l1")
+		assert.NoError(t, err)
+		assert.Equal(t, hnswgo.L1Space, val)
+	})
+
+	t.Run("HammingSpace", func(t *testing.T) {
+		t.Parallel()
+		val, err := hnswgo.SpaceTypeFromString("hamming")
+		assert.NoError(t, err)
+		assert.Equal(t, hnswgo.HammingSpace, val)
+	})
+
 	t.Run("invalid", func(t *testing.T) {
 		t.Parallel()
 		_, err := hnswgo.SpaceTypeFromString("foo")
@@ -60,6 +81,67 @@ func TestSpaceTypeFromString(t *testing.T) {
 	})
 }
 
+func TestIndexTypeFromString(t *testing.T) {
+	t.Parallel()
+
+	t.Run("HNSWIndex", func(t *testing.T) {
+		t.Parallel()
+		val, err := hnswgo.IndexTypeFromString("hnsw")
+		assert.NoError(t, err)
+		assert.Equal(t, hnswgo.HNSWIndex, val)
+	})
+
+	t.Run("FlatIndex", func(t *testing.T) {
+		t.Parallel()
+		val, err := hnswgo.IndexTypeFromString("flat")
+		assert.NoError(t, err)
+		assert.Equal(t, hnswgo.FlatIndex, val)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		_, err := hnswgo.IndexTypeFromString("foo")
+		assert.Error(t, err)
+	})
+}
+
+func TestConfig_ValidateStorage(t *testing.T) {
+	t.Parallel()
+
+	valid := []hnswgo.Config{
+		{Dim: 4},
+		{Dim: 4, Storage: hnswgo.Float32Storage, IndexType: hnswgo.FlatIndex},
+		{Dim: 4, SpaceType: hnswgo.L1Space, Storage: hnswgo.Float32Storage},
+		{Dim: 4, Storage: hnswgo.Float16Storage},
+		{Dim: 4, Storage: hnswgo.BFloat16Storage},
+		{Dim: 4, Storage: hnswgo.SQ8Storage, TrainingSize: 10, Rerank: true},
+		{Dim: 4, Storage: hnswgo.PQStorage, PQSubquantizers: 2},
+		{Dim: 4, Storage: hnswgo.PQStorage, PQSubquantizers: 4, PQBits: 8, Rerank: true},
+	}
+	for _, config := range valid {
+		assert.NoError(t, config.ValidateStorage(), config)
+	}
+
+	invalid := []hnswgo.Config{
+		{Dim: 4, Storage: "foo"},
+		{Dim: 4, Rerank: true},
+		{Dim: 4, Storage: hnswgo.Float16Storage, Rerank: true},
+		{Dim: 4, Storage: hnswgo.BFloat16Storage, IndexType: hnswgo.FlatIndex},
+		{Dim: 4, SpaceType: hnswgo.HammingSpace, Storage: hnswgo.Float16Storage},
+		{Dim: 4, SpaceType: hnswgo.HammingSpace, Storage: hnswgo.PQStorage, PQSubquantizers: 2},
+		{Dim: 4, SpaceType: hnswgo.L1Space, Storage: hnswgo.BFloat16Storage},
+		{Dim: 4, SpaceType: hnswgo.L1Space, Storage: hnswgo.SQ8Storage},
+		{Dim: 4, Storage: hnswgo.SQ8Storage, IndexType: hnswgo.FlatIndex},
+		{Dim: 4, Storage: hnswgo.SQ8Storage, TrainingSize: -1},
+		{Dim: 4, Storage: hnswgo.PQStorage},
+		{Dim: 4, Storage: hnswgo.PQStorage, PQSubquantizers: 3},
+		{Dim: 4, Storage: hnswgo.PQStorage, PQSubquantizers: 2, PQBits: 9},
+	}
+	for _, config := range invalid {
+		assert.Error(t, config.ValidateStorage(), config)
+	}
+}
+
 func TestHNSW_IPSpace(t *testing.T) {
 	t.Parallel()
 	dir := createTempDir(t)
@@ -116,6 +198,140 @@ func TestHNSW_CosineSpace(t *testing.T) {
 	assert.Greater(t, results[1].Distance, results[0].Distance)
 }
 
+func TestHNSW_CosineNorms(t *testing.T) {
+	t.Parallel()
+
+	norm := func(v []float32) float64 {
+		var sum float64
+		for _, x := range v {
+			sum += float64(x) * float64(x)
+		}
+		return math.Sqrt(sum)
+	}
+	assertVector := func(t *testing.T, expected, actual []float32) {
+		t.Helper()
+		require.Len(t, actual, len(expected))
+		for i := range expected {
+			assert.InDelta(t, expected[i], actual[i], 1e-5)
+		}
+	}
+
+	// The dimensions select the scalar and each SIMD normalization path.
+	for _, dim := range []int{3, 5, 16, 21} {
+		dim := dim
+		t.Run(fmt.Sprintf("HNSW index %d", dim), func(t *testing.T) {
+			t.Parallel()
+			dir := createTempDir(t)
+			defer deleteDir(t, dir)
+			indexDir := path.Join(dir, "index")
+
+			r := rand.New(rand.NewSource(int64(dim)))
+			vectors := make([][]float32, 20)
+			ids := make([]uint32, len(vectors))
+			for i := range vectors {
+				vectors[i] = make([]float32, dim)
+				for j := range vectors[i] {
+					vectors[i][j] = (r.Float32()*2 - 1) * float32(i+1)
+				}
+				ids[i] = uint32(i + 1)
+			}
+
+			config := makeConfig(hnswgo.CosineSpace, false)
+			config.Dim = dim
+			config.MaxElements = 50
+			hnsw := hnswgo.New(indexDir, config, zerolog.Nop())
+			assert.True(t, hnsw.StoresNorms())
+			require.NoError(t, hnsw.Save(ctx))
+			require.NoError(t, hnsw.AddPoints(ctx, vectors[:10], ids[:10], 2))
+			require.NoError(t, hnsw.Save(ctx))
+			require.NoError(t, hnsw.AddPoints(ctx, vectors[10:], ids[10:], 2))
+
+			assert.Error(t, hnsw.AddPoint(make([]float32, dim), 100))
+
+			loaded, err := hnswgo.Load(indexDir, zerolog.Nop())
+			require.NoError(t, err)
+			assert.True(t, loaded.StoresNorms())
+
+			c, err := loaded.Compact(ctx, path.Join(dir, "new"), 2)
+			require.NoError(t, err)
+			compacted, err := c.Commit(path.Join(dir, "old"))
+			require.NoError(t, err)
+
+			for _, h := range []*hnswgo.HNSW{hnsw, loaded, compacted} {
+				for i, vector := range vectors {
+					normalized, found := h.GetVector(ids[i])
+					require.True(t, found)
+					assert.InDelta(t, 1.0, norm(normalized), 1e-5)
+
+					original, found := h.GetOriginalVector(ids[i])
+					require.True(t, found)
+					assertVector(t, vector, original)
+
+					results := h.SearchKNNExact(vector, 1)
+					require.Len(t, results, 1)
+					assert.Equal(t, ids[i], results[0].ID)
+					assert.InDelta(t, 0.0, results[0].Distance, 1e-5)
+				}
+			}
+		})
+	}
+
+	t.Run("flat index", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		config := makeConfig(hnswgo.CosineSpace, false)
+		config.IndexType = hnswgo.FlatIndex
+		hnsw := hnswgo.New(dir, config, zerolog.Nop())
+		assert.False(t, hnsw.StoresNorms())
+		require.NoError(t, hnsw.AddPoint(sampleVectors[0], 1))
+		assert.Error(t, hnsw.AddPoint(make([]float32, 5), 2))
+
+		normalized, found := hnsw.GetVector(1)
+		require.True(t, found)
+		original, found := hnsw.GetOriginalVector(1)
+		require.True(t, found)
+		assert.Equal(t, normalized, original)
+	})
+}
+
+func TestHNSW_LegacyCosineZeroVector(t *testing.T) {
+	t.Parallel()
+	dir := createTempDir(t)
+	defer deleteDir(t, dir)
+	indexDir := path.Join(dir, "index")
+	require.NoError(t, os.Mkdir(indexDir, 0755))
+
+	// The index was created before the native cosine space was introduced,
+	// and its log holds a zero vector with ID 3.
+	for _, name := range []string{"state", "index", "log"} {
+		data, err := os.ReadFile(path.Join("testdata", "legacy-cosine-index", name))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path.Join(indexDir, name), data, 0644))
+	}
+
+	loaded, err := hnswgo.Load(indexDir, zerolog.Nop())
+	require.NoError(t, err)
+	assert.False(t, loaded.StoresNorms())
+	assert.ElementsMatch(t, []uint32{1, 2, 3}, loaded.IDs())
+
+	// New zero vectors are still accepted, as they were by previous versions.
+	assert.NoError(t, loaded.ValidateVector(make([]float32, 5)))
+	require.NoError(t, loaded.AddPoint(make([]float32, 5), 4))
+
+	c, err := loaded.Compact(ctx, path.Join(dir, "new"), 2)
+	require.NoError(t, err)
+	compacted, err := c.Commit(path.Join(dir, "old"))
+	require.NoError(t, err)
+	assert.False(t, compacted.StoresNorms())
+	assert.ElementsMatch(t, []uint32{1, 2, 3, 4}, compacted.IDs())
+
+	results := compacted.SearchKNN(sampleVectors[1], 1)
+	require.Len(t, results, 1)
+	assert.Equal(t, uint32(2), results[0].ID)
+}
+
 func TestHNSW_AutoIDDisabled(t *testing.T) {
 	t.Parallel()
 	dir := createTempDir(t)
@@ -154,6 +370,191 @@ func TestHNSW_AutoIDEnabled(t *testing.T) {
 	assert.Equal(t, uint32(2), results[1].ID)
 }
 
+func TestHNSW_AddPoints(t *testing.T) {
+	t.Parallel()
+
+	t.Run("custom IDs", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		{
+			hnsw := hnswgo.New(dir, makeConfig(hnswgo.L2Space, false), zerolog.Nop())
+			require.NoError(t, hnsw.Save(ctx))
+
+			vectors := [][]float32{sampleVectors[1], sampleVectors[1], sampleVectors[0]}
+			assert.NoError(t, hnsw.AddPoints(ctx, vectors, []uint32{10, 20, 10}, 4))
+
+			results := hnsw.SearchKNN(sampleVectors[0], 3)
+			assert.Len(t, results, 2)
+			assert.Equal(t, uint32(10), results[0].ID)
+			assert.InDelta(t, 0.0, results[0].Distance, 1e-6)
+			assert.Equal(t, uint32(20), results[1].ID)
+
+			_, err := hnsw.AddPointsAutoID(ctx, vectors, 4)
+			assert.Error(t, err)
+		}
+
+		// The points are recovered from the log
+		hnsw, err := hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		results := hnsw.SearchKNN(sampleVectors[0], 3)
+		assert.Len(t, results, 2)
+		assert.Equal(t, uint32(10), results[0].ID)
+		assert.InDelta(t, 0.0, results[0].Distance, 1e-6)
+	})
+
+	t.Run("auto IDs", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := hnswgo.New(dir, makeConfig(hnswgo.L2Space, true), zerolog.Nop())
+		_, err := hnsw.AddPointAutoID(sampleVectors[0])
+		require.NoError(t, err)
+
+		ids, err := hnsw.AddPointsAutoID(ctx, [][]float32{sampleVectors[1], sampleVectors[0], sampleVectors[1]}, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint32{2, 3, 4}, ids)
+
+		results := hnsw.SearchKNN(sampleVectors[1], 4)
+		assert.Len(t, results, 4)
+
+		assert.Error(t, hnsw.AddPoints(ctx, sampleVectors, []uint32{1, 2}, 2))
+	})
+
+	t.Run("invalid vectors", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := hnswgo.New(dir, makeConfig(hnswgo.L2Space, false), zerolog.Nop())
+		assert.Error(t, hnsw.AddPoints(ctx, [][]float32{sampleVectors[0], {1, 2}}, []uint32{1, 2}, 2))
+		assert.Error(t, hnsw.AddPoints(ctx, sampleVectors, []uint32{1}, 2))
+		assert.Empty(t, hnsw.SearchKNN(sampleVectors[0], 2))
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		hnsw := hnswgo.New(dir, makeConfig(hnswgo.L2Space, true), zerolog.Nop())
+		_, err := hnsw.AddPointsAutoID(canceledCtx, sampleVectors, 2)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, hnsw.SearchKNN(sampleVectors[0], 2))
+	})
+}
+
+func TestHNSW_ImportPoints(t *testing.T) {
+	t.Parallel()
+
+	t.Run("custom IDs", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := hnswgo.New(dir, makeConfig(hnswgo.L2Space, false), zerolog.Nop())
+		require.NoError(t, hnsw.ImportPoints(ctx, sampleVectors, []uint32{10, 20}, 2))
+
+		// Nothing is written to the log
+		_, err := os.Stat(path.Join(dir, "log"))
+		assert.True(t, os.IsNotExist(err))
+
+		_, err = hnsw.ImportPointsAutoID(ctx, sampleVectors, 2)
+		assert.Error(t, err)
+
+		require.NoError(t, hnsw.Save(ctx))
+		hnsw, err = hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		results := hnsw.SearchKNN(sampleVectors[1], 2)
+		assert.Len(t, results, 2)
+		assert.Equal(t, uint32(20), results[0].ID)
+	})
+
+	t.Run("auto IDs", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := hnswgo.New(dir, makeConfig(hnswgo.L2Space, true), zerolog.Nop())
+		ids, err := hnsw.ImportPointsAutoID(ctx, sampleVectors, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []uint32{1, 2}, ids)
+		assert.Error(t, hnsw.ImportPoints(ctx, sampleVectors, []uint32{1, 2}, 2))
+
+		// The last generated ID is persisted
+		require.NoError(t, hnsw.Save(ctx))
+		hnsw, err = hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		id, err := hnsw.AddPointAutoID(sampleVectors[0])
+		require.NoError(t, err)
+		assert.Equal(t, uint32(3), id)
+	})
+}
+
+func TestHNSW_IDsAndGetVector(t *testing.T) {
+	t.Parallel()
+	dir := createTempDir(t)
+	defer deleteDir(t, dir)
+
+	hnsw := hnswgo.New(dir, makeConfig(hnswgo.L2Space, false), zerolog.Nop())
+	assert.Empty(t, hnsw.IDs())
+
+	require.NoError(t, hnsw.AddPoints(ctx, [][]float32{sampleVectors[0], sampleVectors[1], sampleVectors[0]}, []uint32{30, 10, 20}, 2))
+	require.NoError(t, hnsw.MarkDelete(20))
+	assert.Equal(t, []uint32{10, 30}, hnsw.IDs())
+
+	vector, ok := hnsw.GetVector(10)
+	assert.True(t, ok)
+	assert.Equal(t, sampleVectors[1], vector)
+
+	_, ok = hnsw.GetVector(20)
+	assert.False(t, ok)
+	_, ok = hnsw.GetVector(40)
+	assert.False(t, ok)
+}
+
+func TestHNSW_ValidateVector(t *testing.T) {
+	t.Parallel()
+	dir := createTempDir(t)
+	defer deleteDir(t, dir)
+
+	hnsw := hnswgo.New(dir, makeConfig(hnswgo.L2Space, false), zerolog.Nop())
+	assert.NoError(t, hnsw.ValidateVector(sampleVectors[0]))
+	assert.Error(t, hnsw.ValidateVector([]float32{1, 2, 3}))
+	assert.Error(t, hnsw.ValidateVector(nil))
+
+	assert.Error(t, hnsw.AddPoint([]float32{1, 2, 3}, 1))
+}
+
+func TestHNSW_MaxElements(t *testing.T) {
+	t.Parallel()
+
+	for _, indexType := range []hnswgo.IndexType{hnswgo.HNSWIndex, hnswgo.FlatIndex} {
+		indexType := indexType
+		t.Run(string(indexType), func(t *testing.T) {
+			t.Parallel()
+			dir := createTempDir(t)
+			defer deleteDir(t, dir)
+
+			config := makeConfig(hnswgo.L2Space, false)
+			config.MaxElements = 1
+			config.IndexType = indexType
+			hnsw := hnswgo.New(dir, config, zerolog.Nop())
+
+			require.NoError(t, hnsw.AddPoint(sampleVectors[0], 1))
+			assert.Error(t, hnsw.AddPoint(sampleVectors[1], 2))
+			assert.Error(t, hnsw.AddPoints(ctx, sampleVectors, []uint32{1, 2}, 2))
+			assert.NoError(t, hnsw.AddPoints(ctx, sampleVectors, []uint32{1, 1}, 2))
+			assert.Equal(t, []uint32{1}, hnsw.IDs())
+		})
+	}
+}
+
 func TestHNSW_MarkDelete(t *testing.T) {
 	t.Parallel()
 	dir := createTempDir(t)
@@ -174,6 +575,215 @@ func TestHNSW_MarkDelete(t *testing.T) {
 	assert.Equal(t, uint32(1), results[0].ID)
 }
 
+func TestHNSW_SearchKNNExact(t *testing.T) {
+	t.Parallel()
+	dir := createTempDir(t)
+	defer deleteDir(t, dir)
+
+	hnsw := hnswgo.New(dir, makeConfig(hnswgo.L2Space, false), zerolog.Nop())
+	vectors := [][]float32{
+		{0, 0, 0, 0, 3},
+		{0, 0, 0, 0, 1},
+		{0, 0, 0, 0, 0},
+		{0, 0, 0, 0, 2},
+	}
+	require.NoError(t, hnsw.AddPoints(ctx, vectors, []uint32{30, 10, 0, 20}, 2))
+	require.NoError(t, hnsw.MarkDelete(10))
+
+	results := hnsw.SearchKNNExact([]float32{0, 0, 0, 0, 0}, 10)
+	assert.Equal(t, []hnswgo.KNNResult{
+		{ID: 0, Distance: 0},
+		{ID: 20, Distance: 4},
+		{ID: 30, Distance: 9},
+	}, results)
+
+	results = hnsw.SearchKNNExact([]float32{0, 0, 0, 0, 3}, 2)
+	assert.Equal(t, []hnswgo.KNNResult{
+		{ID: 30, Distance: 0},
+		{ID: 20, Distance: 1},
+	}, results)
+}
+
+func TestHNSW_UnmarkDelete(t *testing.T) {
+	t.Parallel()
+
+	t.Run("restoring deleted elements", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := hnswgo.New(dir, makeConfig(hnswgo.L2Space, false), zerolog.Nop())
+		require.NoError(t, hnsw.Save(ctx))
+		for i, vector := range sampleVectors {
+			require.NoError(t, hnsw.AddPoint(vector, uint32(i)))
+		}
+		require.NoError(t, hnsw.MarkDelete(0))
+		assert.Equal(t, []uint32{1}, hnsw.IDs())
+
+		found, err := hnsw.UnmarkDelete(0)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []uint32{0, 1}, hnsw.IDs())
+		results := hnsw.SearchKNN(sampleVectors[0], 1)
+		assert.Equal(t, uint32(0), results[0].ID)
+
+		// Elements which are not deleted are left unchanged
+		found, err = hnsw.UnmarkDelete(1)
+		require.NoError(t, err)
+		assert.True(t, found)
+
+		found, err = hnsw.UnmarkDelete(42)
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		// The operation is recovered from the log
+		require.NoError(t, hnsw.MarkDelete(1))
+		require.NoError(t, hnsw.MarkDelete(0))
+		_, err = hnsw.UnmarkDelete(1)
+		require.NoError(t, err)
+
+		loaded, err := hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		assert.Equal(t, []uint32{1}, loaded.IDs())
+	})
+
+	t.Run("replaced elements cannot be restored", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		config := makeConfig(hnswgo.L2Space, false)
+		config.AllowReplaceDeleted = true
+		hnsw := hnswgo.New(dir, config, zerolog.Nop())
+		require.NoError(t, hnsw.AddPoint(sampleVectors[0], 1))
+		require.NoError(t, hnsw.MarkDelete(1))
+		require.NoError(t, hnsw.AddPoint(sampleVectors[1], 2))
+
+		found, err := hnsw.UnmarkDelete(1)
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.Equal(t, []uint32{2}, hnsw.IDs())
+	})
+
+	t.Run("restored elements are no longer replaced", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		config := makeConfig(hnswgo.L2Space, false)
+		config.MaxElements = 2
+		config.AllowReplaceDeleted = true
+		hnsw := hnswgo.New(dir, config, zerolog.Nop())
+		require.NoError(t, hnsw.AddPoint(sampleVectors[0], 1))
+		require.NoError(t, hnsw.MarkDelete(1))
+		found, err := hnsw.UnmarkDelete(1)
+		require.NoError(t, err)
+		assert.True(t, found)
+
+		require.NoError(t, hnsw.AddPoint(sampleVectors[1], 2))
+		assert.Equal(t, []uint32{1, 2}, hnsw.IDs())
+	})
+}
+
+func TestHNSW_AllowReplaceDeleted(t *testing.T) {
+	t.Parallel()
+
+	newIndex := func(dir string) *hnswgo.HNSW {
+		config := makeConfig(hnswgo.L2Space, false)
+		config.MaxElements = 2
+		config.AllowReplaceDeleted = true
+		return hnswgo.New(dir, config, zerolog.Nop())
+	}
+
+	t.Run("deleted elements are replaced", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := newIndex(dir)
+		require.NoError(t, hnsw.AddPoint(sampleVectors[0], 1))
+		require.NoError(t, hnsw.AddPoint(sampleVectors[1], 2))
+		require.NoError(t, hnsw.MarkDelete(1))
+
+		// The index is full, but element 1 can be replaced
+		require.NoError(t, hnsw.AddPoint(sampleVectors[0], 3))
+		assert.Equal(t, []uint32{2, 3}, hnsw.IDs())
+		_, found := hnsw.GetVector(1)
+		assert.False(t, found)
+		vector, found := hnsw.GetVector(3)
+		require.True(t, found)
+		assert.Equal(t, sampleVectors[0], vector)
+
+		results := hnsw.SearchKNN(sampleVectors[0], 2)
+		require.Len(t, results, 2)
+		assert.Equal(t, uint32(3), results[0].ID)
+
+		require.NoError(t, hnsw.MarkDelete(2))
+		require.NoError(t, hnsw.MarkDelete(3))
+		require.NoError(t, hnsw.AddPoints(ctx, [][]float32{sampleVectors[0], sampleVectors[1], sampleVectors[1]}, []uint32{4, 5, 4}, 2))
+		assert.Equal(t, []uint32{4, 5}, hnsw.IDs())
+		vector, found = hnsw.GetVector(4)
+		require.True(t, found)
+		assert.Equal(t, sampleVectors[1], vector)
+	})
+
+	t.Run("the log replay replaces the same elements", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := newIndex(dir)
+		require.NoError(t, hnsw.Save(ctx))
+		require.NoError(t, hnsw.AddPoints(ctx, [][]float32{sampleVectors[0], sampleVectors[1]}, []uint32{1, 2}, 2))
+		require.NoError(t, hnsw.MarkDelete(2))
+		require.NoError(t, hnsw.MarkDelete(1))
+		require.NoError(t, hnsw.AddPoint(sampleVectors[1], 3))
+		require.NoError(t, hnsw.AddPoint(sampleVectors[0], 4))
+		originalResults := hnsw.SearchKNN(sampleVectors[0], 2)
+
+		loaded, err := hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		assert.Equal(t, []uint32{3, 4}, loaded.IDs())
+		assert.Equal(t, originalResults, loaded.SearchKNN(sampleVectors[0], 2))
+
+		require.NoError(t, loaded.MarkDelete(4))
+		require.NoError(t, loaded.AddPoint(sampleVectors[1], 5))
+		assert.Equal(t, []uint32{3, 5}, loaded.IDs())
+	})
+
+	t.Run("the deleted elements of a saved index are replaced", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := newIndex(dir)
+		require.NoError(t, hnsw.AddPoint(sampleVectors[0], 1))
+		require.NoError(t, hnsw.AddPoint(sampleVectors[1], 2))
+		require.NoError(t, hnsw.MarkDelete(1))
+		require.NoError(t, hnsw.Save(ctx))
+
+		loaded, err := hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		require.NoError(t, loaded.AddPoint(sampleVectors[0], 3))
+		assert.Equal(t, []uint32{2, 3}, loaded.IDs())
+	})
+
+	t.Run("re-adding a deleted ID reuses its element", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := newIndex(dir)
+		require.NoError(t, hnsw.AddPoint(sampleVectors[0], 1))
+		require.NoError(t, hnsw.AddPoint(sampleVectors[1], 2))
+		require.NoError(t, hnsw.MarkDelete(1))
+		require.NoError(t, hnsw.MarkDelete(2))
+		require.NoError(t, hnsw.AddPoint(sampleVectors[1], 1))
+		require.NoError(t, hnsw.AddPoint(sampleVectors[0], 3))
+		assert.Equal(t, []uint32{1, 3}, hnsw.IDs())
+	})
+}
+
 func TestHNSW_SaveAndLoad(t *testing.T) {
 	t.Parallel()
 
@@ -193,7 +803,7 @@ func TestHNSW_SaveAndLoad(t *testing.T) {
 			originalResults = hnsw.SearchKNN(sampleVectors[0], 2)
 			require.Len(t, originalResults, 2)
 
-			assert.NoError(t, hnsw.Save())
+			assert.NoError(t, hnsw.Save(ctx))
 		}
 
 		hnsw, err := hnswgo.Load(dir, zerolog.Nop())
@@ -203,6 +813,21 @@ func TestHNSW_SaveAndLoad(t *testing.T) {
 		assert.Equal(t, originalResults, newResults)
 	})
 
+	t.Run("ef is saved", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := hnswgo.New(dir, makeConfig(hnswgo.L2Space, false), zerolog.Nop())
+		require.NoError(t, hnsw.Save(ctx))
+		require.NoError(t, hnsw.SetEf(42))
+		require.NoError(t, hnsw.Save(ctx))
+
+		loaded, err := hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		assert.Equal(t, 42, loaded.Ef())
+	})
+
 	t.Run("load auto-id index from log without explicit save", func(t *testing.T) {
 		t.Parallel()
 		dir := createTempDir(t)
@@ -211,7 +836,7 @@ func TestHNSW_SaveAndLoad(t *testing.T) {
 		{
 			hnsw := hnswgo.New(dir, makeConfig(hnswgo.CosineSpace, true), zerolog.Nop())
 			// Initial save, just for creating the files
-			require.NoError(t, hnsw.Save())
+			require.NoError(t, hnsw.Save(ctx))
 
 			_, err := hnsw.AddPointAutoID(sampleVectors[0])
 			require.NoError(t, err)
@@ -239,7 +864,7 @@ func TestHNSW_SaveAndLoad(t *testing.T) {
 		{
 			hnsw := hnswgo.New(dir, makeConfig(hnswgo.CosineSpace, false), zerolog.Nop())
 			// Initial save, just for creating the files
-			require.NoError(t, hnsw.Save())
+			require.NoError(t, hnsw.Save(ctx))
 
 			require.NoError(t, hnsw.AddPoint(sampleVectors[0], 1))
 			require.NoError(t, hnsw.AddPoint(sampleVectors[1], 2))
@@ -263,7 +888,7 @@ func TestHNSW_SaveAndLoad(t *testing.T) {
 		{
 			hnsw := hnswgo.New(dir, makeConfig(hnswgo.CosineSpace, true), zerolog.Nop())
 			// Initial save, just for creating the files
-			require.NoError(t, hnsw.Save())
+			require.NoError(t, hnsw.Save(ctx))
 
 			_, err := hnsw.AddPointAutoID(sampleVectors[0])
 			require.NoError(t, err)
@@ -293,7 +918,23 @@ func TestHNSW_Save(t *testing.T) {
 		defer deleteDir(t, dir)
 
 		hnsw := hnswgo.New(path.Join(dir, "foo", "bar"), makeConfig(hnswgo.CosineSpace, true), zerolog.Nop())
-		assert.Error(t, hnsw.Save())
+		assert.Error(t, hnsw.Save(ctx))
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		hnsw := hnswgo.New(dir, makeConfig(hnswgo.CosineSpace, true), zerolog.Nop())
+		assert.ErrorIs(t, hnsw.Save(canceledCtx), context.Canceled)
+		assert.NoFileExists(t, path.Join(dir, "state"))
+		assert.NoFileExists(t, path.Join(dir, "index"))
+		assert.NoFileExists(t, path.Join(dir, "state.tmp"))
+		assert.NoFileExists(t, path.Join(dir, "index.tmp"))
 	})
 }
 
@@ -363,6 +1004,137 @@ func TestHNSW_Loading(t *testing.T) {
 	})
 }
 
+func TestHNSW_LoadWithOptions(t *testing.T) {
+	t.Parallel()
+
+	// newSavedIndex saves an index of random vectors, with room for more,
+	// whose first 10 elements are deleted.
+	newSavedIndex := func(t *testing.T, dir string) (*hnswgo.HNSW, [][]float32) {
+		t.Helper()
+		config := makeConfig(hnswgo.L2Space, false)
+		config.Dim = 16
+		config.MaxElements = 400
+		hnsw := hnswgo.New(dir, config, zerolog.Nop())
+
+		r := rand.New(rand.NewSource(1))
+		vectors := make([][]float32, 300)
+		ids := make([]uint32, len(vectors))
+		for i := range vectors {
+			vectors[i] = make([]float32, config.Dim)
+			for j := range vectors[i] {
+				vectors[i][j] = r.Float32()
+			}
+			ids[i] = uint32(i)
+		}
+		require.NoError(t, hnsw.AddPoints(ctx, vectors, ids, 1))
+		for id := uint32(0); id < 10; id++ {
+			require.NoError(t, hnsw.MarkDelete(id))
+		}
+		require.NoError(t, hnsw.Save(ctx))
+		return hnsw, vectors
+	}
+
+	t.Run("memory-mapped index", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		saved, vectors := newSavedIndex(t, dir)
+
+		loaded, err := hnswgo.LoadWithOptions(dir, hnswgo.LoadOptions{Mmap: true}, zerolog.Nop())
+		require.NoError(t, err)
+		assert.True(t, loaded.Mapped())
+		assert.False(t, loaded.ReadOnly())
+		assert.ElementsMatch(t, saved.IDs(), loaded.IDs())
+		_, found := loaded.GetVector(0)
+		assert.False(t, found)
+		vector, found := loaded.GetVector(299)
+		assert.True(t, found)
+		assert.Equal(t, vectors[299], vector)
+		assert.Equal(t, saved.SearchKNN(vectors[42], 10), loaded.SearchKNN(vectors[42], 10))
+
+		// The changes are saved to a new file.
+		require.NoError(t, loaded.AddPoint(vectors[0], 300))
+		require.NoError(t, loaded.MarkDelete(20))
+		require.NoError(t, loaded.Save(ctx))
+
+		reloaded, err := hnswgo.LoadWithOptions(dir, hnswgo.LoadOptions{Mmap: true}, zerolog.Nop())
+		require.NoError(t, err)
+		assert.True(t, reloaded.Mapped())
+		vector, found = reloaded.GetVector(300)
+		assert.True(t, found)
+		assert.Equal(t, vectors[0], vector)
+		_, found = reloaded.GetVector(20)
+		assert.False(t, found)
+		assert.Equal(t, loaded.SearchKNN(vectors[42], 10), reloaded.SearchKNN(vectors[42], 10))
+	})
+
+	t.Run("read-only index", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		saved, vectors := newSavedIndex(t, dir)
+
+		loaded, err := hnswgo.LoadWithOptions(dir, hnswgo.LoadOptions{ReadOnly: true}, zerolog.Nop())
+		require.NoError(t, err)
+		assert.True(t, loaded.Mapped())
+		assert.True(t, loaded.ReadOnly())
+		assert.Equal(t, saved.SearchKNN(vectors[42], 10), loaded.SearchKNN(vectors[42], 10))
+
+		assert.ErrorIs(t, loaded.AddPoint(vectors[0], 300), hnswgo.ErrReadOnly)
+		assert.ErrorIs(t, loaded.AddPoints(ctx, vectors[:1], []uint32{300}, 1), hnswgo.ErrReadOnly)
+		assert.ErrorIs(t, loaded.ImportPoints(ctx, vectors[:1], []uint32{300}, 1), hnswgo.ErrReadOnly)
+		assert.ErrorIs(t, loaded.MarkDelete(20), hnswgo.ErrReadOnly)
+		_, err = loaded.UnmarkDelete(0)
+		assert.ErrorIs(t, err, hnswgo.ErrReadOnly)
+		_, err = loaded.DeleteExpired(time.Now())
+		assert.ErrorIs(t, err, hnswgo.ErrReadOnly)
+		_, err = loaded.Compact(ctx, path.Join(dir, "compacted"), 1)
+		assert.ErrorIs(t, err, hnswgo.ErrReadOnly)
+		assert.ErrorIs(t, loaded.Save(ctx), hnswgo.ErrReadOnly)
+
+		// The "ef" parameter can be set, but it is not persisted.
+		require.NoError(t, loaded.SetEf(42))
+		assert.Equal(t, 42, loaded.Ef())
+
+		reloaded, err := hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		assert.False(t, reloaded.Mapped())
+		assert.Equal(t, saved.Ef(), reloaded.Ef())
+		assert.ElementsMatch(t, saved.IDs(), reloaded.IDs())
+	})
+
+	t.Run("index saved in the previous format", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		for _, name := range []string{"state", "index"} {
+			data, err := os.ReadFile(path.Join("testdata", "legacy-index", name))
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(path.Join(dir, name), data, 0644))
+		}
+
+		assertIndex := func(t *testing.T, hnsw *hnswgo.HNSW) {
+			t.Helper()
+			assert.ElementsMatch(t, []uint32{0, 1}, hnsw.IDs())
+			vector, found := hnsw.GetVector(1)
+			assert.True(t, found)
+			assert.Equal(t, sampleVectors[1], vector)
+		}
+
+		// The file is read into memory, and converted on saving.
+		loaded, err := hnswgo.LoadWithOptions(dir, hnswgo.LoadOptions{Mmap: true}, zerolog.Nop())
+		require.NoError(t, err)
+		assert.False(t, loaded.Mapped())
+		assertIndex(t, loaded)
+		require.NoError(t, loaded.Save(ctx))
+
+		reloaded, err := hnswgo.LoadWithOptions(dir, hnswgo.LoadOptions{Mmap: true}, zerolog.Nop())
+		require.NoError(t, err)
+		assert.True(t, reloaded.Mapped())
+		assertIndex(t, reloaded)
+	})
+}
+
 func createAndSaveSampleIndex(t *testing.T, dir string) {
 	t.Helper()
 	hnsw := hnswgo.New(dir, makeConfig(hnswgo.CosineSpace, true), zerolog.Nop())
@@ -371,7 +1143,7 @@ func createAndSaveSampleIndex(t *testing.T, dir string) {
 		require.NoError(t, err)
 		require.Equal(t, i+1, int(id))
 	}
-	require.NoError(t, hnsw.Save())
+	require.NoError(t, hnsw.Save(ctx))
 
 	require.FileExists(t, path.Join(dir, "state"))
 	require.FileExists(t, path.Join(dir, "index"))
@@ -4,44 +4,157 @@
 #include <thread>
 #include <atomic>
 
-HNSW initHNSW(int dim, unsigned long int max_elements, int M, int ef_construction, int rand_seed, char stype) {
+// Index owns the space of the native index, which is not released by it.
+// Cosine is the same space, if it is a cosine space, or NULL otherwise.
+struct Index {
   hnswlib::SpaceInterface<float> *space;
-  if (stype == 'i') {
-    space = new hnswlib::InnerProductSpace(dim);
-  } else {
-    space = new hnswlib::L2Space(dim);
+  hnswlib::CosineSpace *cosine;
+  hnswlib::HierarchicalNSW<float> *alg;
+};
+
+static hnswlib::HierarchicalNSW<float> *algorithm(HNSW index) {
+  return ((Index*)index)->alg;
+}
+
+// prepare returns the given vector in the format expected by the native
+// index. In a cosine space, it is normalized in a thread-local buffer, which
+// is valid until the next call on the same thread.
+static void *prepare(HNSW index, void *vec) {
+  hnswlib::CosineSpace *cosine = ((Index*)index)->cosine;
+  if (cosine == NULL) {
+    return vec;
   }
-  hnswlib::HierarchicalNSW<float> *appr_alg = new hnswlib::HierarchicalNSW<float>(space, max_elements, M, ef_construction, rand_seed);
-  return (void*)appr_alg;
+  thread_local std::vector<float> buffer;
+  return cosine->normalize(vec, buffer);
 }
 
-HNSW loadHNSW(char *location, int dim, unsigned long int max_elements, char stype) {
-  hnswlib::SpaceInterface<float> *space;
+// floatSpace returns the space of the given type, storing float vectors.
+static hnswlib::SpaceInterface<float> *floatSpace(int dim, char stype) {
   if (stype == 'i') {
-    space = new hnswlib::InnerProductSpace(dim);
-  } else {
-    space = new hnswlib::L2Space(dim);
+    return new hnswlib::InnerProductSpace(dim);
+  }
+  if (stype == 'm') {
+    return new hnswlib::L1Space(dim);
+  }
+  if (stype == 'c') {
+    return new hnswlib::CosineSpace(dim);
+  }
+  return new hnswlib::L2Space(dim);
+}
+
+SPACE newSQ8Space(int dim, float *min, float *scale, char stype) {
+  return (void*)new hnswlib::SQ8Space(dim, min, scale, stype == 'i');
+}
+
+SPACE newPQSpace(int dim, int m, int bits, float *centroids, char stype) {
+  return (void*)new hnswlib::PQSpace(dim, m, bits, centroids, stype == 'i');
+}
+
+SPACE newHalfSpace(int dim, int bfloat16, char stype) {
+  return (void*)new hnswlib::HalfSpace(dim, bfloat16 != 0, stype == 'i');
+}
+
+SPACE newHammingSpace(int dim) {
+  return (void*)new hnswlib::HammingSpace(dim);
+}
+
+HNSW initHNSW(int dim, unsigned long int max_elements, int M, int ef_construction, int rand_seed, char stype, int allow_replace_deleted, SPACE space) {
+  Index *index = new Index;
+  index->space = space != NULL ? (hnswlib::SpaceInterface<float>*)space : floatSpace(dim, stype);
+  index->cosine = space == NULL && stype == 'c' ? (hnswlib::CosineSpace*)index->space : NULL;
+  index->alg = new hnswlib::HierarchicalNSW<float>(index->space, max_elements, M, ef_construction, rand_seed, allow_replace_deleted);
+  return (void*)index;
+}
+
+// loadHNSW reads an index saved by saveHNSW, or in the format of upstream
+// hnswlib. If map is not zero, the level 0 data of the former is
+// memory-mapped instead of being read into memory.
+//
+// If the file cannot be read, it returns NULL, and sets error to a copy of
+// the message, which must be freed by the caller.
+HNSW loadHNSW(char *location, int dim, unsigned long int max_elements, char stype, int allow_replace_deleted, int map, SPACE space, char **error) {
+  Index *index = new Index;
+  index->space = space != NULL ? (hnswlib::SpaceInterface<float>*)space : floatSpace(dim, stype);
+  index->cosine = space == NULL && stype == 'c' ? (hnswlib::CosineSpace*)index->space : NULL;
+  std::string filename(location);
+  try {
+    if (hnswlib::HierarchicalNSW<float>::isMappedIndex(filename)) {
+      index->alg = new hnswlib::HierarchicalNSW<float>(index->space);
+      index->alg->allow_replace_deleted_ = allow_replace_deleted;
+      index->alg->loadMappedIndex(filename, index->space, max_elements, map != 0);
+    } else {
+      index->alg = new hnswlib::HierarchicalNSW<float>(index->space, filename, false, max_elements, allow_replace_deleted);
+    }
+  } catch (const std::exception &e) {
+    // The partially loaded algorithm is leaked: its destructor would
+    // release fields which may not be initialized.
+    *error = strdup(e.what());
+    delete index->space;
+    delete index;
+    return NULL;
   }
-  hnswlib::HierarchicalNSW<float> *appr_alg = new hnswlib::HierarchicalNSW<float>(space, std::string(location), false, max_elements);
-  return (void*)appr_alg;
+  return (void*)index;
 }
 
+// saveHNSW saves the index in the format whose level 0 data can be
+// memory-mapped by loadHNSW.
 void saveHNSW(HNSW index, char *location) {
-  ((hnswlib::HierarchicalNSW<float>*)index)->saveIndex(location);
+  algorithm(index)->saveMappedIndex(location);
 }
 
-void addPoint(HNSW index, float *vec, unsigned long int label) {
-  ((hnswlib::HierarchicalNSW<float>*)index)->addPoint(vec, label);
+int isMappedHNSW(HNSW index) {
+  return algorithm(index)->mapped_size_ > 0;
+}
+
+void freeHNSW(HNSW index) {
+  delete algorithm(index);
+  delete ((Index*)index)->space;
+  delete (Index*)index;
+}
+
+int addPoint(HNSW index, void *vec, unsigned long int label) {
+  try {
+    algorithm(index)->addPoint(prepare(index, vec), label);
+  } catch (const std::exception& e) {
+    return 0;
+  }
+  return 1;
+}
+
+long long reserveDeletedElement(HNSW index, unsigned long int label) {
+  return algorithm(index)->reserveDeletedElement(label);
+}
+
+void replaceDeletedElement(HNSW index, void *vec, unsigned long int label, unsigned int internal_id) {
+  algorithm(index)->replaceDeletedElement(prepare(index, vec), label, internal_id);
+}
+
+int markDelete(HNSW index, unsigned long int label) {
+  try {
+    algorithm(index)->markDelete(label);
+  } catch (const std::exception& e) {
+    return 0;
+  }
+  return 1;
+}
+
+int unmarkDelete(HNSW index, unsigned long int label) {
+  try {
+    algorithm(index)->unmarkDelete(label);
+  } catch (const std::exception& e) {
+    return 0;
+  }
+  return 1;
 }
 
-void markDelete(HNSW index, unsigned long int label) {
-  ((hnswlib::HierarchicalNSW<float>*)index)->markDelete(label);
+int searchKnn(HNSW index, void *vec, int N, unsigned long int *label, float *dist) {
+  return searchKnnWithEf(index, vec, N, algorithm(index)->ef_, label, dist);
 }
 
-int searchKnn(HNSW index, float *vec, int N, unsigned long int *label, float *dist) {
+int searchKnnWithEf(HNSW index, void *vec, int N, int ef, unsigned long int *label, float *dist) {
   std::priority_queue<std::pair<float, hnswlib::labeltype>> gt;
   try {
-    gt = ((hnswlib::HierarchicalNSW<float>*)index)->searchKnn(vec, N);
+    gt = algorithm(index)->searchKnn(prepare(index, vec), N, ef);
   } catch (const std::exception& e) { 
     return 0;
   }
@@ -57,6 +170,168 @@ int searchKnn(HNSW index, float *vec, int N, unsigned long int *label, float *di
   return n;
 }
 
+// searchKnnExact compares the query with all the elements which are not marked
+// deleted, like hnswlib::BruteforceSearch, but reading the data stored in the
+// HNSW index instead of keeping a copy of it.
+int searchKnnExact(HNSW index, void *vec, int N, unsigned long int *label, float *dist) {
+  hnswlib::HierarchicalNSW<float> *alg = algorithm(index);
+  if (N <= 0) {
+    return 0;
+  }
+  vec = prepare(index, vec);
+  std::vector<std::pair<hnswlib::labeltype, hnswlib::tableint>> elements;
+  {
+    std::unique_lock <std::mutex> lock(alg->cur_element_count_guard_);
+    elements.assign(alg->label_lookup_.begin(), alg->label_lookup_.end());
+  }
+
+  std::priority_queue<std::pair<float, hnswlib::labeltype>> gt;
+  for (auto const& item : elements) {
+    // Elements being added hold their lock until their data is initialized,
+    // while their label is written only then: a mismatching label means that
+    // the element is not added yet.
+    std::unique_lock <std::mutex> lock(alg->link_list_locks_[item.second]);
+    if (alg->isMarkedDeleted(item.second) || alg->getExternalLabel(item.second) != item.first) {
+      continue;
+    }
+    float d = alg->fstdistfunc_(vec, alg->getDataByInternalId(item.second), alg->dist_func_param_);
+    if ((int)gt.size() < N) {
+      gt.push(std::pair<float, hnswlib::labeltype>(d, item.first));
+    } else if (d < gt.top().first) {
+      gt.pop();
+      gt.push(std::pair<float, hnswlib::labeltype>(d, item.first));
+    }
+  }
+
+  int n = gt.size();
+  std::pair<float, hnswlib::labeltype> pair;
+  for (int i = n - 1; i >= 0; i--) {
+    pair = gt.top();
+    *(dist+i) = pair.first;
+    *(label+i) = pair.second;
+    gt.pop();
+  }
+  return n;
+}
+
 void setEf(HNSW index, int ef) {
-    ((hnswlib::HierarchicalNSW<float>*)index)->ef_ = ef;
+    algorithm(index)->ef_ = ef;
+}
+
+int getEf(HNSW index) {
+    return algorithm(index)->ef_;
+}
+
+unsigned long int getCurrentCount(HNSW index) {
+  hnswlib::HierarchicalNSW<float> *alg = algorithm(index);
+  std::unique_lock <std::mutex> lock(alg->cur_element_count_guard_);
+  return alg->cur_element_count;
+}
+
+// getMemoryUsage estimates the memory taken by the elements of the index:
+// their level 0 data, including the vectors, and their upper layers.
+unsigned long int getMemoryUsage(HNSW index) {
+  hnswlib::HierarchicalNSW<float> *alg = algorithm(index);
+  std::unique_lock <std::mutex> lock(alg->cur_element_count_guard_);
+  unsigned long int size = alg->cur_element_count * alg->size_data_per_element_;
+  for (size_t i = 0; i < alg->cur_element_count; i++) {
+    size += alg->element_levels_[i] * alg->size_links_per_element_;
+  }
+  return size;
+}
+
+// checkHNSW verifies the links of the graph and the labels of its elements,
+// counting the inconsistencies in check. Unlike checkIntegrity, it never
+// reads outside the link lists, nor aborts.
+void checkHNSW(HNSW index, GraphCheck *check) {
+  hnswlib::HierarchicalNSW<float> *alg = algorithm(index);
+  std::unique_lock <std::mutex> lock(alg->cur_element_count_guard_);
+  memset(check, 0, sizeof(GraphCheck));
+  size_t count = alg->cur_element_count;
+  check->elements = count;
+
+  std::vector<unsigned long int> inbound(count, 0);
+  for (size_t i = 0; i < count; i++) {
+    if (alg->isMarkedDeleted(i)) {
+      check->deleted++;
+    }
+    int levels = alg->element_levels_[i];
+    if (levels < 0 || levels > alg->maxlevel_) {
+      check->invalid_levels++;
+      continue;
+    }
+    for (int l = 0; l <= levels; l++) {
+      hnswlib::linklistsizeint *ll = alg->get_linklist_at_level(i, l);
+      size_t size = alg->getListCount(ll);
+      size_t max_size = l == 0 ? alg->maxM0_ : alg->maxM_;
+      if (size > max_size) {
+        check->oversized_lists++;
+        size = max_size;
+      }
+      hnswlib::tableint *links = (hnswlib::tableint *) (ll + 1);
+      std::unordered_set<hnswlib::tableint> seen;
+      for (size_t j = 0; j < size; j++) {
+        check->connections++;
+        if (links[j] >= count) {
+          check->invalid_links++;
+        } else if (links[j] == i) {
+          check->self_links++;
+        } else if (!seen.insert(links[j]).second) {
+          check->duplicate_links++;
+        } else {
+          inbound[links[j]]++;
+        }
+      }
+    }
+  }
+
+  if (count > 0) {
+    size_t entry_point = alg->enterpoint_node_;
+    if (entry_point >= count || alg->element_levels_[entry_point] != alg->maxlevel_) {
+      check->invalid_entry_point = 1;
+    }
+    for (size_t i = 0; count > 1 && i < count; i++) {
+      if (inbound[i] == 0 && i != entry_point) {
+        check->unreachable++;
+      }
+    }
+  }
+
+  for (size_t i = 0; i < count; i++) {
+    auto search = alg->label_lookup_.find(alg->getExternalLabel(i));
+    if (search == alg->label_lookup_.end() || search->second != i) {
+      check->inconsistent_labels++;
+    }
+  }
+  for (auto const& item : alg->label_lookup_) {
+    if (item.second >= count || alg->getExternalLabel(item.second) != item.first) {
+      check->inconsistent_labels++;
+    }
+  }
+}
+
+unsigned long int getLabels(HNSW index, unsigned long int *labels, unsigned long int size) {
+  hnswlib::HierarchicalNSW<float> *alg = algorithm(index);
+  std::unique_lock <std::mutex> lock(alg->cur_element_count_guard_);
+  unsigned long int n = 0;
+  for (auto const& item : alg->label_lookup_) {
+    if (n >= size) {
+      break;
+    }
+    if (!alg->isMarkedDeleted(item.second)) {
+      labels[n++] = item.first;
+    }
+  }
+  return n;
+}
+
+int getDataByLabel(HNSW index, unsigned long int label, void *vec, int include_deleted) {
+  hnswlib::HierarchicalNSW<float> *alg = algorithm(index);
+  std::unique_lock <std::mutex> lock(alg->cur_element_count_guard_);
+  auto search = alg->label_lookup_.find(label);
+  if (search == alg->label_lookup_.end() || (!include_deleted && alg->isMarkedDeleted(search->second))) {
+    return 0;
+  }
+  memcpy(vec, alg->getDataByInternalId(search->second), alg->data_size_);
+  return 1;
 }
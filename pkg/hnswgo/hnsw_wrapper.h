@@ -2,13 +2,44 @@
 extern "C" {
 #endif
   typedef void* HNSW;
-  HNSW initHNSW(int dim, unsigned long int max_elements, int M, int ef_construction, int rand_seed, char stype);
-  HNSW loadHNSW(char *location, int dim, unsigned long int max_elements, char stype);
+  typedef void* SPACE;
+  typedef struct {
+    unsigned long int elements;
+    unsigned long int deleted;
+    unsigned long int connections;
+    unsigned long int invalid_links;
+    unsigned long int self_links;
+    unsigned long int duplicate_links;
+    unsigned long int oversized_lists;
+    unsigned long int invalid_levels;
+    unsigned long int unreachable;
+    unsigned long int inconsistent_labels;
+    int invalid_entry_point;
+  } GraphCheck;
+  SPACE newSQ8Space(int dim, float *min, float *scale, char stype);
+  SPACE newPQSpace(int dim, int m, int bits, float *centroids, char stype);
+  SPACE newHalfSpace(int dim, int bfloat16, char stype);
+  SPACE newHammingSpace(int dim);
+  HNSW initHNSW(int dim, unsigned long int max_elements, int M, int ef_construction, int rand_seed, char stype, int allow_replace_deleted, SPACE space);
+  HNSW loadHNSW(char *location, int dim, unsigned long int max_elements, char stype, int allow_replace_deleted, int map, SPACE space, char **error);
   void saveHNSW(HNSW index, char *location);
-  void addPoint(HNSW index, float *vec, unsigned long int label);
-  void markDelete(HNSW index, unsigned long int label);
-  int searchKnn(HNSW index, float *vec, int N, unsigned long int *label, float *dist);
+  int isMappedHNSW(HNSW index);
+  void freeHNSW(HNSW index);
+  int addPoint(HNSW index, void *vec, unsigned long int label);
+  long long reserveDeletedElement(HNSW index, unsigned long int label);
+  void replaceDeletedElement(HNSW index, void *vec, unsigned long int label, unsigned int internal_id);
+  int markDelete(HNSW index, unsigned long int label);
+  int unmarkDelete(HNSW index, unsigned long int label);
+  int searchKnn(HNSW index, void *vec, int N, unsigned long int *label, float *dist);
+  int searchKnnWithEf(HNSW index, void *vec, int N, int ef, unsigned long int *label, float *dist);
+  int searchKnnExact(HNSW index, void *vec, int N, unsigned long int *label, float *dist);
   void setEf(HNSW index, int ef);
+  int getEf(HNSW index);
+  unsigned long int getCurrentCount(HNSW index);
+  unsigned long int getMemoryUsage(HNSW index);
+  void checkHNSW(HNSW index, GraphCheck *check);
+  unsigned long int getLabels(HNSW index, unsigned long int *labels, unsigned long int size);
+  int getDataByLabel(HNSW index, unsigned long int label, void *vec, int include_deleted);
 #ifdef __cplusplus
 }
 #endif
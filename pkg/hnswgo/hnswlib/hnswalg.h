@@ -7,12 +7,23 @@
 #include <stdlib.h>
 #include <assert.h>
 #include <unordered_set>
+#include <set>
 #include <list>
+#include <cstring>
+#include <fcntl.h>
+#include <unistd.h>
+#include <sys/mman.h>
 
 namespace hnswlib {
     typedef unsigned int tableint;
     typedef unsigned int linklistsizeint;
 
+    // MAPPED_INDEX_MAGIC starts the files written by saveMappedIndex.
+    static const char MAPPED_INDEX_MAGIC[8] = {'H', 'N', 'S', 'W', 'M', 'A', 'P', '1'};
+    // MAPPED_INDEX_ALIGNMENT is the alignment of the level 0 data in the
+    // files written by saveMappedIndex, a multiple of the common page sizes.
+    static const size_t MAPPED_INDEX_ALIGNMENT = 65536;
+
     template<typename dist_t>
     class HierarchicalNSW : public AlgorithmInterface<dist_t> {
     public:
@@ -21,12 +32,14 @@ namespace hnswlib {
 
         }
 
-        HierarchicalNSW(SpaceInterface<dist_t> *s, const std::string &location, bool nmslib = false, size_t max_elements=0) {
+        HierarchicalNSW(SpaceInterface<dist_t> *s, const std::string &location, bool nmslib = false, size_t max_elements=0, bool allow_replace_deleted = false) :
+                allow_replace_deleted_(allow_replace_deleted) {
             loadIndex(location, s, max_elements);
         }
 
-        HierarchicalNSW(SpaceInterface<dist_t> *s, size_t max_elements, size_t M = 16, size_t ef_construction = 200, size_t random_seed = 100) :
-                link_list_locks_(max_elements), link_list_update_locks_(max_update_element_locks), element_levels_(max_elements) {
+        HierarchicalNSW(SpaceInterface<dist_t> *s, size_t max_elements, size_t M = 16, size_t ef_construction = 200, size_t random_seed = 100, bool allow_replace_deleted = false) :
+                link_list_locks_(max_elements), link_list_update_locks_(max_update_element_locks), element_levels_(max_elements),
+                allow_replace_deleted_(allow_replace_deleted) {
             max_elements_ = max_elements;
 
             has_deletions_=false;
@@ -79,7 +92,10 @@ namespace hnswlib {
 
         ~HierarchicalNSW() {
 
-            free(data_level0_memory_);
+            if (mapped_size_ > 0)
+                munmap(data_level0_memory_, mapped_size_);
+            else
+                free(data_level0_memory_);
             for (tableint i = 0; i < cur_element_count; i++) {
                 if (element_levels_[i] > 0)
                     free(linkLists_[i]);
@@ -118,6 +134,9 @@ namespace hnswlib {
 
 
         char *data_level0_memory_;
+        // The size of data_level0_memory_ if it is memory-mapped by
+        // loadMappedIndex, or zero if it is allocated on the heap.
+        size_t mapped_size_ = 0;
         char **linkLists_;
         std::vector<int> element_levels_;
 
@@ -131,6 +150,15 @@ namespace hnswlib {
         void *dist_func_param_;
         std::unordered_map<labeltype, tableint> label_lookup_;
 
+        // Flag to replace deleted elements (marked as deleted) during insertions.
+        bool allow_replace_deleted_ = false;
+        // Contains the internal ids of deleted elements, if they can be replaced.
+        // Unlike upstream hnswlib, the set is ordered: the element being replaced
+        // only depends on which elements are deleted, so replaying the same
+        // operations reproduces the same replacements.
+        std::set<tableint> deleted_elements;
+        std::mutex deleted_elements_lock;
+
         std::default_random_engine level_generator_;
         std::default_random_engine update_probability_generator_;
 
@@ -567,6 +595,8 @@ namespace hnswlib {
         void resizeIndex(size_t new_max_elements){
             if (new_max_elements<cur_element_count)
                 throw std::runtime_error("Cannot resize, max element is less than the current number of elements");
+            if (mapped_size_ > 0)
+                throw std::runtime_error("Cannot resize a memory-mapped index");
 
 
             delete visited_list_pool_;
@@ -734,8 +764,11 @@ namespace hnswlib {
             has_deletions_=false;
 
             for (size_t i = 0; i < cur_element_count; i++) {
-                if(isMarkedDeleted(i))
+                if(isMarkedDeleted(i)) {
                     has_deletions_=true;
+                    if (allow_replace_deleted_)
+                        deleted_elements.insert(i);
+                }
             }
 
             input.close();
@@ -743,6 +776,206 @@ namespace hnswlib {
             return;
         }
 
+        /**
+         * Saves the index in the format read by loadMappedIndex. Unlike saveIndex, the
+         * labels and the deletion marks are written before the link lists, and the
+         * level 0 data is written last, aligned to MAPPED_INDEX_ALIGNMENT, so that it
+         * can be memory-mapped, and the index can be loaded without reading it.
+         */
+        void saveMappedIndex(const std::string &location) {
+            std::ofstream output(location, std::ios::binary);
+
+            output.write(MAPPED_INDEX_MAGIC, sizeof(MAPPED_INDEX_MAGIC));
+            size_t level0_offset = 0;
+            writeBinaryPOD(output, level0_offset);
+
+            writeBinaryPOD(output, offsetLevel0_);
+            writeBinaryPOD(output, max_elements_);
+            writeBinaryPOD(output, cur_element_count);
+            writeBinaryPOD(output, size_data_per_element_);
+            writeBinaryPOD(output, label_offset_);
+            writeBinaryPOD(output, offsetData_);
+            writeBinaryPOD(output, maxlevel_);
+            writeBinaryPOD(output, enterpoint_node_);
+            writeBinaryPOD(output, maxM_);
+
+            writeBinaryPOD(output, maxM0_);
+            writeBinaryPOD(output, M_);
+            writeBinaryPOD(output, mult_);
+            writeBinaryPOD(output, ef_construction_);
+
+            for (size_t i = 0; i < cur_element_count; i++) {
+                writeBinaryPOD(output, getExternalLabel(i));
+            }
+            for (size_t i = 0; i < cur_element_count; i++) {
+                unsigned char deleted = isMarkedDeleted(i) ? 1 : 0;
+                writeBinaryPOD(output, deleted);
+            }
+            for (size_t i = 0; i < cur_element_count; i++) {
+                unsigned int linkListSize = element_levels_[i] > 0 ? size_links_per_element_ * element_levels_[i] : 0;
+                writeBinaryPOD(output, linkListSize);
+                if (linkListSize)
+                    output.write(linkLists_[i], linkListSize);
+            }
+
+            size_t position = output.tellp();
+            level0_offset = (position + MAPPED_INDEX_ALIGNMENT - 1) / MAPPED_INDEX_ALIGNMENT * MAPPED_INDEX_ALIGNMENT;
+            std::vector<char> padding(level0_offset - position);
+            output.write(padding.data(), padding.size());
+            output.write(data_level0_memory_, cur_element_count * size_data_per_element_);
+
+            output.seekp(sizeof(MAPPED_INDEX_MAGIC));
+            writeBinaryPOD(output, level0_offset);
+            output.close();
+        }
+
+        /**
+         * Reports whether the file was written by saveMappedIndex.
+         */
+        static bool isMappedIndex(const std::string &location) {
+            std::ifstream input(location, std::ios::binary);
+            char magic[sizeof(MAPPED_INDEX_MAGIC)];
+            input.read(magic, sizeof(magic));
+            return input && memcmp(magic, MAPPED_INDEX_MAGIC, sizeof(magic)) == 0;
+        }
+
+        /**
+         * Loads an index saved by saveMappedIndex. If map is true, the level 0 data
+         * is memory-mapped privately: it is paged in from the file when accessed,
+         * and the pages which are modified are copied, leaving the file unchanged.
+         * Otherwise, it is read into memory, as done by loadIndex.
+         */
+        void loadMappedIndex(const std::string &location, SpaceInterface<dist_t> *s, size_t max_elements_i, bool map) {
+            std::ifstream input(location, std::ios::binary);
+
+            if (!input.is_open())
+                throw std::runtime_error("Cannot open file");
+
+            input.seekg(0, input.end);
+            size_t total_filesize = input.tellg();
+            input.seekg(0, input.beg);
+
+            char magic[sizeof(MAPPED_INDEX_MAGIC)];
+            input.read(magic, sizeof(magic));
+            if (!input || memcmp(magic, MAPPED_INDEX_MAGIC, sizeof(magic)) != 0)
+                throw std::runtime_error("Index seems to be corrupted or unsupported");
+            size_t level0_offset;
+            readBinaryPOD(input, level0_offset);
+
+            readBinaryPOD(input, offsetLevel0_);
+            readBinaryPOD(input, max_elements_);
+            readBinaryPOD(input, cur_element_count);
+
+            size_t max_elements = max_elements_i;
+            if (max_elements < cur_element_count)
+                max_elements = max_elements_;
+            max_elements_ = max_elements;
+            readBinaryPOD(input, size_data_per_element_);
+            readBinaryPOD(input, label_offset_);
+            readBinaryPOD(input, offsetData_);
+            readBinaryPOD(input, maxlevel_);
+            readBinaryPOD(input, enterpoint_node_);
+
+            readBinaryPOD(input, maxM_);
+            readBinaryPOD(input, maxM0_);
+            readBinaryPOD(input, M_);
+            readBinaryPOD(input, mult_);
+            readBinaryPOD(input, ef_construction_);
+
+            if (!input || level0_offset % MAPPED_INDEX_ALIGNMENT != 0 ||
+                level0_offset + cur_element_count * size_data_per_element_ != total_filesize)
+                throw std::runtime_error("Index seems to be corrupted or unsupported");
+
+            data_size_ = s->get_data_size();
+            fstdistfunc_ = s->get_dist_func();
+            dist_func_param_ = s->get_dist_func_param();
+
+            size_links_per_element_ = maxM_ * sizeof(tableint) + sizeof(linklistsizeint);
+            size_links_level0_ = maxM0_ * sizeof(tableint) + sizeof(linklistsizeint);
+            std::vector<std::mutex>(max_elements).swap(link_list_locks_);
+            std::vector<std::mutex>(max_update_element_locks).swap(link_list_update_locks_);
+
+            visited_list_pool_ = new VisitedListPool(1, max_elements);
+
+            std::vector<labeltype> labels(cur_element_count);
+            input.read((char *) labels.data(), cur_element_count * sizeof(labeltype));
+            std::vector<unsigned char> deleted(cur_element_count);
+            input.read((char *) deleted.data(), cur_element_count);
+
+            linkLists_ = (char **) malloc(sizeof(void *) * max_elements);
+            if (linkLists_ == nullptr)
+                throw std::runtime_error("Not enough memory: loadIndex failed to allocate linklists");
+            element_levels_ = std::vector<int>(max_elements);
+            revSize_ = 1.0 / mult_;
+            ef_ = 10;
+            for (size_t i = 0; i < cur_element_count; i++) {
+                label_lookup_[labels[i]] = i;
+                unsigned int linkListSize = 0;
+                readBinaryPOD(input, linkListSize);
+                if (linkListSize == 0) {
+                    element_levels_[i] = 0;
+
+                    linkLists_[i] = nullptr;
+                } else {
+                    element_levels_[i] = linkListSize / size_links_per_element_;
+                    linkLists_[i] = (char *) malloc(linkListSize);
+                    if (linkLists_[i] == nullptr)
+                        throw std::runtime_error("Not enough memory: loadIndex failed to allocate linklist");
+                    input.read(linkLists_[i], linkListSize);
+                }
+            }
+            if (!input || (size_t) input.tellg() > level0_offset)
+                throw std::runtime_error("Index seems to be corrupted or unsupported");
+
+            has_deletions_ = false;
+            for (size_t i = 0; i < cur_element_count; i++) {
+                if (deleted[i]) {
+                    has_deletions_ = true;
+                    if (allow_replace_deleted_)
+                        deleted_elements.insert(i);
+                }
+            }
+
+            if (map) {
+                input.close();
+                mapLevel0(location, level0_offset);
+                return;
+            }
+
+            data_level0_memory_ = (char *) malloc(max_elements * size_data_per_element_);
+            if (data_level0_memory_ == nullptr)
+                throw std::runtime_error("Not enough memory: loadIndex failed to allocate level0");
+            input.seekg(level0_offset, input.beg);
+            input.read(data_level0_memory_, cur_element_count * size_data_per_element_);
+            input.close();
+        }
+
+        /**
+         * Maps the level 0 data of the elements from the file, at the given offset,
+         * within an anonymous mapping large enough for max_elements_ elements.
+         */
+        void mapLevel0(const std::string &location, size_t offset) {
+            size_t size = max_elements_ * size_data_per_element_;
+            void *memory = mmap(nullptr, size, PROT_READ | PROT_WRITE, MAP_PRIVATE | MAP_ANONYMOUS, -1, 0);
+            if (memory == MAP_FAILED)
+                throw std::runtime_error("Not enough memory: loadIndex failed to map level0");
+            data_level0_memory_ = (char *) memory;
+            mapped_size_ = size;
+
+            size_t data_size = cur_element_count * size_data_per_element_;
+            if (data_size == 0)
+                return;
+            int fd = open(location.c_str(), O_RDONLY);
+            if (fd < 0)
+                throw std::runtime_error("Cannot open file");
+            void *data = mmap(memory, data_size, PROT_READ | PROT_WRITE, MAP_PRIVATE | MAP_FIXED, fd, offset);
+            close(fd);
+            if (data == MAP_FAILED)
+                throw std::runtime_error("Cannot map level0");
+            // The graph is traversed in no particular order.
+            madvise(data, data_size, MADV_RANDOM);
+        }
+
         template<typename data_t>
         std::vector<data_t> getDataByLabel(labeltype label)
         {
@@ -780,6 +1013,19 @@ namespace hnswlib {
             markDeletedInternal(search->second);
         }
 
+        /**
+         * Removes the deleted mark of the element with the given label.
+         * Ported from newer upstream hnswlib versions.
+         */
+        void unmarkDelete(labeltype label)
+        {
+            auto search = label_lookup_.find(label);
+            if (search == label_lookup_.end()) {
+                throw std::runtime_error("Label not found");
+            }
+            unmarkDeletedInternal(search->second);
+        }
+
         /**
          * Uses the first 8 bits of the memory for the linked list to store the mark,
          * whereas maxM0_ has to be limited to the lower 24 bits, however, still large enough in almost all cases.
@@ -788,6 +1034,10 @@ namespace hnswlib {
         void markDeletedInternal(tableint internalId) {
             unsigned char *ll_cur = ((unsigned char *)get_linklist0(internalId))+2;
             *ll_cur |= DELETE_MARK;
+            if (allow_replace_deleted_) {
+                std::unique_lock <std::mutex> lock_deleted_elements(deleted_elements_lock);
+                deleted_elements.insert(internalId);
+            }
         }
 
         /**
@@ -797,6 +1047,10 @@ namespace hnswlib {
         void unmarkDeletedInternal(tableint internalId) {
             unsigned char *ll_cur = ((unsigned char *)get_linklist0(internalId))+2;
             *ll_cur &= ~DELETE_MARK;
+            if (allow_replace_deleted_) {
+                std::unique_lock <std::mutex> lock_deleted_elements(deleted_elements_lock);
+                deleted_elements.erase(internalId);
+            }
         }
 
         /**
@@ -821,6 +1075,73 @@ namespace hnswlib {
             addPoint(data_point, label,-1);
         }
 
+        /**
+         * Adds a new element. If replace_deleted is true, an element marked deleted is replaced, if any,
+         * instead of using a new slot. Replacement must be enabled in the constructor.
+         * As in upstream hnswlib, there must be no concurrent operations on the deleted element being replaced.
+         */
+        void addPoint(const void *data_point, labeltype label, bool replace_deleted) {
+            if (!replace_deleted) {
+                addPoint(data_point, label, -1);
+                return;
+            }
+            long long internal_id_replaced = reserveDeletedElement(label);
+            if (internal_id_replaced < 0) {
+                addPoint(data_point, label, -1);
+            } else {
+                replaceDeletedElement(data_point, label, internal_id_replaced);
+            }
+        }
+
+        /**
+         * Chooses the deleted element which a new element with the given label will replace, and returns its
+         * internal id, so that it can no longer be chosen.
+         * It returns -1 if no element is replaced: either no element is marked deleted, or an element with the
+         * same label already exists, and it will be updated instead (if it is marked deleted, it can no longer
+         * be chosen either).
+         */
+        long long reserveDeletedElement(labeltype label) {
+            if (!allow_replace_deleted_) {
+                throw std::runtime_error("Replacement of deleted elements is disabled in constructor");
+            }
+            std::unique_lock <std::mutex> lock_table(cur_element_count_guard_);
+            std::unique_lock <std::mutex> lock_deleted_elements(deleted_elements_lock);
+
+            auto search = label_lookup_.find(label);
+            if (search != label_lookup_.end()) {
+                deleted_elements.erase(search->second);
+                return -1;
+            }
+            if (deleted_elements.empty()) {
+                return -1;
+            }
+            tableint internal_id = *deleted_elements.begin();
+            deleted_elements.erase(deleted_elements.begin());
+            return internal_id;
+        }
+
+        /**
+         * Replaces the deleted element chosen by reserveDeletedElement with a new element.
+         */
+        void replaceDeletedElement(const void *data_point, labeltype label, tableint internal_id_replaced) {
+            {
+                std::unique_lock <std::mutex> lock_table(cur_element_count_guard_);
+                labeltype label_replaced = getExternalLabel(internal_id_replaced);
+                auto search = label_lookup_.find(label_replaced);
+                if (search != label_lookup_.end() && search->second == internal_id_replaced) {
+                    label_lookup_.erase(search);
+                }
+                setExternalLabel(internal_id_replaced, label);
+                label_lookup_[label] = internal_id_replaced;
+            }
+
+            std::unique_lock <std::mutex> lock_el_update(link_list_update_locks_[(internal_id_replaced & (max_update_element_locks - 1))]);
+            // The element is updated before removing the mark, so that searches never find the old data with
+            // the new label.
+            updatePoint(data_point, internal_id_replaced, 1.0);
+            unmarkDeletedInternal(internal_id_replaced);
+        }
+
         void updatePoint(const void *dataPoint, tableint internalId, float updateNeighborProbability) {
             // update the feature vector associated with existing point with new vector
             memcpy(getDataByInternalId(internalId), dataPoint, data_size_);
@@ -949,7 +1270,8 @@ namespace hnswlib {
                 // Since element_levels_ is being used to get `dataPointLevel`, there could be cases where `topCandidates` could just contains entry point itself.
                 // To prevent self loops, the `topCandidates` is filtered and thus can be empty.
                 if (filteredTopCandidates.size() > 0) {
-                    bool epDeleted = isMarkedDeleted(entryPointInternalId);
+                    // The element being updated might be the entry point, still marked deleted while it is replaced.
+                    bool epDeleted = isMarkedDeleted(entryPointInternalId) && entryPointInternalId != dataPointInternalId;
                     if (epDeleted) {
                         filteredTopCandidates.emplace(fstdistfunc_(dataPoint, getDataByInternalId(entryPointInternalId), dist_func_param_), entryPointInternalId);
                         if (filteredTopCandidates.size() > ef_construction_)
@@ -1099,6 +1421,15 @@ namespace hnswlib {
 
         std::priority_queue<std::pair<dist_t, labeltype >>
         searchKnn(const void *query_data, size_t k) const {
+            return searchKnn(query_data, k, ef_);
+        }
+
+        /**
+         * Same as searchKnn, with the given "ef" parameter instead of ef_, so that different values can be
+         * evaluated without affecting concurrent searches.
+         */
+        std::priority_queue<std::pair<dist_t, labeltype >>
+        searchKnn(const void *query_data, size_t k, size_t ef) const {
             std::priority_queue<std::pair<dist_t, labeltype >> result;
             if (cur_element_count == 0) return result;
 
@@ -1135,11 +1466,11 @@ namespace hnswlib {
             std::priority_queue<std::pair<dist_t, tableint>, std::vector<std::pair<dist_t, tableint>>, CompareByFirst> top_candidates;
             if (has_deletions_) {
                 top_candidates=searchBaseLayerST<true,true>(
-                        currObj, query_data, std::max(ef_, k));
+                        currObj, query_data, std::max(ef, k));
             }
             else{
                 top_candidates=searchBaseLayerST<false,true>(
-                        currObj, query_data, std::max(ef_, k));
+                        currObj, query_data, std::max(ef, k));
             }
 
             while (top_candidates.size() > k) {
@@ -104,5 +104,11 @@ namespace hnswlib {
 
 #include "space_l2.h"
 #include "space_ip.h"
+#include "space_l1.h"
+#include "space_cosine.h"
+#include "space_sq8.h"
+#include "space_pq.h"
+#include "space_half.h"
+#include "space_hamming.h"
 #include "bruteforce.h"
 #include "hnswalg.h"
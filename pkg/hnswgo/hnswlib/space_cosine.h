@@ -0,0 +1,111 @@
+#pragma once
+#include "hnswlib.h"
+#include <cmath>
+
+namespace hnswlib {
+
+    // NormalizeInPlace divides the vector by its norm, and returns the norm.
+    // Zero vectors are left unchanged.
+    static float
+    NormalizeInPlace(float *pVect, size_t qty) {
+        size_t i = 0;
+        float sum = 0;
+
+#if defined(USE_AVX)
+        float PORTABLE_ALIGN32 TmpRes[8];
+        __m256 v;
+        __m256 sum8 = _mm256_set1_ps(0);
+        for (; i + 8 <= qty; i += 8) {
+            v = _mm256_loadu_ps(pVect + i);
+            sum8 = _mm256_add_ps(sum8, _mm256_mul_ps(v, v));
+        }
+        _mm256_store_ps(TmpRes, sum8);
+        sum = TmpRes[0] + TmpRes[1] + TmpRes[2] + TmpRes[3] + TmpRes[4] + TmpRes[5] + TmpRes[6] + TmpRes[7];
+#elif defined(USE_SSE)
+        float PORTABLE_ALIGN32 TmpRes[8];
+        __m128 v;
+        __m128 sum4 = _mm_set1_ps(0);
+        for (; i + 4 <= qty; i += 4) {
+            v = _mm_loadu_ps(pVect + i);
+            sum4 = _mm_add_ps(sum4, _mm_mul_ps(v, v));
+        }
+        _mm_store_ps(TmpRes, sum4);
+        sum = TmpRes[0] + TmpRes[1] + TmpRes[2] + TmpRes[3];
+#endif
+        for (; i < qty; i++) {
+            sum += pVect[i] * pVect[i];
+        }
+        if (sum == 0) {
+            return 0;
+        }
+
+        float norm = std::sqrt(sum);
+        float inv = 1.0f / norm;
+        i = 0;
+#if defined(USE_AVX)
+        const __m256 inv8 = _mm256_set1_ps(inv);
+        for (; i + 8 <= qty; i += 8) {
+            _mm256_storeu_ps(pVect + i, _mm256_mul_ps(_mm256_loadu_ps(pVect + i), inv8));
+        }
+#elif defined(USE_SSE)
+        const __m128 inv4 = _mm_set1_ps(inv);
+        for (; i + 4 <= qty; i += 4) {
+            _mm_storeu_ps(pVect + i, _mm_mul_ps(_mm_loadu_ps(pVect + i), inv4));
+        }
+#endif
+        for (; i < qty; i++) {
+            pVect[i] *= inv;
+        }
+        return norm;
+    }
+
+    // CosineSpace stores each vector normalized, followed by its original
+    // norm, and compares the normalized components by inner product. The
+    // vectors are prepared for it by normalize.
+    class CosineSpace : public SpaceInterface<float> {
+
+        DISTFUNC<float> fstdistfunc_;
+        size_t data_size_;
+        size_t dim_;
+    public:
+        CosineSpace(size_t dim) {
+            fstdistfunc_ = InnerProduct;
+    #if defined(USE_AVX) || defined(USE_SSE)
+            if (dim % 16 == 0)
+                fstdistfunc_ = InnerProductSIMD16Ext;
+            else if (dim % 4 == 0)
+                fstdistfunc_ = InnerProductSIMD4Ext;
+            else if (dim > 16)
+                fstdistfunc_ = InnerProductSIMD16ExtResiduals;
+            else if (dim > 4)
+                fstdistfunc_ = InnerProductSIMD4ExtResiduals;
+    #endif
+            dim_ = dim;
+            data_size_ = (dim + 1) * sizeof(float);
+        }
+
+        // normalize copies the vector to the buffer, normalizing it in
+        // place and appending its norm, and returns the buffer data.
+        float *normalize(const void *vec, std::vector<float> &buffer) {
+            buffer.resize(dim_ + 1);
+            memcpy(buffer.data(), vec, dim_ * sizeof(float));
+            buffer[dim_] = NormalizeInPlace(buffer.data(), dim_);
+            return buffer.data();
+        }
+
+        size_t get_data_size() {
+            return data_size_;
+        }
+
+        DISTFUNC<float> get_dist_func() {
+            return fstdistfunc_;
+        }
+
+        void *get_dist_func_param() {
+            return &dim_;
+        }
+
+        ~CosineSpace() {}
+    };
+
+}
@@ -0,0 +1,109 @@
+#pragma once
+#include "hnswlib.h"
+
+namespace hnswlib {
+
+    // Half-precision vectors store two bytes per dimension, in the native
+    // byte order: either IEEE 754 binary16 (float16) values, or the upper
+    // halves of float32 values (bfloat16). They are converted to float32
+    // while computing the distances.
+    struct HalfParams {
+        size_t dim;
+        bool bfloat16;
+    };
+
+    static inline float
+    Float16ToFloat(uint16_t h) {
+        uint32_t sign = ((uint32_t) (h & 0x8000)) << 16;
+        uint32_t exp = (h >> 10) & 0x1f;
+        uint32_t mant = h & 0x3ff;
+        uint32_t bits;
+        if (exp == 0x1f) {
+            bits = sign | 0x7f800000 | (mant << 13);
+        } else if (exp != 0) {
+            bits = sign | ((exp + 127 - 15) << 23) | (mant << 13);
+        } else if (mant == 0) {
+            bits = sign;
+        } else {
+            // Subnormal values are normalized.
+            exp = 127 - 15 + 1;
+            while ((mant & 0x400) == 0) {
+                mant <<= 1;
+                exp--;
+            }
+            bits = sign | (exp << 23) | ((mant & 0x3ff) << 13);
+        }
+        float f;
+        memcpy(&f, &bits, sizeof(f));
+        return f;
+    }
+
+    static inline float
+    BFloat16ToFloat(uint16_t h) {
+        uint32_t bits = ((uint32_t) h) << 16;
+        float f;
+        memcpy(&f, &bits, sizeof(f));
+        return f;
+    }
+
+    static inline float
+    HalfToFloat(uint16_t h, bool bfloat16) {
+        return bfloat16 ? BFloat16ToFloat(h) : Float16ToFloat(h);
+    }
+
+    static float
+    HalfL2Sqr(const void *pVect1v, const void *pVect2v, const void *param_ptr) {
+        const uint16_t *pVect1 = (const uint16_t *) pVect1v;
+        const uint16_t *pVect2 = (const uint16_t *) pVect2v;
+        const HalfParams *params = (const HalfParams *) param_ptr;
+
+        float res = 0;
+        for (size_t i = 0; i < params->dim; i++) {
+            float t = HalfToFloat(pVect1[i], params->bfloat16) - HalfToFloat(pVect2[i], params->bfloat16);
+            res += t * t;
+        }
+        return (res);
+    }
+
+    static float
+    HalfInnerProduct(const void *pVect1v, const void *pVect2v, const void *param_ptr) {
+        const uint16_t *pVect1 = (const uint16_t *) pVect1v;
+        const uint16_t *pVect2 = (const uint16_t *) pVect2v;
+        const HalfParams *params = (const HalfParams *) param_ptr;
+
+        float res = 0;
+        for (size_t i = 0; i < params->dim; i++) {
+            res += HalfToFloat(pVect1[i], params->bfloat16) * HalfToFloat(pVect2[i], params->bfloat16);
+        }
+        return (1.0f - res);
+    }
+
+    class HalfSpace : public SpaceInterface<float> {
+
+        DISTFUNC<float> fstdistfunc_;
+        size_t data_size_;
+        HalfParams params_;
+    public:
+        HalfSpace(size_t dim, bool bfloat16, bool inner_product) {
+            fstdistfunc_ = inner_product ? HalfInnerProduct : HalfL2Sqr;
+            params_.dim = dim;
+            params_.bfloat16 = bfloat16;
+            data_size_ = dim * sizeof(uint16_t);
+        }
+
+        size_t get_data_size() {
+            return data_size_;
+        }
+
+        DISTFUNC<float> get_dist_func() {
+            return fstdistfunc_;
+        }
+
+        void *get_dist_func_param() {
+            return &params_;
+        }
+
+        ~HalfSpace() {}
+    };
+
+}
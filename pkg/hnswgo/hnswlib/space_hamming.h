@@ -0,0 +1,65 @@
+#pragma once
+#include "hnswlib.h"
+
+namespace hnswlib {
+
+    // Binary vectors store one bit per dimension, packed in bytes, with
+    // the unused bits of the last byte set to zero. The distance is the
+    // number of differing bits.
+
+    static inline unsigned int
+    Popcount64(uint64_t v) {
+#ifdef _MSC_VER
+        return (unsigned int) __popcnt64(v);
+#else
+        return (unsigned int) __builtin_popcountll(v);
+#endif
+    }
+
+    static float
+    Hamming(const void *pVect1v, const void *pVect2v, const void *qty_ptr) {
+        const unsigned char *pVect1 = (const unsigned char *) pVect1v;
+        const unsigned char *pVect2 = (const unsigned char *) pVect2v;
+        size_t qty = *((size_t *) qty_ptr);
+
+        unsigned int res = 0;
+        size_t i = 0;
+        for (; i + 8 <= qty; i += 8) {
+            uint64_t a, b;
+            memcpy(&a, pVect1 + i, sizeof(a));
+            memcpy(&b, pVect2 + i, sizeof(b));
+            res += Popcount64(a ^ b);
+        }
+        for (; i < qty; i++) {
+            res += Popcount64((uint64_t) (pVect1[i] ^ pVect2[i]));
+        }
+        return (float) res;
+    }
+
+    class HammingSpace : public SpaceInterface<float> {
+
+        size_t data_size_;
+        size_t qty_;
+    public:
+        // dim is the number of bits of the vectors.
+        HammingSpace(size_t dim) {
+            qty_ = (dim + 7) / 8;
+            data_size_ = qty_;
+        }
+
+        size_t get_data_size() {
+            return data_size_;
+        }
+
+        DISTFUNC<float> get_dist_func() {
+            return Hamming;
+        }
+
+        void *get_dist_func_param() {
+            return &qty_;
+        }
+
+        ~HammingSpace() {}
+    };
+
+}
@@ -0,0 +1,101 @@
+#pragma once
+#include "hnswlib.h"
+
+namespace hnswlib {
+
+    // PQ vectors are split into m sub-vectors of dim / m dimensions, each
+    // one stored as the code of the nearest of the 2^bits centroids of its
+    // subspace. The codes are packed, least significant bits first, after a
+    // leading zero byte.
+    //
+    // Queries are not encoded: they start with a non-zero byte, followed at
+    // offset sizeof(float) by the table of the distances (or inner products)
+    // between each sub-vector of the query and each centroid of its
+    // subspace, so that they are compared with the stored vectors by
+    // asymmetric distance computation.
+    struct PQParams {
+        size_t dim;
+        size_t m;
+        size_t bits;
+        size_t ksub;
+        size_t dsub;
+        bool inner_product;
+        // centroids holds the ksub centroids of each subspace, in order.
+        std::vector<float> centroids;
+    };
+
+    static inline size_t
+    PQCode(const unsigned char *codes, size_t j, size_t bits) {
+        size_t bit = j * bits;
+        unsigned int v = codes[bit >> 3];
+        if ((bit & 7) + bits > 8) {
+            v |= ((unsigned int) codes[(bit >> 3) + 1]) << 8;
+        }
+        return (v >> (bit & 7)) & ((1u << bits) - 1);
+    }
+
+    static float
+    PQDistance(const void *pVect1v, const void *pVect2v, const void *param_ptr) {
+        const unsigned char *pVect1 = (const unsigned char *) pVect1v;
+        const unsigned char *pVect2 = (const unsigned char *) pVect2v;
+        const PQParams *params = (const PQParams *) param_ptr;
+        if (pVect2[0] != 0) {
+            std::swap(pVect1, pVect2);
+        }
+
+        float res = 0;
+        if (pVect1[0] != 0) {
+            const float *table = (const float *) (pVect1 + sizeof(float));
+            for (size_t j = 0; j < params->m; j++) {
+                res += table[j * params->ksub + PQCode(pVect2 + 1, j, params->bits)];
+            }
+        } else {
+            const float *centroids = params->centroids.data();
+            for (size_t j = 0; j < params->m; j++) {
+                const float *c1 = centroids + (j * params->ksub + PQCode(pVect1 + 1, j, params->bits)) * params->dsub;
+                const float *c2 = centroids + (j * params->ksub + PQCode(pVect2 + 1, j, params->bits)) * params->dsub;
+                for (size_t i = 0; i < params->dsub; i++) {
+                    if (params->inner_product) {
+                        res += c1[i] * c2[i];
+                    } else {
+                        float t = c1[i] - c2[i];
+                        res += t * t;
+                    }
+                }
+            }
+        }
+        return params->inner_product ? 1.0f - res : res;
+    }
+
+    class PQSpace : public SpaceInterface<float> {
+
+        size_t data_size_;
+        PQParams params_;
+    public:
+        PQSpace(size_t dim, size_t m, size_t bits, const float *centroids, bool inner_product) {
+            params_.dim = dim;
+            params_.m = m;
+            params_.bits = bits;
+            params_.ksub = (size_t) 1 << bits;
+            params_.dsub = dim / m;
+            params_.inner_product = inner_product;
+            params_.centroids.assign(centroids, centroids + params_.ksub * dim);
+            data_size_ = 1 + (m * bits + 7) / 8;
+        }
+
+        size_t get_data_size() {
+            return data_size_;
+        }
+
+        DISTFUNC<float> get_dist_func() {
+            return PQDistance;
+        }
+
+        void *get_dist_func_param() {
+            return &params_;
+        }
+
+        ~PQSpace() {}
+    };
+
+}
@@ -0,0 +1,73 @@
+#pragma once
+#include "hnswlib.h"
+
+namespace hnswlib {
+
+    // SQ8 vectors store one unsigned byte per dimension: the value of
+    // dimension i is min[i] + code * scale[i].
+    struct SQ8Params {
+        size_t dim;
+        std::vector<float> min;
+        std::vector<float> scale;
+    };
+
+    static float
+    SQ8L2Sqr(const void *pVect1v, const void *pVect2v, const void *param_ptr) {
+        unsigned char *pVect1 = (unsigned char *) pVect1v;
+        unsigned char *pVect2 = (unsigned char *) pVect2v;
+        const SQ8Params *params = (const SQ8Params *) param_ptr;
+        const float *scale = params->scale.data();
+
+        float res = 0;
+        for (size_t i = 0; i < params->dim; i++) {
+            float t = ((float) pVect1[i] - (float) pVect2[i]) * scale[i];
+            res += t * t;
+        }
+        return (res);
+    }
+
+    static float
+    SQ8InnerProduct(const void *pVect1v, const void *pVect2v, const void *param_ptr) {
+        unsigned char *pVect1 = (unsigned char *) pVect1v;
+        unsigned char *pVect2 = (unsigned char *) pVect2v;
+        const SQ8Params *params = (const SQ8Params *) param_ptr;
+        const float *min = params->min.data();
+        const float *scale = params->scale.data();
+
+        float res = 0;
+        for (size_t i = 0; i < params->dim; i++) {
+            res += (min[i] + pVect1[i] * scale[i]) * (min[i] + pVect2[i] * scale[i]);
+        }
+        return (1.0f - res);
+    }
+
+    class SQ8Space : public SpaceInterface<float> {
+
+        DISTFUNC<float> fstdistfunc_;
+        size_t data_size_;
+        SQ8Params params_;
+    public:
+        SQ8Space(size_t dim, const float *min, const float *scale, bool inner_product) {
+            fstdistfunc_ = inner_product ? SQ8InnerProduct : SQ8L2Sqr;
+            params_.dim = dim;
+            params_.min.assign(min, min + dim);
+            params_.scale.assign(scale, scale + dim);
+            data_size_ = dim * sizeof(unsigned char);
+        }
+
+        size_t get_data_size() {
+            return data_size_;
+        }
+
+        DISTFUNC<float> get_dist_func() {
+            return fstdistfunc_;
+        }
+
+        void *get_dist_func_param() {
+            return &params_;
+        }
+
+        ~SQ8Space() {}
+    };
+
+}
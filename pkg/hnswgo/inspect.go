@@ -0,0 +1,282 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/osutils"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/wal"
+	"github.com/rs/zerolog"
+	"io"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Formats of the index files described by IndexHeader.
+const (
+	// MappedFormat is the format of the native graphs saved by Save, whose
+	// base layer can be memory-mapped.
+	MappedFormat = "hnsw-mapped"
+	// UpstreamFormat is the format of the native graphs saved by upstream
+	// hnswlib, and by Save before MappedFormat was introduced.
+	UpstreamFormat = "hnsw"
+	// FlatFormat is the format of flat indices, and of the vectors stored
+	// by quantized indices until training.
+	FlatFormat = "flat"
+)
+
+// mappedIndexMagic starts the index files in MappedFormat.
+var mappedIndexMagic = []byte("HNSWMAP1")
+
+// Inspection describes the files of an index dir, as read by Inspect.
+type Inspection struct {
+	// State is the state of the index, or nil if it cannot be decoded (see
+	// StateErr).
+	State *StateInfo
+	// StateErr is the error decoding the state of the index, if any.
+	StateErr error
+	// Header is the header of the file of the data structure of the index,
+	// or nil if it cannot be read (see HeaderErr). It is only read if the
+	// state is decoded.
+	Header *IndexHeader
+	// HeaderErr is the error reading the header, if any.
+	HeaderErr error
+	// LogEntries is the number of entries of the write-ahead log, by type
+	// (for example, "PointAddition").
+	LogEntries map[string]int
+	// Log is the verification of the write-ahead log (see wal.Log.Check).
+	Log wal.LogCheck
+	// Files are the files in the index dir, sorted by name, including the
+	// temporary files left by an interrupted saving. Subdirs are ignored.
+	Files []FileInfo
+}
+
+// StateInfo is the state of an index, as saved with it.
+type StateInfo struct {
+	Config
+	// LastAutoID is the last ID generated, if Config.AutoIDEnabled is true.
+	LastAutoID uint32
+	// Ef is the "ef" parameter when the index was saved. Zero means the
+	// initial value of the index.
+	Ef int
+	// NativeCosine reports whether the vectors of a cosine space are
+	// normalized by the native index (see HNSW.StoresNorms).
+	NativeCosine bool
+	// Expirations is the number of elements which expire.
+	Expirations int
+}
+
+// IndexHeader is the header of the file of the data structure of an index.
+type IndexHeader struct {
+	// File is the name of the file, in the index dir: "training" for
+	// quantized indices which are not trained yet, "index" otherwise.
+	File string
+	// Format is MappedFormat, UpstreamFormat or FlatFormat.
+	Format string
+	// Elements is the number of elements, including the ones marked
+	// deleted.
+	Elements uint64
+	// Dim is the dimension of the vectors of the FlatFormat.
+	Dim uint64
+	// The remaining fields are only set for native graphs.
+	MaxElements    uint64
+	M              uint64
+	MaxM           uint64
+	MaxM0          uint64
+	EfConstruction uint64
+	// MaxLevel is the top level of the graph.
+	MaxLevel int32
+	// EntryPoint is the internal ID of the element where the searches
+	// start.
+	EntryPoint uint32
+}
+
+// FileInfo describes a file of an index dir.
+type FileInfo struct {
+	Name string
+	Size int64
+	// Leftover reports whether the file is a temporary file, left by an
+	// interrupted saving.
+	Leftover bool
+}
+
+// Inspect describes the files of the index in dir: its state, the header of
+// its data structure, the entries of its write-ahead log and the size of
+// its files. Unlike Load and Verify, it only reads the headers of the
+// files, so it is fast even for large indices. The errors decoding the
+// files are reported, while the returned error is only set if the
+// inspection could not be performed.
+//
+// The index may be loaded in the meantime: if it is being modified, the
+// inspection may not be consistent.
+func Inspect(dir string) (*Inspection, error) {
+	exists, err := osutils.DirExists(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("index dir %#v not found", dir)
+	}
+
+	in := &Inspection{LogEntries: make(map[string]int)}
+	state, err := loadState(dir, zerolog.Nop())
+	if err != nil {
+		in.StateErr = err
+	} else {
+		in.State = &StateInfo{
+			Config:       state.Config,
+			LastAutoID:   state.LastAutoID,
+			Ef:           state.Ef,
+			NativeCosine: state.NativeCosine,
+			Expirations:  len(state.Expirations),
+		}
+		in.Header, in.HeaderErr = readIndexHeader(dir, state)
+	}
+
+	in.Log, err = wal.NewLog(path.Join(dir, "log")).Check(func(e interface{}) error {
+		in.LogEntries[reflect.TypeOf(e).Name()]++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading content of dir %#v: %w", dir, err)
+	}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			return nil, fmt.Errorf("error reading file %#v: %w", file.Name(), err)
+		}
+		in.Files = append(in.Files, FileInfo{
+			Name:     file.Name(),
+			Size:     info.Size(),
+			Leftover: strings.HasSuffix(file.Name(), ".tmp"),
+		})
+	}
+	sort.Slice(in.Files, func(i, j int) bool {
+		return in.Files[i].Name < in.Files[j].Name
+	})
+	return in, nil
+}
+
+// readIndexHeader reads the header of the file of the data structure of
+// the index, choosing it as loadIndex does.
+func readIndexHeader(dir string, state *hnswState) (*IndexHeader, error) {
+	name := "index"
+	if state.quantized() {
+		trained := state.Quantizer != nil
+		if state.Storage == PQStorage {
+			exists, err := osutils.FileExists(path.Join(dir, "codebooks"))
+			if err != nil {
+				return nil, err
+			}
+			trained = exists
+		}
+		exists, err := osutils.FileExists(path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		if !trained || !exists {
+			name = "training"
+		}
+	}
+	filename := path.Join(dir, name)
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %#v: %w", filename, err)
+	}
+	defer file.Close()
+	r := bufio.NewReader(file)
+
+	var header *IndexHeader
+	if state.IndexType == FlatIndex || name == "training" {
+		header, err = readFlatHeader(r)
+	} else {
+		header, err = readGraphHeader(r)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading header of file %#v: %w", filename, err)
+	}
+	header.File = name
+	return header, nil
+}
+
+// readFlatHeader reads the header of a file in FlatFormat.
+func readFlatHeader(r io.Reader) (*IndexHeader, error) {
+	var header [2]uint64
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	return &IndexHeader{
+		Format:   FlatFormat,
+		Elements: header[0],
+		Dim:      header[1],
+	}, nil
+}
+
+// readGraphHeader reads the header of a native graph, in MappedFormat or
+// UpstreamFormat. The fields are written by hnswlib without padding.
+func readGraphHeader(r *bufio.Reader) (*IndexHeader, error) {
+	h := &IndexHeader{Format: UpstreamFormat}
+	magic, err := r.Peek(len(mappedIndexMagic))
+	if err == nil && bytes.Equal(magic, mappedIndexMagic) {
+		h.Format = MappedFormat
+		// The magic number is followed by the offset of the base layer.
+		if _, err = r.Discard(len(mappedIndexMagic) + 8); err != nil {
+			return nil, err
+		}
+	}
+
+	var fields struct {
+		OffsetLevel0       uint64
+		MaxElements        uint64
+		Elements           uint64
+		SizeDataPerElement uint64
+		LabelOffset        uint64
+		OffsetData         uint64
+		MaxLevel           int32
+		EntryPoint         uint32
+		MaxM               uint64
+		MaxM0              uint64
+		M                  uint64
+		Mult               float64
+		EfConstruction     uint64
+	}
+	if err = binary.Read(r, binary.LittleEndian, &fields); err != nil {
+		return nil, err
+	}
+	h.Elements = fields.Elements
+	h.MaxElements = fields.MaxElements
+	h.M = fields.M
+	h.MaxM = fields.MaxM
+	h.MaxM0 = fields.MaxM0
+	h.EfConstruction = fields.EfConstruction
+	h.MaxLevel = fields.MaxLevel
+	h.EntryPoint = fields.EntryPoint
+	return h, nil
+}
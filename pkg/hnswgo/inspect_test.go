@@ -0,0 +1,138 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo_test
+
+import (
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	t.Parallel()
+
+	t.Run("HNSW index", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		createAndSaveSampleIndex(t, dir)
+		hnsw, err := hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		require.NoError(t, hnsw.SetEf(42))
+		_, err = hnsw.AddPointAutoID(sampleVectors[0])
+		require.NoError(t, err)
+		require.NoError(t, hnsw.MarkDelete(1))
+		require.NoError(t, os.WriteFile(path.Join(dir, "index.tmp"), []byte("foo"), 0666))
+
+		in, err := hnswgo.Inspect(dir)
+		require.NoError(t, err)
+		assert.NoError(t, in.StateErr)
+		require.NotNil(t, in.State)
+		assert.Equal(t, makeConfig(hnswgo.CosineSpace, true), in.State.Config)
+		assert.Equal(t, uint32(2), in.State.LastAutoID)
+		assert.True(t, in.State.NativeCosine)
+
+		assert.NoError(t, in.HeaderErr)
+		require.NotNil(t, in.Header)
+		assert.Equal(t, "index", in.Header.File)
+		assert.Equal(t, hnswgo.MappedFormat, in.Header.Format)
+		assert.Equal(t, uint64(2), in.Header.Elements)
+		assert.Equal(t, uint64(10), in.Header.MaxElements)
+		assert.Equal(t, uint64(10), in.Header.M)
+		assert.Equal(t, uint64(200), in.Header.EfConstruction)
+		assert.Less(t, in.Header.EntryPoint, uint32(2))
+
+		assert.Equal(t, map[string]int{"EfSetting": 1, "PointAddition": 1, "DeletionMark": 1}, in.LogEntries)
+		assert.NoError(t, in.Log.Err)
+		require.Len(t, in.Files, 4)
+		assert.Equal(t, []string{"index", "index.tmp", "log", "state"}, fileNames(in.Files))
+		assert.Equal(t, hnswgo.FileInfo{Name: "index.tmp", Size: 3, Leftover: true}, in.Files[1])
+		assert.Equal(t, in.Log.Size, in.Files[2].Size)
+	})
+
+	t.Run("index saved in the previous format", func(t *testing.T) {
+		t.Parallel()
+		in, err := hnswgo.Inspect(path.Join("testdata", "legacy-index"))
+		require.NoError(t, err)
+		require.NotNil(t, in.Header)
+		assert.Equal(t, hnswgo.UpstreamFormat, in.Header.Format)
+		// One of the elements is marked deleted.
+		assert.Equal(t, uint64(3), in.Header.Elements)
+		assert.Empty(t, in.LogEntries)
+	})
+
+	t.Run("flat index", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		config := makeConfig(hnswgo.L2Space, true)
+		config.IndexType = hnswgo.FlatIndex
+		hnsw := hnswgo.New(dir, config, zerolog.Nop())
+		_, err := hnsw.AddPointAutoID(sampleVectors[0])
+		require.NoError(t, err)
+		require.NoError(t, hnsw.Save(ctx))
+
+		in, err := hnswgo.Inspect(dir)
+		require.NoError(t, err)
+		assert.Equal(t, &hnswgo.IndexHeader{File: "index", Format: hnswgo.FlatFormat, Elements: 1, Dim: 5}, in.Header)
+	})
+
+	t.Run("quantized index which is not trained", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		config := makeConfig(hnswgo.L2Space, true)
+		config.Storage = hnswgo.SQ8Storage
+		hnsw := hnswgo.New(dir, config, zerolog.Nop())
+		require.NoError(t, hnsw.Save(ctx))
+
+		in, err := hnswgo.Inspect(dir)
+		require.NoError(t, err)
+		assert.Equal(t, &hnswgo.IndexHeader{File: "training", Format: hnswgo.FlatFormat, Dim: 5}, in.Header)
+	})
+
+	t.Run("undecodable state", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		createAndSaveSampleIndex(t, dir)
+		require.NoError(t, os.WriteFile(path.Join(dir, "state"), []byte("foo"), 0666))
+
+		in, err := hnswgo.Inspect(dir)
+		require.NoError(t, err)
+		assert.Error(t, in.StateErr)
+		assert.Nil(t, in.State)
+		assert.Nil(t, in.Header)
+		assert.Len(t, in.Files, 2)
+	})
+
+	t.Run("nonexistent dir", func(t *testing.T) {
+		t.Parallel()
+		_, err := hnswgo.Inspect(path.Join("testdata", "foo"))
+		assert.Error(t, err)
+	})
+}
+
+func fileNames(files []hnswgo.FileInfo) []string {
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = file.Name
+	}
+	return names
+}
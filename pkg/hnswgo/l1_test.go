@@ -0,0 +1,94 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo_test
+
+import (
+	"fmt"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"testing"
+)
+
+func TestHNSW_L1Space(t *testing.T) {
+	t.Parallel()
+
+	l1 := func(a, b []float32) float32 {
+		var sum float32
+		for i := range a {
+			if a[i] > b[i] {
+				sum += a[i] - b[i]
+			} else {
+				sum += b[i] - a[i]
+			}
+		}
+		return sum
+	}
+
+	// The dimensions select the scalar and each SIMD distance function.
+	for _, dim := range []int{3, 8, 13, 32, 37} {
+		for _, indexType := range []hnswgo.IndexType{hnswgo.HNSWIndex, hnswgo.FlatIndex} {
+			dim, indexType := dim, indexType
+			r := rand.New(rand.NewSource(int64(dim)))
+			vectors := make([][]float32, 50)
+			ids := make([]uint32, len(vectors))
+			for i := range vectors {
+				vectors[i] = make([]float32, dim)
+				for j := range vectors[i] {
+					vectors[i][j] = r.Float32()*2 - 1
+				}
+				ids[i] = uint32(i + 1)
+			}
+
+			t.Run(fmt.Sprintf("%s %d", indexType, dim), func(t *testing.T) {
+				t.Parallel()
+				dir := createTempDir(t)
+				defer deleteDir(t, dir)
+
+				hnsw := hnswgo.New(dir, hnswgo.Config{
+					SpaceType:      hnswgo.L1Space,
+					Dim:            dim,
+					MaxElements:    100,
+					M:              8,
+					EfConstruction: 100,
+					RandSeed:       100,
+					IndexType:      indexType,
+				}, zerolog.Nop())
+				require.NoError(t, hnsw.AddPoints(ctx, vectors, ids, 2))
+
+				vector, found := hnsw.GetVector(1)
+				require.True(t, found)
+				assert.Equal(t, vectors[0], vector)
+
+				query := append([]float32(nil), vectors[1]...)
+				query[0] += 0.01
+				results := hnsw.SearchKNNExact(query, 10)
+				require.Len(t, results, 10)
+				assert.Equal(t, ids[1], results[0].ID)
+				for _, result := range results {
+					assert.InDelta(t, l1(query, vectors[result.ID-1]), result.Distance, 1e-4)
+				}
+				assert.Equal(t, results[:1], hnsw.SearchKNN(query, 1))
+
+				require.NoError(t, hnsw.Save(ctx))
+				loaded, err := hnswgo.Load(dir, zerolog.Nop())
+				require.NoError(t, err)
+				assert.Equal(t, results, loaded.SearchKNNExact(query, 10))
+			})
+		}
+	}
+}
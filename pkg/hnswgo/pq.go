@@ -0,0 +1,296 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+const (
+	// DefaultPQTrainingSize is the amount of vectors on which the codebooks
+	// of a PQStorage index are trained, unless set otherwise.
+	DefaultPQTrainingSize = 10000
+	// DefaultPQBits is the size of the codes of PQStorage indices, unless
+	// set otherwise.
+	DefaultPQBits = 8
+)
+
+// pqIterations is the maximum number of k-means iterations performed to
+// train each codebook.
+const pqIterations = 25
+
+// pqQuantizer splits the vectors into m sub-vectors, and encodes each one
+// with the nearest of the 2^bits centroids of its subspace (its codebook).
+//
+// The stored data is a zero byte, followed by the codes, packed with the
+// least significant bits first. The query data is a non-zero byte, followed
+// at offset 4 by the distances (or the inner products, in an inner product
+// space) between each sub-vector of the query and each centroid of its
+// subspace: see space_pq.h.
+type pqQuantizer struct {
+	dim          int
+	m            int
+	bits         int
+	innerProduct bool
+	// centroids holds the 2^bits centroids of each subspace, in order.
+	centroids []float32
+}
+
+var _ quantizer = &pqQuantizer{}
+
+func newPQQuantizer(config Config) *pqQuantizer {
+	q := &pqQuantizer{
+		dim:          config.Dim,
+		m:            config.PQSubquantizers,
+		bits:         config.PQBits,
+		innerProduct: config.SpaceType != L2Space,
+	}
+	if q.bits <= 0 {
+		q.bits = DefaultPQBits
+	}
+	return q
+}
+
+// trainPQQuantizer runs k-means on each subspace of the given vectors,
+// stored contiguously, to find the centroids. Subspaces are trained
+// concurrently, each one with its own deterministic random source.
+func trainPQQuantizer(config Config, data []float32) *pqQuantizer {
+	q := newPQQuantizer(config)
+	ksub, dsub := q.ksub(), q.dsub()
+	q.centroids = make([]float32, q.m*ksub*dsub)
+	n := len(data) / q.dim
+
+	subspaces := make(chan int, q.m)
+	for j := 0; j < q.m; j++ {
+		subspaces <- j
+	}
+	close(subspaces)
+
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0) && w < q.m; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			points := make([]float32, n*dsub)
+			for j := range subspaces {
+				for i := 0; i < n; i++ {
+					copy(points[i*dsub:(i+1)*dsub], data[i*q.dim+j*dsub:])
+				}
+				r := rand.New(rand.NewSource(int64(config.RandSeed + j)))
+				kmeans(points, dsub, q.centroids[j*ksub*dsub:(j+1)*ksub*dsub], r)
+			}
+		}()
+	}
+	wg.Wait()
+	return q
+}
+
+// kmeans clusters the points, stored contiguously, filling the centroids.
+// Centroids are initialized with distinct random points, if there are
+// enough of them, and clusters left empty are moved to random points.
+func kmeans(points []float32, dim int, centroids []float32, r *rand.Rand) {
+	n, k := len(points)/dim, len(centroids)/dim
+	perm := r.Perm(n)
+	for c := 0; c < k; c++ {
+		i := perm[c%n]
+		copy(centroids[c*dim:(c+1)*dim], points[i*dim:(i+1)*dim])
+	}
+
+	assignments := make([]int, n)
+	counts := make([]int, k)
+	for iteration := 0; iteration < pqIterations; iteration++ {
+		changed := false
+		for i := range assignments {
+			c := nearestCentroid(points[i*dim:(i+1)*dim], centroids, dim)
+			if c != assignments[i] || iteration == 0 {
+				changed = true
+			}
+			assignments[i] = c
+		}
+		if !changed {
+			return
+		}
+
+		for i := range centroids {
+			centroids[i] = 0
+		}
+		for c := range counts {
+			counts[c] = 0
+		}
+		for i, c := range assignments {
+			counts[c]++
+			centroid := centroids[c*dim : (c+1)*dim]
+			for d, v := range points[i*dim : (i+1)*dim] {
+				centroid[d] += v
+			}
+		}
+		for c, count := range counts {
+			centroid := centroids[c*dim : (c+1)*dim]
+			if count == 0 {
+				i := r.Intn(n)
+				copy(centroid, points[i*dim:(i+1)*dim])
+				continue
+			}
+			for d := range centroid {
+				centroid[d] /= float32(count)
+			}
+		}
+	}
+}
+
+// nearestCentroid returns the index of the centroid with the smallest
+// Euclidean distance from the vector.
+func nearestCentroid(vector []float32, centroids []float32, dim int) int {
+	nearest, minDistance := 0, float32(0)
+	for c := 0; c < len(centroids)/dim; c++ {
+		d := l2Distance(vector, centroids[c*dim:(c+1)*dim])
+		if c == 0 || d < minDistance {
+			nearest, minDistance = c, d
+		}
+	}
+	return nearest
+}
+
+func (q *pqQuantizer) ksub() int {
+	return 1 << q.bits
+}
+
+func (q *pqQuantizer) dsub() int {
+	return q.dim / q.m
+}
+
+// centroid returns the centroid with the given code of a subspace.
+func (q *pqQuantizer) centroid(subspace, code int) []float32 {
+	dsub := q.dsub()
+	offset := (subspace*q.ksub() + code) * dsub
+	return q.centroids[offset : offset+dsub]
+}
+
+// encode returns the codes of the nearest centroids of the sub-vectors.
+func (q *pqQuantizer) encode(vector []float32) []byte {
+	data := make([]byte, q.dataSize())
+	ksub, dsub := q.ksub(), q.dsub()
+	for j := 0; j < q.m; j++ {
+		codebook := q.centroids[j*ksub*dsub : (j+1)*ksub*dsub]
+		code := nearestCentroid(vector[j*dsub:(j+1)*dsub], codebook, dsub)
+		bit := j * q.bits
+		v := code << (bit & 7)
+		data[1+bit>>3] |= byte(v)
+		if bit&7+q.bits > 8 {
+			data[2+bit>>3] |= byte(v >> 8)
+		}
+	}
+	return data
+}
+
+// decode returns the concatenation of the centroids of the codes.
+func (q *pqQuantizer) decode(data []byte) []float32 {
+	vector := make([]float32, 0, q.dim)
+	for j := 0; j < q.m; j++ {
+		bit := j * q.bits
+		v := int(data[1+bit>>3])
+		if bit&7+q.bits > 8 {
+			v |= int(data[2+bit>>3]) << 8
+		}
+		code := (v >> (bit & 7)) & (q.ksub() - 1)
+		vector = append(vector, q.centroid(j, code)...)
+	}
+	return vector
+}
+
+// query returns the table of the distances between the sub-vectors and the
+// centroids, for asymmetric distance computation.
+func (q *pqQuantizer) query(vector []float32) []byte {
+	ksub, dsub := q.ksub(), q.dsub()
+	data := make([]byte, 4*(1+q.m*ksub))
+	data[0] = 1
+	table := unsafe.Slice((*float32)(unsafe.Pointer(&data[4])), q.m*ksub)
+	for j := 0; j < q.m; j++ {
+		sub := vector[j*dsub : (j+1)*dsub]
+		for c := 0; c < ksub; c++ {
+			if q.innerProduct {
+				table[j*ksub+c] = 1 - ipDistance(sub, q.centroid(j, c))
+			} else {
+				table[j*ksub+c] = l2Distance(sub, q.centroid(j, c))
+			}
+		}
+	}
+	return data
+}
+
+func (q *pqQuantizer) dataSize() int {
+	return 1 + (q.m*q.bits+7)/8
+}
+
+// save writes the dimension, the number of sub-quantizers and the size of
+// the codes, followed by all the centroids, in little-endian order.
+func (q *pqQuantizer) save(name string) (err error) {
+	file, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("error creating file %#v: %w", name, err)
+	}
+	defer func() {
+		if e := file.Close(); e != nil && err == nil {
+			err = fmt.Errorf("error closing file %#v: %w", name, e)
+		}
+	}()
+	w := bufio.NewWriter(file)
+
+	header := [3]uint64{uint64(q.dim), uint64(q.m), uint64(q.bits)}
+	for _, v := range []interface{}{header, q.centroids} {
+		if err = binary.Write(w, binary.LittleEndian, v); err != nil {
+			return fmt.Errorf("error writing codebooks %#v: %w", name, err)
+		}
+	}
+	if err = w.Flush(); err != nil {
+		return fmt.Errorf("error writing codebooks %#v: %w", name, err)
+	}
+	return nil
+}
+
+// loadPQQuantizer reads the codebooks written by pqQuantizer.save.
+func loadPQQuantizer(filename string, config Config) (_ *pqQuantizer, err error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %#v: %w", filename, err)
+	}
+	defer func() {
+		if e := file.Close(); e != nil && err == nil {
+			err = fmt.Errorf("error closing file %#v: %w", filename, e)
+		}
+	}()
+	r := bufio.NewReader(file)
+
+	q := newPQQuantizer(config)
+	var header [3]uint64
+	if err = binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("error reading codebooks %#v: %w", filename, err)
+	}
+	if header != [3]uint64{uint64(q.dim), uint64(q.m), uint64(q.bits)} {
+		return nil, fmt.Errorf("codebooks %#v do not match the configuration of the index", filename)
+	}
+	q.centroids = make([]float32, q.ksub()*q.dim)
+	if err = binary.Read(r, binary.LittleEndian, q.centroids); err != nil {
+		return nil, fmt.Errorf("error reading codebooks %#v: %w", filename, err)
+	}
+	return q, nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo_test
+
+import (
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestHNSW_PQStorage(t *testing.T) {
+	t.Parallel()
+
+	makePQConfig := func(spaceType hnswgo.SpaceType, rerank bool) hnswgo.Config {
+		return hnswgo.Config{
+			SpaceType:       spaceType,
+			Dim:             16,
+			MaxElements:     100,
+			M:               8,
+			EfConstruction:  100,
+			RandSeed:        100,
+			Storage:         hnswgo.PQStorage,
+			TrainingSize:    40,
+			PQSubquantizers: 8,
+			PQBits:          4,
+			Rerank:          rerank,
+		}
+	}
+
+	r := rand.New(rand.NewSource(1))
+	vectors := make([][]float32, 80)
+	ids := make([]uint32, len(vectors))
+	for i := range vectors {
+		vectors[i] = make([]float32, 16)
+		for j := range vectors[i] {
+			vectors[i][j] = r.Float32()
+		}
+		ids[i] = uint32(i + 1)
+	}
+
+	fileExists := func(t *testing.T, name string) bool {
+		t.Helper()
+		_, err := os.Stat(name)
+		if os.IsNotExist(err) {
+			return false
+		}
+		require.NoError(t, err)
+		return true
+	}
+
+	t.Run("vectors are stored in full precision until trained", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := hnswgo.New(dir, makePQConfig(hnswgo.L2Space, false), zerolog.Nop())
+		require.NoError(t, hnsw.AddPoints(ctx, vectors[:39], ids[:39], 2))
+		vector, found := hnsw.GetVector(1)
+		require.True(t, found)
+		assert.Equal(t, vectors[0], vector)
+
+		require.NoError(t, hnsw.Save(ctx))
+		assert.True(t, fileExists(t, path.Join(dir, "training")))
+		assert.False(t, fileExists(t, path.Join(dir, "index")))
+		assert.False(t, fileExists(t, path.Join(dir, "codebooks")))
+
+		loaded, err := hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		vector, found = loaded.GetVector(1)
+		require.True(t, found)
+		assert.Equal(t, vectors[0], vector)
+
+		require.NoError(t, loaded.AddPoint(vectors[39], ids[39]))
+		vector, found = loaded.GetVector(1)
+		require.True(t, found)
+		assert.Len(t, vector, 16)
+		assert.NotEqual(t, vectors[0], vector)
+	})
+
+	t.Run("quantized vectors are searched", func(t *testing.T) {
+		t.Parallel()
+
+		for _, spaceType := range []hnswgo.SpaceType{hnswgo.L2Space, hnswgo.IPSpace} {
+			dir := createTempDir(t)
+			defer deleteDir(t, dir)
+
+			hnsw := hnswgo.New(dir, makePQConfig(spaceType, false), zerolog.Nop())
+			require.NoError(t, hnsw.AddPoints(ctx, vectors, ids, 2))
+
+			for _, query := range vectors[:5] {
+				assert.Equal(t, hnsw.SearchKNNExact(query, 1), hnsw.SearchKNN(query, 1), spaceType)
+			}
+			assert.Len(t, hnsw.SearchKNN(vectors[0], 10), 10, spaceType)
+		}
+	})
+
+	t.Run("the codebooks are saved", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := hnswgo.New(dir, makePQConfig(hnswgo.L2Space, false), zerolog.Nop())
+		require.NoError(t, hnsw.AddPoints(ctx, vectors[:30], ids[:30], 2))
+		require.NoError(t, hnsw.Save(ctx))
+		require.NoError(t, hnsw.AddPoints(ctx, vectors[30:60], ids[30:60], 2))
+		require.NoError(t, hnsw.Save(ctx))
+		assert.True(t, fileExists(t, path.Join(dir, "index")))
+		assert.True(t, fileExists(t, path.Join(dir, "codebooks")))
+		assert.False(t, fileExists(t, path.Join(dir, "training")))
+		require.NoError(t, hnsw.AddPoints(ctx, vectors[60:], ids[60:], 2))
+
+		loaded, err := hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		assert.Equal(t, hnsw.IDs(), loaded.IDs())
+		for _, id := range []uint32{1, 70} {
+			expected, found := hnsw.GetVector(id)
+			require.True(t, found)
+			actual, found := loaded.GetVector(id)
+			require.True(t, found)
+			assert.Equal(t, expected, actual)
+		}
+		assert.Equal(t, hnsw.SearchKNNExact(vectors[0], 5), loaded.SearchKNNExact(vectors[0], 5))
+	})
+
+	t.Run("full-precision vectors re-rank the results", func(t *testing.T) {
+		t.Parallel()
+
+		for _, spaceType := range []hnswgo.SpaceType{hnswgo.L2Space, hnswgo.IPSpace} {
+			dir := createTempDir(t)
+			defer deleteDir(t, dir)
+
+			hnsw := hnswgo.New(path.Join(dir, "pq"), makePQConfig(spaceType, true), zerolog.Nop())
+			config := makePQConfig(spaceType, false)
+			config.Storage = hnswgo.Float32Storage
+			float32Index := hnswgo.New(path.Join(dir, "float32"), config, zerolog.Nop())
+			for _, h := range []*hnswgo.HNSW{hnsw, float32Index} {
+				require.NoError(t, h.Save(ctx))
+				require.NoError(t, h.AddPoints(ctx, vectors[:60], ids[:60], 2))
+			}
+			require.NoError(t, hnsw.Save(ctx))
+			require.NoError(t, hnsw.AddPoints(ctx, vectors[60:], ids[60:], 2))
+			require.NoError(t, float32Index.AddPoints(ctx, vectors[60:], ids[60:], 2))
+
+			loaded, err := hnswgo.Load(path.Join(dir, "pq"), zerolog.Nop())
+			require.NoError(t, err)
+			for _, h := range []*hnswgo.HNSW{hnsw, loaded} {
+				for _, id := range []uint32{1, 80} {
+					vector, found := h.GetVector(id)
+					require.True(t, found)
+					assert.Equal(t, vectors[id-1], vector)
+				}
+				for _, query := range vectors[:5] {
+					expected := float32Index.SearchKNNExact(query, 3)
+					actual := h.SearchKNNExact(query, 3)
+					require.Len(t, actual, len(expected))
+					for i := range expected {
+						assert.Equal(t, expected[i].ID, actual[i].ID, spaceType)
+						assert.InDelta(t, expected[i].Distance, actual[i].Distance, 1e-5, spaceType)
+					}
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,250 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo
+
+import (
+	"sync"
+)
+
+// quantizer converts the vectors to and from the format stored by the
+// native space of a quantized or half-precision index (see Config.Storage).
+type quantizer interface {
+	// encode returns the data stored for a vector.
+	encode(vector []float32) []byte
+	// decode returns the vector approximated by the stored data.
+	decode(data []byte) []float32
+	// query returns the data compared with the stored data when searching
+	// for a vector.
+	query(vector []float32) []byte
+	// dataSize is the size of the stored data.
+	dataSize() int
+}
+
+// trainQuantizer trains the quantizer of the configured storage on the
+// given vectors, stored contiguously.
+func trainQuantizer(config Config, data []float32) quantizer {
+	if config.Storage == PQStorage {
+		return trainPQQuantizer(config, data)
+	}
+	return trainSQ8Quantizer(data, config.Dim)
+}
+
+// trainingSize returns the amount of vectors on which the quantizer of the
+// configured storage is trained.
+func trainingSize(config Config) int {
+	switch {
+	case config.TrainingSize > 0:
+		return config.TrainingSize
+	case config.Storage == PQStorage:
+		return DefaultPQTrainingSize
+	default:
+		return DefaultSQ8TrainingSize
+	}
+}
+
+// quantizedIndex is the vectorIndex of HNSWIndex indices storing quantized
+// vectors.
+//
+// Until the quantizer is trained, the vectors are stored in full precision
+// by a flat index. Once it holds enough vectors, the quantizer is trained
+// on them, and they are moved to a native graph storing the encoded data.
+type quantizedIndex struct {
+	// mx is only locked for writing while training.
+	mx           sync.RWMutex
+	config       Config
+	trainingSize int
+	// Exactly one of flat and graph is set, depending on whether the
+	// quantizer is trained.
+	flat  *flatIndex
+	graph *graphIndex
+}
+
+var _ vectorIndex = &quantizedIndex{}
+
+func newQuantizedIndex(config Config) *quantizedIndex {
+	return &quantizedIndex{
+		config:       config,
+		trainingSize: trainingSize(config),
+		flat:         newFlatIndex(config),
+	}
+}
+
+// loadQuantizedIndex reads an index written by quantizedIndex.save: the
+// native graph if the quantizer is given, or the flat index of the vectors
+// stored until training otherwise. The native graph is memory-mapped if
+// mmap is true.
+func loadQuantizedIndex(filename string, config Config, quantizer quantizer, mmap bool) (*quantizedIndex, error) {
+	q := newQuantizedIndex(config)
+	if quantizer != nil {
+		q.flat = nil
+		graph, err := loadGraphIndex(filename, config, quantizer, false, mmap)
+		if err != nil {
+			return nil, err
+		}
+		q.graph = graph
+		return q, nil
+	}
+	flat, err := loadFlatIndex(filename, config)
+	if err != nil {
+		return nil, err
+	}
+	q.flat = flat
+	return q, nil
+}
+
+// quantizer returns the trained quantizer, or nil if it is not trained yet.
+func (q *quantizedIndex) quantizer() quantizer {
+	q.mx.RLock()
+	defer q.mx.RUnlock()
+	if q.graph == nil {
+		return nil
+	}
+	return q.graph.quantizer
+}
+
+// train trains the quantizer on the first vectors stored, once there are
+// enough of them, moving all the elements to the native graph, with their
+// deletion marks. It has no effect if the quantizer is already trained.
+//
+// No element must be reserved for replacement in the meantime (see
+// vectorIndex.reserveDeletedElement).
+func (q *quantizedIndex) train() {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	f := q.flat
+	if f == nil || len(f.labels) < q.trainingSize {
+		return
+	}
+	g := newGraphIndex(q.config, trainQuantizer(q.config, f.data[:q.trainingSize*f.dim]), false)
+	g.setEf(f.efValue)
+	// Elements are added in the order of their internal IDs, which are
+	// kept by the graph: the same elements are replaced first.
+	// The graph has the same capacity as the flat index.
+	for i, label := range f.labels {
+		_ = g.addPoint(f.vector(i), label)
+	}
+	for i, label := range f.labels {
+		if f.deleted[i] {
+			g.markDelete(label)
+		}
+	}
+	f.free()
+	q.flat, q.graph = nil, g
+}
+
+// current returns the index storing the vectors, with the function
+// unlocking it.
+func (q *quantizedIndex) current() (vectorIndex, func()) {
+	q.mx.RLock()
+	if q.graph != nil {
+		return q.graph, q.mx.RUnlock
+	}
+	return q.flat, q.mx.RUnlock
+}
+
+func (q *quantizedIndex) save(name string) error {
+	index, unlock := q.current()
+	defer unlock()
+	return index.save(name)
+}
+
+func (q *quantizedIndex) free() {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+	if q.graph != nil {
+		q.graph.free()
+	} else {
+		q.flat.free()
+	}
+}
+
+func (q *quantizedIndex) addPoint(vector []float32, id uint32) error {
+	index, unlock := q.current()
+	defer unlock()
+	return index.addPoint(vector, id)
+}
+
+func (q *quantizedIndex) reserveDeletedElement(id uint32) int64 {
+	index, unlock := q.current()
+	defer unlock()
+	return index.reserveDeletedElement(id)
+}
+
+func (q *quantizedIndex) replaceDeletedElement(vector []float32, id uint32, replaced int64) {
+	index, unlock := q.current()
+	defer unlock()
+	index.replaceDeletedElement(vector, id, replaced)
+}
+
+func (q *quantizedIndex) markDelete(id uint32) bool {
+	index, unlock := q.current()
+	defer unlock()
+	return index.markDelete(id)
+}
+
+func (q *quantizedIndex) unmarkDelete(id uint32) bool {
+	index, unlock := q.current()
+	defer unlock()
+	return index.unmarkDelete(id)
+}
+
+func (q *quantizedIndex) searchKNN(vector []float32, N int, exact bool) []KNNResult {
+	index, unlock := q.current()
+	defer unlock()
+	return index.searchKNN(vector, N, exact)
+}
+
+func (q *quantizedIndex) searchKNNWithEf(vector []float32, N int, ef int) []KNNResult {
+	index, unlock := q.current()
+	defer unlock()
+	return index.searchKNNWithEf(vector, N, ef)
+}
+
+func (q *quantizedIndex) ids() []uint32 {
+	index, unlock := q.current()
+	defer unlock()
+	return index.ids()
+}
+
+func (q *quantizedIndex) getVector(id uint32, includeDeleted bool) ([]float32, bool) {
+	index, unlock := q.current()
+	defer unlock()
+	return index.getVector(id, includeDeleted)
+}
+
+func (q *quantizedIndex) count() int {
+	index, unlock := q.current()
+	defer unlock()
+	return index.count()
+}
+
+func (q *quantizedIndex) memoryUsage() int64 {
+	index, unlock := q.current()
+	defer unlock()
+	return index.memoryUsage()
+}
+
+func (q *quantizedIndex) setEf(ef int) {
+	index, unlock := q.current()
+	defer unlock()
+	index.setEf(ef)
+}
+
+func (q *quantizedIndex) ef() int {
+	index, unlock := q.current()
+	defer unlock()
+	return index.ef()
+}
@@ -0,0 +1,282 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// RecallEvaluation is the result of HNSW.EvaluateRecall.
+type RecallEvaluation struct {
+	// Queries is the amount of queries evaluated.
+	Queries int
+	// Ef is the "ef" parameter used by the approximate searches.
+	Ef int
+	// Recall is the mean fraction of the exact K nearest neighbors which
+	// are also found by the approximate search (recall@K).
+	Recall float64
+	// ApproximateLatency is the mean duration of the approximate searches.
+	ApproximateLatency time.Duration
+	// ExactLatency is the mean duration of the exact searches.
+	ExactLatency time.Duration
+}
+
+// EvaluateRecall compares the results of SearchKNN, at the current "ef",
+// with the ones of SearchKNNExact for each query, and returns the recall@k
+// and the latency of both searches.
+func (h *HNSW) EvaluateRecall(ctx context.Context, queries [][]float32, k int) (RecallEvaluation, error) {
+	h, unlock, err := h.acquire()
+	if err != nil {
+		return RecallEvaluation{}, err
+	}
+	defer unlock()
+
+	e, err := h.newRecallEvaluator(ctx, queries, k)
+	if err != nil {
+		return RecallEvaluation{}, err
+	}
+	return e.evaluate(ctx, h, h.ef())
+}
+
+// EfTuning is the result of HNSW.AutoTuneEf.
+type EfTuning struct {
+	// Ef is the chosen "ef" parameter, which is set on the index.
+	Ef int
+	// Recall is the recall@k measured with Ef.
+	Recall float64
+	// TargetReached reports whether Recall meets the target recall.
+	TargetReached bool
+	// Evaluations are the measurements taken for each "ef" parameter
+	// evaluated, in ascending order of "ef".
+	Evaluations []RecallEvaluation
+}
+
+// AutoTuneEf looks for the smallest "ef" parameter whose recall@k on the
+// given queries, measured as by EvaluateRecall, meets the target recall,
+// and sets it (see SetEf).
+//
+// The values from k (smaller values behave the same) up to maxEf are binary
+// searched, assuming that the recall grows with "ef". If even maxEf does
+// not meet the target, it is chosen anyway. Searches performed in the
+// meantime keep using the current "ef".
+//
+// The index is only locked while each "ef" is evaluated, so that it can be
+// saved in the meantime.
+func (h *HNSW) AutoTuneEf(ctx context.Context, queries [][]float32, k int, targetRecall float64, maxEf int) (EfTuning, error) {
+	if targetRecall <= 0 || targetRecall > 1 {
+		return EfTuning{}, fmt.Errorf("invalid target recall %g: it must be in (0, 1]", targetRecall)
+	}
+	if maxEf <= 0 {
+		return EfTuning{}, fmt.Errorf("invalid maximum ef %d: it must be positive", maxEf)
+	}
+
+	a, unlock, err := h.acquire()
+	if err != nil {
+		return EfTuning{}, err
+	}
+	e, err := a.newRecallEvaluator(ctx, queries, k)
+	unlock()
+	if err != nil {
+		return EfTuning{}, err
+	}
+
+	evaluations := make(map[int]RecallEvaluation)
+	evaluate := func(ef int) (RecallEvaluation, error) {
+		if ev, ok := evaluations[ef]; ok {
+			return ev, nil
+		}
+		a, unlock, err := h.acquire()
+		if err != nil {
+			return RecallEvaluation{}, err
+		}
+		ev, err := e.evaluate(ctx, a, ef)
+		unlock()
+		if err == nil {
+			evaluations[ef] = ev
+		}
+		return ev, err
+	}
+
+	lo, hi := k, maxEf
+	if hi < lo {
+		hi = lo
+	}
+	ev, err := evaluate(hi)
+	if err != nil {
+		return EfTuning{}, err
+	}
+	if ev.Recall >= targetRecall {
+		for lo < hi {
+			mid := lo + (hi-lo)/2
+			ev, err = evaluate(mid)
+			if err != nil {
+				return EfTuning{}, err
+			}
+			if ev.Recall >= targetRecall {
+				hi = mid
+			} else {
+				lo = mid + 1
+			}
+		}
+	}
+
+	tuning := EfTuning{
+		Ef:            hi,
+		Recall:        evaluations[hi].Recall,
+		TargetReached: evaluations[hi].Recall >= targetRecall,
+		Evaluations:   make([]RecallEvaluation, 0, len(evaluations)),
+	}
+	for _, ev := range evaluations {
+		tuning.Evaluations = append(tuning.Evaluations, ev)
+	}
+	sort.Slice(tuning.Evaluations, func(i, j int) bool {
+		return tuning.Evaluations[i].Ef < tuning.Evaluations[j].Ef
+	})
+
+	a, unlock, err = h.acquire()
+	if err != nil {
+		return EfTuning{}, err
+	}
+	defer unlock()
+	if err = a.setEf(tuning.Ef, !a.readOnly); err != nil {
+		return EfTuning{}, err
+	}
+	return tuning, nil
+}
+
+// recallEvaluator measures the recall of approximate searches with
+// different "ef" parameters, computing the exact results only once.
+type recallEvaluator struct {
+	// queries are normalized by HNSW.normalize.
+	queries [][]float32
+	k       int
+	// exact are the IDs of the exact results of each query.
+	exact        []map[uint32]struct{}
+	exactLatency time.Duration
+}
+
+// newRecallEvaluator validates the queries, and performs the exact
+// searches. The index must be already acquired (see acquire).
+func (h *HNSW) newRecallEvaluator(ctx context.Context, queries [][]float32, k int) (*recallEvaluator, error) {
+	if k <= 0 {
+		return nil, fmt.Errorf("invalid k %d: it must be positive", k)
+	}
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("no queries to evaluate")
+	}
+	for _, query := range queries {
+		if err := h.ValidateVector(query); err != nil {
+			return nil, err
+		}
+	}
+
+	e := &recallEvaluator{
+		queries: make([][]float32, 0, len(queries)),
+		k:       k,
+		exact:   make([]map[uint32]struct{}, 0, len(queries)),
+	}
+	var exactTime time.Duration
+	for _, query := range queries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		query = h.normalize(query)
+		start := time.Now()
+		results := h.search(query, k, true, 0)
+		elapsed := time.Since(start)
+
+		// Queries without results are not evaluated, so their latency is
+		// not counted either, as for the approximate searches.
+		if len(results) == 0 {
+			continue
+		}
+		exactTime += elapsed
+		ids := make(map[uint32]struct{}, len(results))
+		for _, r := range results {
+			ids[r.ID] = struct{}{}
+		}
+		e.queries = append(e.queries, query)
+		e.exact = append(e.exact, ids)
+	}
+
+	if len(e.queries) == 0 {
+		return nil, fmt.Errorf("the index is empty")
+	}
+	e.exactLatency = exactTime / time.Duration(len(e.queries))
+	return e, nil
+}
+
+// evaluate performs the approximate searches with the given "ef" on the
+// index, which must be already acquired, and compares their results with
+// the exact ones.
+func (e *recallEvaluator) evaluate(ctx context.Context, h *HNSW, ef int) (RecallEvaluation, error) {
+	var recallSum float64
+	var approximateTime time.Duration
+	for i, query := range e.queries {
+		if err := ctx.Err(); err != nil {
+			return RecallEvaluation{}, err
+		}
+
+		start := time.Now()
+		results := h.search(query, e.k, false, ef)
+		approximateTime += time.Since(start)
+
+		hits := 0
+		for _, r := range results {
+			if _, ok := e.exact[i][r.ID]; ok {
+				hits++
+			}
+		}
+		recallSum += float64(hits) / float64(len(e.exact[i]))
+	}
+
+	n := len(e.queries)
+	return RecallEvaluation{
+		Queries:            n,
+		Ef:                 ef,
+		Recall:             recallSum / float64(n),
+		ApproximateLatency: approximateTime / time.Duration(n),
+		ExactLatency:       e.exactLatency,
+	}, nil
+}
+
+// SampleVectors returns up to n vectors, chosen at random among the elements
+// which are not marked deleted.
+//
+// In a cosine space, the returned vectors are the normalized ones.
+func (h *HNSW) SampleVectors(n int) [][]float32 {
+	if n <= 0 {
+		return nil
+	}
+	ids := h.IDs()
+	rand.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+
+	vectors := make([][]float32, 0, n)
+	for _, id := range ids {
+		if len(vectors) >= n {
+			break
+		}
+		// Elements deleted in the meantime are skipped.
+		if vector, ok := h.GetVector(id); ok {
+			vectors = append(vectors, vector)
+		}
+	}
+	return vectors
+}
@@ -0,0 +1,187 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo_test
+
+import (
+	"context"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"testing"
+)
+
+func TestHNSW_EvaluateRecall(t *testing.T) {
+	t.Parallel()
+
+	t.Run("recall grows with ef", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := newRandomHNSW(t, dir, 500)
+		queries := hnsw.SampleVectors(50)
+		require.Len(t, queries, 50)
+
+		require.NoError(t, hnsw.SetEf(1))
+		low, err := hnsw.EvaluateRecall(ctx, queries, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 50, low.Queries)
+		assert.Equal(t, 1, low.Ef)
+		assert.Less(t, low.Recall, 1.0)
+		assert.Positive(t, low.ApproximateLatency)
+		assert.Positive(t, low.ExactLatency)
+
+		require.NoError(t, hnsw.SetEf(500))
+		high, err := hnsw.EvaluateRecall(ctx, queries, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 500, high.Ef)
+		assert.Greater(t, high.Recall, low.Recall)
+		assert.InDelta(t, 1.0, high.Recall, 0.05)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := hnswgo.New(dir, makeConfig(hnswgo.L2Space, false), zerolog.Nop())
+		_, err := hnsw.EvaluateRecall(ctx, sampleVectors, 10)
+		assert.Error(t, err, "empty index")
+
+		require.NoError(t, hnsw.AddPoints(ctx, sampleVectors, []uint32{1, 2}, 1))
+		_, err = hnsw.EvaluateRecall(ctx, sampleVectors, 0)
+		assert.Error(t, err, "invalid k")
+		_, err = hnsw.EvaluateRecall(ctx, nil, 10)
+		assert.Error(t, err, "no queries")
+		_, err = hnsw.EvaluateRecall(ctx, [][]float32{{1, 2, 3}}, 10)
+		assert.Error(t, err, "invalid query")
+
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = hnsw.EvaluateRecall(canceledCtx, sampleVectors, 10)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestHNSW_AutoTuneEf(t *testing.T) {
+	t.Parallel()
+
+	t.Run("smallest ef meeting the target", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := newRandomHNSW(t, dir, 500)
+		queries := hnsw.SampleVectors(50)
+
+		tuning, err := hnsw.AutoTuneEf(ctx, queries, 10, 0.95, 500)
+		require.NoError(t, err)
+		assert.True(t, tuning.TargetReached)
+		assert.GreaterOrEqual(t, tuning.Recall, 0.95)
+		assert.Equal(t, tuning.Ef, hnsw.Ef())
+
+		require.NotEmpty(t, tuning.Evaluations)
+		assert.Equal(t, 500, tuning.Evaluations[len(tuning.Evaluations)-1].Ef)
+		for i, ev := range tuning.Evaluations {
+			assert.Equal(t, 50, ev.Queries)
+			if i > 0 {
+				assert.Greater(t, ev.Ef, tuning.Evaluations[i-1].Ef)
+			}
+			if ev.Ef < tuning.Ef {
+				assert.Less(t, ev.Recall, 0.95)
+			}
+			if ev.Ef == tuning.Ef {
+				assert.Equal(t, tuning.Recall, ev.Recall)
+			}
+		}
+
+		evaluation, err := hnsw.EvaluateRecall(ctx, queries, 10)
+		require.NoError(t, err)
+		assert.Equal(t, tuning.Recall, evaluation.Recall)
+	})
+
+	t.Run("unreachable target", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := newRandomHNSW(t, dir, 500)
+		tuning, err := hnsw.AutoTuneEf(ctx, hnsw.SampleVectors(50), 10, 1, 1)
+		require.NoError(t, err)
+		assert.False(t, tuning.TargetReached)
+		assert.Equal(t, 10, tuning.Ef)
+		assert.Less(t, tuning.Recall, 1.0)
+		assert.Len(t, tuning.Evaluations, 1)
+		assert.Equal(t, 10, hnsw.Ef())
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := hnswgo.New(dir, makeConfig(hnswgo.L2Space, false), zerolog.Nop())
+		require.NoError(t, hnsw.AddPoints(ctx, sampleVectors, []uint32{1, 2}, 1))
+		_, err := hnsw.AutoTuneEf(ctx, sampleVectors, 1, 0, 10)
+		assert.Error(t, err, "zero target")
+		_, err = hnsw.AutoTuneEf(ctx, sampleVectors, 1, 1.5, 10)
+		assert.Error(t, err, "target above one")
+		_, err = hnsw.AutoTuneEf(ctx, sampleVectors, 1, 0.9, 0)
+		assert.Error(t, err, "invalid maximum ef")
+		_, err = hnsw.AutoTuneEf(ctx, sampleVectors, 0, 0.9, 10)
+		assert.Error(t, err, "invalid k")
+	})
+}
+
+func TestHNSW_SampleVectors(t *testing.T) {
+	t.Parallel()
+	dir := createTempDir(t)
+	defer deleteDir(t, dir)
+
+	hnsw := hnswgo.New(dir, makeConfig(hnswgo.L2Space, false), zerolog.Nop())
+	assert.Empty(t, hnsw.SampleVectors(5))
+
+	require.NoError(t, hnsw.AddPoints(ctx, sampleVectors, []uint32{1, 2}, 1))
+	require.NoError(t, hnsw.MarkDelete(1))
+	assert.Equal(t, [][]float32{sampleVectors[1]}, hnsw.SampleVectors(5))
+	assert.Empty(t, hnsw.SampleVectors(0))
+}
+
+func newRandomHNSW(t *testing.T, dir string, size int) *hnswgo.HNSW {
+	t.Helper()
+	hnsw := hnswgo.New(dir, hnswgo.Config{
+		SpaceType:      hnswgo.L2Space,
+		Dim:            16,
+		MaxElements:    size,
+		M:              4,
+		EfConstruction: 10,
+		RandSeed:       100,
+		AutoIDEnabled:  true,
+	}, zerolog.Nop())
+
+	r := rand.New(rand.NewSource(1))
+	vectors := make([][]float32, size)
+	for i := range vectors {
+		vectors[i] = make([]float32, 16)
+		for j := range vectors[i] {
+			vectors[i][j] = r.Float32()
+		}
+	}
+	_, err := hnsw.AddPointsAutoID(ctx, vectors, 1)
+	require.NoError(t, err)
+	return hnsw
+}
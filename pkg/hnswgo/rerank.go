@@ -0,0 +1,220 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"syscall"
+)
+
+// rerankOversampling is the factor by which the amount of requested results
+// is multiplied when searching the quantized vectors, before re-ranking
+// them with the full-precision ones.
+const rerankOversampling = 4
+
+// vectorStore keeps the full-precision vectors of a quantized index on
+// disk, for re-ranking (see Config.Rerank). The file is memory-mapped, so
+// that the operating system only keeps its most used pages in memory. The
+// vectors added since the file was last written are kept in memory.
+//
+// The file holds the amount of vectors and the dimension, followed by all
+// the IDs and the vectors, in little-endian order.
+type vectorStore struct {
+	dim int
+	mx  sync.RWMutex
+	// mapping is nil until the store is written for the first time.
+	mapping []byte
+	// positions are the positions of the vectors in the file, by ID.
+	positions map[uint32]int
+	pending   map[uint32][]float32
+}
+
+func newVectorStore(dim int) *vectorStore {
+	return &vectorStore{
+		dim:       dim,
+		positions: make(map[uint32]int),
+		pending:   make(map[uint32][]float32),
+	}
+}
+
+// openVectorStore opens a file written by vectorStore.write.
+func openVectorStore(filename string, dim int) (*vectorStore, error) {
+	s := newVectorStore(dim)
+	if err := s.open(filename); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// open replaces the file of the store with the given one, which must hold
+// all the vectors, including the pending ones.
+func (s *vectorStore) open(filename string) (err error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error reading file %#v: %w", filename, err)
+	}
+	// The mapping stays valid once the file is closed.
+	defer func() {
+		if e := file.Close(); e != nil && err == nil {
+			err = fmt.Errorf("error closing file %#v: %w", filename, e)
+		}
+	}()
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("error reading file %#v: %w", filename, err)
+	}
+	if info.Size() < 16 {
+		return fmt.Errorf("vectors %#v are truncated", filename)
+	}
+	mapping, err := syscall.Mmap(int(file.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return fmt.Errorf("error mapping file %#v: %w", filename, err)
+	}
+
+	count := int(binary.LittleEndian.Uint64(mapping))
+	dim := int(binary.LittleEndian.Uint64(mapping[8:]))
+	switch {
+	case dim != s.dim:
+		err = fmt.Errorf("vectors %#v have dimension %d, expected %d", filename, dim, s.dim)
+	case len(mapping) != 16+count*4*(1+dim):
+		err = fmt.Errorf("vectors %#v are truncated", filename)
+	}
+	if err != nil {
+		_ = syscall.Munmap(mapping)
+		return err
+	}
+
+	positions := make(map[uint32]int, count)
+	for i := 0; i < count; i++ {
+		positions[binary.LittleEndian.Uint32(mapping[16+i*4:])] = i
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.close()
+	s.mapping, s.positions = mapping, positions
+	s.pending = make(map[uint32][]float32)
+	return nil
+}
+
+// close unmaps the file of the store, if any. It must not be called
+// concurrently with the other methods.
+func (s *vectorStore) close() {
+	if s.mapping != nil {
+		_ = syscall.Munmap(s.mapping)
+		s.mapping = nil
+	}
+}
+
+// put stores the vector of an element, replacing the existing one, if any.
+func (s *vectorStore) put(vector []float32, id uint32) {
+	v := make([]float32, len(vector))
+	copy(v, vector)
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.pending[id] = v
+}
+
+// get returns the vector of an element, and reports whether it is found.
+func (s *vectorStore) get(id uint32) ([]float32, bool) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	if v, ok := s.pending[id]; ok {
+		return v, true
+	}
+	i, ok := s.positions[id]
+	if !ok || s.mapping == nil {
+		return nil, false
+	}
+
+	offset := 16 + len(s.positions)*4 + i*s.dim*4
+	vector := make([]float32, s.dim)
+	for j := range vector {
+		vector[j] = math.Float32frombits(binary.LittleEndian.Uint32(s.mapping[offset+j*4:]))
+	}
+	return vector, true
+}
+
+// write writes the vectors of the elements for which keep returns true
+// to a new file, which can then replace the current one (see open).
+func (s *vectorStore) write(name string, keep func(id uint32) bool) (err error) {
+	s.mx.RLock()
+	ids := make([]uint32, 0, len(s.positions)+len(s.pending))
+	for id := range s.positions {
+		if _, ok := s.pending[id]; !ok && keep(id) {
+			ids = append(ids, id)
+		}
+	}
+	for id := range s.pending {
+		if keep(id) {
+			ids = append(ids, id)
+		}
+	}
+	s.mx.RUnlock()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	file, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("error creating file %#v: %w", name, err)
+	}
+	defer func() {
+		if e := file.Close(); e != nil && err == nil {
+			err = fmt.Errorf("error closing file %#v: %w", name, e)
+		}
+	}()
+	w := bufio.NewWriter(file)
+
+	header := [2]uint64{uint64(len(ids)), uint64(s.dim)}
+	for _, v := range []interface{}{header, ids} {
+		if err = binary.Write(w, binary.LittleEndian, v); err != nil {
+			return fmt.Errorf("error writing vectors %#v: %w", name, err)
+		}
+	}
+	for _, id := range ids {
+		vector, _ := s.get(id)
+		if err = binary.Write(w, binary.LittleEndian, vector); err != nil {
+			return fmt.Errorf("error writing vectors %#v: %w", name, err)
+		}
+	}
+	if err = w.Flush(); err != nil {
+		return fmt.Errorf("error writing vectors %#v: %w", name, err)
+	}
+	return nil
+}
+
+// rerank replaces the distances of the results with the ones computed on
+// the full-precision vectors, and returns the nearest N results. The
+// results whose vector is not found keep their distance.
+func (h *HNSW) rerank(vector []float32, results []KNNResult, N int) []KNNResult {
+	distance := spaceDistance(h.state.SpaceType)
+	for i, r := range results {
+		if original, found := h.originals.get(r.ID); found {
+			results[i].Distance = distance(vector, original)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Distance < results[j].Distance })
+	if len(results) > N {
+		results = results[:N]
+	}
+	return results
+}
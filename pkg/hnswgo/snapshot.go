@@ -0,0 +1,199 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/osutils"
+	"io"
+	"os"
+	"path"
+)
+
+const (
+	// previousDir is the subdir of an index dir where Save keeps the
+	// previous snapshot of the index, with the write-ahead log written
+	// after it.
+	previousDir = "previous"
+	// damagedDir is the subdir of an index dir where RestorePrevious moves
+	// the files it replaces.
+	damagedDir = "damaged"
+)
+
+// snapshotFiles are the files of an index dir written by Save, besides the
+// write-ahead log. Only some of them exist, depending on the index.
+var snapshotFiles = []string{"state", "codebooks", "index", "training", "vectors"}
+
+// ErrNoPreviousSnapshot is returned by RestorePrevious if the index dir has
+// no previous snapshot.
+var ErrNoPreviousSnapshot = errors.New("no previous snapshot")
+
+// keepPrevious hard-links the files of the snapshot in the index dir, and
+// its write-ahead log, into previousDir, replacing the previous snapshot
+// kept there, if any. Since the files are linked, and not copied, they are
+// only kept on disk once Save replaces them. It has no effect if the index
+// was never saved.
+func keepPrevious(dir string) error {
+	exists, err := osutils.FileExists(path.Join(dir, "state"))
+	if err != nil || !exists {
+		return err
+	}
+
+	tmpDir := path.Join(dir, previousDir+".tmp")
+	if err = os.RemoveAll(tmpDir); err != nil {
+		return fmt.Errorf("error removing dir %#v: %w", tmpDir, err)
+	}
+	if err = os.Mkdir(tmpDir, 0777); err != nil {
+		return fmt.Errorf("error creating dir %#v: %w", tmpDir, err)
+	}
+	for _, name := range append(snapshotFiles, "log") {
+		err = os.Link(path.Join(dir, name), path.Join(tmpDir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			_ = os.RemoveAll(tmpDir)
+			return fmt.Errorf("error linking file %#v: %w", name, err)
+		}
+	}
+
+	prevDir := path.Join(dir, previousDir)
+	if err = os.RemoveAll(prevDir); err != nil {
+		return fmt.Errorf("error removing dir %#v: %w", prevDir, err)
+	}
+	if err = os.Rename(tmpDir, prevDir); err != nil {
+		return fmt.Errorf("error moving dir %#v to %#v: %w", tmpDir, prevDir, err)
+	}
+	return nil
+}
+
+// removePrevious removes the previous snapshot kept in the index dir, if
+// any, which would no longer match the write-ahead log once Save replaces
+// the last snapshot without keeping it.
+func removePrevious(dir string) error {
+	prevDir := path.Join(dir, previousDir)
+	if err := os.RemoveAll(prevDir); err != nil {
+		return fmt.Errorf("error removing dir %#v: %w", prevDir, err)
+	}
+	return nil
+}
+
+// SetKeepPrevious sets whether Save keeps the previous snapshot of the
+// index, as LoadOptions.KeepPrevious, which is false for the indices
+// created by New. Since the previous snapshot is hard-linked, it takes no
+// additional disk space until the next Save replaces the files, which then
+// take about twice their size. Otherwise, Save removes the previous
+// snapshot, if any.
+func (h *HNSW) SetKeepPrevious(keep bool) {
+	h, unlock := h.acquireExclusive()
+	defer unlock()
+	h.keepPrevious = keep
+}
+
+// RestorePrevious replaces the snapshot of the index in dir with the
+// previous one, kept by Save, and prepends the write-ahead log written after
+// the previous snapshot to the current log, so that loading the index
+// replays all the operations performed since then. It allows repairing an
+// index whose last snapshot is damaged, without losing the operations in
+// the log. The replaced files and log are moved to the "damaged" subdir,
+// and the previous snapshot is kept.
+//
+// The index must not be loaded. If no previous snapshot was kept,
+// ErrNoPreviousSnapshot is returned.
+func RestorePrevious(dir string) error {
+	prevDir := path.Join(dir, previousDir)
+	exists, err := osutils.DirExists(prevDir)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNoPreviousSnapshot
+	}
+
+	logFile := path.Join(dir, "log")
+	tmpLogFile := path.Join(dir, "log.tmp")
+	err = concatFiles(tmpLogFile, path.Join(prevDir, "log"), logFile)
+	if err != nil {
+		_ = os.Remove(tmpLogFile)
+		return err
+	}
+
+	damaged := path.Join(dir, damagedDir)
+	if err = os.RemoveAll(damaged); err != nil {
+		return fmt.Errorf("error removing dir %#v: %w", damaged, err)
+	}
+	if err = os.Mkdir(damaged, 0777); err != nil {
+		return fmt.Errorf("error creating dir %#v: %w", damaged, err)
+	}
+	for _, name := range append(snapshotFiles, "log") {
+		err = os.Rename(path.Join(dir, name), path.Join(damaged, name))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error moving file %#v: %w", name, err)
+		}
+	}
+
+	if err = os.Rename(tmpLogFile, logFile); err != nil {
+		return fmt.Errorf("error moving file %#v to %#v: %w", tmpLogFile, logFile, err)
+	}
+	for _, name := range snapshotFiles {
+		err = os.Link(path.Join(prevDir, name), path.Join(dir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error linking file %#v: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// concatFiles writes the content of the given files, which may not exist,
+// to a new file.
+func concatFiles(name string, files ...string) (err error) {
+	out, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("error creating file %#v: %w", name, err)
+	}
+	defer func() {
+		if e := out.Close(); e != nil && err == nil {
+			err = fmt.Errorf("error closing file %#v: %w", name, e)
+		}
+	}()
+
+	for _, file := range files {
+		if err = appendFile(out, file); err != nil {
+			return err
+		}
+	}
+	if err = out.Sync(); err != nil {
+		return fmt.Errorf("error syncing file %#v: %w", name, err)
+	}
+	return nil
+}
+
+// appendFile copies the content of the given file, if it exists, to w.
+func appendFile(w io.Writer, name string) error {
+	file, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error opening file %#v: %w", name, err)
+	}
+	defer file.Close()
+
+	if _, err = io.Copy(w, file); err != nil {
+		return fmt.Errorf("error copying file %#v: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo_test
+
+import (
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestRestorePrevious(t *testing.T) {
+	t.Parallel()
+
+	t.Run("the previous snapshot and the logs are restored", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := hnswgo.New(dir, makeConfig(hnswgo.L2Space, true), zerolog.Nop())
+		hnsw.SetKeepPrevious(true)
+		require.NoError(t, hnsw.Save(ctx))
+		assert.NoDirExists(t, path.Join(dir, "previous"))
+
+		// Vectors added before the last saving are only in the previous log.
+		_, err := hnsw.AddPointAutoID(sampleVectors[0])
+		require.NoError(t, err)
+		require.NoError(t, hnsw.Save(ctx))
+		assert.FileExists(t, path.Join(dir, "previous", "state"))
+		assert.FileExists(t, path.Join(dir, "previous", "log"))
+
+		_, err = hnsw.AddPointAutoID(sampleVectors[1])
+		require.NoError(t, err)
+		require.NoError(t, hnsw.MarkDelete(1))
+		require.NoError(t, hnsw.Unload(ctx))
+		_, err = hnsw.AddPointAutoID(sampleVectors[0])
+		require.NoError(t, err)
+		require.NoError(t, hnsw.Unload(ctx))
+		_, err = hnsw.AddPointAutoID(sampleVectors[1])
+		require.NoError(t, err)
+		require.NoError(t, hnsw.MarkDelete(3))
+
+		// The last snapshot is damaged.
+		require.NoError(t, os.Remove(path.Join(dir, "index")))
+		_, err = hnswgo.Load(dir, zerolog.Nop())
+		require.Error(t, err)
+
+		require.NoError(t, hnswgo.RestorePrevious(dir))
+		assert.FileExists(t, path.Join(dir, "damaged", "state"))
+		assert.FileExists(t, path.Join(dir, "damaged", "log"))
+		assert.DirExists(t, path.Join(dir, "previous"))
+
+		restored, err := hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uint32{2, 4}, restored.IDs())
+		vector, found := restored.GetVector(4)
+		require.True(t, found)
+		assert.InDeltaSlice(t, sampleVectors[1], vector, 1e-6)
+	})
+
+	t.Run("the previous snapshot is only kept if enabled", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		createAndSaveSampleIndex(t, dir)
+
+		hnsw, err := hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		require.NoError(t, hnsw.Save(ctx))
+		assert.NoDirExists(t, path.Join(dir, "previous"))
+
+		hnsw, err = hnswgo.LoadWithOptions(dir, hnswgo.LoadOptions{KeepPrevious: true}, zerolog.Nop())
+		require.NoError(t, err)
+		require.NoError(t, hnsw.Save(ctx))
+		assert.FileExists(t, path.Join(dir, "previous", "state"))
+
+		// The previous snapshot would no longer match the log.
+		hnsw.SetKeepPrevious(false)
+		require.NoError(t, hnsw.Save(ctx))
+		assert.NoDirExists(t, path.Join(dir, "previous"))
+	})
+
+	t.Run("no previous snapshot", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		createAndSaveSampleIndex(t, dir)
+
+		assert.ErrorIs(t, hnswgo.RestorePrevious(dir), hnswgo.ErrNoPreviousSnapshot)
+		assert.FileExists(t, path.Join(dir, "index"))
+	})
+}
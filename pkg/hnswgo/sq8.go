@@ -0,0 +1,101 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo
+
+import (
+	"math"
+)
+
+// DefaultSQ8TrainingSize is the amount of vectors on which the quantizer of
+// an SQ8Storage index is trained, unless set otherwise.
+const DefaultSQ8TrainingSize = 1000
+
+// sq8Quantizer maps the values of each dimension linearly to the codes from
+// 0 to 255: the value of a code c for dimension i is Min[i] + c*Scale[i].
+//
+// Its fields are exported to be serialized with hnswState.
+type sq8Quantizer struct {
+	Min   []float32
+	Scale []float32
+}
+
+// trainSQ8Quantizer makes a quantizer covering the range of values of each
+// dimension of the given vectors, stored contiguously.
+func trainSQ8Quantizer(data []float32, dim int) *sq8Quantizer {
+	q := &sq8Quantizer{
+		Min:   make([]float32, dim),
+		Scale: make([]float32, dim),
+	}
+	max := make([]float32, dim)
+	for i := range q.Min {
+		q.Min[i] = float32(math.Inf(1))
+		max[i] = float32(math.Inf(-1))
+	}
+	for offset := 0; offset < len(data); offset += dim {
+		for i, v := range data[offset : offset+dim] {
+			if v < q.Min[i] {
+				q.Min[i] = v
+			}
+			if v > max[i] {
+				max[i] = v
+			}
+		}
+	}
+	for i := range q.Scale {
+		q.Scale[i] = (max[i] - q.Min[i]) / 255
+	}
+	return q
+}
+
+var _ quantizer = &sq8Quantizer{}
+
+// encode returns the codes closest to the values of the vector. Values out
+// of the trained range get the code of the nearest bound.
+func (q *sq8Quantizer) encode(vector []float32) []byte {
+	codes := make([]byte, len(vector))
+	for i, v := range vector {
+		if q.Scale[i] == 0 {
+			continue
+		}
+		c := math.Round(float64((v - q.Min[i]) / q.Scale[i]))
+		switch {
+		case c <= 0:
+			codes[i] = 0
+		case c >= 255:
+			codes[i] = 255
+		default:
+			codes[i] = uint8(c)
+		}
+	}
+	return codes
+}
+
+// decode returns the vector approximated by the codes.
+func (q *sq8Quantizer) decode(codes []byte) []float32 {
+	vector := make([]float32, len(codes))
+	for i, c := range codes {
+		vector[i] = q.Min[i] + float32(c)*q.Scale[i]
+	}
+	return vector
+}
+
+// query returns the codes of the vector: queries are quantized as well.
+func (q *sq8Quantizer) query(vector []float32) []byte {
+	return q.encode(vector)
+}
+
+func (q *sq8Quantizer) dataSize() int {
+	return len(q.Min)
+}
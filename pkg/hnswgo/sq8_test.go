@@ -0,0 +1,240 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo_test
+
+import (
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"path"
+	"testing"
+)
+
+func TestHNSW_SQ8Storage(t *testing.T) {
+	t.Parallel()
+
+	makeSQ8Config := func(spaceType hnswgo.SpaceType, rerank bool) hnswgo.Config {
+		return hnswgo.Config{
+			SpaceType:      spaceType,
+			Dim:            16,
+			MaxElements:    100,
+			M:              8,
+			EfConstruction: 100,
+			RandSeed:       100,
+			Storage:        hnswgo.SQ8Storage,
+			TrainingSize:   20,
+			Rerank:         rerank,
+		}
+	}
+
+	r := rand.New(rand.NewSource(1))
+	vectors := make([][]float32, 50)
+	ids := make([]uint32, len(vectors))
+	for i := range vectors {
+		vectors[i] = make([]float32, 16)
+		for j := range vectors[i] {
+			vectors[i][j] = r.Float32()
+		}
+		ids[i] = uint32(i + 1)
+	}
+	// The maximum error of a value quantized with the training vectors.
+	const maxError = 1.0 / 255 / 2
+
+	assertApproximated := func(t *testing.T, expected, actual []float32) {
+		t.Helper()
+		require.Len(t, actual, len(expected))
+		for i := range expected {
+			assert.InDelta(t, expected[i], actual[i], maxError+1e-6)
+		}
+	}
+
+	t.Run("vectors are stored in full precision until trained", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := hnswgo.New(dir, makeSQ8Config(hnswgo.L2Space, false), zerolog.Nop())
+		require.NoError(t, hnsw.Save(ctx))
+		require.NoError(t, hnsw.AddPoints(ctx, vectors[:19], ids[:19], 2))
+		vector, found := hnsw.GetVector(1)
+		require.True(t, found)
+		assert.Equal(t, vectors[0], vector)
+
+		require.NoError(t, hnsw.AddPoint(vectors[19], ids[19]))
+		vector, found = hnsw.GetVector(1)
+		require.True(t, found)
+		assert.NotEqual(t, vectors[0], vector)
+		assertApproximated(t, vectors[0], vector)
+	})
+
+	t.Run("quantized vectors are searched", func(t *testing.T) {
+		t.Parallel()
+
+		for _, spaceType := range []hnswgo.SpaceType{hnswgo.L2Space, hnswgo.CosineSpace} {
+			dir := createTempDir(t)
+			defer deleteDir(t, dir)
+
+			hnsw := hnswgo.New(dir, makeSQ8Config(spaceType, false), zerolog.Nop())
+			require.NoError(t, hnsw.Save(ctx))
+			require.NoError(t, hnsw.AddPoints(ctx, vectors, ids, 2))
+
+			for i, query := range vectors[:5] {
+				results := hnsw.SearchKNN(query, 1)
+				require.Len(t, results, 1, spaceType)
+				assert.Equal(t, ids[i], results[0].ID, spaceType)
+				assert.Equal(t, results, hnsw.SearchKNNExact(query, 1), spaceType)
+			}
+			assert.Len(t, hnsw.SearchKNN(vectors[0], 10), 10, spaceType)
+		}
+	})
+
+	t.Run("the quantizer is saved", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := hnswgo.New(dir, makeSQ8Config(hnswgo.L2Space, false), zerolog.Nop())
+		require.NoError(t, hnsw.Save(ctx))
+		require.NoError(t, hnsw.AddPoints(ctx, vectors[:30], ids[:30], 2))
+		require.NoError(t, hnsw.Save(ctx))
+		require.NoError(t, hnsw.AddPoints(ctx, vectors[30:], ids[30:], 2))
+
+		loaded, err := hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		assert.Equal(t, hnsw.IDs(), loaded.IDs())
+		for _, id := range []uint32{1, 40} {
+			expected, found := hnsw.GetVector(id)
+			require.True(t, found)
+			actual, found := loaded.GetVector(id)
+			require.True(t, found)
+			assert.Equal(t, expected, actual)
+		}
+		assert.Equal(t, hnsw.SearchKNNExact(vectors[0], 5), loaded.SearchKNNExact(vectors[0], 5))
+	})
+
+	t.Run("the quantizer is trained after loading", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := hnswgo.New(dir, makeSQ8Config(hnswgo.L2Space, false), zerolog.Nop())
+		require.NoError(t, hnsw.AddPoints(ctx, vectors[:10], ids[:10], 2))
+		require.NoError(t, hnsw.MarkDelete(2))
+		require.NoError(t, hnsw.Save(ctx))
+
+		loaded, err := hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		vector, found := loaded.GetVector(1)
+		require.True(t, found)
+		assert.Equal(t, vectors[0], vector)
+
+		require.NoError(t, loaded.AddPoints(ctx, vectors[10:20], ids[10:20], 2))
+		vector, found = loaded.GetVector(1)
+		require.True(t, found)
+		assertApproximated(t, vectors[0], vector)
+		_, found = loaded.GetVector(2)
+		assert.False(t, found)
+		assert.Len(t, loaded.IDs(), 19)
+	})
+
+	t.Run("deleted elements are replaced after training", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		config := makeSQ8Config(hnswgo.L2Space, false)
+		config.MaxElements = 20
+		config.AllowReplaceDeleted = true
+		hnsw := hnswgo.New(dir, config, zerolog.Nop())
+		require.NoError(t, hnsw.AddPoints(ctx, vectors[:19], ids[:19], 2))
+		require.NoError(t, hnsw.MarkDelete(5))
+		require.NoError(t, hnsw.AddPoint(vectors[19], ids[19]))
+
+		require.NoError(t, hnsw.AddPoint(vectors[20], ids[20]))
+		_, found := hnsw.GetVector(5)
+		assert.False(t, found)
+		found, err := hnsw.UnmarkDelete(5)
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.Len(t, hnsw.IDs(), 20)
+	})
+
+	t.Run("the maximum number of elements is enforced before training", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		config := makeSQ8Config(hnswgo.L2Space, false)
+		config.MaxElements = 10
+		hnsw := hnswgo.New(dir, config, zerolog.Nop())
+		require.NoError(t, hnsw.Save(ctx))
+		assert.Error(t, hnsw.AddPoints(ctx, vectors[:11], ids[:11], 2))
+		assert.Empty(t, hnsw.IDs())
+
+		require.NoError(t, hnsw.AddPoints(ctx, vectors[:10], ids[:10], 2))
+		assert.Error(t, hnsw.AddPoint(vectors[10], ids[10]))
+		assert.NoError(t, hnsw.AddPoint(vectors[10], ids[0]))
+
+		// The rejected vectors are not written to the log.
+		loaded, err := hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		assert.Equal(t, ids[:10], loaded.IDs())
+	})
+
+	t.Run("full-precision vectors re-rank the results", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := hnswgo.New(path.Join(dir, "sq8"), makeSQ8Config(hnswgo.L2Space, true), zerolog.Nop())
+		config := makeSQ8Config(hnswgo.L2Space, false)
+		config.Storage = hnswgo.Float32Storage
+		float32Index := hnswgo.New(path.Join(dir, "float32"), config, zerolog.Nop())
+		for _, h := range []*hnswgo.HNSW{hnsw, float32Index} {
+			require.NoError(t, h.Save(ctx))
+			require.NoError(t, h.AddPoints(ctx, vectors[:40], ids[:40], 2))
+		}
+		require.NoError(t, hnsw.Save(ctx))
+		require.NoError(t, hnsw.AddPoints(ctx, vectors[40:], ids[40:], 2))
+		require.NoError(t, float32Index.AddPoints(ctx, vectors[40:], ids[40:], 2))
+
+		assertReranked := func(t *testing.T, h *hnswgo.HNSW) {
+			t.Helper()
+			for _, id := range []uint32{1, 50} {
+				vector, found := h.GetVector(id)
+				require.True(t, found)
+				assert.Equal(t, vectors[id-1], vector)
+			}
+			for _, query := range vectors[:5] {
+				expected := float32Index.SearchKNNExact(query, 5)
+				actual := h.SearchKNNExact(query, 5)
+				require.Len(t, actual, len(expected))
+				for i := range expected {
+					assert.Equal(t, expected[i].ID, actual[i].ID)
+					assert.InDelta(t, expected[i].Distance, actual[i].Distance, 1e-5)
+				}
+			}
+		}
+		assertReranked(t, hnsw)
+
+		loaded, err := hnswgo.Load(path.Join(dir, "sq8"), zerolog.Nop())
+		require.NoError(t, err)
+		assertReranked(t, loaded)
+		require.NoError(t, loaded.Save(ctx))
+		assertReranked(t, loaded)
+	})
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// Unload saves the index, and releases its data structure, keeping only its
+// state in memory. The index can still be used: the next operation which
+// needs the data loads it again from the index dir (see Reload), with the
+// same LoadOptions. Unloading an unloaded index has no effect.
+//
+// Read-only indices are not saved: the "ef" parameter set since they were
+// loaded is lost.
+//
+// An index cannot be unloaded while it is being compacted, or while it is
+// pinned (see Pin).
+func (h *HNSW) Unload(ctx context.Context) error {
+	h, unlock := h.acquireExclusive()
+	defer unlock()
+	if h.unloaded {
+		return nil
+	}
+
+	h.journalMx.Lock()
+	compacting := h.compacting
+	h.journalMx.Unlock()
+	if compacting {
+		return fmt.Errorf("cannot unload an index while it is being compacted")
+	}
+	if atomic.LoadInt32(h.pins) > 0 {
+		return fmt.Errorf("cannot unload an index while it is pinned")
+	}
+
+	if !h.readOnly {
+		if err := h.save(ctx); err != nil {
+			return err
+		}
+	}
+	if err := h.log.Close(); err != nil {
+		return err
+	}
+	h.release()
+	return nil
+}
+
+// Reload loads the data structure of the index again from its dir, if it
+// was unloaded (see Unload) or loaded with LoadOptions.Lazy, replaying its
+// write-ahead log. Otherwise, it has no effect.
+//
+// The other operations load unloaded indices as well, returning the loading
+// error, if any. The ones which cannot return errors (e.g. SearchKNN) log
+// it, and behave as if the index were empty: Reload or Pin allow handling
+// the error beforehand.
+func (h *HNSW) Reload() error {
+	h, unlock := h.acquireExclusive()
+	defer unlock()
+	if !h.unloaded {
+		return nil
+	}
+	if err := h.load(); err != nil {
+		return fmt.Errorf("error loading index %#v: %w", h.dir, err)
+	}
+	return nil
+}
+
+// Pin loads the index, if it is unloaded, and prevents it from being
+// unloaded until the returned function is called, so that the operations
+// performed in the meantime cannot fail loading it. If the loading fails,
+// the error is returned.
+func (h *HNSW) Pin() (func(), error) {
+	for {
+		if unpin, ok := h.PinResident(); ok {
+			return unpin, nil
+		}
+		// The index might be unloaded again before it is pinned.
+		if err := h.Reload(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// PinResident is the same as Pin, but it does not load unloaded indices: it
+// reports whether the index is resident, and it was pinned.
+func (h *HNSW) PinResident() (func(), bool) {
+	h, unlock := h.peek()
+	defer unlock()
+	if h.unloaded {
+		return nil, false
+	}
+	pins := h.pins
+	atomic.AddInt32(pins, 1)
+	return func() { atomic.AddInt32(pins, -1) }, true
+}
+
+// Pinned reports whether the index is pinned (see Pin).
+func (h *HNSW) Pinned() bool {
+	h, unlock := h.peek()
+	defer unlock()
+	return atomic.LoadInt32(h.pins) > 0
+}
+
+// release frees the data structure of the index, and closes the store of
+// its full-precision vectors, if any, marking the index unloaded. The index
+// must be locked for writing, unless it is not shared yet.
+func (h *HNSW) release() {
+	if h.index != nil {
+		h.index.free()
+		h.index = nil
+	}
+	if h.originals != nil {
+		h.originals.close()
+		h.originals = nil
+	}
+	h.unloaded = true
+}
+
+// Resident reports whether the data structure of the index is loaded in
+// memory, that is, whether the index is not unloaded (see Unload).
+func (h *HNSW) Resident() bool {
+	h, unlock := h.peek()
+	defer unlock()
+	return !h.unloaded
+}
+
+// MemoryUsage estimates the memory taken by the vectors and the graph of
+// the index, in bytes. It is zero for unloaded indices.
+func (h *HNSW) MemoryUsage() int64 {
+	h, unlock := h.peek()
+	defer unlock()
+	if h.unloaded {
+		return 0
+	}
+	return h.index.memoryUsage()
+}
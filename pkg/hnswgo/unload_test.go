@@ -0,0 +1,164 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo_test
+
+import (
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestHNSW_Unload(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unloaded indices are loaded again by the next operation", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := newRandomHNSW(t, dir, 100)
+		query := hnsw.SampleVectors(1)[0]
+		results := hnsw.SearchKNN(query, 10)
+		assert.True(t, hnsw.Resident())
+		assert.Positive(t, hnsw.MemoryUsage())
+
+		// The vectors added since the last saving are saved.
+		require.NoError(t, hnsw.Unload(ctx))
+		assert.False(t, hnsw.Resident())
+		assert.Zero(t, hnsw.MemoryUsage())
+		assert.False(t, hnsw.Mapped())
+		assert.Equal(t, 16, hnsw.Config().Dim)
+		assert.FileExists(t, path.Join(dir, "index"))
+		assert.NoFileExists(t, path.Join(dir, "log"))
+
+		// Unloaded indices were already saved.
+		require.NoError(t, hnsw.Unload(ctx))
+		require.NoError(t, hnsw.Save(ctx))
+		assert.False(t, hnsw.Resident())
+
+		assert.Len(t, hnsw.IDs(), 100)
+		assert.True(t, hnsw.Resident())
+		assert.Equal(t, results, hnsw.SearchKNN(query, 10))
+
+		require.NoError(t, hnsw.MarkDelete(1))
+		assert.Len(t, hnsw.IDs(), 99)
+	})
+
+	t.Run("lazy loading", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		createAndSaveSampleIndex(t, dir)
+
+		hnsw, err := hnswgo.LoadWithOptions(dir, hnswgo.LoadOptions{Lazy: true}, zerolog.Nop())
+		require.NoError(t, err)
+		assert.False(t, hnsw.Resident())
+		assert.True(t, hnsw.Config().AutoIDEnabled)
+
+		require.NoError(t, hnsw.Reload())
+		assert.True(t, hnsw.Resident())
+		assert.ElementsMatch(t, []uint32{1, 2}, hnsw.IDs())
+		require.NoError(t, hnsw.Reload())
+	})
+
+	t.Run("loading errors", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		createAndSaveSampleIndex(t, dir)
+
+		hnsw, err := hnswgo.LoadWithOptions(dir, hnswgo.LoadOptions{Lazy: true}, zerolog.Nop())
+		require.NoError(t, err)
+		require.NoError(t, os.Remove(path.Join(dir, "index")))
+
+		assert.Error(t, hnsw.Reload())
+		assert.False(t, hnsw.Resident())
+
+		// The other operations return the error, or behave as if the index
+		// were empty.
+		_, err = hnsw.Pin()
+		assert.Error(t, err)
+		assert.Error(t, hnsw.MarkDelete(1))
+		_, err = hnsw.DeleteExpired(time.Now())
+		assert.Error(t, err)
+		assert.Empty(t, hnsw.SearchKNN(sampleVectors[0], 1))
+		assert.Empty(t, hnsw.IDs())
+		_, found := hnsw.GetVector(1)
+		assert.False(t, found)
+	})
+
+	t.Run("pinned indices cannot be unloaded", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		createAndSaveSampleIndex(t, dir)
+
+		hnsw, err := hnswgo.LoadWithOptions(dir, hnswgo.LoadOptions{Lazy: true}, zerolog.Nop())
+		require.NoError(t, err)
+		_, resident := hnsw.PinResident()
+		assert.False(t, resident)
+		assert.False(t, hnsw.Pinned())
+
+		unpin, err := hnsw.Pin()
+		require.NoError(t, err)
+		assert.True(t, hnsw.Resident())
+		assert.True(t, hnsw.Pinned())
+		assert.Error(t, hnsw.Unload(ctx))
+		assert.True(t, hnsw.Resident())
+
+		unpin()
+		assert.False(t, hnsw.Pinned())
+		assert.NoError(t, hnsw.Unload(ctx))
+		assert.False(t, hnsw.Resident())
+	})
+
+	t.Run("read-only indices are not saved", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		createAndSaveSampleIndex(t, dir)
+
+		hnsw, err := hnswgo.LoadWithOptions(dir, hnswgo.LoadOptions{ReadOnly: true}, zerolog.Nop())
+		require.NoError(t, err)
+		ef := hnsw.Ef()
+		require.NoError(t, hnsw.SetEf(ef+1))
+
+		require.NoError(t, hnsw.Unload(ctx))
+		assert.False(t, hnsw.Resident())
+		assert.Equal(t, ef, hnsw.Ef())
+		assert.True(t, hnsw.Mapped())
+		assert.True(t, hnsw.ReadOnly())
+	})
+
+	t.Run("indices being compacted cannot be unloaded", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		hnsw := newRandomHNSW(t, dir, 10)
+		c, err := hnsw.Compact(ctx, path.Join(dir, "compacted"), 1)
+		require.NoError(t, err)
+		assert.Error(t, hnsw.Unload(ctx))
+		assert.True(t, hnsw.Resident())
+
+		c.Abort()
+		assert.NoError(t, hnsw.Unload(ctx))
+	})
+}
@@ -0,0 +1,248 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo
+
+import (
+	"context"
+	"fmt"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/osutils"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/wal"
+	"github.com/rs/zerolog"
+	"path"
+)
+
+// GraphCheck reports the consistency of the native graph of an index, as
+// verified by Verify.
+type GraphCheck struct {
+	// Elements is the number of elements of the graph, including the ones
+	// marked deleted.
+	Elements uint64
+	// Deleted is the number of elements marked deleted.
+	Deleted uint64
+	// Connections is the number of links between the elements, at all
+	// levels.
+	Connections uint64
+	// InvalidLinks is the number of links to elements which do not exist.
+	InvalidLinks uint64
+	// SelfLinks is the number of links from an element to itself.
+	SelfLinks uint64
+	// DuplicateLinks is the number of links repeated in the same list.
+	DuplicateLinks uint64
+	// OversizedLists is the number of link lists longer than allowed by the
+	// M parameter. Only the allowed links are verified.
+	OversizedLists uint64
+	// InvalidLevels is the number of elements whose level exceeds the top
+	// level of the graph. Their links are not verified.
+	InvalidLevels uint64
+	// InvalidEntryPoint reports whether the entry point of the searches is
+	// not an element of the top level.
+	InvalidEntryPoint bool
+	// InconsistentLabels is the number of elements whose ID is not mapped
+	// to them, plus the number of IDs which are mapped to elements with
+	// another ID.
+	InconsistentLabels uint64
+	// Unreachable is the number of elements, besides the entry point,
+	// which are not linked by any other element. They cannot be found by
+	// approximate searches, but they do not make the graph inconsistent.
+	Unreachable uint64
+}
+
+// problems describes the inconsistencies of the graph.
+func (c GraphCheck) problems() []string {
+	var problems []string
+	for _, p := range []struct {
+		count uint64
+		what  string
+	}{
+		{c.InvalidLinks, "links to elements which do not exist"},
+		{c.SelfLinks, "links from an element to itself"},
+		{c.DuplicateLinks, "duplicate links"},
+		{c.OversizedLists, "link lists longer than allowed"},
+		{c.InvalidLevels, "elements above the top level"},
+		{c.InconsistentLabels, "IDs inconsistent with their elements"},
+	} {
+		if p.count > 0 {
+			problems = append(problems, fmt.Sprintf("graph: %d %s", p.count, p.what))
+		}
+	}
+	if c.InvalidEntryPoint {
+		problems = append(problems, "graph: invalid entry point")
+	}
+	return problems
+}
+
+// VerifyReport is the result of Verify.
+type VerifyReport struct {
+	// StateErr is the error decoding the state of the index, if any. If it
+	// is set, only the write-ahead log is verified.
+	StateErr error
+	// IndexErr is the error loading the data structure of the index, if
+	// any.
+	IndexErr error
+	// Graph is the verification of the native graph of the index, if it
+	// could be loaded. It is nil for flat indices, and for quantized
+	// indices which are not trained yet.
+	Graph *GraphCheck
+	// Log is the verification of the write-ahead log.
+	Log wal.LogCheck
+	// LogRepaired reports whether the corrupted part of the log was
+	// discarded.
+	LogRepaired bool
+}
+
+// Problems describes the problems found, which prevent the index from
+// being loaded, or make it behave incorrectly or lose data.
+func (r *VerifyReport) Problems() []string {
+	var problems []string
+	if r.StateErr != nil {
+		problems = append(problems, fmt.Sprintf("state: %v", r.StateErr))
+	}
+	if r.IndexErr != nil {
+		problems = append(problems, fmt.Sprintf("index: %v", r.IndexErr))
+	}
+	if r.Graph != nil {
+		problems = append(problems, r.Graph.problems()...)
+	}
+	if r.Log.Err != nil && !r.LogRepaired {
+		problems = append(problems, fmt.Sprintf("log: %v", r.Log.Err))
+	}
+	return problems
+}
+
+// Warnings describes the findings which are not problems, but may be
+// worth knowing, such as the repairs performed.
+func (r *VerifyReport) Warnings() []string {
+	var warnings []string
+	if r.Graph != nil && r.Graph.Unreachable > 0 {
+		warnings = append(warnings, fmt.Sprintf("graph: %d unreachable elements", r.Graph.Unreachable))
+	}
+	if r.LogRepaired {
+		warnings = append(warnings, fmt.Sprintf("log: discarded %d corrupted bytes after %d entries: %v",
+			r.Log.Size-r.Log.ValidSize, r.Log.Entries, r.Log.Err))
+	}
+	return warnings
+}
+
+// OK reports whether no problem was found, or all of them were repaired.
+func (r *VerifyReport) OK() bool {
+	return len(r.Problems()) == 0
+}
+
+// Verify verifies the integrity of the index in dir: that its state can be
+// decoded, that its data structure can be loaded, that the links of its
+// native graph and the IDs of its elements are consistent, and that its
+// write-ahead log can be decoded. The problems found are reported, while
+// the returned error is only set if the verification could not be
+// performed.
+//
+// If repair is true and the end of the log is corrupted, for example
+// because an entry was only partially written, it is discarded (see
+// wal.Log.Truncate), so that the entries appended to the log afterwards
+// are not lost.
+//
+// The index must not be loaded: loaded indices can be verified with
+// HNSW.Verify. The native graph is memory-mapped while it is verified.
+func Verify(dir string, repair bool, logger zerolog.Logger) (*VerifyReport, error) {
+	exists, err := osutils.DirExists(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("index dir %#v not found", dir)
+	}
+
+	report := &VerifyReport{}
+	state, err := loadState(dir, logger)
+	if err != nil {
+		report.StateErr = err
+	} else {
+		report.IndexErr = verifyIndex(dir, state, report, logger)
+	}
+
+	log := wal.NewLog(path.Join(dir, "log"))
+	report.Log, err = log.Check(nil)
+	if err != nil {
+		return nil, err
+	}
+	if repair && report.Log.Err != nil {
+		if err = log.Truncate(report.Log.ValidSize); err != nil {
+			return nil, err
+		}
+		report.LogRepaired = true
+	}
+	return report, nil
+}
+
+// verifyIndex loads the data structure of the index, and verifies its
+// native graph, if any, setting VerifyReport.Graph. It returns the loading
+// error.
+func verifyIndex(dir string, state *hnswState, report *VerifyReport, logger zerolog.Logger) error {
+	index, err := loadIndex(dir, state, true, logger)
+	if err != nil {
+		return err
+	}
+	defer index.free()
+
+	var graph *graphIndex
+	switch i := index.(type) {
+	case *graphIndex:
+		graph = i
+	case *quantizedIndex:
+		graph = i.graph
+	}
+	if graph != nil {
+		check := graph.check()
+		report.Graph = &check
+	}
+
+	if state.reranks() {
+		originals, err := openVectorStore(path.Join(dir, "vectors"), state.Dim)
+		if err != nil {
+			return err
+		}
+		originals.close()
+	}
+	return nil
+}
+
+// Verify verifies the integrity of the files of the index, like the
+// function Verify, while the index is locked, so that it is not modified,
+// nor saved, in the meantime.
+//
+// The entries which follow the corrupted part of the log, if any, were
+// appended after the index was loaded, and they would be lost by the
+// repair performed by Verify. Instead, if repair is true, a loaded index
+// whose log is corrupted is saved, emptying the log. Read-only indices
+// cannot be repaired: ErrReadOnly is returned.
+func (h *HNSW) Verify(ctx context.Context, repair bool) (*VerifyReport, error) {
+	if repair && h.readOnly {
+		return nil, ErrReadOnly
+	}
+	h, unlock := h.acquireExclusive()
+	defer unlock()
+	if h.unloaded {
+		return Verify(h.dir, repair, h.logger)
+	}
+
+	report, err := Verify(h.dir, false, h.logger)
+	if err != nil || !repair || report.Log.Err == nil {
+		return report, err
+	}
+	if err = h.save(ctx); err != nil {
+		return nil, fmt.Errorf("error saving index: %w", err)
+	}
+	report.LogRepaired = true
+	return report, nil
+}
@@ -0,0 +1,194 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hnswgo_test
+
+import (
+	"encoding/binary"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid index", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		createAndSaveSampleIndex(t, dir)
+		hnsw, err := hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		require.NoError(t, hnsw.MarkDelete(1))
+
+		report, err := hnswgo.Verify(dir, false, zerolog.Nop())
+		require.NoError(t, err)
+		assert.True(t, report.OK())
+		assert.Empty(t, report.Problems())
+		assert.Empty(t, report.Warnings())
+		require.NotNil(t, report.Graph)
+		assert.Equal(t, uint64(2), report.Graph.Elements)
+		assert.Equal(t, uint64(0), report.Graph.Deleted)
+		assert.Equal(t, uint64(2), report.Graph.Connections)
+		assert.Equal(t, 1, report.Log.Entries)
+		assert.Equal(t, report.Log.Size, report.Log.ValidSize)
+	})
+
+	t.Run("torn log", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		createAndSaveSampleIndex(t, dir)
+		hnsw, err := hnswgo.Load(dir, zerolog.Nop())
+		require.NoError(t, err)
+		require.NoError(t, hnsw.MarkDelete(1))
+		require.NoError(t, hnsw.MarkDelete(2))
+		tearFile(t, path.Join(dir, "log"))
+
+		report, err := hnswgo.Verify(dir, false, zerolog.Nop())
+		require.NoError(t, err)
+		assert.False(t, report.OK())
+		assert.Len(t, report.Problems(), 1)
+		assert.Equal(t, 1, report.Log.Entries)
+		assert.Less(t, report.Log.ValidSize, report.Log.Size)
+
+		report, err = hnswgo.Verify(dir, true, zerolog.Nop())
+		require.NoError(t, err)
+		assert.True(t, report.OK())
+		assert.True(t, report.LogRepaired)
+		assert.Len(t, report.Warnings(), 1)
+
+		report, err = hnswgo.Verify(dir, false, zerolog.Nop())
+		require.NoError(t, err)
+		assert.True(t, report.OK())
+		assert.Equal(t, 1, report.Log.Entries)
+	})
+
+	t.Run("damaged index file", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		createAndSaveSampleIndex(t, dir)
+		tearFile(t, path.Join(dir, "index"))
+
+		report, err := hnswgo.Verify(dir, false, zerolog.Nop())
+		require.NoError(t, err)
+		assert.False(t, report.OK())
+		assert.Error(t, report.IndexErr)
+		assert.Nil(t, report.Graph)
+
+		_, err = hnswgo.Load(dir, zerolog.Nop())
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid links", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		createAndSaveSampleIndex(t, dir)
+
+		// The level 0 offset follows the magic number: the first link of
+		// the first element follows the size of its list.
+		file, err := os.OpenFile(path.Join(dir, "index"), os.O_RDWR, 0)
+		require.NoError(t, err)
+		var offset uint64
+		_, err = file.Seek(8, 0)
+		require.NoError(t, err)
+		require.NoError(t, binary.Read(file, binary.LittleEndian, &offset))
+		_, err = file.WriteAt([]byte{0xff, 0xff, 0xff, 0x7f}, int64(offset)+4)
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+
+		report, err := hnswgo.Verify(dir, false, zerolog.Nop())
+		require.NoError(t, err)
+		assert.False(t, report.OK())
+		require.NotNil(t, report.Graph)
+		assert.Equal(t, uint64(1), report.Graph.InvalidLinks)
+		assert.Contains(t, report.Problems(), "graph: 1 links to elements which do not exist")
+	})
+
+	t.Run("undecodable state", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		createAndSaveSampleIndex(t, dir)
+		require.NoError(t, os.WriteFile(path.Join(dir, "state"), []byte("foo"), 0666))
+
+		report, err := hnswgo.Verify(dir, false, zerolog.Nop())
+		require.NoError(t, err)
+		assert.Error(t, report.StateErr)
+		assert.Nil(t, report.Graph)
+		assert.Len(t, report.Problems(), 1)
+	})
+
+	t.Run("nonexistent dir", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		_, err := hnswgo.Verify(path.Join(dir, "foo"), false, zerolog.Nop())
+		assert.Error(t, err)
+	})
+}
+
+func TestHNSW_Verify(t *testing.T) {
+	t.Parallel()
+
+	dir := createTempDir(t)
+	defer deleteDir(t, dir)
+	createAndSaveSampleIndex(t, dir)
+	hnsw, err := hnswgo.Load(dir, zerolog.Nop())
+	require.NoError(t, err)
+	require.NoError(t, hnsw.MarkDelete(1))
+	require.NoError(t, hnsw.MarkDelete(2))
+	tearFile(t, path.Join(dir, "log"))
+
+	// The entries following the torn one are lost when the log is read.
+	hnsw, err = hnswgo.Load(dir, zerolog.Nop())
+	require.NoError(t, err)
+	_, err = hnsw.AddPointAutoID(sampleVectors[0])
+	require.NoError(t, err)
+
+	report, err := hnsw.Verify(ctx, false)
+	require.NoError(t, err)
+	assert.False(t, report.OK())
+
+	report, err = hnsw.Verify(ctx, true)
+	require.NoError(t, err)
+	assert.True(t, report.OK())
+	assert.True(t, report.LogRepaired)
+	assert.NoFileExists(t, path.Join(dir, "log"))
+
+	hnsw, err = hnswgo.Load(dir, zerolog.Nop())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uint32{2, 3}, hnsw.IDs())
+
+	readOnly, err := hnswgo.LoadWithOptions(dir, hnswgo.LoadOptions{ReadOnly: true}, zerolog.Nop())
+	require.NoError(t, err)
+	_, err = readOnly.Verify(ctx, true)
+	assert.ErrorIs(t, err, hnswgo.ErrReadOnly)
+}
+
+// tearFile removes the last bytes of a file, as if it was only partially
+// written.
+func tearFile(t *testing.T, name string) {
+	t.Helper()
+	info, err := os.Stat(name)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(name, info.Size()-3))
+}
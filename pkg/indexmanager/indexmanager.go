@@ -15,6 +15,7 @@
 package indexmanager
 
 import (
+	"context"
 	"fmt"
 	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
 	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/osutils"
@@ -22,40 +23,142 @@ import (
 	"os"
 	"path"
 	"regexp"
+	"runtime"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 var indexNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
 
+const (
+	// compactionDirSuffix is the suffix of the hidden dir where a compacted
+	// index is built, before replacing the original one.
+	compactionDirSuffix = ".compact"
+	// compactedDirSuffix is the suffix of the hidden dir where the original
+	// index is moved, once replaced by its compacted version, before being
+	// removed.
+	compactedDirSuffix = ".compacted"
+)
+
 // IndexManager allows easy handling of multiple HNSW indices.
 type IndexManager struct {
 	path    string
 	logger  zerolog.Logger
 	indices map[string]*hnswgo.HNSW
-	rwMx    sync.RWMutex
+	// compactions contains the names of the indices being compacted.
+	compactions map[string]struct{}
+	// loading contains the names of the indices which were discovered, and
+	// are not loaded yet, reporting whether their loading started.
+	loading map[string]bool
+	// failures contains the indices which failed to load, by name.
+	failures map[string]FailedIndex
+	// quarantine reports whether the indices which fail to load are
+	// quarantined (see EnableQuarantine).
+	quarantine bool
+	// statusListener is notified of the changes of the index statuses.
+	statusListener StatusListener
+	// loadOptions are the options of the indices loaded from disk.
+	loadOptions hnswgo.LoadOptions
+	// lazy holds the options of lazy loading, if enabled (see
+	// LoadIndicesLazily).
+	lazy *LazyOptions
+	rwMx sync.RWMutex
+	// lastAccess holds the time of the last access to each index, by name,
+	// when lazy loading is enabled. It is guarded by accessMx.
+	lastAccess map[string]time.Time
+	accessMx   sync.Mutex
+	// loaded is signaled when an index is loaded lazily, so that
+	// RunEvictor enforces the memory budget without waiting.
+	loaded chan struct{}
+}
+
+// LazyOptions provides the settings for unloading the indices which are
+// loaded lazily (see IndexManager.LoadIndicesLazily).
+type LazyOptions struct {
+	// MemoryBudget is the maximum memory taken by the resident indices, in
+	// bytes, as estimated by hnswgo.HNSW.MemoryUsage. Zero means no limit.
+	MemoryBudget int64
+	// IdleTimeout is the time after which the indices which are not
+	// accessed are unloaded. Zero means never.
+	IdleTimeout time.Duration
 }
 
 // New creates a new IndexManager.
 func New(path string, logger zerolog.Logger) *IndexManager {
 	return &IndexManager{
-		path:    path,
-		logger:  logger,
-		indices: make(map[string]*hnswgo.HNSW),
-		rwMx:    sync.RWMutex{},
+		path:        path,
+		logger:      logger,
+		indices:     make(map[string]*hnswgo.HNSW),
+		compactions: make(map[string]struct{}),
+		loading:     make(map[string]bool),
+		failures:    make(map[string]FailedIndex),
+		rwMx:        sync.RWMutex{},
+		lastAccess:  make(map[string]time.Time),
+		loaded:      make(chan struct{}, 1),
 	}
 }
 
 // LoadIndices load all HNSW indices stored in the configured path.
 func (im *IndexManager) LoadIndices() error {
+	return im.LoadIndicesWithOptions(hnswgo.LoadOptions{})
+}
+
+// LoadIndicesLazily discovers all HNSW indices stored in the configured
+// path, only reading their state (see hnswgo.LoadOptions.Lazy). Each index
+// is loaded by its first access through GetIndex, with the given options,
+// and it is unloaded by Evict once idle, or to fit the memory budget.
+//
+// As for LoadIndicesWithOptions, the indices whose state cannot be read are
+// reported by the returned error, while the other ones can be used anyway.
+func (im *IndexManager) LoadIndicesLazily(opts hnswgo.LoadOptions, lazy LazyOptions) error {
+	im.rwMx.Lock()
+	im.lazy = &lazy
+	im.rwMx.Unlock()
+
+	opts.Lazy = true
+	return im.LoadIndicesWithOptions(opts)
+}
+
+// LoadIndicesWithOptions loads all HNSW indices stored in the configured
+// path, with the given options (see hnswgo.LoadWithOptions), in parallel.
+// The progress is logged for each index. It is the same as DiscoverIndices
+// followed by LoadDiscoveredIndices: the indices which fail to load are
+// reported by the returned error, while the other ones can be used anyway.
+func (im *IndexManager) LoadIndicesWithOptions(opts hnswgo.LoadOptions) error {
+	if err := im.DiscoverIndices(opts); err != nil {
+		return err
+	}
+	return im.LoadDiscoveredIndices(runtime.NumCPU())
+}
+
+// DiscoverIndices finds the HNSW indices stored in the configured path,
+// after cleaning up the dirs of interrupted compactions, without loading
+// them. The indices have IndexLoading status until they are loaded by
+// LoadDiscoveredIndices: in the meantime, they are not found by GetIndex.
+// The indices which are already loaded or loading are left unchanged, and
+// the indices which failed to load are loaded again. The quarantined
+// indices (see EnableQuarantine) are found as failed.
+//
+// The options are used by all the indices loaded afterwards (see
+// hnswgo.LoadWithOptions).
+func (im *IndexManager) DiscoverIndices(opts hnswgo.LoadOptions) error {
 	im.rwMx.Lock()
 	defer im.rwMx.Unlock()
 
-	im.logger.Info().Msgf("loading all indices from dir %#v...", im.path)
+	im.loadOptions = opts
 	files, err := os.ReadDir(im.path)
 	if err != nil {
 		return fmt.Errorf("error reading content of indices dir %#v: %w", im.path, err)
 	}
+	if err = im.recoverCompactions(files); err != nil {
+		return err
+	}
+	files, err = os.ReadDir(im.path)
+	if err != nil {
+		return fmt.Errorf("error reading content of indices dir %#v: %w", im.path, err)
+	}
 
 	for _, file := range files {
 		name := file.Name()
@@ -64,38 +167,168 @@ func (im *IndexManager) LoadIndices() error {
 		if !file.IsDir() || strings.HasPrefix(name, ".") {
 			continue
 		}
+		if status := im.status(name); status == IndexReady || status == IndexLoading {
+			continue
+		}
+		delete(im.failures, name)
+		im.loading[name] = false
+		im.notifyStatus(name)
+	}
+	if err = im.discoverQuarantined(); err != nil {
+		return err
+	}
+	im.logger.Info().Msgf("%d indices found in dir %#v", len(im.loading), im.path)
+	return nil
+}
 
-		im.logger.Info().Msgf("loading index %#v...", name)
-		err = im.loadIndex(name)
-		if err != nil {
-			return err
+// LoadDiscoveredIndices loads the indices found by DiscoverIndices whose
+// loading has not started yet, with the given number of parallel workers,
+// logging the progress. Each index becomes ready, and can be used, as soon
+// as it is loaded. The indices which cannot be loaded get IndexFailed
+// status, and the returned error, wrapping ErrIndicesFailed, reports all of
+// them.
+func (im *IndexManager) LoadDiscoveredIndices(workers int) error {
+	im.rwMx.Lock()
+	opts := im.indexLoadOptions()
+	names := make([]string, 0, len(im.loading))
+	for name, started := range im.loading {
+		if !started {
+			im.loading[name] = true
+			names = append(names, name)
 		}
 	}
+	im.rwMx.Unlock()
+
+	sort.Strings(names)
+	if workers < 1 {
+		workers = 1
+	}
+	im.logger.Info().Msgf("loading %d indices from dir %#v...", len(names), im.path)
+	startTime := time.Now()
+
+	var (
+		wg     sync.WaitGroup
+		mx     sync.Mutex
+		loaded int
+		errs   []string
+	)
+	jobs := make(chan string)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range jobs {
+				indexStartTime := time.Now()
+				storage, err := im.loadIndex(name, opts)
+
+				mx.Lock()
+				loaded++
+				progress := fmt.Sprintf("%d/%d", loaded, len(names))
+				if err != nil {
+					errs = append(errs, err.Error())
+				}
+				mx.Unlock()
+
+				if err != nil {
+					im.logger.Err(err).Msgf("index %#v failed to load (%s)", name, progress)
+					continue
+				}
+				im.logger.Info().Msgf("index %#v loaded %s in %s (%s)", name, storage, time.Since(indexStartTime).Round(time.Millisecond), progress)
+			}
+		}()
+	}
+	for _, name := range names {
+		jobs <- name
+	}
+	close(jobs)
+	wg.Wait()
 
-	im.logger.Info().Msg("all indices successfully loaded")
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("%d of %d %w:\n%s", len(errs), len(names), ErrIndicesFailed, strings.Join(errs, "\n"))
+	}
+	im.logger.Info().Msgf("all indices successfully loaded in %s", time.Since(startTime).Round(time.Millisecond))
 	return nil
 }
 
 // GetIndex returns the HNSW index (if it exists) and reports whether it is found.
+//
+// With lazy loading, the access is recorded, and the index is loaded if it
+// is not resident. If the loading fails, the error is logged, and the index
+// is not found.
 func (im *IndexManager) GetIndex(name string) (*hnswgo.HNSW, bool) {
+	im.rwMx.RLock()
+	index, found := im.indices[name]
+	lazy := im.lazy != nil
+	im.rwMx.RUnlock()
+
+	if !found || !lazy {
+		return index, found
+	}
+	if im.access(name, index) != nil {
+		return nil, false
+	}
+	return index, true
+}
+
+// AcquireIndex is the same as GetIndex, but the index is pinned (see
+// hnswgo.HNSW.Pin), so that it is not unloaded until the returned function
+// is called. If the index is not ready, or it cannot be loaded, the error
+// is returned.
+func (im *IndexManager) AcquireIndex(name string) (*hnswgo.HNSW, func(), error) {
+	im.rwMx.RLock()
+	index, found := im.indices[name]
+	lazy := im.lazy != nil
+	var err error
+	if !found {
+		err = im.notReadyError(name)
+	}
+	im.rwMx.RUnlock()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if lazy {
+		if err = im.access(name, index); err != nil {
+			return nil, nil, err
+		}
+	}
+	unpin, err := index.Pin()
+	if err != nil {
+		return nil, nil, err
+	}
+	return index, unpin, nil
+}
+
+// IsResident reports whether the index exists, and it is loaded in memory
+// (see hnswgo.HNSW.Resident). Unlike GetIndex, it never loads the index.
+func (im *IndexManager) IsResident(name string) bool {
 	im.rwMx.RLock()
 	defer im.rwMx.RUnlock()
 
 	index, found := im.indices[name]
-	return index, found
+	return found && index.Resident()
 }
 
 // CreateIndex creates and persists a new index with the given name.
 // If the name is not acceptable or an index with the same name already
 // exists, an error is returned.
-func (im *IndexManager) CreateIndex(name string, config hnswgo.Config) (*hnswgo.HNSW, error) {
+//
+// If the index cannot be persisted (for example because the context is
+// done), it is not added, and any partially written data is removed.
+//
+// If the indices are loaded read-only, hnswgo.ErrReadOnly is returned.
+func (im *IndexManager) CreateIndex(ctx context.Context, name string, config hnswgo.Config) (*hnswgo.HNSW, error) {
 	im.rwMx.Lock()
 	defer im.rwMx.Unlock()
 
-	if !isValidIndexName(name) {
+	if im.loadOptions.ReadOnly {
+		return nil, hnswgo.ErrReadOnly
+	}
+	if !IsValidIndexName(name) {
 		return nil, fmt.Errorf("invalid index name")
 	}
-	if _, ok := im.indices[name]; ok {
+	if im.status(name) != IndexNotFound {
 		return nil, fmt.Errorf("index with name %#v already exists", name)
 	}
 
@@ -109,42 +342,98 @@ func (im *IndexManager) CreateIndex(name string, config hnswgo.Config) (*hnswgo.
 	}
 
 	index := hnswgo.New(dir, config, im.loggerForIndex(name))
-	im.indices[name] = index
+	index.SetKeepPrevious(im.quarantine)
 
-	err = index.Save()
+	err = index.Save(ctx)
 	if err != nil {
+		if e := os.RemoveAll(dir); e != nil {
+			im.logger.Warn().Err(e).Msgf("error removing dir %#v of index %#v", dir, name)
+		}
 		return nil, fmt.Errorf("error persisting new index %#v: %w", name, err)
 	}
 
+	im.indices[name] = index
+	if im.lazy != nil {
+		im.accessMx.Lock()
+		im.lastAccess[name] = time.Now()
+		im.accessMx.Unlock()
+	}
+	im.notifyStatus(name)
 	return index, nil
 }
 
 // PersistIndex saves the current index to disk.
-func (im *IndexManager) PersistIndex(name string) error {
+// See hnswgo.HNSW.Save for details about context handling.
+func (im *IndexManager) PersistIndex(ctx context.Context, name string) error {
 	im.rwMx.RLock()
 	defer im.rwMx.RUnlock()
 
 	index, indexExists := im.indices[name]
 	if !indexExists {
-		return fmt.Errorf("index does not exist")
+		return im.notReadyError(name)
 	}
-	err := index.Save()
+	err := index.Save(ctx)
 	if err != nil {
 		return fmt.Errorf("error persisting index %#v: %w", name, err)
 	}
 	return nil
 }
 
+// VerifyIndex verifies the integrity of the files of an index (see
+// hnswgo.Verify). Indices which failed to load can be verified as well,
+// including the quarantined ones, but not the ones which are still loading.
+//
+// If repair is true, the corrupted end of the write-ahead log of the index,
+// if any, is repaired (see hnswgo.HNSW.Verify). If the indices are loaded
+// read-only, hnswgo.ErrReadOnly is returned.
+func (im *IndexManager) VerifyIndex(ctx context.Context, name string, repair bool) (*hnswgo.VerifyReport, error) {
+	im.rwMx.RLock()
+	defer im.rwMx.RUnlock()
+
+	if repair && im.loadOptions.ReadOnly {
+		return nil, hnswgo.ErrReadOnly
+	}
+
+	var report *hnswgo.VerifyReport
+	var err error
+	switch im.status(name) {
+	case IndexReady:
+		report, err = im.indices[name].Verify(ctx, repair)
+	case IndexFailed:
+		dir := path.Join(im.path, name)
+		if im.failures[name].Quarantined {
+			dir = path.Join(im.path, quarantineDir, name)
+		}
+		report, err = hnswgo.Verify(dir, repair, im.loggerForIndex(name))
+	default:
+		return nil, im.notReadyError(name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error verifying index %#v: %w", name, err)
+	}
+	return report, nil
+}
+
 // DeleteIndex remove an index, also removing data from disk.
+// Indices which failed to load can be deleted as well, but not the ones
+// which are still loading.
+// If the indices are loaded read-only, hnswgo.ErrReadOnly is returned.
 func (im *IndexManager) DeleteIndex(name string) error {
 	im.rwMx.Lock()
 	defer im.rwMx.Unlock()
 
-	if _, ok := im.indices[name]; !ok {
-		return fmt.Errorf("index does not exist")
+	if im.loadOptions.ReadOnly {
+		return hnswgo.ErrReadOnly
+	}
+	switch im.status(name) {
+	case IndexNotFound, IndexLoading:
+		return im.notReadyError(name)
 	}
 
 	filename := path.Join(im.path, name)
+	if im.failures[name].Quarantined {
+		filename = path.Join(im.path, quarantineDir, name)
+	}
 	dirExists, err := osutils.DirExists(filename)
 	if err != nil {
 		return err
@@ -157,10 +446,403 @@ func (im *IndexManager) DeleteIndex(name string) error {
 	}
 
 	delete(im.indices, name)
+	delete(im.failures, name)
+	im.accessMx.Lock()
+	delete(im.lastAccess, name)
+	im.accessMx.Unlock()
+	im.notifyStatus(name)
 	return nil
 }
 
-// IndicesNames returns the names of all indices.
+// CompactIndex rebuilds the given index from its elements which are not
+// marked deleted, reclaiming their capacity, and replaces it in the manager
+// (see hnswgo.HNSW.Compact). The index keeps serving all operations in the
+// meantime.
+//
+// If the index is deleted before the end of the compaction, the compaction
+// is aborted.
+func (im *IndexManager) CompactIndex(ctx context.Context, name string) error {
+	index, err := im.beginCompaction(name)
+	if err != nil {
+		return err
+	}
+	defer im.endCompaction(name)
+	return im.compactIndex(ctx, name, index)
+}
+
+// StartCompaction starts the compaction of the given index in background,
+// logging its outcome. See CompactIndex.
+func (im *IndexManager) StartCompaction(name string) error {
+	index, err := im.beginCompaction(name)
+	if err != nil {
+		return err
+	}
+	go func() {
+		defer im.endCompaction(name)
+		err := im.compactIndex(context.Background(), name, index)
+		if err != nil {
+			logger := im.loggerForIndex(name)
+			logger.Err(err).Msg("compaction failed")
+		}
+	}()
+	return nil
+}
+
+func (im *IndexManager) beginCompaction(name string) (*hnswgo.HNSW, error) {
+	im.rwMx.Lock()
+	defer im.rwMx.Unlock()
+
+	index, ok := im.indices[name]
+	if !ok {
+		return nil, im.notReadyError(name)
+	}
+	if _, ok = im.compactions[name]; ok {
+		return nil, fmt.Errorf("a compaction of index %#v is already in progress", name)
+	}
+	im.compactions[name] = struct{}{}
+	return index, nil
+}
+
+func (im *IndexManager) endCompaction(name string) {
+	im.rwMx.Lock()
+	defer im.rwMx.Unlock()
+	delete(im.compactions, name)
+}
+
+func (im *IndexManager) compactIndex(ctx context.Context, name string, index *hnswgo.HNSW) error {
+	logger := im.loggerForIndex(name)
+	newDir := path.Join(im.path, "."+name+compactionDirSuffix)
+	oldDir := path.Join(im.path, "."+name+compactedDirSuffix)
+	if err := os.RemoveAll(newDir); err != nil {
+		return fmt.Errorf("error removing dir %#v: %w", newDir, err)
+	}
+
+	logger.Info().Msg("compacting index...")
+	startTime := time.Now()
+
+	// Half of the CPUs are left for serving requests.
+	workers := runtime.NumCPU() / 2
+	if workers < 1 {
+		workers = 1
+	}
+	c, err := index.Compact(ctx, newDir, workers)
+	if err != nil {
+		return fmt.Errorf("error compacting index %#v: %w", name, err)
+	}
+
+	// No other index operation can be performed by the manager until the
+	// new index is in place.
+	im.rwMx.Lock()
+	defer im.rwMx.Unlock()
+
+	if im.indices[name] != index {
+		c.Abort()
+		return fmt.Errorf("error compacting index %#v: the index was deleted", name)
+	}
+	newIndex, err := c.Commit(oldDir)
+	if err != nil {
+		return fmt.Errorf("error compacting index %#v: %w", name, err)
+	}
+	im.indices[name] = newIndex
+
+	logger.Info().Msgf("index compacted in %s", time.Since(startTime).Round(time.Millisecond))
+	return nil
+}
+
+// recoverCompactions cleans up the dirs of compactions which were
+// interrupted. If the original index was already moved, but not yet
+// replaced, it is moved back: its log contains all operations.
+func (im *IndexManager) recoverCompactions(files []os.DirEntry) error {
+	for _, file := range files {
+		name := file.Name()
+		if !file.IsDir() || !strings.HasPrefix(name, ".") {
+			continue
+		}
+		dir := path.Join(im.path, name)
+
+		if strings.HasSuffix(name, compactionDirSuffix) {
+			im.logger.Warn().Msgf("removing dir %#v of an interrupted compaction", dir)
+			if err := os.RemoveAll(dir); err != nil {
+				return fmt.Errorf("error removing dir %#v: %w", dir, err)
+			}
+			continue
+		}
+		if !strings.HasSuffix(name, compactedDirSuffix) {
+			continue
+		}
+
+		indexDir := path.Join(im.path, strings.TrimSuffix(strings.TrimPrefix(name, "."), compactedDirSuffix))
+		exists, err := osutils.DirExists(indexDir)
+		if err != nil {
+			return err
+		}
+		if exists {
+			im.logger.Warn().Msgf("removing dir %#v of a completed compaction", dir)
+			if err = os.RemoveAll(dir); err != nil {
+				return fmt.Errorf("error removing dir %#v: %w", dir, err)
+			}
+			continue
+		}
+		im.logger.Warn().Msgf("restoring dir %#v of an interrupted compaction", dir)
+		if err = os.Rename(dir, indexDir); err != nil {
+			return fmt.Errorf("error moving dir %#v to %#v: %w", dir, indexDir, err)
+		}
+	}
+	return nil
+}
+
+// DeleteExpired marks deleted the expired elements of all indices, and
+// returns their total amount (see hnswgo.HNSW.DeleteExpired). Read-only
+// indices are skipped, as well as the indices which are not resident: their
+// expired elements are deleted once they are loaded again. Errors are
+// logged, without stopping the deletion on the other indices.
+func (im *IndexManager) DeleteExpired(now time.Time) int {
+	im.rwMx.RLock()
+	indices := make(map[string]*hnswgo.HNSW, len(im.indices))
+	for name, index := range im.indices {
+		indices[name] = index
+	}
+	im.rwMx.RUnlock()
+
+	total := 0
+	for name, index := range indices {
+		if index.ReadOnly() {
+			continue
+		}
+		// The index is pinned, not to be unloaded in the meantime.
+		unpin, resident := index.PinResident()
+		if !resident {
+			continue
+		}
+		logger := im.loggerForIndex(name)
+		n, err := index.DeleteExpired(now)
+		unpin()
+		if err != nil {
+			logger.Err(err).Msg("error deleting expired vectors")
+			continue
+		}
+		if n > 0 {
+			logger.Info().Msgf("%d expired vectors deleted", n)
+		}
+		total += n
+	}
+	return total
+}
+
+// RunExpirySweeper calls DeleteExpired at each interval, until the context
+// is done.
+func (im *IndexManager) RunExpirySweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			im.DeleteExpired(now)
+		}
+	}
+}
+
+// EfTuningOptions provides the settings for tuning the "ef" parameter of
+// the indices (see hnswgo.HNSW.AutoTuneEf).
+type EfTuningOptions struct {
+	// TargetRecall is the recall@K to be met.
+	TargetRecall float64
+	// K is the number of nearest neighbors searched for each query.
+	K int
+	// SampleSize is the number of stored vectors, chosen at random, used as
+	// queries.
+	SampleSize int
+	// MaxEf is the maximum "ef" parameter which can be chosen.
+	MaxEf int
+}
+
+// AutoTuneEf tunes the "ef" parameter of all HNSW indices, using a random
+// sample of their vectors as queries. Flat indices, empty indices and
+// indices which are not resident are skipped. Errors are logged, without stopping the tuning of the other
+// indices.
+func (im *IndexManager) AutoTuneEf(ctx context.Context, opts EfTuningOptions) {
+	im.rwMx.RLock()
+	indices := make(map[string]*hnswgo.HNSW, len(im.indices))
+	for name, index := range im.indices {
+		indices[name] = index
+	}
+	im.rwMx.RUnlock()
+
+	for name, index := range indices {
+		if ctx.Err() != nil {
+			return
+		}
+		if index.Config().IndexType == hnswgo.FlatIndex {
+			continue
+		}
+		unpin, resident := index.PinResident()
+		if !resident {
+			continue
+		}
+		queries := index.SampleVectors(opts.SampleSize)
+		if len(queries) == 0 {
+			unpin()
+			continue
+		}
+
+		logger := im.loggerForIndex(name)
+		tuning, err := index.AutoTuneEf(ctx, queries, opts.K, opts.TargetRecall, opts.MaxEf)
+		unpin()
+		if err != nil {
+			logger.Err(err).Msg("error tuning ef")
+			continue
+		}
+		if !tuning.TargetReached {
+			logger.Warn().Msgf("ef set to %d, with recall %.4f below the target", tuning.Ef, tuning.Recall)
+			continue
+		}
+		logger.Debug().Msgf("ef set to %d, with recall %.4f", tuning.Ef, tuning.Recall)
+	}
+}
+
+// RunEfTuner calls AutoTuneEf at each interval, until the context is done.
+func (im *IndexManager) RunEfTuner(ctx context.Context, interval time.Duration, opts EfTuningOptions) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			im.AutoTuneEf(ctx, opts)
+		}
+	}
+}
+
+// access records an access to an index loaded lazily, loading it if it is
+// not resident. The loading error, if any, is logged and returned.
+func (im *IndexManager) access(name string, index *hnswgo.HNSW) error {
+	im.accessMx.Lock()
+	im.lastAccess[name] = time.Now()
+	im.accessMx.Unlock()
+
+	if index.Resident() {
+		return nil
+	}
+	logger := im.loggerForIndex(name)
+	startTime := time.Now()
+	if err := index.Reload(); err != nil {
+		logger.Err(err).Msg("error loading index")
+		return err
+	}
+	logger.Info().Msgf("index loaded in %s", time.Since(startTime).Round(time.Millisecond))
+
+	select {
+	case im.loaded <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Evict unloads the resident indices which were not accessed for
+// LazyOptions.IdleTimeout, and then the least recently used ones, until the
+// memory taken by the resident indices fits LazyOptions.MemoryBudget, and
+// returns the number of indices unloaded (see hnswgo.HNSW.Unload). The most
+// recently used index is never unloaded to fit the budget, and indices
+// being compacted or pinned (see AcquireIndex) are skipped.
+//
+// It has no effect unless lazy loading is enabled. Errors are logged,
+// leaving the index loaded.
+func (im *IndexManager) Evict(ctx context.Context, now time.Time) int {
+	type candidate struct {
+		name       string
+		index      *hnswgo.HNSW
+		lastAccess time.Time
+		memory     int64
+	}
+
+	im.rwMx.RLock()
+	lazy := im.lazy
+	candidates := make([]candidate, 0, len(im.indices))
+	for name, index := range im.indices {
+		if _, ok := im.compactions[name]; ok || !index.Resident() || index.Pinned() {
+			continue
+		}
+		candidates = append(candidates, candidate{name: name, index: index})
+	}
+	im.rwMx.RUnlock()
+	if lazy == nil {
+		return 0
+	}
+
+	im.accessMx.Lock()
+	for i := range candidates {
+		candidates[i].lastAccess = im.lastAccess[candidates[i].name]
+	}
+	im.accessMx.Unlock()
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].lastAccess.Before(candidates[j].lastAccess)
+	})
+
+	var total int64
+	for i := range candidates {
+		candidates[i].memory = candidates[i].index.MemoryUsage()
+		total += candidates[i].memory
+	}
+
+	unloaded := 0
+	for i, c := range candidates {
+		idle := lazy.IdleTimeout > 0 && now.Sub(c.lastAccess) >= lazy.IdleTimeout
+		overBudget := lazy.MemoryBudget > 0 && total > lazy.MemoryBudget && i < len(candidates)-1
+		if !idle && !overBudget {
+			break
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if !im.unloadIndex(ctx, c.name, c.index) {
+			continue
+		}
+		total -= c.memory
+		unloaded++
+	}
+	return unloaded
+}
+
+// unloadIndex unloads the given index, unless it was deleted or replaced in
+// the meantime, and reports whether it succeeded. Errors are logged.
+func (im *IndexManager) unloadIndex(ctx context.Context, name string, index *hnswgo.HNSW) bool {
+	// As for PersistIndex, the index cannot be deleted while it is saved.
+	im.rwMx.RLock()
+	defer im.rwMx.RUnlock()
+
+	if im.indices[name] != index {
+		return false
+	}
+	logger := im.loggerForIndex(name)
+	if err := index.Unload(ctx); err != nil {
+		logger.Err(err).Msg("error unloading index")
+		return false
+	}
+	logger.Info().Msg("index unloaded")
+	return true
+}
+
+// RunEvictor calls Evict at each interval, and whenever an index is loaded
+// lazily, until the context is done.
+func (im *IndexManager) RunEvictor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			im.Evict(ctx, now)
+		case <-im.loaded:
+			im.Evict(ctx, time.Now())
+		}
+	}
+}
+
+// IndicesNames returns the names of all ready indices.
 func (im *IndexManager) IndicesNames() []string {
 	im.rwMx.RLock()
 	defer im.rwMx.RUnlock()
@@ -180,20 +862,35 @@ func (im *IndexManager) Size() int {
 	return len(im.indices)
 }
 
-func (im *IndexManager) loadIndex(name string) error {
-	if _, ok := im.indices[name]; ok {
-		return fmt.Errorf("index %#v was already loaded", name)
-	}
-
-	h, err := hnswgo.Load(path.Join(im.path, name), im.loggerForIndex(name))
+// loadIndex loads a discovered index, making it ready or failed (see
+// failIndex), and describes how it is stored.
+func (im *IndexManager) loadIndex(name string, opts hnswgo.LoadOptions) (storage string, err error) {
+	h, err := hnswgo.LoadWithOptions(path.Join(im.path, name), opts, im.loggerForIndex(name))
 	if err != nil {
-		return fmt.Errorf("error loading index %#v: %w", name, err)
+		err = fmt.Errorf("error loading index %#v: %w", name, err)
+		im.failIndex(name, err)
+		return "", err
 	}
+	switch {
+	case opts.Lazy:
+		storage = "lazily"
+	case h.Mapped():
+		storage = "memory-mapped"
+	default:
+		storage = "in memory"
+	}
+
+	im.rwMx.Lock()
+	defer im.rwMx.Unlock()
+	delete(im.loading, name)
 	im.indices[name] = h
-	return nil
+	im.notifyStatus(name)
+	return storage, nil
 }
 
-func isValidIndexName(name string) bool {
+// IsValidIndexName reports whether the given string can be used as index
+// name.
+func IsValidIndexName(name string) bool {
 	return len(name) <= 255 && indexNameRegexp.MatchString(name)
 }
 
@@ -15,6 +15,7 @@
 package indexmanager_test
 
 import (
+	"context"
 	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
 	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/indexmanager"
 	"github.com/rs/zerolog"
@@ -23,6 +24,7 @@ import (
 	"os"
 	"path"
 	"testing"
+	"time"
 )
 
 var sampleConfig = hnswgo.Config{
@@ -35,6 +37,8 @@ var sampleConfig = hnswgo.Config{
 	AutoIDEnabled:  true,
 }
 
+var ctx = context.Background()
+
 func TestIndexManager_LoadIndices(t *testing.T) {
 	t.Parallel()
 
@@ -118,6 +122,54 @@ func TestIndexManager_LoadIndices(t *testing.T) {
 	})
 }
 
+func TestIndexManager_LoadIndicesWithOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("memory-mapped indices", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		createDir(t, path.Join(dir, "foo"))
+		createAndSaveSampleIndex(t, path.Join(dir, "foo"))
+
+		im := indexmanager.New(dir, zerolog.Nop())
+		require.NoError(t, im.LoadIndicesWithOptions(hnswgo.LoadOptions{Mmap: true}))
+		index, found := im.GetIndex("foo")
+		require.True(t, found)
+		assert.True(t, index.Mapped())
+		assert.False(t, index.ReadOnly())
+
+		_, err := im.CreateIndex(ctx, "bar", sampleConfig)
+		assert.NoError(t, err)
+	})
+
+	t.Run("read-only indices", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		createDir(t, path.Join(dir, "foo"))
+		createAndSaveSampleIndex(t, path.Join(dir, "foo"))
+
+		im := indexmanager.New(dir, zerolog.Nop())
+		require.NoError(t, im.LoadIndicesWithOptions(hnswgo.LoadOptions{ReadOnly: true}))
+		index, found := im.GetIndex("foo")
+		require.True(t, found)
+		assert.True(t, index.Mapped())
+		assert.True(t, index.ReadOnly())
+		assert.Len(t, index.SearchKNN(sampleVectors[0], 2), 2)
+
+		_, err := im.CreateIndex(ctx, "bar", sampleConfig)
+		assert.ErrorIs(t, err, hnswgo.ErrReadOnly)
+		assert.ErrorIs(t, im.DeleteIndex("foo"), hnswgo.ErrReadOnly)
+		assert.ErrorIs(t, im.PersistIndex(ctx, "foo"), hnswgo.ErrReadOnly)
+		assert.ErrorIs(t, im.CompactIndex(ctx, "foo"), hnswgo.ErrReadOnly)
+		assert.Zero(t, im.DeleteExpired(time.Now()))
+		assert.DirExists(t, path.Join(dir, "foo"))
+	})
+}
+
 func TestIndexManager_CreateIndex(t *testing.T) {
 	t.Parallel()
 
@@ -127,11 +179,11 @@ func TestIndexManager_CreateIndex(t *testing.T) {
 		defer deleteDir(t, dir)
 		im := indexmanager.New(dir, zerolog.Nop())
 
-		index, err := im.CreateIndex("foo", sampleConfig)
+		index, err := im.CreateIndex(ctx, "foo", sampleConfig)
 		assert.NoError(t, err)
 		assert.NotNil(t, index)
 
-		index, err = im.CreateIndex("bar", sampleConfig)
+		index, err = im.CreateIndex(ctx, "bar", sampleConfig)
 		assert.NoError(t, err)
 		assert.NotNil(t, index)
 
@@ -159,7 +211,7 @@ func TestIndexManager_CreateIndex(t *testing.T) {
 		defer deleteDir(t, dir)
 		im := indexmanager.New(dir, zerolog.Nop())
 
-		index, err := im.CreateIndex("foo!?", sampleConfig)
+		index, err := im.CreateIndex(ctx, "foo!?", sampleConfig)
 		assert.Error(t, err)
 		assert.Nil(t, index)
 	})
@@ -170,11 +222,11 @@ func TestIndexManager_CreateIndex(t *testing.T) {
 		defer deleteDir(t, dir)
 		im := indexmanager.New(dir, zerolog.Nop())
 
-		index, err := im.CreateIndex("foo", sampleConfig)
+		index, err := im.CreateIndex(ctx, "foo", sampleConfig)
 		require.NoError(t, err)
 		require.NotNil(t, index)
 
-		index, err = im.CreateIndex("foo", sampleConfig)
+		index, err = im.CreateIndex(ctx, "foo", sampleConfig)
 		assert.Error(t, err)
 		assert.Nil(t, index)
 	})
@@ -187,7 +239,7 @@ func TestIndexManager_CreateIndex(t *testing.T) {
 
 		require.NoError(t, os.Mkdir(path.Join(dir, "foo"), 0777))
 
-		index, err := im.CreateIndex("foo", sampleConfig)
+		index, err := im.CreateIndex(ctx, "foo", sampleConfig)
 		assert.Error(t, err)
 		assert.Nil(t, index)
 	})
@@ -203,10 +255,26 @@ func TestIndexManager_CreateIndex(t *testing.T) {
 		require.NoError(t, err)
 		require.NoError(t, file.Close())
 
-		index, err := im.CreateIndex("foo", sampleConfig)
+		index, err := im.CreateIndex(ctx, "foo", sampleConfig)
 		assert.Error(t, err)
 		assert.Nil(t, index)
 	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := indexmanager.New(dir, zerolog.Nop())
+
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		index, err := im.CreateIndex(canceledCtx, "foo", sampleConfig)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, index)
+		assert.Equal(t, 0, im.Size())
+		assert.NoDirExists(t, path.Join(dir, "foo"))
+	})
 }
 
 func TestIndexManager_PersistIndex(t *testing.T) {
@@ -220,18 +288,18 @@ func TestIndexManager_PersistIndex(t *testing.T) {
 		{
 			im := indexmanager.New(dir, zerolog.Nop())
 
-			foo, err := im.CreateIndex("foo", sampleConfig)
+			foo, err := im.CreateIndex(ctx, "foo", sampleConfig)
 			require.NoError(t, err)
 			_, err = foo.AddPointAutoID(sampleVectors[0])
 			require.NoError(t, err)
 
-			bar, err := im.CreateIndex("bar", sampleConfig)
+			bar, err := im.CreateIndex(ctx, "bar", sampleConfig)
 			require.NoError(t, err)
 			_, err = bar.AddPointAutoID(sampleVectors[1])
 			require.NoError(t, err)
 
-			assert.NoError(t, im.PersistIndex("foo"))
-			assert.NoError(t, im.PersistIndex("bar"))
+			assert.NoError(t, im.PersistIndex(ctx, "foo"))
+			assert.NoError(t, im.PersistIndex(ctx, "bar"))
 		}
 		{
 			im := indexmanager.New(dir, zerolog.Nop())
@@ -257,7 +325,7 @@ func TestIndexManager_PersistIndex(t *testing.T) {
 		t.Parallel()
 		im := indexmanager.New(os.TempDir(), zerolog.Nop())
 
-		assert.Error(t, im.PersistIndex("foo"))
+		assert.Error(t, im.PersistIndex(ctx, "foo"))
 	})
 
 	t.Run("saving error", func(t *testing.T) {
@@ -270,15 +338,56 @@ func TestIndexManager_PersistIndex(t *testing.T) {
 
 		im := indexmanager.New(subDir, zerolog.Nop())
 
-		_, err := im.CreateIndex("foo", sampleConfig)
+		_, err := im.CreateIndex(ctx, "foo", sampleConfig)
 		require.NoError(t, err)
 
 		deleteDir(t, subDir)
 
-		assert.Error(t, im.PersistIndex("foo"))
+		assert.Error(t, im.PersistIndex(ctx, "foo"))
 	})
 }
 
+func TestIndexManager_VerifyIndex(t *testing.T) {
+	t.Parallel()
+
+	dir := createTempDir(t)
+	defer deleteDir(t, dir)
+	im := indexmanager.New(dir, zerolog.Nop())
+	foo, err := im.CreateIndex(ctx, "foo", sampleConfig)
+	require.NoError(t, err)
+	_, err = foo.AddPointAutoID(sampleVectors[0])
+	require.NoError(t, err)
+	_, err = im.CreateIndex(ctx, "bar", sampleConfig)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path.Join(dir, "bar", "index"), 10))
+
+	im = indexmanager.New(dir, zerolog.Nop())
+	im.EnableQuarantine()
+	require.ErrorIs(t, im.LoadIndices(), indexmanager.ErrIndicesFailed)
+
+	report, err := im.VerifyIndex(ctx, "foo", true)
+	require.NoError(t, err)
+	assert.True(t, report.OK())
+	assert.Equal(t, 1, report.Log.Entries)
+
+	// Failed indices are verified in the quarantine dir.
+	report, err = im.VerifyIndex(ctx, "bar", false)
+	require.NoError(t, err)
+	assert.False(t, report.OK())
+	assert.Error(t, report.IndexErr)
+
+	_, err = im.VerifyIndex(ctx, "baz", false)
+	assert.Error(t, err)
+
+	im = indexmanager.New(dir, zerolog.Nop())
+	require.NoError(t, im.LoadIndicesWithOptions(hnswgo.LoadOptions{ReadOnly: true}))
+	report, err = im.VerifyIndex(ctx, "foo", false)
+	require.NoError(t, err)
+	assert.True(t, report.OK())
+	_, err = im.VerifyIndex(ctx, "foo", true)
+	assert.ErrorIs(t, err, hnswgo.ErrReadOnly)
+}
+
 func TestIndexManager_DeleteIndex(t *testing.T) {
 	t.Parallel()
 
@@ -288,7 +397,7 @@ func TestIndexManager_DeleteIndex(t *testing.T) {
 		defer deleteDir(t, dir)
 		im := indexmanager.New(dir, zerolog.Nop())
 
-		_, err := im.CreateIndex("bar", sampleConfig)
+		_, err := im.CreateIndex(ctx, "bar", sampleConfig)
 		require.NoError(t, err)
 
 		require.Equal(t, []string{"bar"}, im.IndicesNames())
@@ -312,14 +421,14 @@ func TestIndexManager_DeleteIndex(t *testing.T) {
 		defer deleteDir(t, dir)
 		im := indexmanager.New(dir, zerolog.Nop())
 
-		_, err := im.CreateIndex("foo", sampleConfig)
+		_, err := im.CreateIndex(ctx, "foo", sampleConfig)
 		require.NoError(t, err)
-		err = im.PersistIndex("foo")
+		err = im.PersistIndex(ctx, "foo")
 		require.NoError(t, err)
 
-		_, err = im.CreateIndex("bar", sampleConfig)
+		_, err = im.CreateIndex(ctx, "bar", sampleConfig)
 		require.NoError(t, err)
-		require.NoError(t, im.PersistIndex("bar"))
+		require.NoError(t, im.PersistIndex(ctx, "bar"))
 
 		names := im.IndicesNames()
 		require.Len(t, names, 2)
@@ -361,6 +470,367 @@ func TestIndexManager_DeleteIndex(t *testing.T) {
 	})
 }
 
+func TestIndexManager_CompactIndex(t *testing.T) {
+	t.Parallel()
+
+	t.Run("successful compaction", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		im := indexmanager.New(dir, zerolog.Nop())
+		oldIndex, err := im.CreateIndex(ctx, "foo", sampleConfig)
+		require.NoError(t, err)
+		_, err = oldIndex.AddPointsAutoID(ctx, sampleVectors, 1)
+		require.NoError(t, err)
+		require.NoError(t, oldIndex.MarkDelete(1))
+
+		require.NoError(t, im.CompactIndex(ctx, "foo"))
+
+		index, found := im.GetIndex("foo")
+		require.True(t, found)
+		assert.NotSame(t, oldIndex, index)
+		assert.Equal(t, []uint32{2}, index.IDs())
+
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		assert.Equal(t, "foo", files[0].Name())
+	})
+
+	t.Run("background compaction", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		im := indexmanager.New(dir, zerolog.Nop())
+		oldIndex, err := im.CreateIndex(ctx, "foo", sampleConfig)
+		require.NoError(t, err)
+		_, err = oldIndex.AddPointsAutoID(ctx, sampleVectors, 1)
+		require.NoError(t, err)
+		require.NoError(t, oldIndex.MarkDelete(2))
+
+		require.NoError(t, im.StartCompaction("foo"))
+		require.Eventually(t, func() bool {
+			index, _ := im.GetIndex("foo")
+			return index != oldIndex
+		}, 5*time.Second, 10*time.Millisecond)
+
+		index, _ := im.GetIndex("foo")
+		assert.Equal(t, []uint32{1}, index.IDs())
+	})
+
+	t.Run("index does not exist", func(t *testing.T) {
+		t.Parallel()
+		im := indexmanager.New(os.TempDir(), zerolog.Nop())
+		assert.Error(t, im.CompactIndex(ctx, "foo"))
+		assert.Error(t, im.StartCompaction("foo"))
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		im := indexmanager.New(dir, zerolog.Nop())
+		oldIndex, err := im.CreateIndex(ctx, "foo", sampleConfig)
+		require.NoError(t, err)
+
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.ErrorIs(t, im.CompactIndex(canceledCtx, "foo"), context.Canceled)
+
+		index, _ := im.GetIndex("foo")
+		assert.Same(t, oldIndex, index)
+		assert.NoDirExists(t, path.Join(dir, ".foo.compact"))
+
+		// A new compaction can be started
+		assert.NoError(t, im.CompactIndex(ctx, "foo"))
+	})
+}
+
+func TestIndexManager_LoadIndicesLazily(t *testing.T) {
+	t.Parallel()
+
+	newLazyManager := func(t *testing.T, dir string, lazy indexmanager.LazyOptions) *indexmanager.IndexManager {
+		t.Helper()
+		for _, name := range []string{"foo", "bar", "baz"} {
+			createDir(t, path.Join(dir, name))
+			createAndSaveSampleIndex(t, path.Join(dir, name))
+		}
+		im := indexmanager.New(dir, zerolog.Nop())
+		require.NoError(t, im.LoadIndicesLazily(hnswgo.LoadOptions{}, lazy))
+		return im
+	}
+
+	t.Run("indices are loaded on first access", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := newLazyManager(t, dir, indexmanager.LazyOptions{})
+
+		assert.Equal(t, 3, im.Size())
+		assert.False(t, im.IsResident("foo"))
+		assert.False(t, im.IsResident("qux"))
+
+		index, found := im.GetIndex("foo")
+		require.True(t, found)
+		assert.True(t, index.Resident())
+		assert.True(t, im.IsResident("foo"))
+		assert.False(t, im.IsResident("bar"))
+		assert.Len(t, index.IDs(), 2)
+
+		// Indices which are not resident are not swept nor tuned.
+		assert.Zero(t, im.DeleteExpired(time.Now()))
+		im.AutoTuneEf(ctx, indexmanager.EfTuningOptions{TargetRecall: 0.9, K: 1, SampleSize: 2, MaxEf: 10})
+		assert.False(t, im.IsResident("bar"))
+
+		// Without limits, indices are never unloaded.
+		assert.Zero(t, im.Evict(ctx, time.Now().Add(24*time.Hour)))
+	})
+
+	t.Run("idle indices are unloaded", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := newLazyManager(t, dir, indexmanager.LazyOptions{IdleTimeout: time.Hour})
+
+		foo, _ := im.GetIndex("foo")
+		_, err := foo.AddPointAutoID(sampleVectors[0])
+		require.NoError(t, err)
+		_, _ = im.GetIndex("bar")
+
+		assert.Zero(t, im.Evict(ctx, time.Now()))
+		assert.Equal(t, 2, im.Evict(ctx, time.Now().Add(time.Hour)))
+		assert.False(t, im.IsResident("foo"))
+		assert.False(t, im.IsResident("bar"))
+
+		// The changes are saved, and the index is loaded again.
+		foo, found := im.GetIndex("foo")
+		require.True(t, found)
+		assert.Len(t, foo.IDs(), 3)
+	})
+
+	t.Run("least recently used indices are unloaded", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := newLazyManager(t, dir, indexmanager.LazyOptions{MemoryBudget: 1})
+
+		for _, name := range []string{"foo", "bar", "baz"} {
+			_, found := im.GetIndex(name)
+			require.True(t, found)
+			time.Sleep(time.Millisecond)
+		}
+		_, _ = im.GetIndex("foo")
+
+		// The most recently used index is kept, even if above the budget.
+		assert.Equal(t, 2, im.Evict(ctx, time.Now()))
+		assert.True(t, im.IsResident("foo"))
+		assert.False(t, im.IsResident("bar"))
+		assert.False(t, im.IsResident("baz"))
+	})
+
+	t.Run("background evictor", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := newLazyManager(t, dir, indexmanager.LazyOptions{MemoryBudget: 1})
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go im.RunEvictor(ctx, time.Hour)
+
+		_, _ = im.GetIndex("foo")
+		time.Sleep(time.Millisecond)
+		_, _ = im.GetIndex("bar")
+		assert.Eventually(t, func() bool {
+			return !im.IsResident("foo") && im.IsResident("bar")
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("loading errors", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := newLazyManager(t, dir, indexmanager.LazyOptions{})
+		require.NoError(t, os.Remove(path.Join(dir, "foo", "index")))
+
+		index, found := im.GetIndex("foo")
+		assert.Nil(t, index)
+		assert.False(t, found)
+		assert.Equal(t, 3, im.Size())
+
+		_, _, err := im.AcquireIndex("foo")
+		assert.Error(t, err)
+	})
+
+	t.Run("acquired indices are not unloaded", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := newLazyManager(t, dir, indexmanager.LazyOptions{IdleTimeout: time.Hour})
+
+		foo, release, err := im.AcquireIndex("foo")
+		require.NoError(t, err)
+		assert.True(t, foo.Resident())
+		_, _ = im.GetIndex("bar")
+
+		// Only bar is unloaded, until foo is released.
+		assert.Equal(t, 1, im.Evict(ctx, time.Now().Add(time.Hour)))
+		assert.True(t, im.IsResident("foo"))
+		assert.Zero(t, im.Evict(ctx, time.Now().Add(time.Hour)))
+
+		release()
+		assert.Equal(t, 1, im.Evict(ctx, time.Now().Add(time.Hour)))
+		assert.False(t, im.IsResident("foo"))
+
+		_, _, err = im.AcquireIndex("qux")
+		assert.EqualError(t, err, "index does not exist")
+	})
+}
+
+func TestIndexManager_DeleteExpired(t *testing.T) {
+	t.Parallel()
+
+	t.Run("expired vectors of all indices", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		im := indexmanager.New(dir, zerolog.Nop())
+		config := sampleConfig
+		config.DefaultTTL = time.Hour
+		for _, name := range []string{"foo", "bar"} {
+			index, err := im.CreateIndex(ctx, name, config)
+			require.NoError(t, err)
+			_, err = index.AddPointAutoIDWithTTL(sampleVectors[0], time.Minute)
+			require.NoError(t, err)
+			_, err = index.AddPointAutoID(sampleVectors[1])
+			require.NoError(t, err)
+		}
+
+		assert.Zero(t, im.DeleteExpired(time.Now()))
+		assert.Equal(t, 2, im.DeleteExpired(time.Now().Add(30*time.Minute)))
+		for _, name := range []string{"foo", "bar"} {
+			index, _ := im.GetIndex(name)
+			assert.Equal(t, []uint32{2}, index.IDs())
+		}
+	})
+
+	t.Run("background sweeper", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		im := indexmanager.New(dir, zerolog.Nop())
+		index, err := im.CreateIndex(ctx, "foo", sampleConfig)
+		require.NoError(t, err)
+		_, err = index.AddPointAutoIDWithTTL(sampleVectors[0], time.Millisecond)
+		require.NoError(t, err)
+
+		sweeperCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			im.RunExpirySweeper(sweeperCtx, 10*time.Millisecond)
+		}()
+
+		assert.Eventually(t, func() bool {
+			return len(index.IDs()) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+		cancel()
+		<-done
+	})
+}
+
+func TestIndexManager_AutoTuneEf(t *testing.T) {
+	t.Parallel()
+
+	opts := indexmanager.EfTuningOptions{TargetRecall: 1, K: 1, SampleSize: 2, MaxEf: 50}
+
+	t.Run("HNSW indices are tuned", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		im := indexmanager.New(dir, zerolog.Nop())
+		flatConfig := sampleConfig
+		flatConfig.IndexType = hnswgo.FlatIndex
+		indices := make(map[string]*hnswgo.HNSW)
+		for name, config := range map[string]hnswgo.Config{"foo": sampleConfig, "flat": flatConfig, "empty": sampleConfig} {
+			index, err := im.CreateIndex(ctx, name, config)
+			require.NoError(t, err)
+			require.NoError(t, index.SetEf(40))
+			if name != "empty" {
+				_, err = index.AddPointsAutoID(ctx, sampleVectors, 1)
+				require.NoError(t, err)
+			}
+			indices[name] = index
+		}
+
+		im.AutoTuneEf(ctx, opts)
+		assert.Equal(t, 1, indices["foo"].Ef())
+		assert.Equal(t, 40, indices["flat"].Ef())
+		assert.Equal(t, 40, indices["empty"].Ef())
+	})
+
+	t.Run("background tuner", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		im := indexmanager.New(dir, zerolog.Nop())
+		index, err := im.CreateIndex(ctx, "foo", sampleConfig)
+		require.NoError(t, err)
+		_, err = index.AddPointsAutoID(ctx, sampleVectors, 1)
+		require.NoError(t, err)
+		require.NoError(t, index.SetEf(40))
+
+		tunerCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			im.RunEfTuner(tunerCtx, 10*time.Millisecond, opts)
+		}()
+
+		assert.Eventually(t, func() bool {
+			return index.Ef() == 1
+		}, 5*time.Second, 10*time.Millisecond)
+		cancel()
+		<-done
+	})
+}
+
+func TestIndexManager_LoadIndices_InterruptedCompactions(t *testing.T) {
+	t.Parallel()
+	dir := createTempDir(t)
+	defer deleteDir(t, dir)
+
+	// The rebuild of "foo" was interrupted
+	createDir(t, path.Join(dir, "foo"))
+	createAndSaveSampleIndex(t, path.Join(dir, "foo"))
+	createDir(t, path.Join(dir, ".foo.compact"))
+
+	// "bar" was moved, but not yet replaced
+	createDir(t, path.Join(dir, ".bar.compacted"))
+	createAndSaveSampleIndex(t, path.Join(dir, ".bar.compacted"))
+	createDir(t, path.Join(dir, ".bar.compact"))
+
+	// "baz" was replaced, but the old dir was not removed
+	createDir(t, path.Join(dir, "baz"))
+	createAndSaveSampleIndex(t, path.Join(dir, "baz"))
+	createDir(t, path.Join(dir, ".baz.compacted"))
+
+	im := indexmanager.New(dir, zerolog.Nop())
+	require.NoError(t, im.LoadIndices())
+	assert.ElementsMatch(t, []string{"foo", "bar", "baz"}, im.IndicesNames())
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 3)
+}
+
 var sampleVectors = [][]float32{
 	{0.1, 0.2, 0.3, 0.4, 0.5},
 	{0.9, 0.8, 0.7, 0.6, 0.5},
@@ -373,7 +843,7 @@ func createAndSaveSampleIndex(t *testing.T, dir string) {
 		_, err := hnsw.AddPointAutoID(vector)
 		require.NoError(t, err)
 	}
-	require.NoError(t, hnsw.Save())
+	require.NoError(t, hnsw.Save(ctx))
 
 	require.FileExists(t, path.Join(dir, "state"))
 	require.FileExists(t, path.Join(dir, "index"))
@@ -0,0 +1,246 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager
+
+import (
+	"errors"
+	"fmt"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/osutils"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// quarantineDir is the hidden dir, in the indices dir, where the
+	// indices which fail to load are moved if the quarantine is enabled.
+	quarantineDir = ".quarantine"
+	// quarantineReasonFile is the file, in the dir of a quarantined index,
+	// which contains the error which made the loading fail.
+	quarantineReasonFile = "quarantine-reason"
+)
+
+// FailedIndex describes an index which could not be loaded.
+type FailedIndex struct {
+	// Name is the name of the index.
+	Name string
+	// Err is the error which made the loading fail.
+	Err error
+	// Quarantined reports whether the dir of the index was moved to the
+	// quarantine dir (see IndexManager.EnableQuarantine).
+	Quarantined bool
+}
+
+// EnableQuarantine makes the dirs of the indices which fail to load be
+// moved to the ".quarantine" dir, in the configured path, along with the
+// error which made the loading fail. Quarantined indices are no longer
+// loaded at startup, but they are still reported as failed, with the same
+// error, until they are deleted or loaded again (see RetryIndex).
+//
+// Indices saved afterwards keep their previous snapshot, so that they can
+// be repaired (see RetryIndex and hnswgo.HNSW.SetKeepPrevious), taking
+// about twice the disk space.
+//
+// The quarantine has no effect if the indices are loaded read-only.
+func (im *IndexManager) EnableQuarantine() {
+	im.rwMx.Lock()
+	defer im.rwMx.Unlock()
+	im.quarantine = true
+	for _, index := range im.indices {
+		index.SetKeepPrevious(true)
+	}
+}
+
+// indexLoadOptions returns the options of the indices loaded from disk,
+// keeping their previous snapshots if the quarantine is enabled. The index
+// manager must be locked.
+func (im *IndexManager) indexLoadOptions() hnswgo.LoadOptions {
+	opts := im.loadOptions
+	opts.KeepPrevious = im.quarantine
+	return opts
+}
+
+// FailedIndices returns the indices which could not be loaded, sorted by
+// name.
+func (im *IndexManager) FailedIndices() []FailedIndex {
+	im.rwMx.RLock()
+	defer im.rwMx.RUnlock()
+
+	failures := make([]FailedIndex, 0, len(im.failures))
+	for _, failure := range im.failures {
+		failures = append(failures, failure)
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].Name < failures[j].Name
+	})
+	return failures
+}
+
+// RetryIndex loads again an index which could not be loaded, moving its dir
+// back from the quarantine dir, if it was quarantined.
+//
+// If repair is true, the last snapshot of the index is first replaced with
+// the previous one, and the write-ahead log written since the previous
+// snapshot is replayed (see hnswgo.RestorePrevious).
+//
+// If the loading fails again, the index is still failed, and quarantined
+// again if the quarantine is enabled. If the indices are loaded read-only,
+// quarantined indices cannot be moved back, nor indices be repaired:
+// hnswgo.ErrReadOnly is returned.
+func (im *IndexManager) RetryIndex(name string, repair bool) error {
+	im.rwMx.Lock()
+	failure, failed := im.failures[name]
+	if !failed {
+		status := im.status(name)
+		im.rwMx.Unlock()
+		return fmt.Errorf("index %#v is %s, not failed", name, status)
+	}
+	if im.loadOptions.ReadOnly && (repair || failure.Quarantined) {
+		im.rwMx.Unlock()
+		return hnswgo.ErrReadOnly
+	}
+	if failure.Quarantined {
+		if err := im.unquarantineIndex(name); err != nil {
+			im.rwMx.Unlock()
+			return err
+		}
+	}
+	delete(im.failures, name)
+	im.loading[name] = true
+	im.notifyStatus(name)
+	opts := im.indexLoadOptions()
+	im.rwMx.Unlock()
+
+	logger := im.loggerForIndex(name)
+	logger.Info().Msgf("retrying to load index (repair: %t)...", repair)
+	startTime := time.Now()
+	if repair {
+		if err := hnswgo.RestorePrevious(path.Join(im.path, name)); err != nil {
+			err = fmt.Errorf("error repairing index %#v: %w", name, err)
+			im.failIndex(name, err)
+			return err
+		}
+	}
+	storage, err := im.loadIndex(name, opts)
+	if err != nil {
+		return err
+	}
+	logger.Info().Msgf("index loaded %s in %s", storage, time.Since(startTime).Round(time.Millisecond))
+	return nil
+}
+
+// failIndex marks failed an index which was loading, quarantining it if
+// the quarantine is enabled.
+func (im *IndexManager) failIndex(name string, err error) {
+	im.rwMx.RLock()
+	quarantine := im.quarantine && !im.loadOptions.ReadOnly
+	im.rwMx.RUnlock()
+
+	// The dir of an index which is loading is not changed by any other
+	// operation.
+	quarantined := quarantine && im.quarantineIndex(name, err)
+
+	im.rwMx.Lock()
+	defer im.rwMx.Unlock()
+	delete(im.loading, name)
+	im.failures[name] = FailedIndex{Name: name, Err: err, Quarantined: quarantined}
+	im.notifyStatus(name)
+}
+
+// quarantineIndex moves the dir of an index which failed to load to the
+// quarantine dir, replacing any index quarantined with the same name, and
+// writes the error to its reason file. It reports whether the dir was
+// moved. Errors are logged.
+func (im *IndexManager) quarantineIndex(name string, reason error) bool {
+	logger := im.loggerForIndex(name)
+	dir := path.Join(im.path, quarantineDir, name)
+	if err := os.MkdirAll(path.Join(im.path, quarantineDir), 0777); err != nil {
+		logger.Err(err).Msg("error creating the quarantine dir")
+		return false
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		logger.Err(err).Msgf("error removing dir %#v", dir)
+		return false
+	}
+	if err := os.Rename(path.Join(im.path, name), dir); err != nil {
+		logger.Err(err).Msg("error quarantining index")
+		return false
+	}
+	err := os.WriteFile(path.Join(dir, quarantineReasonFile), []byte(reason.Error()+"\n"), 0666)
+	if err != nil {
+		logger.Warn().Err(err).Msg("error writing the quarantine reason")
+	}
+	logger.Warn().Msgf("index quarantined to dir %#v", dir)
+	return true
+}
+
+// unquarantineIndex moves the dir of a quarantined index back to the
+// indices dir. The index manager must be locked for writing.
+func (im *IndexManager) unquarantineIndex(name string) error {
+	dir := path.Join(im.path, name)
+	exists, err := osutils.DirExists(dir)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("cannot restore quarantined index %#v: dir %#v already exists", name, dir)
+	}
+
+	quarantined := path.Join(im.path, quarantineDir, name)
+	if err = os.Rename(quarantined, dir); err != nil {
+		return fmt.Errorf("error moving dir %#v to %#v: %w", quarantined, dir, err)
+	}
+	err = os.Remove(path.Join(dir, quarantineReasonFile))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing the quarantine reason of index %#v: %w", name, err)
+	}
+	failure := im.failures[name]
+	failure.Quarantined = false
+	im.failures[name] = failure
+	return nil
+}
+
+// discoverQuarantined marks failed the indices in the quarantine dir which
+// are not found in the indices dir, with the reason of their quarantine.
+// The index manager must be locked for writing.
+func (im *IndexManager) discoverQuarantined() error {
+	dir := path.Join(im.path, quarantineDir)
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading content of quarantine dir %#v: %w", dir, err)
+	}
+
+	for _, file := range files {
+		name := file.Name()
+		if !file.IsDir() || im.status(name) != IndexNotFound {
+			continue
+		}
+		reason := "quarantined"
+		data, err := os.ReadFile(path.Join(dir, name, quarantineReasonFile))
+		if err == nil && len(strings.TrimSpace(string(data))) > 0 {
+			reason = strings.TrimSpace(string(data))
+		}
+		im.failures[name] = FailedIndex{Name: name, Err: errors.New(reason), Quarantined: true}
+		im.notifyStatus(name)
+		im.logger.Warn().Msgf("index %#v is quarantined: %s", name, reason)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/indexmanager"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestIndexManager_EnableQuarantine(t *testing.T) {
+	t.Parallel()
+
+	t.Run("failed indices are quarantined", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		createDir(t, path.Join(dir, "foo"))
+		createAndSaveSampleIndex(t, path.Join(dir, "foo"))
+		createDir(t, path.Join(dir, "bar"))
+		createAndSaveSampleIndex(t, path.Join(dir, "bar"))
+		require.NoError(t, os.Remove(path.Join(dir, "bar", "index")))
+
+		im := indexmanager.New(dir, zerolog.Nop())
+		im.EnableQuarantine()
+		err := im.LoadIndices()
+		assert.ErrorIs(t, err, indexmanager.ErrIndicesFailed)
+		assert.Equal(t, []string{"foo"}, im.IndicesNames())
+
+		failures := im.FailedIndices()
+		require.Len(t, failures, 1)
+		assert.Equal(t, "bar", failures[0].Name)
+		assert.True(t, failures[0].Quarantined)
+		assert.Error(t, failures[0].Err)
+		assert.NoDirExists(t, path.Join(dir, "bar"))
+		assert.FileExists(t, path.Join(dir, ".quarantine", "bar", "state"))
+		assert.FileExists(t, path.Join(dir, ".quarantine", "bar", "quarantine-reason"))
+
+		// Quarantined indices are not loaded again, but still reported.
+		im = indexmanager.New(dir, zerolog.Nop())
+		require.NoError(t, im.LoadIndices())
+		assert.Equal(t, []string{"foo"}, im.IndicesNames())
+		status, err := im.IndexStatus("bar")
+		assert.Equal(t, indexmanager.IndexFailed, status)
+		assert.Equal(t, failures[0].Err.Error(), err.Error())
+		assert.Equal(t, []indexmanager.FailedIndex{{Name: "bar", Err: err, Quarantined: true}}, im.FailedIndices())
+
+		// The index is quarantined again if it still fails to load.
+		im.EnableQuarantine()
+		assert.Error(t, im.RetryIndex("bar", false))
+		assert.True(t, im.FailedIndices()[0].Quarantined)
+		assert.NoDirExists(t, path.Join(dir, "bar"))
+
+		require.NoError(t, im.DeleteIndex("bar"))
+		assert.NoDirExists(t, path.Join(dir, ".quarantine", "bar"))
+		assert.Empty(t, im.FailedIndices())
+	})
+
+	t.Run("quarantined indices can be repaired", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		im := indexmanager.New(dir, zerolog.Nop())
+		im.EnableQuarantine()
+		index, err := im.CreateIndex(ctx, "foo", sampleConfig)
+		require.NoError(t, err)
+		_, err = index.AddPointAutoID(sampleVectors[0])
+		require.NoError(t, err)
+		require.NoError(t, im.PersistIndex(ctx, "foo"))
+		_, err = index.AddPointAutoID(sampleVectors[1])
+		require.NoError(t, err)
+		require.NoError(t, im.PersistIndex(ctx, "foo"))
+		require.NoError(t, os.WriteFile(path.Join(dir, "foo", "state"), []byte("corrupt"), 0666))
+
+		im = indexmanager.New(dir, zerolog.Nop())
+		im.EnableQuarantine()
+		assert.Error(t, im.LoadIndices())
+		assert.Equal(t, "foo", im.FailedIndices()[0].Name)
+
+		require.NoError(t, im.RetryIndex("foo", true))
+		assert.Empty(t, im.FailedIndices())
+		assert.NoFileExists(t, path.Join(dir, "foo", "quarantine-reason"))
+		index, found := im.GetIndex("foo")
+		require.True(t, found)
+		assert.ElementsMatch(t, []uint32{1, 2}, index.IDs())
+
+		err = im.RetryIndex("foo", false)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "is ready, not failed")
+	})
+
+	t.Run("indices with a torn index file are quarantined", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+
+		im := indexmanager.New(dir, zerolog.Nop())
+		im.EnableQuarantine()
+		index, err := im.CreateIndex(ctx, "foo", sampleConfig)
+		require.NoError(t, err)
+		for _, vector := range sampleVectors {
+			_, err = index.AddPointAutoID(vector)
+			require.NoError(t, err)
+			require.NoError(t, im.PersistIndex(ctx, "foo"))
+		}
+		info, err := os.Stat(path.Join(dir, "foo", "index"))
+		require.NoError(t, err)
+		require.NoError(t, os.Truncate(path.Join(dir, "foo", "index"), info.Size()/2))
+
+		// The loading error does not crash the server.
+		im = indexmanager.New(dir, zerolog.Nop())
+		im.EnableQuarantine()
+		assert.ErrorIs(t, im.LoadIndices(), indexmanager.ErrIndicesFailed)
+		failures := im.FailedIndices()
+		require.Len(t, failures, 1)
+		assert.True(t, failures[0].Quarantined)
+		assert.FileExists(t, path.Join(dir, ".quarantine", "foo", "index"))
+
+		require.NoError(t, im.RetryIndex("foo", true))
+		index, found := im.GetIndex("foo")
+		require.True(t, found)
+		assert.ElementsMatch(t, []uint32{1, 2}, index.IDs())
+	})
+
+	t.Run("failed indices which cannot be repaired", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		createDir(t, path.Join(dir, "foo"))
+		createAndSaveSampleIndex(t, path.Join(dir, "foo"))
+		require.NoError(t, os.Remove(path.Join(dir, "foo", "index")))
+
+		im := indexmanager.New(dir, zerolog.Nop())
+		assert.Error(t, im.LoadIndices())
+
+		err := im.RetryIndex("foo", true)
+		assert.ErrorIs(t, err, hnswgo.ErrNoPreviousSnapshot)
+		failures := im.FailedIndices()
+		require.Len(t, failures, 1)
+		assert.False(t, failures[0].Quarantined)
+		assert.Equal(t, err, failures[0].Err)
+		assert.DirExists(t, path.Join(dir, "foo"))
+	})
+
+	t.Run("read-only indices are not quarantined", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		require.NoError(t, os.Mkdir(path.Join(dir, "foo"), 0777))
+
+		im := indexmanager.New(dir, zerolog.Nop())
+		im.EnableQuarantine()
+		assert.Error(t, im.LoadIndicesWithOptions(hnswgo.LoadOptions{ReadOnly: true}))
+		assert.False(t, im.FailedIndices()[0].Quarantined)
+		assert.DirExists(t, path.Join(dir, "foo"))
+		assert.ErrorIs(t, im.RetryIndex("foo", true), hnswgo.ErrReadOnly)
+		assert.Error(t, im.RetryIndex("foo", false))
+	})
+}
@@ -0,0 +1,141 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrIndexLoading is returned by the operations on indices which are
+	// not loaded yet (see IndexLoading).
+	ErrIndexLoading = errors.New("index is loading")
+	// ErrIndicesFailed is wrapped by the errors reporting the indices which
+	// failed to load (see IndexFailed), while the other ones were loaded.
+	ErrIndicesFailed = errors.New("indices failed to load")
+)
+
+// IndexStatus is the loading status of an index.
+type IndexStatus int
+
+const (
+	// IndexNotFound is the status of the names which do not belong to any
+	// index.
+	IndexNotFound IndexStatus = iota
+	// IndexLoading is the status of the indices which were discovered (see
+	// IndexManager.DiscoverIndices), and are not loaded yet.
+	IndexLoading
+	// IndexReady is the status of the indices which can be used, because
+	// they were loaded or created.
+	IndexReady
+	// IndexFailed is the status of the indices which could not be loaded.
+	IndexFailed
+)
+
+// String returns the name of the status.
+func (s IndexStatus) String() string {
+	switch s {
+	case IndexNotFound:
+		return "not found"
+	case IndexLoading:
+		return "loading"
+	case IndexReady:
+		return "ready"
+	case IndexFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("IndexStatus(%d)", int(s))
+	}
+}
+
+// StatusListener is notified of the changes of the status of the indices
+// (see IndexManager.SetStatusListener). When an index is deleted, its
+// status becomes IndexNotFound.
+//
+// It is called while the IndexManager is locked, so it must not call the
+// IndexManager.
+type StatusListener func(name string, status IndexStatus)
+
+// IndexStatus returns the status of the index with the given name. If the
+// index failed to load, the error is returned as well.
+func (im *IndexManager) IndexStatus(name string) (IndexStatus, error) {
+	im.rwMx.RLock()
+	defer im.rwMx.RUnlock()
+
+	if failure, failed := im.failures[name]; failed {
+		return IndexFailed, failure.Err
+	}
+	return im.status(name), nil
+}
+
+// SetStatusListener sets the function notified of each change of the status
+// of the indices, replacing any previous one, and calls it with the current
+// status of all indices.
+func (im *IndexManager) SetStatusListener(listener StatusListener) {
+	im.rwMx.Lock()
+	defer im.rwMx.Unlock()
+
+	im.statusListener = listener
+	if listener == nil {
+		return
+	}
+	for name := range im.indices {
+		listener(name, IndexReady)
+	}
+	for name := range im.loading {
+		listener(name, IndexLoading)
+	}
+	for name := range im.failures {
+		listener(name, IndexFailed)
+	}
+}
+
+// status returns the status of the index with the given name. The index
+// manager must be locked.
+func (im *IndexManager) status(name string) IndexStatus {
+	if _, ok := im.indices[name]; ok {
+		return IndexReady
+	}
+	if _, ok := im.loading[name]; ok {
+		return IndexLoading
+	}
+	if _, ok := im.failures[name]; ok {
+		return IndexFailed
+	}
+	return IndexNotFound
+}
+
+// notReadyError returns the error of the operations on an index which is
+// not ready. The index manager must be locked.
+func (im *IndexManager) notReadyError(name string) error {
+	switch im.status(name) {
+	case IndexLoading:
+		return fmt.Errorf("%w: %#v", ErrIndexLoading, name)
+	case IndexFailed:
+		return im.failures[name].Err
+	default:
+		return fmt.Errorf("index does not exist")
+	}
+}
+
+// notifyStatus notifies the status listener, if any, of the current status
+// of the index with the given name. The index manager must be locked for
+// writing.
+func (im *IndexManager) notifyStatus(name string) {
+	if im.statusListener != nil {
+		im.statusListener(name, im.status(name))
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexmanager_test
+
+import (
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/indexmanager"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path"
+	"sync"
+	"testing"
+)
+
+func TestIndexManager_DiscoverIndices(t *testing.T) {
+	t.Parallel()
+
+	dir := createTempDir(t)
+	defer deleteDir(t, dir)
+	createDir(t, path.Join(dir, "foo"))
+	createDir(t, path.Join(dir, "bar"))
+	createAndSaveSampleIndex(t, path.Join(dir, "foo"))
+	createAndSaveSampleIndex(t, path.Join(dir, "bar"))
+	// An empty directory is surely not a valid index
+	require.NoError(t, os.Mkdir(path.Join(dir, "not-an-index"), 0777))
+
+	im := indexmanager.New(dir, zerolog.Nop())
+	listener := &statusRecorder{}
+	im.SetStatusListener(listener.record)
+	require.NoError(t, im.DiscoverIndices(hnswgo.LoadOptions{}))
+
+	assert.Equal(t, map[string][]indexmanager.IndexStatus{
+		"foo":          {indexmanager.IndexLoading},
+		"bar":          {indexmanager.IndexLoading},
+		"not-an-index": {indexmanager.IndexLoading},
+	}, listener.statuses())
+
+	// Discovered indices cannot be used until they are loaded.
+	status, err := im.IndexStatus("foo")
+	assert.Equal(t, indexmanager.IndexLoading, status)
+	assert.NoError(t, err)
+	_, found := im.GetIndex("foo")
+	assert.False(t, found)
+	assert.Empty(t, im.IndicesNames())
+	assert.ErrorIs(t, im.PersistIndex(ctx, "foo"), indexmanager.ErrIndexLoading)
+	assert.ErrorIs(t, im.DeleteIndex("foo"), indexmanager.ErrIndexLoading)
+	assert.ErrorIs(t, im.CompactIndex(ctx, "foo"), indexmanager.ErrIndexLoading)
+	_, err = im.CreateIndex(ctx, "foo", sampleConfig)
+	assert.Error(t, err)
+
+	// Discovering the indices again does not change them.
+	require.NoError(t, im.DiscoverIndices(hnswgo.LoadOptions{}))
+	assert.Len(t, listener.statuses()["foo"], 1)
+
+	err = im.LoadDiscoveredIndices(2)
+	assert.ErrorIs(t, err, indexmanager.ErrIndicesFailed)
+	assert.Contains(t, err.Error(), "1 of 3 indices failed to load")
+	assert.ElementsMatch(t, []string{"foo", "bar"}, im.IndicesNames())
+	assert.Equal(t, map[string][]indexmanager.IndexStatus{
+		"foo":          {indexmanager.IndexLoading, indexmanager.IndexReady},
+		"bar":          {indexmanager.IndexLoading, indexmanager.IndexReady},
+		"not-an-index": {indexmanager.IndexLoading, indexmanager.IndexFailed},
+	}, listener.statuses())
+
+	status, err = im.IndexStatus("foo")
+	assert.Equal(t, indexmanager.IndexReady, status)
+	assert.NoError(t, err)
+	index, found := im.GetIndex("foo")
+	require.True(t, found)
+	assert.Len(t, index.IDs(), 2)
+
+	status, err = im.IndexStatus("not-an-index")
+	assert.Equal(t, indexmanager.IndexFailed, status)
+	assert.Error(t, err)
+	assert.Equal(t, err, im.PersistIndex(ctx, "not-an-index"))
+	_, err = im.CreateIndex(ctx, "not-an-index", sampleConfig)
+	assert.Error(t, err)
+
+	// Indices which failed to load can be deleted.
+	require.NoError(t, im.DeleteIndex("not-an-index"))
+	assert.NoDirExists(t, path.Join(dir, "not-an-index"))
+	status, err = im.IndexStatus("not-an-index")
+	assert.Equal(t, indexmanager.IndexNotFound, status)
+	assert.NoError(t, err)
+
+	_, err = im.CreateIndex(ctx, "baz", sampleConfig)
+	require.NoError(t, err)
+	require.NoError(t, im.DeleteIndex("foo"))
+	assert.Equal(t, map[string][]indexmanager.IndexStatus{
+		"foo":          {indexmanager.IndexLoading, indexmanager.IndexReady, indexmanager.IndexNotFound},
+		"bar":          {indexmanager.IndexLoading, indexmanager.IndexReady},
+		"baz":          {indexmanager.IndexReady},
+		"not-an-index": {indexmanager.IndexLoading, indexmanager.IndexFailed, indexmanager.IndexNotFound},
+	}, listener.statuses())
+
+	// A new listener is notified of the current statuses.
+	listener = &statusRecorder{}
+	im.SetStatusListener(listener.record)
+	assert.Equal(t, map[string][]indexmanager.IndexStatus{
+		"bar": {indexmanager.IndexReady},
+		"baz": {indexmanager.IndexReady},
+	}, listener.statuses())
+}
+
+func TestIndexStatus_String(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "not found", indexmanager.IndexNotFound.String())
+	assert.Equal(t, "loading", indexmanager.IndexLoading.String())
+	assert.Equal(t, "ready", indexmanager.IndexReady.String())
+	assert.Equal(t, "failed", indexmanager.IndexFailed.String())
+	assert.Equal(t, "IndexStatus(42)", indexmanager.IndexStatus(42).String())
+}
+
+// statusRecorder records the statuses notified to a StatusListener.
+type statusRecorder struct {
+	mx sync.Mutex
+	m  map[string][]indexmanager.IndexStatus
+}
+
+func (r *statusRecorder) record(name string, status indexmanager.IndexStatus) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if r.m == nil {
+		r.m = make(map[string][]indexmanager.IndexStatus)
+	}
+	r.m[name] = append(r.m[name], status)
+}
+
+func (r *statusRecorder) statuses() map[string][]indexmanager.IndexStatus {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.m
+}
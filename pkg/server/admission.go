@@ -0,0 +1,403 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RPCClass identifies a group of RPC methods sharing the same admission
+// control limits.
+type RPCClass int
+
+const (
+	// SearchClass identifies read-only, latency-critical methods.
+	SearchClass RPCClass = iota
+	// WriteClass identifies methods inserting or modifying data.
+	WriteClass
+	// AdminClass identifies index management methods.
+	AdminClass
+)
+
+// String returns the name of the RPC class.
+func (c RPCClass) String() string {
+	switch c {
+	case SearchClass:
+		return "search"
+	case WriteClass:
+		return "write"
+	case AdminClass:
+		return "admin"
+	default:
+		return fmt.Sprintf("RPCClass(%d)", int(c))
+	}
+}
+
+// serviceMethodPrefix is the prefix of the full method names of the
+// HNSW gRPC service.
+const serviceMethodPrefix = "/grpcapi.Server/"
+
+// methodClasses maps full gRPC method names to their RPC class.
+// Methods of the HNSW service which are not listed here belong to
+// AdminClass; methods of other services (e.g. health checks) are not
+// subject to any limit.
+var methodClasses = map[string]RPCClass{
+	serviceMethodPrefix + "SearchKNN":            SearchClass,
+	serviceMethodPrefix + "GetVectors":           SearchClass,
+	serviceMethodPrefix + "EvaluateRecall":       SearchClass,
+	serviceMethodPrefix + "InsertVector":         WriteClass,
+	serviceMethodPrefix + "InsertVectors":        WriteClass,
+	serviceMethodPrefix + "InsertVectorWithId":   WriteClass,
+	serviceMethodPrefix + "InsertVectorsWithIds": WriteClass,
+	serviceMethodPrefix + "DeleteVectors":        WriteClass,
+	serviceMethodPrefix + "UndeleteVectors":      WriteClass,
+}
+
+// methodClass returns the RPC class of the given full method name, and
+// reports whether the method belongs to the HNSW service at all.
+func methodClass(fullMethod string) (RPCClass, bool) {
+	if class, ok := methodClasses[fullMethod]; ok {
+		return class, true
+	}
+	return AdminClass, strings.HasPrefix(fullMethod, serviceMethodPrefix)
+}
+
+// ClassLimits provides admission control limits for a single RPC class.
+// Zero values mean no limit.
+type ClassLimits struct {
+	// MaxConcurrent is the maximum number of requests served at the same time.
+	MaxConcurrent int
+	// Rate is the maximum number of requests per second.
+	Rate float64
+	// Burst is the token bucket size for Rate. If zero, it defaults to Rate,
+	// rounded up.
+	Burst int
+	// ClientRate is the maximum number of requests per second for each client.
+	ClientRate float64
+	// ClientBurst is the token bucket size for ClientRate.
+	ClientBurst int
+	// IndexRate is the maximum number of requests per second for each index.
+	// Each message received on streaming calls counts as a request.
+	IndexRate float64
+	// IndexBurst is the token bucket size for IndexRate.
+	IndexBurst int
+}
+
+// AdmissionConfig provides admission control limits for each RPC class.
+type AdmissionConfig struct {
+	Search ClassLimits
+	Write  ClassLimits
+	Admin  ClassLimits
+}
+
+// ClientIDMetadataKey is the gRPC metadata key a client can use to identify
+// itself for per-client limits. If missing, the peer IP address is used.
+const ClientIDMetadataKey = "x-client-id"
+
+// concurrencyRetryDelay is the retry hint given when a request is rejected
+// because too many requests of the same class are being served.
+const concurrencyRetryDelay = 50 * time.Millisecond
+
+// maxKeyedBuckets is the amount of per-client or per-index buckets above
+// which idle buckets are discarded.
+const maxKeyedBuckets = 10000
+
+// Admission implements admission control for the gRPC server, with
+// separate concurrency pools and token-bucket rate limits for each RPC class.
+//
+// Excess requests are rejected with codes.ResourceExhausted, carrying an
+// errdetails.RetryInfo detail with a retry hint.
+type Admission struct {
+	classes map[RPCClass]*classLimiter
+}
+
+type classLimiter struct {
+	class     RPCClass
+	slots     chan struct{}
+	bucket    *tokenBucket
+	perClient *keyedBuckets
+	perIndex  *keyedBuckets
+}
+
+// NewAdmission creates a new Admission.
+func NewAdmission(config AdmissionConfig) *Admission {
+	return &Admission{
+		classes: map[RPCClass]*classLimiter{
+			SearchClass: newClassLimiter(SearchClass, config.Search),
+			WriteClass:  newClassLimiter(WriteClass, config.Write),
+			AdminClass:  newClassLimiter(AdminClass, config.Admin),
+		},
+	}
+}
+
+func newClassLimiter(class RPCClass, limits ClassLimits) *classLimiter {
+	cl := &classLimiter{
+		class:     class,
+		bucket:    newTokenBucket(limits.Rate, limits.Burst),
+		perClient: newKeyedBuckets(limits.ClientRate, limits.ClientBurst),
+		perIndex:  newKeyedBuckets(limits.IndexRate, limits.IndexBurst),
+	}
+	if limits.MaxConcurrent > 0 {
+		cl.slots = make(chan struct{}, limits.MaxConcurrent)
+	}
+	return cl
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor performing
+// admission control.
+func (a *Admission) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		cl, ok := a.limiterFor(info.FullMethod)
+		if !ok {
+			return handler(ctx, req)
+		}
+		release, err := cl.admit(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		if err = cl.admitIndex(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor performing
+// admission control. Per-index limits are applied to each received message.
+func (a *Admission) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		cl, ok := a.limiterFor(info.FullMethod)
+		if !ok {
+			return handler(srv, ss)
+		}
+		release, err := cl.admit(ss.Context())
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		return handler(srv, &admissionServerStream{ServerStream: ss, limiter: cl})
+	}
+}
+
+func (a *Admission) limiterFor(fullMethod string) (*classLimiter, bool) {
+	class, ok := methodClass(fullMethod)
+	if !ok {
+		return nil, false
+	}
+	return a.classes[class], true
+}
+
+// admit acquires a concurrency slot, then applies the class and per-client
+// rate limits, so that requests rejected for lack of slots do not take any
+// token. On success, the returned function must be called to release the
+// slot.
+func (cl *classLimiter) admit(ctx context.Context) (func(), error) {
+	release := func() {}
+	if cl.slots != nil {
+		select {
+		case cl.slots <- struct{}{}:
+			release = func() { <-cl.slots }
+		default:
+			return nil, resourceExhausted(concurrencyRetryDelay, "too many concurrent %s requests", cl.class)
+		}
+	}
+
+	now := time.Now()
+	if ok, delay := cl.bucket.take(now); !ok {
+		release()
+		return nil, resourceExhausted(delay, "%s rate limit exceeded", cl.class)
+	}
+	if ok, delay := cl.perClient.take(clientID(ctx), now); !ok {
+		release()
+		return nil, resourceExhausted(delay, "%s rate limit exceeded for client", cl.class)
+	}
+	return release, nil
+}
+
+// admitIndex applies the per-index rate limit, if the request refers to
+// an index.
+func (cl *classLimiter) admitIndex(req interface{}) error {
+	r, ok := req.(interface{ GetIndexName() string })
+	if !ok {
+		return nil
+	}
+	name := r.GetIndexName()
+	if ok, delay := cl.perIndex.take(name, time.Now()); !ok {
+		return resourceExhausted(delay, "%s rate limit exceeded for index %#v", cl.class, name)
+	}
+	return nil
+}
+
+type admissionServerStream struct {
+	grpc.ServerStream
+	limiter *classLimiter
+}
+
+func (s *admissionServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err != nil {
+		return err
+	}
+	return s.limiter.admitIndex(m)
+}
+
+func clientID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(ClientIDMetadataKey); len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr := p.Addr.String()
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			return host
+		}
+		return addr
+	}
+	return ""
+}
+
+func resourceExhausted(retryDelay time.Duration, format string, a ...interface{}) error {
+	st := status.Newf(codes.ResourceExhausted, format, a...)
+	detailed, err := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(retryDelay),
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// tokenBucket is a simple thread-safe token bucket rate limiter.
+// A nil *tokenBucket never limits.
+type tokenBucket struct {
+	mx     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	b := float64(burst)
+	if burst <= 0 {
+		b = math.Ceil(rate)
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  b,
+		tokens: b,
+		last:   time.Now(),
+	}
+}
+
+// take attempts to consume a token. If none is available, it returns false
+// and the estimated time after which a token will be available.
+func (b *tokenBucket) take(now time.Time) (bool, time.Duration) {
+	if b == nil {
+		return true, 0
+	}
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// full reports whether the bucket is completely refilled, that is, it is
+// equivalent to a newly created bucket.
+func (b *tokenBucket) full(now time.Time) bool {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.refill(now)
+	return b.tokens >= b.burst
+}
+
+// keyedBuckets holds a separate token bucket for each key.
+// A nil *keyedBuckets never limits.
+type keyedBuckets struct {
+	mx      sync.Mutex
+	rate    float64
+	burst   int
+	buckets map[string]*tokenBucket
+}
+
+func newKeyedBuckets(rate float64, burst int) *keyedBuckets {
+	if rate <= 0 {
+		return nil
+	}
+	return &keyedBuckets{
+		rate:    rate,
+		burst:   burst,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+func (kb *keyedBuckets) take(key string, now time.Time) (bool, time.Duration) {
+	if kb == nil {
+		return true, 0
+	}
+	return kb.bucket(key, now).take(now)
+}
+
+func (kb *keyedBuckets) bucket(key string, now time.Time) *tokenBucket {
+	kb.mx.Lock()
+	defer kb.mx.Unlock()
+
+	if b, ok := kb.buckets[key]; ok {
+		return b
+	}
+	if len(kb.buckets) >= maxKeyedBuckets {
+		for k, b := range kb.buckets {
+			if b.full(now) {
+				delete(kb.buckets, k)
+			}
+		}
+	}
+	b := newTokenBucket(kb.rate, kb.burst)
+	kb.buckets[key] = b
+	return b
+}
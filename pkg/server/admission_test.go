@@ -0,0 +1,248 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server_test
+
+import (
+	"context"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/grpcapi"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestAdmission_UnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unlimited by default", func(t *testing.T) {
+		t.Parallel()
+		interceptor := server.NewAdmission(server.AdmissionConfig{}).UnaryServerInterceptor()
+
+		for i := 0; i < 100; i++ {
+			_, err := interceptor(ctx, searchRequest("foo"), unaryInfo("SearchKNN"), okHandler)
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("class rate limit", func(t *testing.T) {
+		t.Parallel()
+		interceptor := server.NewAdmission(server.AdmissionConfig{
+			Search: server.ClassLimits{Rate: 0.001, Burst: 2},
+		}).UnaryServerInterceptor()
+
+		for i := 0; i < 2; i++ {
+			_, err := interceptor(ctx, searchRequest("foo"), unaryInfo("SearchKNN"), okHandler)
+			assert.NoError(t, err)
+		}
+		_, err := interceptor(ctx, searchRequest("foo"), unaryInfo("SearchKNN"), okHandler)
+		assertResourceExhaustedWithRetryInfo(t, err)
+
+		// GetVectors shares the search limits
+		_, err = interceptor(ctx, &grpcapi.GetVectorsRequest{IndexName: "foo"}, unaryInfo("GetVectors"), okHandler)
+		assertResourceExhaustedWithRetryInfo(t, err)
+
+		// Other classes are not affected
+		_, err = interceptor(ctx, &grpcapi.InsertVectorRequest{IndexName: "foo"}, unaryInfo("InsertVector"), okHandler)
+		assert.NoError(t, err)
+		_, err = interceptor(ctx, &grpcapi.FlushRequest{IndexName: "foo"}, unaryInfo("FlushIndex"), okHandler)
+		assert.NoError(t, err)
+	})
+
+	t.Run("per-client rate limit", func(t *testing.T) {
+		t.Parallel()
+		interceptor := server.NewAdmission(server.AdmissionConfig{
+			Search: server.ClassLimits{ClientRate: 0.001, ClientBurst: 1},
+		}).UnaryServerInterceptor()
+
+		ctxA := metadata.NewIncomingContext(ctx, metadata.Pairs(server.ClientIDMetadataKey, "a"))
+		ctxB := metadata.NewIncomingContext(ctx, metadata.Pairs(server.ClientIDMetadataKey, "b"))
+
+		_, err := interceptor(ctxA, searchRequest("foo"), unaryInfo("SearchKNN"), okHandler)
+		assert.NoError(t, err)
+		_, err = interceptor(ctxA, searchRequest("foo"), unaryInfo("SearchKNN"), okHandler)
+		assertResourceExhaustedWithRetryInfo(t, err)
+		_, err = interceptor(ctxB, searchRequest("foo"), unaryInfo("SearchKNN"), okHandler)
+		assert.NoError(t, err)
+	})
+
+	t.Run("per-index rate limit", func(t *testing.T) {
+		t.Parallel()
+		interceptor := server.NewAdmission(server.AdmissionConfig{
+			Search: server.ClassLimits{IndexRate: 0.001, IndexBurst: 1},
+		}).UnaryServerInterceptor()
+
+		_, err := interceptor(ctx, searchRequest("foo"), unaryInfo("SearchKNN"), okHandler)
+		assert.NoError(t, err)
+		_, err = interceptor(ctx, searchRequest("foo"), unaryInfo("SearchKNN"), okHandler)
+		assertResourceExhaustedWithRetryInfo(t, err)
+		_, err = interceptor(ctx, searchRequest("bar"), unaryInfo("SearchKNN"), okHandler)
+		assert.NoError(t, err)
+	})
+
+	t.Run("concurrency limit", func(t *testing.T) {
+		t.Parallel()
+		interceptor := server.NewAdmission(server.AdmissionConfig{
+			Search: server.ClassLimits{MaxConcurrent: 1},
+		}).UnaryServerInterceptor()
+
+		entered := make(chan struct{})
+		unblock := make(chan struct{})
+		done := make(chan error)
+		go func() {
+			_, err := interceptor(ctx, searchRequest("foo"), unaryInfo("SearchKNN"),
+				func(context.Context, interface{}) (interface{}, error) {
+					close(entered)
+					<-unblock
+					return nil, nil
+				})
+			done <- err
+		}()
+		<-entered
+
+		_, err := interceptor(ctx, searchRequest("foo"), unaryInfo("SearchKNN"), okHandler)
+		assertResourceExhaustedWithRetryInfo(t, err)
+
+		close(unblock)
+		require.NoError(t, <-done)
+
+		_, err = interceptor(ctx, searchRequest("foo"), unaryInfo("SearchKNN"), okHandler)
+		assert.NoError(t, err)
+	})
+
+	t.Run("concurrency rejections take no tokens", func(t *testing.T) {
+		t.Parallel()
+		interceptor := server.NewAdmission(server.AdmissionConfig{
+			Search: server.ClassLimits{
+				MaxConcurrent: 1,
+				Rate:          0.001,
+				Burst:         2,
+				ClientRate:    0.001,
+				ClientBurst:   2,
+			},
+		}).UnaryServerInterceptor()
+
+		entered := make(chan struct{})
+		unblock := make(chan struct{})
+		done := make(chan error)
+		go func() {
+			_, err := interceptor(ctx, searchRequest("foo"), unaryInfo("SearchKNN"),
+				func(context.Context, interface{}) (interface{}, error) {
+					close(entered)
+					<-unblock
+					return nil, nil
+				})
+			done <- err
+		}()
+		<-entered
+
+		for i := 0; i < 5; i++ {
+			_, err := interceptor(ctx, searchRequest("foo"), unaryInfo("SearchKNN"), okHandler)
+			assertResourceExhaustedWithRetryInfo(t, err)
+			assert.Contains(t, status.Convert(err).Message(), "too many concurrent")
+		}
+
+		close(unblock)
+		require.NoError(t, <-done)
+
+		// The second token of both buckets is still available.
+		_, err := interceptor(ctx, searchRequest("foo"), unaryInfo("SearchKNN"), okHandler)
+		assert.NoError(t, err)
+		_, err = interceptor(ctx, searchRequest("foo"), unaryInfo("SearchKNN"), okHandler)
+		assertResourceExhaustedWithRetryInfo(t, err)
+		assert.Contains(t, status.Convert(err).Message(), "rate limit exceeded")
+	})
+
+	t.Run("other services are not limited", func(t *testing.T) {
+		t.Parallel()
+		interceptor := server.NewAdmission(server.AdmissionConfig{
+			Admin: server.ClassLimits{Rate: 0.001, Burst: 1},
+		}).UnaryServerInterceptor()
+
+		info := &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}
+		for i := 0; i < 10; i++ {
+			_, err := interceptor(ctx, nil, info, okHandler)
+			assert.NoError(t, err)
+		}
+	})
+}
+
+func TestAdmission_StreamServerInterceptor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("per-index rate limit on each message", func(t *testing.T) {
+		t.Parallel()
+		interceptor := server.NewAdmission(server.AdmissionConfig{
+			Write: server.ClassLimits{IndexRate: 0.001, IndexBurst: 2},
+		}).StreamServerInterceptor()
+
+		info := &grpc.StreamServerInfo{FullMethod: "/grpcapi.Server/InsertVectors"}
+
+		received := 0
+		err := interceptor(nil, newInsertVectorsServerStream(nil), info, func(_ interface{}, ss grpc.ServerStream) error {
+			for {
+				// The test stream leaves the message untouched
+				if err := ss.RecvMsg(&grpcapi.InsertVectorRequest{IndexName: "foo"}); err != nil {
+					return err
+				}
+				received++
+			}
+		})
+		assertResourceExhaustedWithRetryInfo(t, err)
+		assert.Equal(t, 2, received)
+	})
+
+	t.Run("class rate limit", func(t *testing.T) {
+		t.Parallel()
+		interceptor := server.NewAdmission(server.AdmissionConfig{
+			Write: server.ClassLimits{Rate: 0.001, Burst: 1},
+		}).StreamServerInterceptor()
+
+		info := &grpc.StreamServerInfo{FullMethod: "/grpcapi.Server/InsertVectors"}
+		handler := func(interface{}, grpc.ServerStream) error { return nil }
+
+		assert.NoError(t, interceptor(nil, newInsertVectorsServerStream(nil), info, handler))
+		err := interceptor(nil, newInsertVectorsServerStream(nil), info, handler)
+		assertResourceExhaustedWithRetryInfo(t, err)
+	})
+}
+
+func searchRequest(indexName string) *grpcapi.SearchRequest {
+	return &grpcapi.SearchRequest{IndexName: indexName}
+}
+
+func unaryInfo(method string) *grpc.UnaryServerInfo {
+	return &grpc.UnaryServerInfo{FullMethod: "/grpcapi.Server/" + method}
+}
+
+func okHandler(context.Context, interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func assertResourceExhaustedWithRetryInfo(t *testing.T, err error) {
+	t.Helper()
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+
+	require.Len(t, st.Details(), 1)
+	retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	assert.Greater(t, retryInfo.GetRetryDelay().AsDuration().Nanoseconds(), int64(0))
+}
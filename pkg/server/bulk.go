@@ -0,0 +1,190 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// defaultInsertBatchSize is the amount of vectors inserted at once by
+// streaming calls, if not configured otherwise.
+const defaultInsertBatchSize = 1024
+
+// bulkInserter pipelines the insertion of vectors received from a stream.
+//
+// Items are added from the receiving goroutine, and grouped in batches.
+// Each full batch is handed over to a separate goroutine, which inserts it
+// with a bounded amount of concurrent workers (see hnswgo.HNSW.AddPoints),
+// while the next batch is being received.
+type bulkInserter struct {
+	ctx       context.Context
+	autoID    bool
+	workers   int
+	batchSize int
+
+	batch   []bulkItem
+	batches chan []bulkItem
+	failed  chan struct{}
+	done    chan struct{}
+	once    sync.Once
+
+	// Only accessed by the inserting goroutine, or after done is closed.
+	err error
+	ids []uint32
+}
+
+type bulkItem struct {
+	index  *hnswgo.HNSW
+	vector []float32
+	id     uint32
+	ttl    time.Duration
+}
+
+// newBulkInserter creates a new bulkInserter and starts its inserting
+// goroutine. The bulkInserter must be closed when no longer used.
+func (s *Server) newBulkInserter(ctx context.Context, autoID bool) *bulkInserter {
+	workers := s.config.InsertWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	batchSize := s.config.InsertBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultInsertBatchSize
+	}
+
+	b := &bulkInserter{
+		ctx:       ctx,
+		autoID:    autoID,
+		workers:   workers,
+		batchSize: batchSize,
+		batch:     make([]bulkItem, 0, batchSize),
+		batches:   make(chan []bulkItem, 1),
+		failed:    make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// add schedules the insertion of a new vector, with the given time to live
+// (see hnswgo.HNSW.AddPointWithTTL). The id is ignored for auto-ID
+// insertions. It returns an error if the insertion of a previous batch
+// failed.
+func (b *bulkInserter) add(index *hnswgo.HNSW, vector []float32, id uint32, ttl time.Duration) error {
+	b.batch = append(b.batch, bulkItem{index: index, vector: vector, id: id, ttl: ttl})
+	if len(b.batch) < b.batchSize {
+		return nil
+	}
+	return b.flush()
+}
+
+// finish inserts any pending item and waits for all insertions to be done.
+// For auto-ID insertions, it returns the generated IDs, in the same order
+// as the items were added.
+func (b *bulkInserter) finish() ([]uint32, error) {
+	if len(b.batch) > 0 {
+		if err := b.flush(); err != nil {
+			return nil, err
+		}
+	}
+	b.close()
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.ids, nil
+}
+
+// close stops the inserting goroutine, waiting for the current batch
+// (if any) to be inserted. It can be called more than once.
+func (b *bulkInserter) close() {
+	b.once.Do(func() {
+		close(b.batches)
+	})
+	<-b.done
+}
+
+func (b *bulkInserter) flush() error {
+	batch := b.batch
+	b.batch = make([]bulkItem, 0, b.batchSize)
+
+	select {
+	case b.batches <- batch:
+		return nil
+	case <-b.failed:
+		b.close()
+		return b.err
+	}
+}
+
+func (b *bulkInserter) run() {
+	defer close(b.done)
+	for batch := range b.batches {
+		if b.err != nil {
+			continue
+		}
+		if err := b.insert(batch); err != nil {
+			b.err = err
+			close(b.failed)
+		}
+	}
+}
+
+type bulkGroup struct {
+	vectors   [][]float32
+	ids       []uint32
+	ttls      []time.Duration
+	positions []int
+}
+
+func (b *bulkInserter) insert(batch []bulkItem) error {
+	offset := len(b.ids)
+	if b.autoID {
+		b.ids = append(b.ids, make([]uint32, len(batch))...)
+	}
+
+	groups := make(map[*hnswgo.HNSW]*bulkGroup)
+	for i, item := range batch {
+		g, ok := groups[item.index]
+		if !ok {
+			g = &bulkGroup{}
+			groups[item.index] = g
+		}
+		g.vectors = append(g.vectors, item.vector)
+		g.ids = append(g.ids, item.id)
+		g.ttls = append(g.ttls, item.ttl)
+		g.positions = append(g.positions, offset+i)
+	}
+
+	for index, g := range groups {
+		if !b.autoID {
+			if err := index.AddPointsWithTTL(b.ctx, g.vectors, g.ids, g.ttls, b.workers); err != nil {
+				return err
+			}
+			continue
+		}
+		ids, err := index.AddPointsAutoIDWithTTL(b.ctx, g.vectors, g.ttls, b.workers)
+		if err != nil {
+			return err
+		}
+		for i, pos := range g.positions {
+			b.ids[pos] = ids[i]
+		}
+	}
+	return nil
+}
@@ -20,4 +20,16 @@ type Config struct {
 	TLSEnabled bool
 	TLSCert    string
 	TLSKey     string
+	// Admission provides admission control limits for each RPC class.
+	Admission AdmissionConfig
+	// InsertWorkers is the maximum number of workers concurrently inserting
+	// vectors received by each streaming call. If zero, the number of
+	// CPUs is used.
+	InsertWorkers int
+	// InsertBatchSize is the amount of vectors inserted (and written to the
+	// write-ahead log) at once by streaming calls. If zero, a default
+	// value is used.
+	InsertBatchSize int
+	// Deadlines provides the maximum duration of requests for each RPC class.
+	Deadlines DeadlineConfig
 }
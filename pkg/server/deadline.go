@@ -0,0 +1,95 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"time"
+)
+
+// DeadlineConfig provides the maximum duration of requests for each RPC
+// class. Zero values mean no limit.
+type DeadlineConfig struct {
+	Search time.Duration
+	Write  time.Duration
+	Admin  time.Duration
+}
+
+// Deadlines enforces a server-side maximum deadline for each RPC class.
+//
+// The context of each request is given a deadline which is the earliest
+// between the one set by the client (if any) and the configured maximum.
+type Deadlines struct {
+	durations map[RPCClass]time.Duration
+}
+
+// NewDeadlines creates a new Deadlines.
+func NewDeadlines(config DeadlineConfig) *Deadlines {
+	return &Deadlines{
+		durations: map[RPCClass]time.Duration{
+			SearchClass: config.Search,
+			WriteClass:  config.Write,
+			AdminClass:  config.Admin,
+		},
+	}
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor enforcing
+// the maximum deadlines.
+func (d *Deadlines) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		max, ok := d.maxDuration(info.FullMethod)
+		if !ok {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, max)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor enforcing
+// the maximum deadlines.
+func (d *Deadlines) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		max, ok := d.maxDuration(info.FullMethod)
+		if !ok {
+			return handler(srv, ss)
+		}
+		ctx, cancel := context.WithTimeout(ss.Context(), max)
+		defer cancel()
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (d *Deadlines) maxDuration(fullMethod string) (time.Duration, bool) {
+	class, ok := methodClass(fullMethod)
+	if !ok {
+		return 0, false
+	}
+	max := d.durations[class]
+	return max, max > 0
+}
+
+// contextServerStream is a grpc.ServerStream with a custom context.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
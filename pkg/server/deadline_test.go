@@ -0,0 +1,88 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server_test
+
+import (
+	"context"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"testing"
+	"time"
+)
+
+func TestDeadlines_UnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+
+	interceptor := server.NewDeadlines(server.DeadlineConfig{
+		Search: time.Minute,
+	}).UnaryServerInterceptor()
+
+	t.Run("maximum deadline is set", func(t *testing.T) {
+		t.Parallel()
+		deadline := captureUnaryDeadline(t, interceptor, ctx, "SearchKNN")
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 10*time.Second)
+	})
+
+	t.Run("earlier client deadline is kept", func(t *testing.T) {
+		t.Parallel()
+		clientCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		expected, _ := clientCtx.Deadline()
+
+		deadline := captureUnaryDeadline(t, interceptor, clientCtx, "SearchKNN")
+		assert.Equal(t, expected, deadline)
+	})
+
+	t.Run("classes without maximum deadline", func(t *testing.T) {
+		t.Parallel()
+		_, err := interceptor(ctx, nil, unaryInfo("InsertVector"), func(ctx context.Context, _ interface{}) (interface{}, error) {
+			_, ok := ctx.Deadline()
+			assert.False(t, ok)
+			return nil, nil
+		})
+		assert.NoError(t, err)
+	})
+}
+
+func TestDeadlines_StreamServerInterceptor(t *testing.T) {
+	t.Parallel()
+
+	interceptor := server.NewDeadlines(server.DeadlineConfig{
+		Write: time.Minute,
+	}).StreamServerInterceptor()
+
+	info := &grpc.StreamServerInfo{FullMethod: "/grpcapi.Server/InsertVectors"}
+	err := interceptor(nil, newInsertVectorsServerStream(nil), info, func(_ interface{}, ss grpc.ServerStream) error {
+		deadline, ok := ss.Context().Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 10*time.Second)
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func captureUnaryDeadline(t *testing.T, interceptor grpc.UnaryServerInterceptor, c context.Context, method string) time.Time {
+	t.Helper()
+	var deadline time.Time
+	var ok bool
+	_, err := interceptor(c, nil, unaryInfo(method), func(ctx context.Context, _ interface{}) (interface{}, error) {
+		deadline, ok = ctx.Deadline()
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	return deadline
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/indexmanager"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// indexHealthServicePrefix is the prefix of the health service names of the
+// indices.
+const indexHealthServicePrefix = "index/"
+
+// IndexHealthService returns the name of the service reporting the status
+// of the given index through the gRPC health checking protocol: SERVING
+// once the index is ready, NOT_SERVING while it is loading, or if it failed
+// to load, and SERVICE_UNKNOWN once it is deleted. The overall health of the
+// server (the empty service name) is SERVING as soon as it listens.
+func IndexHealthService(name string) string {
+	return indexHealthServicePrefix + name
+}
+
+var healthStatusMap = map[indexmanager.IndexStatus]grpc_health_v1.HealthCheckResponse_ServingStatus{
+	indexmanager.IndexNotFound: grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN,
+	indexmanager.IndexLoading:  grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+	indexmanager.IndexReady:    grpc_health_v1.HealthCheckResponse_SERVING,
+	indexmanager.IndexFailed:   grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+}
+
+// newHealthServer creates a health server kept up to date with the status
+// of the indices of the manager.
+func newHealthServer(indexManager *indexmanager.IndexManager) *health.Server {
+	hs := health.NewServer()
+	indexManager.SetStatusListener(func(name string, status indexmanager.IndexStatus) {
+		hs.SetServingStatus(IndexHealthService(name), healthStatusMap[status])
+	})
+	return hs
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server_test
+
+import (
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/grpcapi"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/indexmanager"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/server"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestServer_Health(t *testing.T) {
+	t.Parallel()
+	dir := createTempDir(t)
+	defer deleteDir(t, dir)
+
+	im := indexmanager.New(dir, zerolog.Nop())
+	index, err := im.CreateIndex(ctx, "foo", hnswgo.Config{
+		SpaceType:      hnswgo.L2Space,
+		Dim:            5,
+		MaxElements:    10,
+		M:              10,
+		EfConstruction: 200,
+		AutoIDEnabled:  true,
+	})
+	require.NoError(t, err)
+	_, err = index.AddPointAutoID(sampleVectors[0])
+	require.NoError(t, err)
+	require.NoError(t, im.PersistIndex(ctx, "foo"))
+	// An empty directory is surely not a valid index
+	require.NoError(t, os.Mkdir(path.Join(dir, "bar"), 0777))
+
+	im = indexmanager.New(dir, zerolog.Nop())
+	require.NoError(t, im.DiscoverIndices(hnswgo.LoadOptions{}))
+
+	srv := server.New(server.Config{Address: "127.0.0.1:0"}, im, zerolog.Nop())
+	listener, err := srv.Listen()
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	health := grpc_health_v1.NewHealthClient(conn)
+	client := grpcapi.NewServerClient(conn)
+
+	checkHealth := func(service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		resp, err := health.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		return resp.GetStatus()
+	}
+	search := &grpcapi.SearchRequest{IndexName: "foo", Vector: &grpcapi.Vector{Value: sampleVectors[0]}, K: 1}
+
+	// The server is serving while the indices are loading.
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, checkHealth(""))
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, checkHealth(server.IndexHealthService("foo")))
+	_, err = client.SearchKNN(ctx, search)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	_, err = client.FlushIndex(ctx, &grpcapi.FlushRequest{IndexName: "foo"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	description, err := client.DescribeIndex(ctx, &grpcapi.DescribeIndexRequest{IndexName: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, grpcapi.IndexStatus_LOADING, description.GetStatus())
+	assert.Zero(t, description.GetDim())
+
+	assert.Error(t, im.LoadDiscoveredIndices(1))
+
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, checkHealth(server.IndexHealthService("foo")))
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, checkHealth(server.IndexHealthService("bar")))
+	resp, err := client.SearchKNN(ctx, search)
+	require.NoError(t, err)
+	assert.Len(t, resp.GetHits(), 1)
+	description, err = client.DescribeIndex(ctx, &grpcapi.DescribeIndexRequest{IndexName: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, grpcapi.IndexStatus_READY, description.GetStatus())
+	assert.Equal(t, int32(5), description.GetDim())
+
+	description, err = client.DescribeIndex(ctx, &grpcapi.DescribeIndexRequest{IndexName: "bar"})
+	require.NoError(t, err)
+	assert.Equal(t, grpcapi.IndexStatus_FAILED, description.GetStatus())
+	assert.NotEmpty(t, description.GetLoadError())
+	_, err = client.SearchKNN(ctx, &grpcapi.SearchRequest{IndexName: "bar", Vector: search.Vector, K: 1})
+	assert.Error(t, err)
+	assert.NotEqual(t, codes.Unavailable, status.Code(err))
+
+	_, err = client.DeleteIndex(ctx, &grpcapi.DeleteIndexRequest{IndexName: "bar"})
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN, checkHealth(server.IndexHealthService("bar")))
+	_, err = client.DescribeIndex(ctx, &grpcapi.DescribeIndexRequest{IndexName: "bar"})
+	assert.Error(t, err)
+}
@@ -21,7 +21,6 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/status"
 	"net"
@@ -30,33 +29,59 @@ import (
 
 // Run runs the server according to the configuration.
 func (s *Server) Run() error {
-	serverOptions, err := s.createServerOptions()
+	listener, err := s.Listen()
 	if err != nil {
 		return err
 	}
+	return s.Serve(listener)
+}
 
-	grpcServer := grpc.NewServer(serverOptions...)
-	grpcapi.RegisterServerServer(grpcServer, s)
-	grpc_health_v1.RegisterHealthServer(grpcServer, health.NewServer())
-
+// Listen announces on the configured address, letting other tasks, like
+// the loading of the indices, start once the address is bound, and before
+// Serve is called.
+func (s *Server) Listen() (net.Listener, error) {
 	listener, err := net.Listen("tcp", s.config.Address)
 	if err != nil {
-		return fmt.Errorf("TCP listen error: %w", err)
+		return nil, fmt.Errorf("TCP listen error: %w", err)
+	}
+	return listener, nil
+}
+
+// Serve serves the requests received by the listener, until it fails. The
+// health service reports the status of each index (see
+// IndexHealthService).
+func (s *Server) Serve(listener net.Listener) error {
+	serverOptions, err := s.createServerOptions()
+	if err != nil {
+		return err
 	}
 
-	s.logger.Info().Msgf("Serving on %s", s.config.Address)
+	grpcServer := grpc.NewServer(serverOptions...)
+	grpcapi.RegisterServerServer(grpcServer, s)
+	grpc_health_v1.RegisterHealthServer(grpcServer, s.health)
+
+	s.logger.Info().Msgf("Serving on %s", listener.Addr())
 	return grpcServer.Serve(listener)
 }
 
 func (s *Server) createServerOptions() ([]grpc.ServerOption, error) {
+	admission := NewAdmission(s.config.Admission)
+	deadlines := NewDeadlines(s.config.Deadlines)
+
 	options := []grpc.ServerOption{
-		grpc.UnaryInterceptor(
+		grpc.ChainUnaryInterceptor(
 			grpcrecovery.UnaryServerInterceptor(
 				grpcrecovery.WithRecoveryHandler(func(p interface{}) error {
 					s.logger.Error().Msgf("Panic! Stack trace:\n%s", string(debug.Stack()))
 					return status.Errorf(codes.Internal, "panic: %v", p)
 				}),
 			),
+			admission.UnaryServerInterceptor(),
+			deadlines.UnaryServerInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			admission.StreamServerInterceptor(),
+			deadlines.StreamServerInterceptor(),
 		),
 	}
 
@@ -16,13 +16,19 @@ package server
 
 import (
 	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/grpcapi"
 	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
 	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/indexmanager"
 	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"io"
+	"math"
 	"strings"
 	"time"
 )
@@ -33,6 +39,8 @@ type Server struct {
 	config       Config
 	indexManager *indexmanager.IndexManager
 	logger       zerolog.Logger
+	// health reports the status of the indices.
+	health *health.Server
 }
 
 var _ grpcapi.ServerServer = &Server{}
@@ -43,39 +51,76 @@ func New(config Config, indexManager *indexmanager.IndexManager, logger zerolog.
 		config:       config,
 		indexManager: indexManager,
 		logger:       logger,
+		health:       newHealthServer(indexManager),
 	}
 }
 
 var spaceTypeMap = map[grpcapi.CreateIndexRequest_SpaceType]hnswgo.SpaceType{
-	grpcapi.CreateIndexRequest_L2:     hnswgo.L2Space,
-	grpcapi.CreateIndexRequest_IP:     hnswgo.IPSpace,
-	grpcapi.CreateIndexRequest_COSINE: hnswgo.CosineSpace,
+	grpcapi.CreateIndexRequest_L2:      hnswgo.L2Space,
+	grpcapi.CreateIndexRequest_IP:      hnswgo.IPSpace,
+	grpcapi.CreateIndexRequest_COSINE:  hnswgo.CosineSpace,
+	grpcapi.CreateIndexRequest_HAMMING: hnswgo.HammingSpace,
+	grpcapi.CreateIndexRequest_L1:      hnswgo.L1Space,
+}
+
+var indexTypeMap = map[grpcapi.CreateIndexRequest_IndexType]hnswgo.IndexType{
+	grpcapi.CreateIndexRequest_HNSW: hnswgo.HNSWIndex,
+	grpcapi.CreateIndexRequest_FLAT: hnswgo.FlatIndex,
+}
+
+var storageTypeMap = map[grpcapi.CreateIndexRequest_StorageType]hnswgo.StorageType{
+	grpcapi.CreateIndexRequest_FLOAT32:  hnswgo.Float32Storage,
+	grpcapi.CreateIndexRequest_SQ8:      hnswgo.SQ8Storage,
+	grpcapi.CreateIndexRequest_PQ:       hnswgo.PQStorage,
+	grpcapi.CreateIndexRequest_FLOAT16:  hnswgo.Float16Storage,
+	grpcapi.CreateIndexRequest_BFLOAT16: hnswgo.BFloat16Storage,
 }
 
 // CreateIndex makes a new index.
-func (s *Server) CreateIndex(_ context.Context, req *grpcapi.CreateIndexRequest) (*emptypb.Empty, error) {
+func (s *Server) CreateIndex(ctx context.Context, req *grpcapi.CreateIndexRequest) (*emptypb.Empty, error) {
 	s.logger.Debug().Interface("req", req).Msg("Server.CreateIndex")
 
 	spaceType, ok := spaceTypeMap[req.GetSpaceType()]
 	if !ok {
 		return nil, fmt.Errorf("invalid space type [%v]", req.GetSpaceType())
 	}
+	indexType, ok := indexTypeMap[req.GetIndexType()]
+	if !ok {
+		return nil, fmt.Errorf("invalid index type [%v]", req.GetIndexType())
+	}
+	storageType, ok := storageTypeMap[req.GetStorageType()]
+	if !ok {
+		return nil, fmt.Errorf("invalid storage type [%v]", req.GetStorageType())
+	}
+	if req.GetDefaultTtlSeconds() < 0 {
+		return nil, fmt.Errorf("invalid default TTL %d: it must not be negative", req.GetDefaultTtlSeconds())
+	}
 
-	_, err := s.indexManager.CreateIndex(
-		req.GetIndexName(),
-		hnswgo.Config{
-			SpaceType:      spaceType,
-			Dim:            int(req.GetDim()),
-			MaxElements:    int(req.GetMaxElements()),
-			M:              int(req.GetM()),
-			EfConstruction: int(req.GetEfConstruction()),
-			RandSeed:       int(req.GetSeed()),
-			AutoIDEnabled:  req.GetAutoId(),
-		},
-	)
-	if err != nil {
+	config := hnswgo.Config{
+		SpaceType:           spaceType,
+		Dim:                 int(req.GetDim()),
+		MaxElements:         int(req.GetMaxElements()),
+		M:                   int(req.GetM()),
+		EfConstruction:      int(req.GetEfConstruction()),
+		RandSeed:            int(req.GetSeed()),
+		AutoIDEnabled:       req.GetAutoId(),
+		AllowReplaceDeleted: req.GetAllowReplaceDeleted(),
+		IndexType:           indexType,
+		Storage:             storageType,
+		TrainingSize:        int(req.GetTrainingSize()),
+		PQSubquantizers:     int(req.GetPqSubquantizers()),
+		PQBits:              int(req.GetPqBits()),
+		Rerank:              req.GetRerank(),
+		DefaultTTL:          secondsToDuration(req.GetDefaultTtlSeconds()),
+	}
+	if err := config.ValidateStorage(); err != nil {
 		return nil, err
 	}
+
+	_, err := s.indexManager.CreateIndex(ctx, req.GetIndexName(), config)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
 	return &emptypb.Empty{}, nil
 }
 
@@ -85,7 +130,7 @@ func (s *Server) DeleteIndex(_ context.Context, req *grpcapi.DeleteIndexRequest)
 
 	err := s.indexManager.DeleteIndex(req.GetIndexName())
 	if err != nil {
-		return nil, err
+		return nil, indexManagerError(err)
 	}
 	return &emptypb.Empty{}, nil
 }
@@ -96,12 +141,17 @@ func (s *Server) InsertVector(_ context.Context, req *grpcapi.InsertVectorReques
 
 	startTime := time.Now()
 
-	index, indexExists := s.indexManager.GetIndex(req.GetIndexName())
-	if !indexExists {
-		return nil, fmt.Errorf("index not found")
+	index, release, err := s.getIndex(req.GetIndexName())
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
-	id, err := index.AddPointAutoID(req.GetVector().GetValue())
+	vector, err := decodeVector(req.GetVector(), index.Config().Dim)
+	if err != nil {
+		return nil, err
+	}
+	id, err := index.AddPointAutoIDWithTTL(vector, secondsToDuration(req.GetTtlSeconds()))
 	if err != nil {
 		return nil, err
 	}
@@ -117,12 +167,17 @@ func (s *Server) InsertVectorWithId(_ context.Context, req *grpcapi.InsertVector
 
 	startTime := time.Now()
 
-	index, indexExists := s.indexManager.GetIndex(req.GetIndexName())
-	if !indexExists {
-		return nil, fmt.Errorf("index not found")
+	index, release, err := s.getIndex(req.GetIndexName())
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
-	err := index.AddPoint(req.GetVector().GetValue(), uint32(req.GetId()))
+	vector, err := decodeVector(req.GetVector(), index.Config().Dim)
+	if err != nil {
+		return nil, err
+	}
+	err = index.AddPointWithTTL(vector, uint32(req.GetId()), secondsToDuration(req.GetTtlSeconds()))
 	if err != nil {
 		return nil, err
 	}
@@ -132,15 +187,26 @@ func (s *Server) InsertVectorWithId(_ context.Context, req *grpcapi.InsertVector
 }
 
 // InsertVectors inserts the new vectors in the given index. It flushes the index at each batch.
+//
+// Vectors are received and validated on the calling goroutine, and
+// concurrently inserted in batches (see bulkInserter).
 func (s *Server) InsertVectors(stream grpcapi.Server_InsertVectorsServer) error {
 	s.logger.Debug().Msg("Server.InsertVectors")
 
 	startTime := time.Now()
+	ctx := stream.Context()
 
-	ids := make([]string, 0)
+	// The indices are released once the inserter is closed.
+	indices := s.newStreamIndices()
+	defer indices.release()
+	inserter := s.newBulkInserter(ctx, true)
+	defer inserter.close()
 	indicesNames := make(map[string]struct{})
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return contextError(ctx, err)
+		}
 		req, err := stream.Recv()
 		if err == io.EOF {
 			break
@@ -150,29 +216,40 @@ func (s *Server) InsertVectors(stream grpcapi.Server_InsertVectorsServer) error
 		}
 
 		indexName := req.GetIndexName()
-		index, indexExists := s.indexManager.GetIndex(indexName)
-		if !indexExists {
-			return fmt.Errorf("index %#v not found", indexName)
+		index, err := indices.get(indexName)
+		if err != nil {
+			return err
 		}
-
-		newID, err := index.AddPointAutoID(req.GetVector().GetValue())
+		if !index.Config().AutoIDEnabled {
+			return fmt.Errorf("index %#v has auto-ID disabled", indexName)
+		}
+		vector, err := decodeVector(req.GetVector(), index.Config().Dim)
 		if err != nil {
 			return err
 		}
+		if err = index.ValidateVector(vector); err != nil {
+			return err
+		}
 
-		ids = append(ids, fmt.Sprintf("%d", newID))
+		if err = inserter.add(index, vector, 0, secondsToDuration(req.GetTtlSeconds())); err != nil {
+			return contextError(ctx, err)
+		}
 		indicesNames[indexName] = struct{}{}
 	}
 
-	errors := make([]string, 0)
-	for name := range indicesNames {
-		err := s.indexManager.PersistIndex(name)
-		if err != nil {
-			errors = append(errors, err.Error())
-		}
+	newIDs, err := inserter.finish()
+	if err != nil {
+		return contextError(ctx, err)
 	}
-	if len(errors) > 0 {
-		return fmt.Errorf("%s", strings.Join(errors, "\n"))
+
+	err = s.persistIndices(ctx, indicesNames)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, len(newIDs))
+	for i, id := range newIDs {
+		ids[i] = fmt.Sprintf("%d", id)
 	}
 
 	return stream.SendAndClose(&grpcapi.InsertVectorsReply{
@@ -182,14 +259,26 @@ func (s *Server) InsertVectors(stream grpcapi.Server_InsertVectorsServer) error
 }
 
 // InsertVectorsWithIds inserts the new vectors in the given index. It flushes the index at each batch.
+//
+// Vectors are received and validated on the calling goroutine, and
+// concurrently inserted in batches (see bulkInserter).
 func (s *Server) InsertVectorsWithIds(stream grpcapi.Server_InsertVectorsWithIdsServer) error {
 	s.logger.Debug().Msg("Server.InsertVectorsWithIds")
 
 	startTime := time.Now()
+	ctx := stream.Context()
 
+	// The indices are released once the inserter is closed.
+	indices := s.newStreamIndices()
+	defer indices.release()
+	inserter := s.newBulkInserter(ctx, false)
+	defer inserter.close()
 	indicesNames := make(map[string]struct{})
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return contextError(ctx, err)
+		}
 		req, err := stream.Recv()
 		if err == io.EOF {
 			break
@@ -199,27 +288,34 @@ func (s *Server) InsertVectorsWithIds(stream grpcapi.Server_InsertVectorsWithIds
 		}
 
 		indexName := req.GetIndexName()
-		index, indexExists := s.indexManager.GetIndex(indexName)
-		if !indexExists {
-			return fmt.Errorf("index %#v not found", indexName)
+		index, err := indices.get(indexName)
+		if err != nil {
+			return err
 		}
-
-		err = index.AddPoint(req.GetVector().GetValue(), uint32(req.GetId()))
+		if index.Config().AutoIDEnabled {
+			return fmt.Errorf("index %#v has auto-ID enabled", indexName)
+		}
+		vector, err := decodeVector(req.GetVector(), index.Config().Dim)
 		if err != nil {
 			return err
 		}
+		if err = index.ValidateVector(vector); err != nil {
+			return err
+		}
+
+		if err = inserter.add(index, vector, uint32(req.GetId()), secondsToDuration(req.GetTtlSeconds())); err != nil {
+			return contextError(ctx, err)
+		}
 		indicesNames[indexName] = struct{}{}
 	}
 
-	errors := make([]string, 0)
-	for name := range indicesNames {
-		err := s.indexManager.PersistIndex(name)
-		if err != nil {
-			errors = append(errors, err.Error())
-		}
+	if _, err := inserter.finish(); err != nil {
+		return contextError(ctx, err)
 	}
-	if len(errors) > 0 {
-		return fmt.Errorf("%s", strings.Join(errors, "\n"))
+
+	err := s.persistIndices(ctx, indicesNames)
+	if err != nil {
+		return err
 	}
 
 	return stream.SendAndClose(&grpcapi.InsertVectorsWithIdsReply{
@@ -233,18 +329,33 @@ func (s *Server) SearchKNN(_ context.Context, req *grpcapi.SearchRequest) (*grpc
 
 	startTime := time.Now()
 
-	index, indexExists := s.indexManager.GetIndex(req.GetIndexName())
-	if !indexExists {
-		return nil, fmt.Errorf("index not found")
+	index, release, err := s.getIndex(req.GetIndexName())
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
-	results := index.SearchKNN(req.GetVector().GetValue(), int(req.GetK()))
+	vector, err := decodeVector(req.GetVector(), index.Config().Dim)
+	if err != nil {
+		return nil, err
+	}
+	if err = index.ValidateVector(vector); err != nil {
+		return nil, err
+	}
+	var results []hnswgo.KNNResult
+	if req.GetExact() {
+		results = index.SearchKNNExact(vector, int(req.GetK()))
+	} else {
+		results = index.SearchKNN(vector, int(req.GetK()))
+	}
 
+	spaceType := index.Config().SpaceType
 	hits := make([]*grpcapi.Hit, len(results))
 	for i, result := range results {
 		hits[i] = &grpcapi.Hit{
-			Id:       fmt.Sprintf("%d", result.ID),
-			Distance: result.Distance,
+			Id:         fmt.Sprintf("%d", result.ID),
+			Distance:   result.Distance,
+			Similarity: similarity(spaceType, result.Distance),
 		}
 	}
 
@@ -255,12 +366,12 @@ func (s *Server) SearchKNN(_ context.Context, req *grpcapi.SearchRequest) (*grpc
 }
 
 // FlushIndex flushes the index to file.
-func (s *Server) FlushIndex(_ context.Context, req *grpcapi.FlushRequest) (*emptypb.Empty, error) {
+func (s *Server) FlushIndex(ctx context.Context, req *grpcapi.FlushRequest) (*emptypb.Empty, error) {
 	s.logger.Debug().Interface("req", req).Msg("Server.FlushIndex")
 
-	err := s.indexManager.PersistIndex(req.GetIndexName())
+	err := s.indexManager.PersistIndex(ctx, req.GetIndexName())
 	if err != nil {
-		return nil, err
+		return nil, contextError(ctx, err)
 	}
 	return &emptypb.Empty{}, nil
 }
@@ -278,13 +389,656 @@ func (s *Server) Indices(context.Context, *emptypb.Empty) (*grpcapi.IndicesReply
 func (s *Server) SetEf(_ context.Context, req *grpcapi.SetEfRequest) (*emptypb.Empty, error) {
 	s.logger.Debug().Msg("Received req for `ef` setting.")
 
-	index, indexExists := s.indexManager.GetIndex(req.GetIndexName())
-	if !indexExists {
-		return nil, fmt.Errorf("index not found")
+	index, release, err := s.getIndex(req.GetIndexName())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	err = index.SetEf(int(req.GetValue()))
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// ExportIndex streams all the vectors of the given index which are not
+// marked deleted, with their IDs, in ascending ID order.
+//
+// The IDs are collected when the call starts: vectors inserted afterwards
+// are not exported, while vectors deleted in the meantime are skipped.
+func (s *Server) ExportIndex(req *grpcapi.ExportIndexRequest, stream grpcapi.Server_ExportIndexServer) error {
+	s.logger.Debug().Interface("req", req).Msg("Server.ExportIndex")
+
+	ctx := stream.Context()
+
+	index, release, err := s.getIndex(req.GetIndexName())
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	for _, id := range index.IDs() {
+		if err := ctx.Err(); err != nil {
+			return contextError(ctx, err)
+		}
+		vector, found := index.GetVector(id)
+		if !found {
+			continue
+		}
+		err := stream.Send(&grpcapi.ExportIndexReply{
+			Id:     fmt.Sprintf("%d", id),
+			Vector: encodeVector(vector, req.GetEncoding()),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetVectors returns the vectors stored with the given IDs, and the IDs
+// which are not found.
+func (s *Server) GetVectors(ctx context.Context, req *grpcapi.GetVectorsRequest) (*grpcapi.GetVectorsReply, error) {
+	s.logger.Debug().Interface("req", req).Msg("Server.GetVectors")
+
+	startTime := time.Now()
+
+	index, release, err := s.getIndex(req.GetIndexName())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	original := req.GetOriginal()
+	if original && index.Config().SpaceType == hnswgo.CosineSpace && !index.StoresNorms() {
+		return nil, fmt.Errorf("the index does not store the original vectors")
+	}
+
+	vectors := make([]*grpcapi.StoredVector, 0, len(req.GetIds()))
+	notFoundIDs := make([]string, 0)
+	for _, id := range req.GetIds() {
+		if err := ctx.Err(); err != nil {
+			return nil, contextError(ctx, err)
+		}
+		var vector []float32
+		var found bool
+		if original {
+			vector, found = index.GetOriginalVector(uint32(id))
+		} else {
+			vector, found = index.GetVector(uint32(id))
+		}
+		if !found {
+			notFoundIDs = append(notFoundIDs, fmt.Sprintf("%d", id))
+			continue
+		}
+		vectors = append(vectors, &grpcapi.StoredVector{
+			Id:     fmt.Sprintf("%d", id),
+			Vector: encodeVector(vector, req.GetEncoding()),
+		})
+	}
+	return &grpcapi.GetVectorsReply{
+		Vectors:     vectors,
+		NotFoundIds: notFoundIDs,
+		Took:        time.Since(startTime).Milliseconds(),
+	}, nil
+}
+
+// CompactIndex rebuilds the given index from the vectors which are not
+// marked deleted, reclaiming their capacity.
+func (s *Server) CompactIndex(ctx context.Context, req *grpcapi.CompactIndexRequest) (*emptypb.Empty, error) {
+	s.logger.Debug().Interface("req", req).Msg("Server.CompactIndex")
+
+	var err error
+	if req.GetWait() {
+		err = s.indexManager.CompactIndex(ctx, req.GetIndexName())
+	} else {
+		err = s.indexManager.StartCompaction(req.GetIndexName())
+	}
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// DeleteVectors marks the given vectors deleted, hiding them from search
+// results. Unknown IDs are ignored.
+func (s *Server) DeleteVectors(ctx context.Context, req *grpcapi.DeleteVectorsRequest) (*grpcapi.DeleteVectorsReply, error) {
+	s.logger.Debug().Interface("req", req).Msg("Server.DeleteVectors")
+
+	startTime := time.Now()
+
+	index, release, err := s.getIndex(req.GetIndexName())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	for _, id := range req.GetIds() {
+		if err := ctx.Err(); err != nil {
+			return nil, contextError(ctx, err)
+		}
+		if err := index.MarkDelete(uint32(id)); err != nil {
+			return nil, err
+		}
+	}
+	return &grpcapi.DeleteVectorsReply{
+		Took: time.Since(startTime).Milliseconds(),
+	}, nil
+}
+
+// UndeleteVectors restores the given vectors marked deleted, and replies
+// with the IDs which cannot be restored.
+func (s *Server) UndeleteVectors(ctx context.Context, req *grpcapi.UndeleteVectorsRequest) (*grpcapi.UndeleteVectorsReply, error) {
+	s.logger.Debug().Interface("req", req).Msg("Server.UndeleteVectors")
+
+	startTime := time.Now()
+
+	index, release, err := s.getIndex(req.GetIndexName())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	notFoundIDs := make([]string, 0)
+	for _, id := range req.GetIds() {
+		if err := ctx.Err(); err != nil {
+			return nil, contextError(ctx, err)
+		}
+		found, err := index.UnmarkDelete(uint32(id))
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			notFoundIDs = append(notFoundIDs, fmt.Sprintf("%d", id))
+		}
+	}
+	return &grpcapi.UndeleteVectorsReply{
+		NotFoundIds: notFoundIDs,
+		Took:        time.Since(startTime).Milliseconds(),
+	}, nil
+}
+
+// DescribeIndex returns the configuration and the statistics of the given
+// index.
+func (s *Server) DescribeIndex(_ context.Context, req *grpcapi.DescribeIndexRequest) (*grpcapi.DescribeIndexReply, error) {
+	s.logger.Debug().Interface("req", req).Msg("Server.DescribeIndex")
+
+	// Indices which are not ready are only described by their status.
+	switch st, err := s.indexManager.IndexStatus(req.GetIndexName()); st {
+	case indexmanager.IndexLoading:
+		return &grpcapi.DescribeIndexReply{
+			IndexName: req.GetIndexName(),
+			Status:    grpcapi.IndexStatus_LOADING,
+		}, nil
+	case indexmanager.IndexFailed:
+		return &grpcapi.DescribeIndexReply{
+			IndexName: req.GetIndexName(),
+			Status:    grpcapi.IndexStatus_FAILED,
+			LoadError: err.Error(),
+		}, nil
 	}
-	err := index.SetEf(int(req.GetValue()))
+
+	// The residency is checked before the index is loaded by getIndex.
+	resident := s.indexManager.IsResident(req.GetIndexName())
+	index, release, err := s.getIndex(req.GetIndexName())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	config := index.Config()
+	expiry := index.ExpiryStats()
+	reply := &grpcapi.DescribeIndexReply{
+		IndexName:           req.GetIndexName(),
+		Dim:                 int32(config.Dim),
+		EfConstruction:      int32(config.EfConstruction),
+		M:                   int32(config.M),
+		MaxElements:         int32(config.MaxElements),
+		Seed:                int32(config.RandSeed),
+		SpaceType:           grpcSpaceType(config.SpaceType),
+		AutoId:              config.AutoIDEnabled,
+		AllowReplaceDeleted: config.AllowReplaceDeleted,
+		DefaultTtlSeconds:   int64(config.DefaultTTL / time.Second),
+		IndexType:           grpcIndexType(config.IndexType),
+		StorageType:         grpcStorageType(config.Storage),
+		TrainingSize:        int32(config.TrainingSize),
+		PqSubquantizers:     int32(config.PQSubquantizers),
+		PqBits:              int32(config.PQBits),
+		Rerank:              config.Rerank,
+		Size:                int64(len(index.IDs())),
+		Expiry: &grpcapi.ExpiryStats{
+			Pending: int64(expiry.Pending),
+			Expired: int64(expiry.Expired),
+		},
+		Resident: resident,
+	}
+	if !expiry.NextExpiration.IsZero() {
+		reply.Expiry.NextExpiration = expiry.NextExpiration.Unix()
+	}
+	return reply, nil
+}
+
+// defaultRecallSampleSize is the number of stored vectors used as queries by
+// EvaluateRecall, unless set otherwise.
+const defaultRecallSampleSize = 100
+
+// EvaluateRecall compares the results of approximate and exact searches on
+// the given index, at its current ef, using either the given queries or a
+// random sample of the stored vectors.
+func (s *Server) EvaluateRecall(ctx context.Context, req *grpcapi.EvaluateRecallRequest) (*grpcapi.EvaluateRecallReply, error) {
+	s.logger.Debug().Str("index", req.GetIndexName()).Int32("k", req.GetK()).Msg("Server.EvaluateRecall")
+
+	startTime := time.Now()
+
+	index, release, err := s.getIndex(req.GetIndexName())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	queries, err := recallQueries(index, req.GetQueries(), req.GetSampleSize())
+	if err != nil {
+		return nil, err
+	}
+	evaluation, err := index.EvaluateRecall(ctx, queries, int(req.GetK()))
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	return &grpcapi.EvaluateRecallReply{
+		Recall:             float32(evaluation.Recall),
+		Queries:            int32(evaluation.Queries),
+		Ef:                 int32(evaluation.Ef),
+		ApproximateLatency: milliseconds(evaluation.ApproximateLatency),
+		ExactLatency:       milliseconds(evaluation.ExactLatency),
+		Took:               time.Since(startTime).Milliseconds(),
+	}, nil
+}
+
+// defaultMaxEf is the maximum ef which can be chosen by AutoTuneEf, unless
+// set otherwise.
+const defaultMaxEf = 1000
+
+// AutoTuneEf sets the smallest ef of the given index meeting the target
+// recall, using either the given queries or a random sample of the stored
+// vectors.
+func (s *Server) AutoTuneEf(ctx context.Context, req *grpcapi.AutoTuneEfRequest) (*grpcapi.AutoTuneEfReply, error) {
+	s.logger.Debug().Str("index", req.GetIndexName()).Float32("target_recall", req.GetTargetRecall()).Msg("Server.AutoTuneEf")
+
+	startTime := time.Now()
+
+	index, release, err := s.getIndex(req.GetIndexName())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	maxEf := int(req.GetMaxEf())
+	if maxEf <= 0 {
+		maxEf = defaultMaxEf
+	}
+	queries, err := recallQueries(index, req.GetQueries(), req.GetSampleSize())
+	if err != nil {
+		return nil, err
+	}
+	tuning, err := index.AutoTuneEf(ctx, queries, int(req.GetK()), float64(req.GetTargetRecall()), maxEf)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	measurements := make([]*grpcapi.RecallMeasurement, len(tuning.Evaluations))
+	for i, ev := range tuning.Evaluations {
+		measurements[i] = &grpcapi.RecallMeasurement{
+			Ef:                 int32(ev.Ef),
+			Recall:             float32(ev.Recall),
+			ApproximateLatency: milliseconds(ev.ApproximateLatency),
+		}
+	}
+	return &grpcapi.AutoTuneEfReply{
+		Ef:            int32(tuning.Ef),
+		Recall:        float32(tuning.Recall),
+		TargetReached: tuning.TargetReached,
+		Measurements:  measurements,
+		ExactLatency:  milliseconds(tuning.Evaluations[0].ExactLatency),
+		Took:          time.Since(startTime).Milliseconds(),
+	}, nil
+}
+
+// FailedIndices returns the indices which could not be loaded, with the
+// errors which made the loading fail.
+func (s *Server) FailedIndices(context.Context, *emptypb.Empty) (*grpcapi.FailedIndicesReply, error) {
+	s.logger.Debug().Msg("Server.FailedIndices")
+
+	failures := s.indexManager.FailedIndices()
+	reply := &grpcapi.FailedIndicesReply{
+		Indices: make([]*grpcapi.FailedIndex, len(failures)),
+	}
+	for i, failure := range failures {
+		reply.Indices[i] = &grpcapi.FailedIndex{
+			IndexName:   failure.Name,
+			Error:       failure.Err.Error(),
+			Quarantined: failure.Quarantined,
+		}
+	}
+	return reply, nil
+}
+
+// RetryIndex loads again an index which could not be loaded, optionally
+// repairing it from its previous snapshot and write-ahead log.
+func (s *Server) RetryIndex(_ context.Context, req *grpcapi.RetryIndexRequest) (*emptypb.Empty, error) {
+	s.logger.Debug().Interface("req", req).Msg("Server.RetryIndex")
+
+	err := s.indexManager.RetryIndex(req.GetIndexName(), req.GetRepair())
 	if err != nil {
 		return nil, err
 	}
 	return &emptypb.Empty{}, nil
 }
+
+// VerifyIndex verifies the integrity of the files of the given index,
+// optionally repairing its write-ahead log.
+func (s *Server) VerifyIndex(ctx context.Context, req *grpcapi.VerifyIndexRequest) (*grpcapi.VerifyIndexReply, error) {
+	s.logger.Debug().Interface("req", req).Msg("Server.VerifyIndex")
+
+	startTime := time.Now()
+
+	report, err := s.indexManager.VerifyIndex(ctx, req.GetIndexName(), req.GetRepair())
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	reply := NewVerifyIndexReply(report)
+	reply.Took = time.Since(startTime).Milliseconds()
+	return reply, nil
+}
+
+// NewVerifyIndexReply converts the report of the verification of an index
+// to the reply of VerifyIndex, without setting its Took field.
+func NewVerifyIndexReply(report *hnswgo.VerifyReport) *grpcapi.VerifyIndexReply {
+	reply := &grpcapi.VerifyIndexReply{
+		Ok:       report.OK(),
+		Problems: report.Problems(),
+		Warnings: report.Warnings(),
+		Log: &grpcapi.LogCheck{
+			Entries:   int64(report.Log.Entries),
+			Size:      report.Log.Size,
+			ValidSize: report.Log.ValidSize,
+			Repaired:  report.LogRepaired,
+		},
+	}
+	if report.StateErr != nil {
+		reply.StateError = report.StateErr.Error()
+	}
+	if report.IndexErr != nil {
+		reply.IndexError = report.IndexErr.Error()
+	}
+	if report.Log.Err != nil {
+		reply.Log.Error = report.Log.Err.Error()
+	}
+	if g := report.Graph; g != nil {
+		reply.Graph = &grpcapi.GraphCheck{
+			Elements:           g.Elements,
+			Deleted:            g.Deleted,
+			Connections:        g.Connections,
+			InvalidLinks:       g.InvalidLinks,
+			SelfLinks:          g.SelfLinks,
+			DuplicateLinks:     g.DuplicateLinks,
+			OversizedLists:     g.OversizedLists,
+			InvalidLevels:      g.InvalidLevels,
+			InvalidEntryPoint:  g.InvalidEntryPoint,
+			InconsistentLabels: g.InconsistentLabels,
+			Unreachable:        g.Unreachable,
+		}
+	}
+	return reply
+}
+
+// recallQueries returns the queries of EvaluateRecall and AutoTuneEf
+// requests, or a random sample of the stored vectors if there are none.
+func recallQueries(index *hnswgo.HNSW, vectors []*grpcapi.Vector, sampleSize int32) ([][]float32, error) {
+	if len(vectors) == 0 {
+		if sampleSize <= 0 {
+			sampleSize = defaultRecallSampleSize
+		}
+		return index.SampleVectors(int(sampleSize)), nil
+	}
+	queries := make([][]float32, len(vectors))
+	for i, query := range vectors {
+		vector, err := decodeVector(query, index.Config().Dim)
+		if err != nil {
+			return nil, err
+		}
+		queries[i] = vector
+	}
+	return queries, nil
+}
+
+// milliseconds converts the latencies of recall evaluations to fractional
+// milliseconds.
+func milliseconds(d time.Duration) float32 {
+	return float32(d.Seconds() * 1000)
+}
+
+// getIndex returns the index with the given name, pinned until the
+// returned function is called (see IndexManager.AcquireIndex), so that it
+// is not unloaded while the call uses it. If the index is still loading,
+// the error has codes.Unavailable, so that clients can retry. If it cannot
+// be loaded again after being unloaded, the error has codes.Internal.
+func (s *Server) getIndex(name string) (*hnswgo.HNSW, func(), error) {
+	index, release, err := s.indexManager.AcquireIndex(name)
+	if err == nil {
+		return index, release, nil
+	}
+	switch st, stErr := s.indexManager.IndexStatus(name); st {
+	case indexmanager.IndexReady:
+		return nil, nil, status.Error(codes.Internal, err.Error())
+	case indexmanager.IndexLoading:
+		return nil, nil, status.Errorf(codes.Unavailable, "index %#v is loading", name)
+	case indexmanager.IndexFailed:
+		return nil, nil, stErr
+	default:
+		return nil, nil, fmt.Errorf("index %#v not found", name)
+	}
+}
+
+// streamIndices are the indices used by a streaming call, which are
+// pinned until the call is complete (see getIndex).
+type streamIndices struct {
+	s        *Server
+	indices  map[string]*hnswgo.HNSW
+	releases []func()
+}
+
+func (s *Server) newStreamIndices() *streamIndices {
+	return &streamIndices{s: s, indices: make(map[string]*hnswgo.HNSW)}
+}
+
+// get is the same as Server.getIndex, pinning each index only once.
+func (si *streamIndices) get(name string) (*hnswgo.HNSW, error) {
+	if index, ok := si.indices[name]; ok {
+		return index, nil
+	}
+	index, release, err := si.s.getIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	si.indices[name] = index
+	si.releases = append(si.releases, release)
+	return index, nil
+}
+
+// release releases all the indices.
+func (si *streamIndices) release() {
+	for _, release := range si.releases {
+		release()
+	}
+	si.releases = nil
+}
+
+// persistIndices persists all the indices with the given names, stopping
+// early if the context is done.
+func (s *Server) persistIndices(ctx context.Context, names map[string]struct{}) error {
+	errors := make([]string, 0)
+	for name := range names {
+		if err := ctx.Err(); err != nil {
+			return contextError(ctx, err)
+		}
+		err := s.indexManager.PersistIndex(ctx, name)
+		if err != nil {
+			errors = append(errors, err.Error())
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return contextError(ctx, err)
+	}
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, "\n"))
+	}
+	return nil
+}
+
+// secondsToDuration converts the TTLs of the requests to durations.
+func secondsToDuration(seconds int64) time.Duration {
+	return time.Duration(seconds) * time.Second
+}
+
+// similarity returns the inner product corresponding to the distance of a
+// search result in IP and cosine spaces, where the distance is one minus the
+// inner product, or nil for the other space types.
+func similarity(spaceType hnswgo.SpaceType, distance float32) *float32 {
+	switch spaceType {
+	case hnswgo.IPSpace, hnswgo.CosineSpace:
+		s := 1 - distance
+		return &s
+	default:
+		return nil
+	}
+}
+
+// grpcSpaceType is the inverse of spaceTypeMap.
+func grpcSpaceType(spaceType hnswgo.SpaceType) grpcapi.CreateIndexRequest_SpaceType {
+	for k, v := range spaceTypeMap {
+		if v == spaceType {
+			return k
+		}
+	}
+	panic(fmt.Sprintf("unexpected SpaceType %#v", spaceType))
+}
+
+// grpcIndexType is the inverse of indexTypeMap. The zero IndexType of the
+// indices created before its introduction means HNSW.
+func grpcIndexType(indexType hnswgo.IndexType) grpcapi.CreateIndexRequest_IndexType {
+	if indexType == hnswgo.FlatIndex {
+		return grpcapi.CreateIndexRequest_FLAT
+	}
+	return grpcapi.CreateIndexRequest_HNSW
+}
+
+// grpcStorageType is the inverse of storageTypeMap. The zero StorageType of
+// the indices created before its introduction means FLOAT32.
+func grpcStorageType(storageType hnswgo.StorageType) grpcapi.CreateIndexRequest_StorageType {
+	switch storageType {
+	case hnswgo.SQ8Storage:
+		return grpcapi.CreateIndexRequest_SQ8
+	case hnswgo.PQStorage:
+		return grpcapi.CreateIndexRequest_PQ
+	case hnswgo.Float16Storage:
+		return grpcapi.CreateIndexRequest_FLOAT16
+	case hnswgo.BFloat16Storage:
+		return grpcapi.CreateIndexRequest_BFLOAT16
+	default:
+		return grpcapi.CreateIndexRequest_FLOAT32
+	}
+}
+
+// decodeVector returns the components of a vector for an index of the
+// given dimension, which are either in its value field, or encoded in its
+// data field.
+func decodeVector(v *grpcapi.Vector, dim int) ([]float32, error) {
+	data := v.GetData()
+	if len(data) == 0 {
+		return v.GetValue(), nil
+	}
+	if v.GetEncoding() == grpcapi.Vector_BINARY {
+		return decodeBinaryVector(data, dim)
+	}
+
+	size := 2
+	if v.GetEncoding() == grpcapi.Vector_FLOAT32 {
+		size = 4
+	}
+	if len(data)%size != 0 {
+		return nil, fmt.Errorf("invalid vector data: %d bytes is not a multiple of %d", len(data), size)
+	}
+
+	vector := make([]float32, len(data)/size)
+	for i := range vector {
+		switch v.GetEncoding() {
+		case grpcapi.Vector_FLOAT32:
+			vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+		case grpcapi.Vector_FLOAT16:
+			vector[i] = hnswgo.Float16ToFloat32(binary.LittleEndian.Uint16(data[i*2:]))
+		case grpcapi.Vector_BFLOAT16:
+			vector[i] = hnswgo.BFloat16ToFloat32(binary.LittleEndian.Uint16(data[i*2:]))
+		default:
+			return nil, fmt.Errorf("invalid vector encoding [%v]", v.GetEncoding())
+		}
+	}
+	return vector, nil
+}
+
+// decodeBinaryVector unpacks the bits of a BINARY vector, most significant
+// first, ignoring the padding bits of the last byte.
+func decodeBinaryVector(data []byte, dim int) ([]float32, error) {
+	if len(data) != (dim+7)/8 {
+		return nil, fmt.Errorf("invalid binary vector size %d bytes: the index has dimension %d", len(data), dim)
+	}
+	vector := make([]float32, dim)
+	for i := range vector {
+		vector[i] = float32(data[i/8] >> (7 - i%8) & 1)
+	}
+	return vector, nil
+}
+
+// encodeVector is the inverse of decodeVector. FLOAT32 vectors are set in
+// the value field.
+func encodeVector(vector []float32, encoding grpcapi.Vector_Encoding) *grpcapi.Vector {
+	var convert func(float32) uint16
+	switch encoding {
+	case grpcapi.Vector_BINARY:
+		data := make([]byte, (len(vector)+7)/8)
+		for i, v := range vector {
+			if v != 0 {
+				data[i/8] |= 1 << (7 - i%8)
+			}
+		}
+		return &grpcapi.Vector{Data: data, Encoding: encoding}
+	case grpcapi.Vector_FLOAT16:
+		convert = hnswgo.Float32ToFloat16
+	case grpcapi.Vector_BFLOAT16:
+		convert = hnswgo.Float32ToBFloat16
+	default:
+		return &grpcapi.Vector{Value: vector}
+	}
+	data := make([]byte, 2*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint16(data[i*2:], convert(v))
+	}
+	return &grpcapi.Vector{Data: data, Encoding: encoding}
+}
+
+// contextError returns a gRPC status error with the appropriate code if
+// the context is done, otherwise it returns err unchanged.
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return status.FromContextError(ctxErr).Err()
+	}
+	return indexManagerError(err)
+}
+
+// indexManagerError converts the errors of the operations on indices which
+// are still loading to codes.Unavailable, so that clients can retry.
+func indexManagerError(err error) error {
+	if errors.Is(err, indexmanager.ErrIndexLoading) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return err
+}
@@ -16,6 +16,7 @@ package server_test
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/grpcapi"
 	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/hnswgo"
@@ -25,11 +26,16 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 	"io"
+	"math"
 	"os"
 	"path"
 	"testing"
+	"time"
 )
 
 func TestServer_CreateIndex(t *testing.T) {
@@ -61,7 +67,7 @@ func TestServer_CreateIndex(t *testing.T) {
 			M:              10,
 			MaxElements:    10,
 			Seed:           100,
-			SpaceType:      3, // this is invalid
+			SpaceType:      5, // this is invalid
 			AutoId:         false,
 		})
 		assert.Error(t, err)
@@ -90,6 +96,366 @@ func TestServer_CreateIndex(t *testing.T) {
 
 		assert.Empty(t, im.IndicesNames())
 	})
+
+	t.Run("replacement of deleted vectors", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := indexmanager.New(dir, zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		_, err := srv.CreateIndex(ctx, &grpcapi.CreateIndexRequest{
+			IndexName:           "foo",
+			Dim:                 5,
+			EfConstruction:      200,
+			M:                   10,
+			MaxElements:         1,
+			Seed:                100,
+			SpaceType:           grpcapi.CreateIndexRequest_L2,
+			AllowReplaceDeleted: true,
+		})
+		require.NoError(t, err)
+		index, ok := im.GetIndex("foo")
+		require.True(t, ok)
+		assert.True(t, index.Config().AllowReplaceDeleted)
+
+		// The only slot is reused
+		require.NoError(t, index.AddPoint([]float32{1, 2, 3, 4, 5}, 1))
+		require.NoError(t, index.MarkDelete(1))
+		require.NoError(t, index.AddPoint([]float32{5, 4, 3, 2, 1}, 2))
+		assert.Equal(t, []uint32{2}, index.IDs())
+	})
+
+	t.Run("flat index", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := indexmanager.New(dir, zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		_, err := srv.CreateIndex(ctx, &grpcapi.CreateIndexRequest{
+			IndexName:   "foo",
+			Dim:         5,
+			MaxElements: 10,
+			SpaceType:   grpcapi.CreateIndexRequest_COSINE,
+			IndexType:   grpcapi.CreateIndexRequest_FLAT,
+		})
+		require.NoError(t, err)
+		index, ok := im.GetIndex("foo")
+		require.True(t, ok)
+		assert.Equal(t, hnswgo.FlatIndex, index.Config().IndexType)
+
+		for i, vector := range sampleVectors {
+			_, err = srv.InsertVectorWithId(ctx, &grpcapi.InsertVectorWithIdRequest{
+				IndexName: "foo",
+				Id:        int32(i + 1),
+				Vector:    &grpcapi.Vector{Value: vector},
+			})
+			require.NoError(t, err)
+		}
+		resp, err := srv.SearchKNN(ctx, &grpcapi.SearchRequest{
+			IndexName: "foo",
+			Vector:    &grpcapi.Vector{Value: sampleVectors[1]},
+			K:         10,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Hits, 2)
+		assert.Equal(t, "2", resp.Hits[0].Id)
+		assert.Equal(t, "1", resp.Hits[1].Id)
+
+		description, err := srv.DescribeIndex(ctx, &grpcapi.DescribeIndexRequest{IndexName: "foo"})
+		require.NoError(t, err)
+		assert.Equal(t, grpcapi.CreateIndexRequest_FLAT, description.GetIndexType())
+	})
+
+	t.Run("SQ8 storage", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := indexmanager.New(dir, zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		_, err := srv.CreateIndex(ctx, &grpcapi.CreateIndexRequest{
+			IndexName:      "foo",
+			Dim:            5,
+			MaxElements:    10,
+			M:              10,
+			EfConstruction: 200,
+			SpaceType:      grpcapi.CreateIndexRequest_L2,
+			StorageType:    grpcapi.CreateIndexRequest_SQ8,
+			TrainingSize:   2,
+			Rerank:         true,
+		})
+		require.NoError(t, err)
+		index, ok := im.GetIndex("foo")
+		require.True(t, ok)
+		assert.Equal(t, hnswgo.SQ8Storage, index.Config().Storage)
+
+		for i, vector := range sampleVectors {
+			_, err = srv.InsertVectorWithId(ctx, &grpcapi.InsertVectorWithIdRequest{
+				IndexName: "foo",
+				Id:        int32(i + 1),
+				Vector:    &grpcapi.Vector{Value: vector},
+			})
+			require.NoError(t, err)
+		}
+		resp, err := srv.SearchKNN(ctx, &grpcapi.SearchRequest{
+			IndexName: "foo",
+			Vector:    &grpcapi.Vector{Value: sampleVectors[1]},
+			K:         10,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Hits, 2)
+		assert.Equal(t, "2", resp.Hits[0].Id)
+		assert.Equal(t, float32(0), resp.Hits[0].Distance)
+
+		description, err := srv.DescribeIndex(ctx, &grpcapi.DescribeIndexRequest{IndexName: "foo"})
+		require.NoError(t, err)
+		assert.Equal(t, grpcapi.CreateIndexRequest_SQ8, description.GetStorageType())
+		assert.Equal(t, int32(2), description.GetTrainingSize())
+		assert.True(t, description.GetRerank())
+	})
+
+	t.Run("PQ storage", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := indexmanager.New(dir, zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		_, err := srv.CreateIndex(ctx, &grpcapi.CreateIndexRequest{
+			IndexName:       "foo",
+			Dim:             5,
+			MaxElements:     10,
+			M:               10,
+			EfConstruction:  200,
+			SpaceType:       grpcapi.CreateIndexRequest_L2,
+			StorageType:     grpcapi.CreateIndexRequest_PQ,
+			TrainingSize:    2,
+			PqSubquantizers: 5,
+			PqBits:          1,
+			Rerank:          true,
+		})
+		require.NoError(t, err)
+		index, ok := im.GetIndex("foo")
+		require.True(t, ok)
+		assert.Equal(t, hnswgo.PQStorage, index.Config().Storage)
+
+		for i, vector := range sampleVectors {
+			_, err = srv.InsertVectorWithId(ctx, &grpcapi.InsertVectorWithIdRequest{
+				IndexName: "foo",
+				Id:        int32(i + 1),
+				Vector:    &grpcapi.Vector{Value: vector},
+			})
+			require.NoError(t, err)
+		}
+		resp, err := srv.SearchKNN(ctx, &grpcapi.SearchRequest{
+			IndexName: "foo",
+			Vector:    &grpcapi.Vector{Value: sampleVectors[1]},
+			K:         10,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Hits, 2)
+		assert.Equal(t, "2", resp.Hits[0].Id)
+		assert.Equal(t, float32(0), resp.Hits[0].Distance)
+
+		description, err := srv.DescribeIndex(ctx, &grpcapi.DescribeIndexRequest{IndexName: "foo"})
+		require.NoError(t, err)
+		assert.Equal(t, grpcapi.CreateIndexRequest_PQ, description.GetStorageType())
+		assert.Equal(t, int32(5), description.GetPqSubquantizers())
+		assert.Equal(t, int32(1), description.GetPqBits())
+	})
+
+	t.Run("half-precision storage", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := indexmanager.New(dir, zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		_, err := srv.CreateIndex(ctx, &grpcapi.CreateIndexRequest{
+			IndexName:      "foo",
+			Dim:            5,
+			MaxElements:    10,
+			M:              10,
+			EfConstruction: 200,
+			SpaceType:      grpcapi.CreateIndexRequest_L2,
+			StorageType:    grpcapi.CreateIndexRequest_FLOAT16,
+		})
+		require.NoError(t, err)
+		index, ok := im.GetIndex("foo")
+		require.True(t, ok)
+		assert.Equal(t, hnswgo.Float16Storage, index.Config().Storage)
+
+		for i, vector := range sampleVectors {
+			_, err = srv.InsertVectorWithId(ctx, &grpcapi.InsertVectorWithIdRequest{
+				IndexName: "foo",
+				Id:        int32(i + 1),
+				Vector:    &grpcapi.Vector{Data: encodeHalfVector(vector, false), Encoding: grpcapi.Vector_FLOAT16},
+			})
+			require.NoError(t, err)
+		}
+		resp, err := srv.SearchKNN(ctx, &grpcapi.SearchRequest{
+			IndexName: "foo",
+			Vector:    &grpcapi.Vector{Data: encodeHalfVector(sampleVectors[1], true), Encoding: grpcapi.Vector_BFLOAT16},
+			K:         10,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Hits, 2)
+		assert.Equal(t, "2", resp.Hits[0].Id)
+		assert.InDelta(t, 0.0, resp.Hits[0].Distance, 1e-4)
+
+		description, err := srv.DescribeIndex(ctx, &grpcapi.DescribeIndexRequest{IndexName: "foo"})
+		require.NoError(t, err)
+		assert.Equal(t, grpcapi.CreateIndexRequest_FLOAT16, description.GetStorageType())
+	})
+
+	t.Run("Hamming space", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := indexmanager.New(dir, zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		_, err := srv.CreateIndex(ctx, &grpcapi.CreateIndexRequest{
+			IndexName:      "foo",
+			Dim:            12,
+			MaxElements:    10,
+			M:              10,
+			EfConstruction: 200,
+			SpaceType:      grpcapi.CreateIndexRequest_HAMMING,
+		})
+		require.NoError(t, err)
+
+		for i, data := range [][]byte{{0xff, 0xf0}, {0x0f, 0x00}, {0x00, 0x30}} {
+			_, err = srv.InsertVectorWithId(ctx, &grpcapi.InsertVectorWithIdRequest{
+				IndexName: "foo",
+				Id:        int32(i + 1),
+				Vector:    &grpcapi.Vector{Data: data, Encoding: grpcapi.Vector_BINARY},
+			})
+			require.NoError(t, err)
+		}
+		_, err = srv.InsertVectorWithId(ctx, &grpcapi.InsertVectorWithIdRequest{
+			IndexName: "foo",
+			Id:        4,
+			Vector:    &grpcapi.Vector{Data: []byte{0xff}, Encoding: grpcapi.Vector_BINARY},
+		})
+		assert.Error(t, err)
+
+		// The padding bits are ignored.
+		resp, err := srv.SearchKNN(ctx, &grpcapi.SearchRequest{
+			IndexName: "foo",
+			Vector:    &grpcapi.Vector{Data: []byte{0x0e, 0x0f}, Encoding: grpcapi.Vector_BINARY},
+			K:         3,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Hits, 3)
+		for i, expected := range []struct {
+			id       string
+			distance float32
+		}{{"2", 1}, {"3", 5}, {"1", 9}} {
+			assert.Equal(t, expected.id, resp.Hits[i].Id)
+			assert.Equal(t, expected.distance, resp.Hits[i].Distance)
+		}
+
+		stream := &exportIndexServerStream{}
+		err = srv.ExportIndex(&grpcapi.ExportIndexRequest{IndexName: "foo", Encoding: grpcapi.Vector_BINARY}, stream)
+		require.NoError(t, err)
+		require.Len(t, stream.Replies, 3)
+		assert.Equal(t, []byte{0xff, 0xf0}, stream.Replies[0].Vector.Data)
+
+		description, err := srv.DescribeIndex(ctx, &grpcapi.DescribeIndexRequest{IndexName: "foo"})
+		require.NoError(t, err)
+		assert.Equal(t, grpcapi.CreateIndexRequest_HAMMING, description.GetSpaceType())
+	})
+
+	t.Run("L1 space", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := indexmanager.New(dir, zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		_, err := srv.CreateIndex(ctx, &grpcapi.CreateIndexRequest{
+			IndexName:      "foo",
+			Dim:            3,
+			MaxElements:    10,
+			M:              10,
+			EfConstruction: 200,
+			SpaceType:      grpcapi.CreateIndexRequest_L1,
+		})
+		require.NoError(t, err)
+
+		for i, value := range [][]float32{{0, 0, 0}, {1, -2, 3}, {4, 4, 4}} {
+			_, err = srv.InsertVectorWithId(ctx, &grpcapi.InsertVectorWithIdRequest{
+				IndexName: "foo",
+				Id:        int32(i + 1),
+				Vector:    &grpcapi.Vector{Value: value},
+			})
+			require.NoError(t, err)
+		}
+
+		resp, err := srv.SearchKNN(ctx, &grpcapi.SearchRequest{
+			IndexName: "foo",
+			Vector:    &grpcapi.Vector{Value: []float32{1, 1, 1}},
+			K:         3,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Hits, 3)
+		for i, expected := range []struct {
+			id       string
+			distance float32
+		}{{"1", 3}, {"2", 5}, {"3", 9}} {
+			assert.Equal(t, expected.id, resp.Hits[i].Id)
+			assert.Equal(t, expected.distance, resp.Hits[i].Distance)
+			assert.Nil(t, resp.Hits[i].Similarity)
+		}
+
+		description, err := srv.DescribeIndex(ctx, &grpcapi.DescribeIndexRequest{IndexName: "foo"})
+		require.NoError(t, err)
+		assert.Equal(t, grpcapi.CreateIndexRequest_L1, description.GetSpaceType())
+	})
+
+	t.Run("invalid storage settings", func(t *testing.T) {
+		t.Parallel()
+		im := indexmanager.New(os.TempDir(), zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		for _, req := range []*grpcapi.CreateIndexRequest{
+			{IndexName: "foo", Dim: 5, MaxElements: 10, StorageType: 5},
+			{IndexName: "foo", Dim: 5, MaxElements: 10, StorageType: grpcapi.CreateIndexRequest_FLOAT16, IndexType: grpcapi.CreateIndexRequest_FLAT},
+			{IndexName: "foo", Dim: 5, MaxElements: 10, StorageType: grpcapi.CreateIndexRequest_FLOAT16, SpaceType: grpcapi.CreateIndexRequest_HAMMING},
+			{IndexName: "foo", Dim: 5, MaxElements: 10, StorageType: grpcapi.CreateIndexRequest_SQ8, SpaceType: grpcapi.CreateIndexRequest_L1},
+			{IndexName: "foo", Dim: 5, MaxElements: 10, StorageType: grpcapi.CreateIndexRequest_SQ8, IndexType: grpcapi.CreateIndexRequest_FLAT},
+			{IndexName: "foo", Dim: 5, MaxElements: 10, Rerank: true},
+			{IndexName: "foo", Dim: 5, MaxElements: 10, StorageType: grpcapi.CreateIndexRequest_SQ8, TrainingSize: -1},
+			{IndexName: "foo", Dim: 5, MaxElements: 10, StorageType: grpcapi.CreateIndexRequest_PQ},
+			{IndexName: "foo", Dim: 5, MaxElements: 10, StorageType: grpcapi.CreateIndexRequest_PQ, PqSubquantizers: 2},
+			{IndexName: "foo", Dim: 5, MaxElements: 10, StorageType: grpcapi.CreateIndexRequest_PQ, PqSubquantizers: 5, PqBits: 9},
+		} {
+			resp, err := srv.CreateIndex(ctx, req)
+			assert.Error(t, err)
+			assert.Nil(t, resp)
+		}
+		assert.Empty(t, im.IndicesNames())
+	})
+
+	t.Run("invalid index type", func(t *testing.T) {
+		t.Parallel()
+		im := indexmanager.New(os.TempDir(), zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		resp, err := srv.CreateIndex(ctx, &grpcapi.CreateIndexRequest{
+			IndexName:   "foo",
+			Dim:         5,
+			MaxElements: 10,
+			IndexType:   2, // this is invalid
+		})
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+
+		assert.Empty(t, im.IndicesNames())
+	})
 }
 
 func TestServer_DeleteIndex(t *testing.T) {
@@ -273,6 +639,55 @@ func TestServer_InsertVectors(t *testing.T) {
 		}
 	})
 
+	t.Run("concurrent insertion in multiple batches", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+
+		config := sampleServerConfig
+		config.InsertWorkers = 3
+		config.InsertBatchSize = 2
+		srv := server.New(config, im, zerolog.Nop())
+
+		requests := make([]*grpcapi.InsertVectorRequest, 7)
+		for i := range requests {
+			name := "test-index-auto-id-1"
+			if i%3 == 0 {
+				name = "test-index-auto-id-2"
+			}
+			requests[i] = &grpcapi.InsertVectorRequest{
+				IndexName: name,
+				Vector:    &grpcapi.Vector{Value: sampleVectors[i%2]},
+			}
+		}
+
+		stream := newInsertVectorsServerStream(requests)
+		assert.NoError(t, srv.InsertVectors(stream))
+		require.NotNil(t, stream.Reply)
+		assert.Equal(t, []string{"3", "3", "4", "4", "5", "6", "5"}, stream.Reply.Ids)
+
+		assertIndexContainsExactlyIDs(t, im, "test-index-auto-id-1", []uint32{1, 2, 3, 4, 5, 6})
+		assertIndexContainsExactlyIDs(t, im, "test-index-auto-id-2", []uint32{1, 2, 3, 4, 5})
+	})
+
+	t.Run("invalid vector", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		stream := newInsertVectorsServerStream([]*grpcapi.InsertVectorRequest{
+			{
+				IndexName: "test-index-auto-id-1",
+				Vector:    &grpcapi.Vector{Value: []float32{1, 2, 3}},
+			},
+		})
+		assert.Error(t, srv.InsertVectors(stream))
+		assert.Nil(t, stream.Reply)
+	})
+
 	t.Run("insertion error", func(t *testing.T) {
 		t.Parallel()
 		dir := createTempDir(t)
@@ -306,6 +721,27 @@ func TestServer_InsertVectors(t *testing.T) {
 		assert.Error(t, srv.InsertVectors(stream))
 		assert.Nil(t, stream.Reply)
 	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		stream := newInsertVectorsServerStream([]*grpcapi.InsertVectorRequest{
+			{
+				IndexName: "test-index-auto-id-1",
+				Vector:    &grpcapi.Vector{Value: sampleVectors[0]},
+			},
+		})
+		stream.ctx = canceledContext()
+
+		err := srv.InsertVectors(stream)
+		assert.Equal(t, codes.Canceled, status.Code(err))
+		assert.Nil(t, stream.Reply)
+		assertIndexContainsExactlyIDs(t, im, "test-index-auto-id-1", []uint32{1, 2})
+	})
 }
 
 func TestServer_InsertVectorsWithIds(t *testing.T) {
@@ -350,6 +786,34 @@ func TestServer_InsertVectorsWithIds(t *testing.T) {
 		}
 	})
 
+	t.Run("time to live", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		stream := newInsertVectorsWithIdsServerStream([]*grpcapi.InsertVectorWithIdRequest{
+			{
+				IndexName:  "test-index-custom-id-1",
+				Vector:     &grpcapi.Vector{Value: sampleVectors[0]},
+				Id:         10,
+				TtlSeconds: 60,
+			},
+			{
+				IndexName: "test-index-custom-id-1",
+				Vector:    &grpcapi.Vector{Value: sampleVectors[1]},
+				Id:        11,
+			},
+		})
+		require.NoError(t, srv.InsertVectorsWithIds(stream))
+
+		index, _ := im.GetIndex("test-index-custom-id-1")
+		assert.Equal(t, 1, index.ExpiryStats().Pending)
+		assert.Equal(t, 1, im.DeleteExpired(time.Now().Add(time.Hour)))
+		assert.Equal(t, []uint32{1, 2, 11}, index.IDs())
+	})
+
 	t.Run("insertion error", func(t *testing.T) {
 		t.Parallel()
 		dir := createTempDir(t)
@@ -386,6 +850,28 @@ func TestServer_InsertVectorsWithIds(t *testing.T) {
 		assert.Error(t, srv.InsertVectorsWithIds(stream))
 		assert.Nil(t, stream.Reply)
 	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		stream := newInsertVectorsWithIdsServerStream([]*grpcapi.InsertVectorWithIdRequest{
+			{
+				IndexName: "test-index-custom-id-1",
+				Vector:    &grpcapi.Vector{Value: sampleVectors[0]},
+				Id:        10,
+			},
+		})
+		stream.ctx = canceledContext()
+
+		err := srv.InsertVectorsWithIds(stream)
+		assert.Equal(t, codes.Canceled, status.Code(err))
+		assert.Nil(t, stream.Reply)
+		assertIndexContainsExactlyIDs(t, im, "test-index-custom-id-1", []uint32{1, 2})
+	})
 }
 
 func TestServer_SearchKNN(t *testing.T) {
@@ -411,24 +897,112 @@ func TestServer_SearchKNN(t *testing.T) {
 		assert.InDelta(t, 0.0, resp.Hits[0].Distance, 1e-6)
 		assert.Equal(t, "2", resp.Hits[1].Id)
 		assert.Greater(t, resp.Hits[1].Distance, resp.Hits[0].Distance)
+		for _, hit := range resp.Hits {
+			require.NotNil(t, hit.Similarity)
+			assert.InDelta(t, 1-hit.Distance, hit.GetSimilarity(), 1e-6)
+		}
+		assert.InDelta(t, 1.0, resp.Hits[0].GetSimilarity(), 1e-6)
 	})
 
-	t.Run("index not found", func(t *testing.T) {
+	t.Run("exact search", func(t *testing.T) {
 		t.Parallel()
 		dir := createTempDir(t)
 		defer deleteDir(t, dir)
-		im := indexmanager.New(dir, zerolog.Nop())
+		im := createManagerWithPersistedIndices(t, dir)
 		srv := server.New(sampleServerConfig, im, zerolog.Nop())
 
 		resp, err := srv.SearchKNN(ctx, &grpcapi.SearchRequest{
-			IndexName: "foo",
-			Vector:    &grpcapi.Vector{Value: sampleVectors[0]},
+			IndexName: "test-index-custom-id-1",
+			Vector:    &grpcapi.Vector{Value: sampleVectors[1]},
 			K:         10,
+			Exact:     true,
 		})
-		assert.Error(t, err)
-		assert.Nil(t, resp)
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+
+		assert.Len(t, resp.Hits, 2)
+		assert.Equal(t, "2", resp.Hits[0].Id)
+		assert.InDelta(t, 0.0, resp.Hits[0].Distance, 1e-6)
+		assert.Equal(t, "1", resp.Hits[1].Id)
+		assert.Greater(t, resp.Hits[1].Distance, resp.Hits[0].Distance)
 	})
-}
+
+	t.Run("encoded vector", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		data := make([]byte, 20)
+		for i, v := range sampleVectors[1] {
+			binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(v))
+		}
+		resp, err := srv.SearchKNN(ctx, &grpcapi.SearchRequest{
+			IndexName: "test-index-custom-id-1",
+			Vector:    &grpcapi.Vector{Data: data},
+			K:         1,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Hits, 1)
+		assert.Equal(t, "2", resp.Hits[0].Id)
+		assert.InDelta(t, 0.0, resp.Hits[0].Distance, 1e-6)
+	})
+
+	t.Run("zero vector", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		resp, err := srv.SearchKNN(ctx, &grpcapi.SearchRequest{
+			IndexName: "test-index-custom-id-1",
+			Vector:    &grpcapi.Vector{Value: make([]float32, 5)},
+			K:         1,
+		})
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("invalid vector data", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		for _, vector := range []*grpcapi.Vector{
+			{Data: []byte{1, 2, 3}, Encoding: grpcapi.Vector_FLOAT16},
+			{Data: []byte{1, 2}},
+			{Data: []byte{1, 2}, Encoding: 3},
+		} {
+			resp, err := srv.SearchKNN(ctx, &grpcapi.SearchRequest{
+				IndexName: "test-index-custom-id-1",
+				Vector:    vector,
+				K:         1,
+			})
+			assert.Error(t, err)
+			assert.Nil(t, resp)
+		}
+	})
+
+	t.Run("index not found", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := indexmanager.New(dir, zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		resp, err := srv.SearchKNN(ctx, &grpcapi.SearchRequest{
+			IndexName: "foo",
+			Vector:    &grpcapi.Vector{Value: sampleVectors[0]},
+			K:         10,
+		})
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
 
 func TestServer_FlushIndex(t *testing.T) {
 	t.Parallel()
@@ -440,7 +1014,7 @@ func TestServer_FlushIndex(t *testing.T) {
 		im := indexmanager.New(dir, zerolog.Nop())
 		srv := server.New(sampleServerConfig, im, zerolog.Nop())
 
-		_, err := im.CreateIndex("foo", hnswgo.Config{
+		_, err := im.CreateIndex(ctx, "foo", hnswgo.Config{
 			SpaceType:      hnswgo.CosineSpace,
 			Dim:            5,
 			MaxElements:    10,
@@ -474,6 +1048,20 @@ func TestServer_FlushIndex(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, resp)
 	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		resp, err := srv.FlushIndex(canceledContext(), &grpcapi.FlushRequest{
+			IndexName: "test-index-auto-id-1",
+		})
+		assert.Equal(t, codes.Canceled, status.Code(err))
+		assert.Nil(t, resp)
+	})
 }
 
 func TestServer_Indices(t *testing.T) {
@@ -498,7 +1086,7 @@ func TestServer_Indices(t *testing.T) {
 	assert.NotNil(t, resp)
 	assert.Empty(t, resp.Indices)
 
-	_, err = im.CreateIndex("foo", cfg)
+	_, err = im.CreateIndex(ctx, "foo", cfg)
 	assert.NoError(t, err)
 
 	resp, err = srv.Indices(ctx, nil)
@@ -506,7 +1094,7 @@ func TestServer_Indices(t *testing.T) {
 	assert.NotNil(t, resp)
 	assert.Equal(t, []string{"foo"}, resp.Indices)
 
-	_, err = im.CreateIndex("bar", cfg)
+	_, err = im.CreateIndex(ctx, "bar", cfg)
 	assert.NoError(t, err)
 
 	resp, err = srv.Indices(ctx, nil)
@@ -551,6 +1139,613 @@ func TestServer_SetEf(t *testing.T) {
 	})
 }
 
+func TestServer_ExportIndex(t *testing.T) {
+	t.Parallel()
+
+	t.Run("successful export", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		index, _ := im.GetIndex("test-index-custom-id-1")
+		require.NoError(t, index.AddPoint(sampleVectors[0], 3))
+		require.NoError(t, index.MarkDelete(1))
+
+		stream := &exportIndexServerStream{}
+		err := srv.ExportIndex(&grpcapi.ExportIndexRequest{IndexName: "test-index-custom-id-1"}, stream)
+		assert.NoError(t, err)
+		require.Len(t, stream.Replies, 2)
+		assert.Equal(t, "2", stream.Replies[0].Id)
+		assert.Equal(t, "3", stream.Replies[1].Id)
+		assert.Len(t, stream.Replies[0].Vector.Value, 5)
+	})
+
+	t.Run("half-precision export", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		stream := &exportIndexServerStream{}
+		err := srv.ExportIndex(&grpcapi.ExportIndexRequest{
+			IndexName: "test-index-custom-id-1",
+			Encoding:  grpcapi.Vector_BFLOAT16,
+		}, stream)
+		assert.NoError(t, err)
+		require.Len(t, stream.Replies, 2)
+		vector := stream.Replies[1].Vector
+		assert.Empty(t, vector.Value)
+		assert.Equal(t, grpcapi.Vector_BFLOAT16, vector.Encoding)
+		index, _ := im.GetIndex("test-index-custom-id-1")
+		stored, found := index.GetVector(2)
+		require.True(t, found)
+		assert.Equal(t, encodeHalfVector(stored, true), vector.Data)
+	})
+
+	t.Run("index not found", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := indexmanager.New(dir, zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		stream := &exportIndexServerStream{}
+		err := srv.ExportIndex(&grpcapi.ExportIndexRequest{IndexName: "foo"}, stream)
+		assert.Error(t, err)
+		assert.Empty(t, stream.Replies)
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		stream := &exportIndexServerStream{baseVectorsStream: baseVectorsStream{ctx: cancelCtx}}
+		err := srv.ExportIndex(&grpcapi.ExportIndexRequest{IndexName: "test-index-custom-id-1"}, stream)
+		assert.Equal(t, codes.Canceled, status.Code(err))
+		assert.Empty(t, stream.Replies)
+	})
+}
+
+func TestServer_GetVectors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("normalized vectors", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		index, _ := im.GetIndex("test-index-custom-id-1")
+		require.NoError(t, index.MarkDelete(1))
+
+		resp, err := srv.GetVectors(ctx, &grpcapi.GetVectorsRequest{
+			IndexName: "test-index-custom-id-1",
+			Ids:       []int32{2, 1, 3},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Vectors, 1)
+		assert.Equal(t, "2", resp.Vectors[0].Id)
+		stored, found := index.GetVector(2)
+		require.True(t, found)
+		assert.Equal(t, stored, resp.Vectors[0].Vector.Value)
+		assert.NotEqual(t, sampleVectors[1], resp.Vectors[0].Vector.Value)
+		assert.Equal(t, []string{"1", "3"}, resp.NotFoundIds)
+	})
+
+	t.Run("original vectors", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		resp, err := srv.GetVectors(ctx, &grpcapi.GetVectorsRequest{
+			IndexName: "test-index-custom-id-1",
+			Ids:       []int32{1, 2},
+			Original:  true,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Vectors, 2)
+		for i, vector := range resp.Vectors {
+			require.Len(t, vector.Vector.Value, 5)
+			for j, v := range sampleVectors[i] {
+				assert.InDelta(t, v, vector.Vector.Value[j], 1e-6)
+			}
+		}
+		assert.Empty(t, resp.NotFoundIds)
+	})
+
+	t.Run("original vectors not stored", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := indexmanager.New(dir, zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		_, err := srv.CreateIndex(ctx, &grpcapi.CreateIndexRequest{
+			IndexName:   "foo",
+			Dim:         5,
+			MaxElements: 10,
+			SpaceType:   grpcapi.CreateIndexRequest_COSINE,
+			IndexType:   grpcapi.CreateIndexRequest_FLAT,
+		})
+		require.NoError(t, err)
+
+		resp, err := srv.GetVectors(ctx, &grpcapi.GetVectorsRequest{
+			IndexName: "foo",
+			Ids:       []int32{1},
+			Original:  true,
+		})
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("index not found", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := indexmanager.New(dir, zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		resp, err := srv.GetVectors(ctx, &grpcapi.GetVectorsRequest{IndexName: "foo", Ids: []int32{1}})
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestServer_CompactIndex(t *testing.T) {
+	t.Parallel()
+
+	t.Run("waiting for the compaction", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		oldIndex, _ := im.GetIndex("test-index-custom-id-1")
+		require.NoError(t, oldIndex.MarkDelete(1))
+
+		resp, err := srv.CompactIndex(ctx, &grpcapi.CompactIndexRequest{
+			IndexName: "test-index-custom-id-1",
+			Wait:      true,
+		})
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+
+		index, _ := im.GetIndex("test-index-custom-id-1")
+		assert.NotSame(t, oldIndex, index)
+		assert.Equal(t, []uint32{2}, index.IDs())
+	})
+
+	t.Run("background compaction", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		oldIndex, _ := im.GetIndex("test-index-auto-id-1")
+		resp, err := srv.CompactIndex(ctx, &grpcapi.CompactIndexRequest{
+			IndexName: "test-index-auto-id-1",
+		})
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+
+		assert.Eventually(t, func() bool {
+			index, _ := im.GetIndex("test-index-auto-id-1")
+			return index != oldIndex
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("index not found", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := indexmanager.New(dir, zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		for _, wait := range []bool{true, false} {
+			resp, err := srv.CompactIndex(ctx, &grpcapi.CompactIndexRequest{
+				IndexName: "foo",
+				Wait:      wait,
+			})
+			assert.Error(t, err)
+			assert.Nil(t, resp)
+		}
+	})
+}
+
+func TestServer_DeleteAndUndeleteVectors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reversible deletion", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+		index, _ := im.GetIndex("test-index-custom-id-1")
+
+		deleteResp, err := srv.DeleteVectors(ctx, &grpcapi.DeleteVectorsRequest{
+			IndexName: "test-index-custom-id-1",
+			Ids:       []int32{1, 2, 42},
+		})
+		require.NoError(t, err)
+		assert.NotNil(t, deleteResp)
+		assert.Empty(t, index.IDs())
+
+		undeleteResp, err := srv.UndeleteVectors(ctx, &grpcapi.UndeleteVectorsRequest{
+			IndexName: "test-index-custom-id-1",
+			Ids:       []int32{2, 42},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"42"}, undeleteResp.GetNotFoundIds())
+		assert.Equal(t, []uint32{2}, index.IDs())
+	})
+
+	t.Run("index not found", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := indexmanager.New(dir, zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		deleteResp, err := srv.DeleteVectors(ctx, &grpcapi.DeleteVectorsRequest{IndexName: "foo", Ids: []int32{1}})
+		assert.Error(t, err)
+		assert.Nil(t, deleteResp)
+
+		undeleteResp, err := srv.UndeleteVectors(ctx, &grpcapi.UndeleteVectorsRequest{IndexName: "foo", Ids: []int32{1}})
+		assert.Error(t, err)
+		assert.Nil(t, undeleteResp)
+	})
+}
+
+func TestServer_DescribeIndex(t *testing.T) {
+	t.Parallel()
+
+	t.Run("successful description", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := indexmanager.New(dir, zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		_, err := srv.CreateIndex(ctx, &grpcapi.CreateIndexRequest{
+			IndexName:         "foo",
+			Dim:               5,
+			EfConstruction:    200,
+			M:                 10,
+			MaxElements:       10,
+			Seed:              100,
+			SpaceType:         grpcapi.CreateIndexRequest_IP,
+			AutoId:            true,
+			DefaultTtlSeconds: 3600,
+		})
+		require.NoError(t, err)
+
+		start := time.Now()
+		for _, ttl := range []int64{0, 60, -1} {
+			_, err = srv.InsertVector(ctx, &grpcapi.InsertVectorRequest{
+				IndexName:  "foo",
+				Vector:     &grpcapi.Vector{Value: sampleVectors[0]},
+				TtlSeconds: ttl,
+			})
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 1, im.DeleteExpired(start.Add(30*time.Minute)))
+
+		resp, err := srv.DescribeIndex(ctx, &grpcapi.DescribeIndexRequest{IndexName: "foo"})
+		require.NoError(t, err)
+		assert.Equal(t, "foo", resp.GetIndexName())
+		assert.Equal(t, int32(5), resp.GetDim())
+		assert.Equal(t, int32(200), resp.GetEfConstruction())
+		assert.Equal(t, int32(10), resp.GetM())
+		assert.Equal(t, int32(10), resp.GetMaxElements())
+		assert.Equal(t, int32(100), resp.GetSeed())
+		assert.Equal(t, grpcapi.CreateIndexRequest_IP, resp.GetSpaceType())
+		assert.True(t, resp.GetAutoId())
+		assert.False(t, resp.GetAllowReplaceDeleted())
+		assert.Equal(t, int64(3600), resp.GetDefaultTtlSeconds())
+		assert.Equal(t, grpcapi.CreateIndexRequest_HNSW, resp.GetIndexType())
+		assert.Equal(t, grpcapi.CreateIndexRequest_FLOAT32, resp.GetStorageType())
+		assert.Equal(t, int64(2), resp.GetSize())
+		assert.Equal(t, int64(1), resp.GetExpiry().GetPending())
+		assert.Equal(t, int64(1), resp.GetExpiry().GetExpired())
+		assert.InDelta(t, start.Add(time.Hour).Unix(), resp.GetExpiry().GetNextExpiration(), 5)
+		assert.True(t, resp.GetResident())
+	})
+
+	t.Run("lazily loaded index", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		srv := server.New(sampleServerConfig, indexmanager.New(dir, zerolog.Nop()), zerolog.Nop())
+		_, err := srv.CreateIndex(ctx, &grpcapi.CreateIndexRequest{
+			IndexName:      "foo",
+			Dim:            5,
+			EfConstruction: 200,
+			M:              10,
+			MaxElements:    10,
+		})
+		require.NoError(t, err)
+		_, err = srv.FlushIndex(ctx, &grpcapi.FlushRequest{IndexName: "foo"})
+		require.NoError(t, err)
+
+		im := indexmanager.New(dir, zerolog.Nop())
+		require.NoError(t, im.LoadIndicesLazily(hnswgo.LoadOptions{}, indexmanager.LazyOptions{}))
+		srv = server.New(sampleServerConfig, im, zerolog.Nop())
+
+		resp, err := srv.DescribeIndex(ctx, &grpcapi.DescribeIndexRequest{IndexName: "foo"})
+		require.NoError(t, err)
+		assert.False(t, resp.GetResident())
+		assert.Equal(t, int32(5), resp.GetDim())
+
+		resp, err = srv.DescribeIndex(ctx, &grpcapi.DescribeIndexRequest{IndexName: "foo"})
+		require.NoError(t, err)
+		assert.True(t, resp.GetResident())
+
+		// Indices which cannot be loaded again are reported as internal
+		// errors.
+		index, _ := im.GetIndex("foo")
+		require.NoError(t, index.Unload(ctx))
+		require.NoError(t, os.Remove(path.Join(dir, "foo", "index")))
+		_, err = srv.DescribeIndex(ctx, &grpcapi.DescribeIndexRequest{IndexName: "foo"})
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+
+	t.Run("invalid default TTL", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := indexmanager.New(dir, zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		_, err := srv.CreateIndex(ctx, &grpcapi.CreateIndexRequest{
+			IndexName:         "foo",
+			Dim:               5,
+			EfConstruction:    200,
+			M:                 10,
+			MaxElements:       10,
+			DefaultTtlSeconds: -1,
+		})
+		assert.Error(t, err)
+		assert.Empty(t, im.IndicesNames())
+	})
+
+	t.Run("index not found", func(t *testing.T) {
+		t.Parallel()
+		im := indexmanager.New(os.TempDir(), zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		resp, err := srv.DescribeIndex(ctx, &grpcapi.DescribeIndexRequest{IndexName: "foo"})
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestServer_EvaluateRecall(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sampled queries", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		resp, err := srv.EvaluateRecall(ctx, &grpcapi.EvaluateRecallRequest{
+			IndexName: "test-index-auto-id-1",
+			K:         2,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(len(sampleVectors)), resp.Queries)
+		assert.Equal(t, float32(1), resp.Recall)
+		assert.Equal(t, int32(10), resp.Ef)
+		assert.Positive(t, resp.ApproximateLatency)
+		assert.Positive(t, resp.ExactLatency)
+	})
+
+	t.Run("given queries", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		resp, err := srv.EvaluateRecall(ctx, &grpcapi.EvaluateRecallRequest{
+			IndexName:  "test-index-auto-id-1",
+			K:          1,
+			SampleSize: 1,
+			Queries: []*grpcapi.Vector{
+				{Value: sampleVectors[0]},
+				{Value: sampleVectors[1]},
+				{Value: sampleVectors[0]},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), resp.Queries)
+		assert.Equal(t, float32(1), resp.Recall)
+	})
+
+	t.Run("invalid k", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		resp, err := srv.EvaluateRecall(ctx, &grpcapi.EvaluateRecallRequest{IndexName: "test-index-auto-id-1"})
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("index not found", func(t *testing.T) {
+		t.Parallel()
+		im := indexmanager.New(os.TempDir(), zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		resp, err := srv.EvaluateRecall(ctx, &grpcapi.EvaluateRecallRequest{IndexName: "foo", K: 10})
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestServer_AutoTuneEf(t *testing.T) {
+	t.Parallel()
+
+	t.Run("successful tuning", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		index, _ := im.GetIndex("test-index-auto-id-1")
+		require.NoError(t, index.SetEf(40))
+
+		resp, err := srv.AutoTuneEf(ctx, &grpcapi.AutoTuneEfRequest{
+			IndexName:    "test-index-auto-id-1",
+			TargetRecall: 1,
+			K:            1,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), resp.Ef)
+		assert.Equal(t, float32(1), resp.Recall)
+		assert.True(t, resp.TargetReached)
+		assert.Positive(t, resp.ExactLatency)
+		require.NotEmpty(t, resp.Measurements)
+		assert.Equal(t, int32(1), resp.Measurements[0].Ef)
+		assert.Equal(t, int32(1000), resp.Measurements[len(resp.Measurements)-1].Ef)
+		assert.Equal(t, 1, index.Ef())
+	})
+
+	t.Run("invalid target recall", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		resp, err := srv.AutoTuneEf(ctx, &grpcapi.AutoTuneEfRequest{IndexName: "test-index-auto-id-1", K: 1})
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("index not found", func(t *testing.T) {
+		t.Parallel()
+		im := indexmanager.New(os.TempDir(), zerolog.Nop())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		resp, err := srv.AutoTuneEf(ctx, &grpcapi.AutoTuneEfRequest{IndexName: "foo", K: 1, TargetRecall: 0.9})
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestServer_RetryIndex(t *testing.T) {
+	t.Parallel()
+
+	t.Run("quarantined index repair", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		// The previous snapshots are kept since the quarantine is enabled.
+		im.EnableQuarantine()
+		require.NoError(t, im.PersistIndex(ctx, "test-index-auto-id-1"))
+		require.NoError(t, os.Remove(path.Join(dir, "test-index-auto-id-1", "index")))
+
+		im = indexmanager.New(dir, zerolog.Nop())
+		im.EnableQuarantine()
+		require.Error(t, im.LoadIndices())
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		failures, err := srv.FailedIndices(ctx, &emptypb.Empty{})
+		require.NoError(t, err)
+		require.Len(t, failures.GetIndices(), 1)
+		failure := failures.GetIndices()[0]
+		assert.Equal(t, "test-index-auto-id-1", failure.GetIndexName())
+		assert.NotEmpty(t, failure.GetError())
+		assert.True(t, failure.GetQuarantined())
+
+		_, err = srv.RetryIndex(ctx, &grpcapi.RetryIndexRequest{IndexName: "test-index-auto-id-1"})
+		assert.Error(t, err)
+		_, err = srv.RetryIndex(ctx, &grpcapi.RetryIndexRequest{IndexName: "test-index-auto-id-1", Repair: true})
+		require.NoError(t, err)
+
+		failures, err = srv.FailedIndices(ctx, &emptypb.Empty{})
+		require.NoError(t, err)
+		assert.Empty(t, failures.GetIndices())
+		description, err := srv.DescribeIndex(ctx, &grpcapi.DescribeIndexRequest{IndexName: "test-index-auto-id-1"})
+		require.NoError(t, err)
+		assert.Equal(t, grpcapi.IndexStatus_READY, description.GetStatus())
+	})
+
+	t.Run("index not failed", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		im := createManagerWithPersistedIndices(t, dir)
+		srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+		_, err := srv.RetryIndex(ctx, &grpcapi.RetryIndexRequest{IndexName: "test-index-auto-id-1"})
+		assert.Error(t, err)
+		_, err = srv.RetryIndex(ctx, &grpcapi.RetryIndexRequest{IndexName: "foo"})
+		assert.Error(t, err)
+	})
+}
+
+func TestServer_VerifyIndex(t *testing.T) {
+	t.Parallel()
+
+	dir := createTempDir(t)
+	defer deleteDir(t, dir)
+	im := createManagerWithPersistedIndices(t, dir)
+	srv := server.New(sampleServerConfig, im, zerolog.Nop())
+
+	reply, err := srv.VerifyIndex(ctx, &grpcapi.VerifyIndexRequest{IndexName: "test-index-auto-id-1"})
+	require.NoError(t, err)
+	assert.True(t, reply.GetOk())
+	assert.Empty(t, reply.GetProblems())
+	assert.Equal(t, uint64(len(sampleVectors)), reply.GetGraph().GetElements())
+
+	// The last entry of the log is only partially written.
+	index, _ := im.GetIndex("test-index-auto-id-1")
+	require.NoError(t, index.MarkDelete(1))
+	require.NoError(t, index.MarkDelete(2))
+	logFile := path.Join(dir, "test-index-auto-id-1", "log")
+	info, err := os.Stat(logFile)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(logFile, info.Size()-3))
+
+	reply, err = srv.VerifyIndex(ctx, &grpcapi.VerifyIndexRequest{IndexName: "test-index-auto-id-1"})
+	require.NoError(t, err)
+	assert.False(t, reply.GetOk())
+	assert.Len(t, reply.GetProblems(), 1)
+	assert.Equal(t, int64(1), reply.GetLog().GetEntries())
+	assert.NotEmpty(t, reply.GetLog().GetError())
+
+	reply, err = srv.VerifyIndex(ctx, &grpcapi.VerifyIndexRequest{IndexName: "test-index-auto-id-1", Repair: true})
+	require.NoError(t, err)
+	assert.True(t, reply.GetOk())
+	assert.True(t, reply.GetLog().GetRepaired())
+	assert.Len(t, reply.GetWarnings(), 1)
+	assert.NoFileExists(t, logFile)
+
+	_, err = srv.VerifyIndex(ctx, &grpcapi.VerifyIndexRequest{IndexName: "foo"})
+	assert.Error(t, err)
+}
+
 var (
 	sampleCreateIndexRequest = &grpcapi.CreateIndexRequest{
 		IndexName:      "foo",
@@ -575,6 +1770,19 @@ var (
 	ctx = context.Background()
 )
 
+// encodeHalfVector encodes a vector as float16 or bfloat16 data.
+func encodeHalfVector(vector []float32, bfloat16 bool) []byte {
+	data := make([]byte, 2*len(vector))
+	for i, v := range vector {
+		h := hnswgo.Float32ToFloat16(v)
+		if bfloat16 {
+			h = hnswgo.Float32ToBFloat16(v)
+		}
+		binary.LittleEndian.PutUint16(data[i*2:], h)
+	}
+	return data
+}
+
 func createManagerWithPersistedIndices(t *testing.T, path string) *indexmanager.IndexManager {
 	t.Helper()
 	im := indexmanager.New(path, zerolog.Nop())
@@ -589,7 +1797,7 @@ func createManagerWithPersistedIndices(t *testing.T, path string) *indexmanager.
 
 		for i := 1; i <= 2; i++ {
 			name := fmt.Sprintf("%s-%d", namePrefix, i)
-			index, err := im.CreateIndex(name, hnswgo.Config{
+			index, err := im.CreateIndex(ctx, name, hnswgo.Config{
 				SpaceType:      hnswgo.CosineSpace,
 				Dim:            5,
 				MaxElements:    10,
@@ -612,7 +1820,7 @@ func createManagerWithPersistedIndices(t *testing.T, path string) *indexmanager.
 				}
 			}
 
-			require.NoError(t, im.PersistIndex(name))
+			require.NoError(t, im.PersistIndex(ctx, name))
 		}
 	}
 
@@ -672,17 +1880,37 @@ func (s *insertVectorsWithIdsServerStream) Recv() (*grpcapi.InsertVectorWithIdRe
 	return req, nil
 }
 
-type baseVectorsStream struct{}
+type exportIndexServerStream struct {
+	baseVectorsStream
+	Replies []*grpcapi.ExportIndexReply
+}
+
+var _ grpcapi.Server_ExportIndexServer = &exportIndexServerStream{}
+
+func (s *exportIndexServerStream) Send(reply *grpcapi.ExportIndexReply) error {
+	s.Replies = append(s.Replies, reply)
+	return nil
+}
+
+type baseVectorsStream struct {
+	ctx context.Context
+}
 
 var _ grpc.ServerStream = baseVectorsStream{}
 
 func (s baseVectorsStream) SetHeader(metadata.MD) error  { return nil }
 func (s baseVectorsStream) SendHeader(metadata.MD) error { return nil }
 func (s baseVectorsStream) SetTrailer(metadata.MD)       {}
-func (s baseVectorsStream) Context() context.Context     { return context.Background() }
 func (s baseVectorsStream) SendMsg(interface{}) error    { return nil }
 func (s baseVectorsStream) RecvMsg(interface{}) error    { return nil }
 
+func (s baseVectorsStream) Context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
 func assertIndexContainsExactlyIDs(t *testing.T, im *indexmanager.IndexManager, indexName string, ids []uint32) {
 	t.Helper()
 	index, indexExists := im.GetIndex(indexName)
@@ -701,6 +1929,12 @@ func assertIndexContainsExactlyIDs(t *testing.T, im *indexmanager.IndexManager,
 	}
 }
 
+func canceledContext() context.Context {
+	c, cancel := context.WithCancel(context.Background())
+	cancel()
+	return c
+}
+
 func createTempDir(t *testing.T) string {
 	t.Helper()
 	dir, err := os.MkdirTemp("", "server_test")
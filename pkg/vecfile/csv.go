@@ -0,0 +1,211 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vecfile
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// csvReader reads vectors from a CSV file with a header row.
+type csvReader struct {
+	name string
+	file *os.File
+	r    *csv.Reader
+	line int
+
+	idColumn     int   // -1 if missing
+	vectorColumn int   // -1 if vector components are in separate columns
+	components   []int // columns of vector components
+}
+
+func openCSV(name string, opts Options) (_ *csvReader, err error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file %#v: %w", name, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = file.Close()
+		}
+	}()
+
+	r := newCSVReader(file)
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header of file %#v: %w", name, err)
+	}
+
+	cr := &csvReader{
+		name:         name,
+		file:         file,
+		r:            r,
+		line:         1,
+		idColumn:     -1,
+		vectorColumn: -1,
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, c := range header {
+		columns[strings.TrimSpace(c)] = i
+	}
+	column := func(c string) (int, error) {
+		i, ok := columns[c]
+		if !ok {
+			return 0, fmt.Errorf("column %#v not found in file %#v", c, name)
+		}
+		return i, nil
+	}
+
+	skipped := make(map[int]bool)
+	if opts.IDColumn != "" {
+		if cr.idColumn, err = column(opts.IDColumn); err != nil {
+			return nil, err
+		}
+		skipped[cr.idColumn] = true
+	}
+	for _, c := range opts.PayloadColumns {
+		i, err := column(c)
+		if err != nil {
+			return nil, err
+		}
+		skipped[i] = true
+	}
+
+	if opts.VectorColumn != "" {
+		if cr.vectorColumn, err = column(opts.VectorColumn); err != nil {
+			return nil, err
+		}
+		return cr, nil
+	}
+	for i := range header {
+		if !skipped[i] {
+			cr.components = append(cr.components, i)
+		}
+	}
+	if len(cr.components) == 0 {
+		return nil, fmt.Errorf("no vector columns found in file %#v", name)
+	}
+	return cr, nil
+}
+
+func newCSVReader(r io.Reader) *csv.Reader {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	return cr
+}
+
+func (r *csvReader) Read() (Record, error) {
+	row, err := r.r.Read()
+	if err == io.EOF {
+		return Record{}, io.EOF
+	}
+	if err != nil {
+		return Record{}, fmt.Errorf("error reading file %#v: %w", r.name, err)
+	}
+	r.line++
+
+	var rec Record
+	if r.idColumn >= 0 {
+		v, err := strconv.ParseInt(strings.TrimSpace(row[r.idColumn]), 10, 64)
+		if err == nil {
+			rec.ID, err = toID(v)
+		}
+		if err != nil {
+			return Record{}, fmt.Errorf("invalid ID at line %d of file %#v: %w", r.line, r.name, err)
+		}
+		rec.HasID = true
+	}
+
+	if r.vectorColumn >= 0 {
+		rec.Vector, err = parseVector(row[r.vectorColumn])
+	} else {
+		rec.Vector = make([]float32, len(r.components))
+		for i, c := range r.components {
+			if rec.Vector[i], err = parseFloat32(row[c]); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return Record{}, fmt.Errorf("invalid vector at line %d of file %#v: %w", r.line, r.name, err)
+	}
+	return rec, nil
+}
+
+// Len counts the records, scanning the whole file.
+func (r *csvReader) Len() (int, error) {
+	file, err := os.Open(r.name)
+	if err != nil {
+		return 0, fmt.Errorf("error opening file %#v: %w", r.name, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	cr := newCSVReader(file)
+	n := -1 // skip the header
+	for {
+		_, err = cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("error reading file %#v: %w", r.name, err)
+		}
+		n++
+	}
+	if n < 0 {
+		n = 0
+	}
+	return n, nil
+}
+
+func (r *csvReader) Close() error {
+	return r.file.Close()
+}
+
+// parseVector parses a list of numbers separated by commas or spaces,
+// optionally surrounded by square brackets.
+func parseVector(s string) ([]float32, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty vector")
+	}
+	vector := make([]float32, len(fields))
+	for i, f := range fields {
+		v, err := parseFloat32(f)
+		if err != nil {
+			return nil, err
+		}
+		vector[i] = v
+	}
+	return vector, nil
+}
+
+func parseFloat32(s string) (float32, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 32)
+	if err != nil {
+		return 0, err
+	}
+	return float32(v), nil
+}
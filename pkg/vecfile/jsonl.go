@@ -0,0 +1,195 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vecfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// defaultJSONLIDKey is the key of JSONL IDs, if not configured otherwise.
+	defaultJSONLIDKey = "id"
+	// defaultJSONLVectorKey is the key of JSONL vectors, if not configured
+	// otherwise.
+	defaultJSONLVectorKey = "vector"
+)
+
+// maxJSONLLineSize is the maximum size of a single line of a JSONL file.
+const maxJSONLLineSize = 64 * 1024 * 1024
+
+// jsonlReader reads vectors from a JSONL file, where each line is an object
+// with a vector and an optional ID.
+type jsonlReader struct {
+	name      string
+	file      *os.File
+	scanner   *bufio.Scanner
+	line      int
+	idKey     string
+	vectorKey string
+}
+
+func openJSONL(name string, opts Options) (*jsonlReader, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file %#v: %w", name, err)
+	}
+	idKey, vectorKey := jsonlKeys(opts)
+	return &jsonlReader{
+		name:      name,
+		file:      file,
+		scanner:   newJSONLScanner(file),
+		idKey:     idKey,
+		vectorKey: vectorKey,
+	}, nil
+}
+
+func jsonlKeys(opts Options) (idKey, vectorKey string) {
+	idKey, vectorKey = opts.IDColumn, opts.VectorColumn
+	if idKey == "" {
+		idKey = defaultJSONLIDKey
+	}
+	if vectorKey == "" {
+		vectorKey = defaultJSONLVectorKey
+	}
+	return
+}
+
+func newJSONLScanner(r io.Reader) *bufio.Scanner {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, maxJSONLLineSize)
+	return s
+}
+
+func (r *jsonlReader) Read() (Record, error) {
+	var line []byte
+	for len(line) == 0 {
+		if !r.scanner.Scan() {
+			if err := r.scanner.Err(); err != nil {
+				return Record{}, fmt.Errorf("error reading file %#v: %w", r.name, err)
+			}
+			return Record{}, io.EOF
+		}
+		r.line++
+		line = bytes.TrimSpace(r.scanner.Bytes())
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(line, &obj); err != nil {
+		return Record{}, fmt.Errorf("invalid JSON at line %d of file %#v: %w", r.line, r.name, err)
+	}
+
+	var rec Record
+	rawVector, ok := obj[r.vectorKey]
+	if !ok {
+		return Record{}, fmt.Errorf("missing %#v at line %d of file %#v", r.vectorKey, r.line, r.name)
+	}
+	if err := json.Unmarshal(rawVector, &rec.Vector); err != nil || len(rec.Vector) == 0 {
+		return Record{}, fmt.Errorf("invalid vector at line %d of file %#v", r.line, r.name)
+	}
+
+	if rawID, ok := obj[r.idKey]; ok {
+		var id int64
+		err := json.Unmarshal(rawID, &id)
+		if err == nil {
+			rec.ID, err = toID(id)
+		}
+		if err != nil {
+			return Record{}, fmt.Errorf("invalid ID at line %d of file %#v: %w", r.line, r.name, err)
+		}
+		rec.HasID = true
+	}
+	return rec, nil
+}
+
+// Len counts the non-empty lines, scanning the whole file.
+func (r *jsonlReader) Len() (int, error) {
+	file, err := os.Open(r.name)
+	if err != nil {
+		return 0, fmt.Errorf("error opening file %#v: %w", r.name, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	n := 0
+	s := newJSONLScanner(file)
+	for s.Scan() {
+		if len(bytes.TrimSpace(s.Bytes())) > 0 {
+			n++
+		}
+	}
+	if err = s.Err(); err != nil {
+		return 0, fmt.Errorf("error reading file %#v: %w", r.name, err)
+	}
+	return n, nil
+}
+
+func (r *jsonlReader) Close() error {
+	return r.file.Close()
+}
+
+// jsonlWriter writes vectors to a JSONL file.
+type jsonlWriter struct {
+	name      string
+	file      *os.File
+	w         *bufio.Writer
+	idKey     string
+	vectorKey string
+}
+
+func createJSONL(name string, opts Options) (*jsonlWriter, error) {
+	file, err := os.Create(name)
+	if err != nil {
+		return nil, fmt.Errorf("error creating file %#v: %w", name, err)
+	}
+	idKey, vectorKey := jsonlKeys(opts)
+	return &jsonlWriter{
+		name:      name,
+		file:      file,
+		w:         bufio.NewWriter(file),
+		idKey:     idKey,
+		vectorKey: vectorKey,
+	}, nil
+}
+
+func (w *jsonlWriter) Write(rec Record) error {
+	obj := map[string]interface{}{w.vectorKey: rec.Vector}
+	if rec.HasID {
+		obj[w.idKey] = rec.ID
+	}
+	line, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("error encoding vector: %w", err)
+	}
+	line = append(line, '\n')
+	if _, err = w.w.Write(line); err != nil {
+		return fmt.Errorf("error writing file %#v: %w", w.name, err)
+	}
+	return nil
+}
+
+func (w *jsonlWriter) Close() error {
+	err := w.w.Flush()
+	if e := w.file.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return fmt.Errorf("error writing file %#v: %w", w.name, err)
+	}
+	return nil
+}
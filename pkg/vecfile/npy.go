@@ -0,0 +1,379 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vecfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// npyMagic is the prefix of all NumPy ".npy" files.
+const npyMagic = "\x93NUMPY"
+
+var (
+	npyDescrRegexp   = regexp.MustCompile(`'descr'\s*:\s*'([^']*)'`)
+	npyFortranRegexp = regexp.MustCompile(`'fortran_order'\s*:\s*(True|False)`)
+	npyShapeRegexp   = regexp.MustCompile(`'shape'\s*:\s*\(([^)]*)\)`)
+)
+
+// npyHeader is the decoded header of a ".npy" file.
+type npyHeader struct {
+	dtype npyDType
+	shape []int
+}
+
+// npyDType describes the type of the elements of a NumPy array.
+type npyDType struct {
+	kind  byte // 'f', 'i' or 'u'
+	size  int
+	order binary.ByteOrder
+}
+
+func parseNPYDType(descr string) (npyDType, error) {
+	if len(descr) < 3 {
+		return npyDType{}, fmt.Errorf("unsupported npy data type %#v", descr)
+	}
+	dt := npyDType{kind: descr[1]}
+	switch descr[0] {
+	case '<', '|', '=':
+		dt.order = binary.LittleEndian
+	case '>':
+		dt.order = binary.BigEndian
+	default:
+		return npyDType{}, fmt.Errorf("unsupported npy data type %#v", descr)
+	}
+	size, err := strconv.Atoi(descr[2:])
+	if err != nil {
+		return npyDType{}, fmt.Errorf("unsupported npy data type %#v", descr)
+	}
+	dt.size = size
+
+	switch {
+	case dt.kind == 'f' && (size == 4 || size == 8),
+		(dt.kind == 'i' || dt.kind == 'u') && (size == 1 || size == 2 || size == 4 || size == 8):
+		return dt, nil
+	default:
+		return npyDType{}, fmt.Errorf("unsupported npy data type %#v", descr)
+	}
+}
+
+// float32 decodes a single element as float32.
+func (dt npyDType) float32(b []byte) float32 {
+	switch dt.kind {
+	case 'f':
+		if dt.size == 4 {
+			return math.Float32frombits(dt.order.Uint32(b))
+		}
+		return float32(math.Float64frombits(dt.order.Uint64(b)))
+	case 'i':
+		return float32(dt.int64(b))
+	default:
+		return float32(dt.uint64(b))
+	}
+}
+
+func (dt npyDType) int64(b []byte) int64 {
+	switch dt.size {
+	case 1:
+		return int64(int8(b[0]))
+	case 2:
+		return int64(int16(dt.order.Uint16(b)))
+	case 4:
+		return int64(int32(dt.order.Uint32(b)))
+	default:
+		return int64(dt.order.Uint64(b))
+	}
+}
+
+func (dt npyDType) uint64(b []byte) uint64 {
+	switch dt.size {
+	case 1:
+		return uint64(b[0])
+	case 2:
+		return uint64(dt.order.Uint16(b))
+	case 4:
+		return uint64(dt.order.Uint32(b))
+	default:
+		return dt.order.Uint64(b)
+	}
+}
+
+func readNPYHeader(r io.Reader) (npyHeader, error) {
+	prefix := make([]byte, len(npyMagic)+2)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return npyHeader{}, fmt.Errorf("error reading npy header: %w", err)
+	}
+	if string(prefix[:len(npyMagic)]) != npyMagic {
+		return npyHeader{}, fmt.Errorf("not a npy file")
+	}
+
+	var headerLen int
+	switch major := prefix[len(npyMagic)]; major {
+	case 1:
+		var n uint16
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return npyHeader{}, fmt.Errorf("error reading npy header: %w", err)
+		}
+		headerLen = int(n)
+	case 2, 3:
+		var n uint32
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return npyHeader{}, fmt.Errorf("error reading npy header: %w", err)
+		}
+		headerLen = int(n)
+	default:
+		return npyHeader{}, fmt.Errorf("unsupported npy format version %d", major)
+	}
+
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return npyHeader{}, fmt.Errorf("error reading npy header: %w", err)
+	}
+	return parseNPYHeader(string(header))
+}
+
+func parseNPYHeader(header string) (npyHeader, error) {
+	descr := npyDescrRegexp.FindStringSubmatch(header)
+	fortran := npyFortranRegexp.FindStringSubmatch(header)
+	shape := npyShapeRegexp.FindStringSubmatch(header)
+	if descr == nil || fortran == nil || shape == nil {
+		return npyHeader{}, fmt.Errorf("invalid npy header %#v", header)
+	}
+	if fortran[1] == "True" {
+		return npyHeader{}, fmt.Errorf("npy arrays in Fortran order are not supported")
+	}
+
+	dtype, err := parseNPYDType(descr[1])
+	if err != nil {
+		return npyHeader{}, err
+	}
+
+	h := npyHeader{dtype: dtype}
+	for _, s := range strings.Split(shape[1], ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return npyHeader{}, fmt.Errorf("invalid npy shape %#v", shape[1])
+		}
+		h.shape = append(h.shape, n)
+	}
+	return h, nil
+}
+
+// npyReader reads vectors from the rows of a 2-dimensional NumPy array.
+type npyReader struct {
+	file  *os.File
+	r     *bufio.Reader
+	dtype npyDType
+	rows  int
+	cols  int
+	row   int
+	buf   []byte
+}
+
+func openNPY(name string) (_ *npyReader, err error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file %#v: %w", name, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = file.Close()
+		}
+	}()
+
+	r := bufio.NewReader(file)
+	h, err := readNPYHeader(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %#v: %w", name, err)
+	}
+	if len(h.shape) != 2 {
+		return nil, fmt.Errorf("error reading file %#v: expected a 2-dimensional array, shape is %v", name, h.shape)
+	}
+	return &npyReader{
+		file:  file,
+		r:     r,
+		dtype: h.dtype,
+		rows:  h.shape[0],
+		cols:  h.shape[1],
+		buf:   make([]byte, h.shape[1]*h.dtype.size),
+	}, nil
+}
+
+func (r *npyReader) Read() (Record, error) {
+	if r.row >= r.rows {
+		return Record{}, io.EOF
+	}
+	if _, err := io.ReadFull(r.r, r.buf); err != nil {
+		return Record{}, fmt.Errorf("error reading npy row %d: %w", r.row, err)
+	}
+	r.row++
+
+	vector := make([]float32, r.cols)
+	for i := range vector {
+		vector[i] = r.dtype.float32(r.buf[i*r.dtype.size:])
+	}
+	return Record{Vector: vector}, nil
+}
+
+func (r *npyReader) Len() (int, error) {
+	return r.rows, nil
+}
+
+func (r *npyReader) Close() error {
+	return r.file.Close()
+}
+
+// readIDsFile reads all IDs from a 1-dimensional NumPy array of integers.
+func readIDsFile(name string) ([]uint32, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading IDs file %#v: %w", name, err)
+	}
+	r := bytes.NewReader(data)
+	h, err := readNPYHeader(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading IDs file %#v: %w", name, err)
+	}
+	if len(h.shape) != 1 || h.dtype.kind == 'f' {
+		return nil, fmt.Errorf("error reading IDs file %#v: expected a 1-dimensional array of integers", name)
+	}
+
+	data = data[len(data)-r.Len():]
+	n := h.shape[0]
+	if len(data) < n*h.dtype.size {
+		return nil, fmt.Errorf("error reading IDs file %#v: unexpected end of file", name)
+	}
+
+	ids := make([]uint32, n)
+	for i := range ids {
+		b := data[i*h.dtype.size:]
+		if h.dtype.kind == 'i' {
+			ids[i], err = toID(h.dtype.int64(b))
+		} else if v := h.dtype.uint64(b); v <= math.MaxUint32 {
+			ids[i] = uint32(v)
+		} else {
+			err = fmt.Errorf("ID %d out of range", v)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading IDs file %#v: %w", name, err)
+		}
+	}
+	return ids, nil
+}
+
+// npyWriterHeaderSize is the space reserved for the header of ".npy" files
+// being written, which is only known once all data is written.
+const npyWriterHeaderSize = 128
+
+// npyWriter writes a little-endian NumPy array, with 1 (for IDs) or 2
+// (for vectors) dimensions.
+type npyWriter struct {
+	name  string
+	file  *os.File
+	w     *bufio.Writer
+	descr string
+	rows  int
+	cols  int // -1 for 1-dimensional arrays
+}
+
+func createNPY(name, descr string, cols int) (*npyWriter, error) {
+	file, err := os.Create(name)
+	if err != nil {
+		return nil, fmt.Errorf("error creating file %#v: %w", name, err)
+	}
+	w := &npyWriter{
+		name:  name,
+		file:  file,
+		w:     bufio.NewWriter(file),
+		descr: descr,
+		cols:  cols,
+	}
+	// The actual header is written on Close.
+	if _, err = w.w.Write(make([]byte, npyWriterHeaderSize)); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("error writing file %#v: %w", name, err)
+	}
+	return w, nil
+}
+
+// Write writes a row of a 2-dimensional float32 array.
+func (w *npyWriter) Write(rec Record) error {
+	if w.rows == 0 && w.cols == 0 {
+		w.cols = len(rec.Vector)
+	}
+	if len(rec.Vector) != w.cols {
+		return fmt.Errorf("cannot write vector of size %d to file %#v: expected size %d", len(rec.Vector), w.name, w.cols)
+	}
+	if err := binary.Write(w.w, binary.LittleEndian, rec.Vector); err != nil {
+		return fmt.Errorf("error writing file %#v: %w", w.name, err)
+	}
+	w.rows++
+	return nil
+}
+
+// writeID writes an element of a 1-dimensional uint32 array.
+func (w *npyWriter) writeID(id uint32) error {
+	if err := binary.Write(w.w, binary.LittleEndian, id); err != nil {
+		return fmt.Errorf("error writing file %#v: %w", w.name, err)
+	}
+	w.rows++
+	return nil
+}
+
+func (w *npyWriter) Close() error {
+	err := w.writeHeader()
+	if e := w.file.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return fmt.Errorf("error writing file %#v: %w", w.name, err)
+	}
+	return nil
+}
+
+func (w *npyWriter) writeHeader() error {
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+
+	shape := fmt.Sprintf("(%d,)", w.rows)
+	if w.cols >= 0 {
+		shape = fmt.Sprintf("(%d, %d)", w.rows, w.cols)
+	}
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': %s, }", w.descr, shape)
+	// Magic string, version and header length take 10 bytes; the header is
+	// padded with spaces and terminated by a newline.
+	padding := npyWriterHeaderSize - 10 - len(header) - 1
+	header += strings.Repeat(" ", padding) + "\n"
+
+	buf := new(bytes.Buffer)
+	buf.WriteString(npyMagic + "\x01\x00")
+	_ = binary.Write(buf, binary.LittleEndian, uint16(len(header)))
+	buf.WriteString(header)
+	_, err := w.file.WriteAt(buf.Bytes(), 0)
+	return err
+}
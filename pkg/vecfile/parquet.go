@@ -0,0 +1,224 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vecfile
+
+import (
+	"fmt"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+	"io"
+)
+
+// defaultParquetVectorColumn is the name of the Parquet vector column, if
+// not configured otherwise.
+const defaultParquetVectorColumn = "vector"
+
+// parquetBatchSize is the amount of rows read from each column at once.
+const parquetBatchSize = 1024
+
+// parquetReader reads vectors from the rows of a Parquet file.
+type parquetReader struct {
+	name       string
+	file       source.ParquetFile
+	pr         *reader.ParquetReader
+	vectorPath string
+	idPath     string // empty if missing
+	rows       int64
+	read       int64
+	records    []Record
+}
+
+func openParquet(name string, opts Options) (_ *parquetReader, err error) {
+	file, err := local.NewLocalFileReader(name)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file %#v: %w", name, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = file.Close()
+		}
+	}()
+	// The Parquet library might panic on malformed files.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("error reading file %#v: %v", name, r)
+		}
+	}()
+
+	pr, err := reader.NewParquetColumnReader(file, 1)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %#v: %w", name, err)
+	}
+
+	vectorColumn := opts.VectorColumn
+	if vectorColumn == "" {
+		vectorColumn = defaultParquetVectorColumn
+	}
+	r := &parquetReader{
+		name: name,
+		file: file,
+		pr:   pr,
+		rows: pr.GetNumRows(),
+	}
+	r.vectorPath, err = r.findColumn(vectorColumn)
+	if err != nil {
+		return nil, err
+	}
+	if opts.IDColumn != "" {
+		r.idPath, err = r.findColumn(opts.IDColumn)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// findColumn returns the internal path of the single leaf column belonging
+// to the top-level field with the given name. For a list field, the leaf
+// column holds the list elements.
+func (r *parquetReader) findColumn(name string) (string, error) {
+	sh := r.pr.SchemaHandler
+	var found []string
+	for _, inPath := range sh.ValueColumns {
+		exPath := common.StrToPath(sh.InPathToExPath[inPath])
+		if len(exPath) > 1 && exPath[1] == name {
+			found = append(found, inPath)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("column %#v not found in file %#v", name, r.name)
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("column %#v of file %#v is not a list of numbers", name, r.name)
+	}
+}
+
+func (r *parquetReader) Read() (Record, error) {
+	if len(r.records) == 0 {
+		if r.read >= r.rows {
+			return Record{}, io.EOF
+		}
+		if err := r.readBatch(); err != nil {
+			return Record{}, err
+		}
+	}
+	rec := r.records[0]
+	r.records = r.records[1:]
+	return rec, nil
+}
+
+func (r *parquetReader) readBatch() (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("error reading file %#v: %v", r.name, p)
+		}
+	}()
+
+	n := r.rows - r.read
+	if n > parquetBatchSize {
+		n = parquetBatchSize
+	}
+
+	values, rls, _, err := r.pr.ReadColumnByPath(r.vectorPath, n)
+	if err != nil {
+		return fmt.Errorf("error reading file %#v: %w", r.name, err)
+	}
+	records := make([]Record, 0, n)
+	for i, v := range values {
+		if i == 0 || rls[i] == 0 {
+			records = append(records, Record{})
+		}
+		if v == nil {
+			// Null or empty list
+			continue
+		}
+		f, err := parquetFloat32(v)
+		if err != nil {
+			return fmt.Errorf("invalid vector at row %d of file %#v: %w", r.read+int64(len(records))-1, r.name, err)
+		}
+		last := &records[len(records)-1]
+		last.Vector = append(last.Vector, f)
+	}
+	if int64(len(records)) != n {
+		return fmt.Errorf("error reading file %#v: expected %d rows, read %d", r.name, n, len(records))
+	}
+	for i, rec := range records {
+		if len(rec.Vector) == 0 {
+			return fmt.Errorf("invalid vector at row %d of file %#v: empty vector", r.read+int64(i), r.name)
+		}
+	}
+
+	if r.idPath != "" {
+		values, _, _, err = r.pr.ReadColumnByPath(r.idPath, n)
+		if err != nil {
+			return fmt.Errorf("error reading file %#v: %w", r.name, err)
+		}
+		if int64(len(values)) != n {
+			return fmt.Errorf("error reading file %#v: expected %d IDs, read %d", r.name, n, len(values))
+		}
+		for i, v := range values {
+			records[i].ID, err = parquetID(v)
+			if err != nil {
+				return fmt.Errorf("invalid ID at row %d of file %#v: %w", r.read+int64(i), r.name, err)
+			}
+			records[i].HasID = true
+		}
+	}
+
+	r.read += n
+	r.records = records
+	return nil
+}
+
+func (r *parquetReader) Len() (int, error) {
+	return int(r.rows), nil
+}
+
+func (r *parquetReader) Close() error {
+	r.pr.ReadStop()
+	return r.file.Close()
+}
+
+func parquetFloat32(v interface{}) (float32, error) {
+	switch n := v.(type) {
+	case float32:
+		return n, nil
+	case float64:
+		return float32(n), nil
+	case int32:
+		return float32(n), nil
+	case int64:
+		return float32(n), nil
+	default:
+		return 0, fmt.Errorf("unexpected value %#v", v)
+	}
+}
+
+func parquetID(v interface{}) (uint32, error) {
+	switch n := v.(type) {
+	case int32:
+		return toID(int64(n))
+	case int64:
+		return toID(n)
+	case nil:
+		return 0, fmt.Errorf("missing ID")
+	default:
+		return 0, fmt.Errorf("unexpected value %#v", v)
+	}
+}
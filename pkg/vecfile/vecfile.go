@@ -0,0 +1,304 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vecfile reads and writes vectors in common dataset file formats.
+package vecfile
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+// Format identifies a vector file format.
+type Format string
+
+const (
+	// NPY identifies a NumPy ".npy" file, containing a 2-dimensional array
+	// of floating point or 8-bit integer values, one vector per row.
+	NPY Format = "npy"
+	// FVecs identifies a ".fvecs" file: each vector is stored as its
+	// dimension (little-endian int32) followed by its float32 components.
+	FVecs Format = "fvecs"
+	// BVecs identifies a ".bvecs" file: each vector is stored as its
+	// dimension (little-endian int32) followed by its uint8 components.
+	BVecs Format = "bvecs"
+	// CSV identifies a comma-separated values file with a header row.
+	CSV Format = "csv"
+	// Parquet identifies an Apache Parquet file, where vectors are stored
+	// in a list column.
+	Parquet Format = "parquet"
+	// JSONL identifies a JSON Lines file: each line is a JSON object
+	// containing a vector and an optional ID.
+	JSONL Format = "jsonl"
+)
+
+// FormatFromString makes a Format value from string.
+// Valid string values are: "npy", "fvecs", "bvecs", "csv", "parquet", or
+// "jsonl".
+func FormatFromString(s string) (Format, error) {
+	switch f := Format(s); f {
+	case NPY, FVecs, BVecs, CSV, Parquet, JSONL:
+		return f, nil
+	default:
+		return "", fmt.Errorf("invalid vector file format %#v", s)
+	}
+}
+
+// FormatFromFilename infers the Format of a file from its extension.
+func FormatFromFilename(name string) (Format, error) {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+	if ext == "" {
+		return "", fmt.Errorf("cannot infer the format of file %#v: missing extension", name)
+	}
+	f, err := FormatFromString(ext)
+	if err != nil {
+		return "", fmt.Errorf("cannot infer the format of file %#v: unknown extension %#v", name, ext)
+	}
+	return f, nil
+}
+
+// Options provides optional settings for reading and writing vector files.
+type Options struct {
+	// IDColumn is the name of the CSV or Parquet column containing vector
+	// IDs. If empty, records have no ID.
+	//
+	// For JSONL files, it is the key of IDs, and defaults to "id": records
+	// without the key have no ID.
+	IDColumn string
+	// VectorColumn is the name of the CSV or Parquet column containing
+	// vectors.
+	//
+	// For CSV files, the column must contain all vector components,
+	// separated by commas or spaces, optionally surrounded by square
+	// brackets. If empty, each column which is neither the ID column nor a
+	// payload column is a vector component.
+	//
+	// For Parquet files, the column must be a list of numbers. If empty,
+	// it defaults to "vector".
+	//
+	// For JSONL files, it is the key of vectors, and defaults to "vector".
+	VectorColumn string
+	// PayloadColumns lists CSV columns which are neither part of the
+	// vector nor the ID. Since payloads cannot be stored in an index, their
+	// values are just skipped. For other formats, only the ID and vector
+	// columns are read, so no payload column needs to be listed.
+	PayloadColumns []string
+	// IDsFile is the name of an optional NumPy ".npy" file, containing a
+	// 1-dimensional array of integer IDs, one for each vector of an NPY,
+	// FVecs or BVecs file. When writing, IDs are stored as uint32.
+	IDsFile string
+}
+
+// Record is a single vector of a file.
+type Record struct {
+	Vector []float32
+	// ID is only meaningful if HasID is true.
+	ID    uint32
+	HasID bool
+}
+
+// Reader reads vectors from a file, one at a time.
+type Reader interface {
+	// Read returns the next record. At the end of the file, it returns
+	// io.EOF.
+	Read() (Record, error)
+	// Len returns the total amount of records in the file. For some
+	// formats, the whole file might be scanned.
+	Len() (int, error)
+	// Close closes the file.
+	Close() error
+}
+
+// Open opens a vector file for reading.
+func Open(name string, format Format, opts Options) (Reader, error) {
+	if err := validateOptions(format, opts); err != nil {
+		return nil, err
+	}
+
+	var r Reader
+	var err error
+	switch format {
+	case NPY:
+		r, err = openNPY(name)
+	case FVecs:
+		r, err = openVecs(name, fvecsComponent)
+	case BVecs:
+		r, err = openVecs(name, bvecsComponent)
+	case CSV:
+		r, err = openCSV(name, opts)
+	case Parquet:
+		r, err = openParquet(name, opts)
+	case JSONL:
+		r, err = openJSONL(name, opts)
+	default:
+		return nil, fmt.Errorf("invalid vector file format %#v", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.IDsFile == "" {
+		return r, nil
+	}
+	ids, err := readIDsFile(opts.IDsFile)
+	if err != nil {
+		_ = r.Close()
+		return nil, err
+	}
+	n, err := r.Len()
+	if err != nil {
+		_ = r.Close()
+		return nil, err
+	}
+	if n != len(ids) {
+		_ = r.Close()
+		return nil, fmt.Errorf("mismatching amount of vectors (%d) and IDs (%d)", n, len(ids))
+	}
+	return &idsReader{Reader: r, ids: ids}, nil
+}
+
+func validateOptions(format Format, opts Options) error {
+	switch format {
+	case CSV, Parquet, JSONL:
+		if opts.IDsFile != "" {
+			return fmt.Errorf("an IDs file cannot be used with %s files: use an ID column instead", format)
+		}
+	default:
+		if opts.IDColumn != "" || opts.VectorColumn != "" || len(opts.PayloadColumns) > 0 {
+			return fmt.Errorf("columns cannot be specified for %s files", format)
+		}
+	}
+	if format != CSV && len(opts.PayloadColumns) > 0 {
+		return fmt.Errorf("payload columns cannot be specified for %s files", format)
+	}
+	return nil
+}
+
+// idsReader assigns IDs, read from a separate file, to the records.
+type idsReader struct {
+	Reader
+	ids []uint32
+	i   int
+}
+
+func (r *idsReader) Read() (Record, error) {
+	rec, err := r.Reader.Read()
+	if err != nil {
+		return rec, err
+	}
+	if r.i >= len(r.ids) {
+		return Record{}, fmt.Errorf("missing ID for vector %d", r.i)
+	}
+	rec.ID = r.ids[r.i]
+	rec.HasID = true
+	r.i++
+	return rec, nil
+}
+
+// Writer writes vectors to a file, one at a time.
+type Writer interface {
+	// Write writes a single record.
+	Write(rec Record) error
+	// Close completes the file and closes it.
+	Close() error
+}
+
+// Create creates a vector file for writing. Only NPY, FVecs and JSONL
+// formats are supported.
+//
+// For NPY and FVecs files, record IDs are written to Options.IDsFile, if
+// set: in this case, all records must have an ID. For JSONL files, records
+// IDs are written to Options.IDColumn key, if the record has an ID.
+// All vectors of an NPY file must have the same size.
+func Create(name string, format Format, opts Options) (Writer, error) {
+	if err := validateOptions(format, opts); err != nil {
+		return nil, err
+	}
+
+	var w Writer
+	var err error
+	switch format {
+	case NPY:
+		w, err = createNPY(name, "<f4", 0)
+	case FVecs:
+		w, err = createFVecs(name)
+	case JSONL:
+		w, err = createJSONL(name, opts)
+	default:
+		return nil, fmt.Errorf("writing %s files is not supported", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.IDsFile == "" {
+		return w, nil
+	}
+	ids, err := createNPY(opts.IDsFile, "<u4", -1)
+	if err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+	return &idsWriter{Writer: w, ids: ids}, nil
+}
+
+// idsWriter writes the IDs of the records to a separate file.
+type idsWriter struct {
+	Writer
+	ids *npyWriter
+}
+
+func (w *idsWriter) Write(rec Record) error {
+	if !rec.HasID {
+		return fmt.Errorf("cannot write a vector without ID to IDs file %#v", w.ids.name)
+	}
+	if err := w.Writer.Write(rec); err != nil {
+		return err
+	}
+	return w.ids.writeID(rec.ID)
+}
+
+func (w *idsWriter) Close() error {
+	err := w.Writer.Close()
+	if e := w.ids.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// toID converts an integer value to an ID, if it is in range.
+func toID(v int64) (uint32, error) {
+	if v < 0 || v > math.MaxUint32 {
+		return 0, fmt.Errorf("ID %d out of range", v)
+	}
+	return uint32(v), nil
+}
+
+// ReadAll reads all remaining records.
+func ReadAll(r Reader) ([]Record, error) {
+	var records []Record
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, rec)
+	}
+}
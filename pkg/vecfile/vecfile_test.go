@@ -0,0 +1,524 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vecfile_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/vecfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/writer"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+var sampleVectors = [][]float32{
+	{0.5, 1, 1.5},
+	{2, 2.5, 3},
+}
+
+func TestFormatFromFilename(t *testing.T) {
+	t.Parallel()
+
+	for name, expected := range map[string]vecfile.Format{
+		"a.npy":            vecfile.NPY,
+		"dir/b.FVECS":      vecfile.FVecs,
+		"c.bvecs":          vecfile.BVecs,
+		"d.csv":            vecfile.CSV,
+		"e.tar.parquet":    vecfile.Parquet,
+		"./relative.fvecs": vecfile.FVecs,
+	} {
+		format, err := vecfile.FormatFromFilename(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, format)
+	}
+
+	for _, name := range []string{"foo", "foo.txt"} {
+		_, err := vecfile.FormatFromFilename(name)
+		assert.Error(t, err)
+	}
+}
+
+func TestOpen_NPY(t *testing.T) {
+	t.Parallel()
+
+	t.Run("float32", func(t *testing.T) {
+		t.Parallel()
+		name := writeFile(t, "v.npy", npyFile("<f4", "(2, 3)", float32s(sampleVectors)))
+
+		records := readAll(t, name, vecfile.NPY, vecfile.Options{})
+		assert.Equal(t, []vecfile.Record{
+			{Vector: sampleVectors[0]},
+			{Vector: sampleVectors[1]},
+		}, records)
+	})
+
+	t.Run("float64 with IDs file", func(t *testing.T) {
+		t.Parallel()
+		var data []float64
+		for _, v := range sampleVectors {
+			for _, x := range v {
+				data = append(data, float64(x))
+			}
+		}
+		name := writeFile(t, "v.npy", npyFile("<f8", "(2, 3)", data))
+		ids := writeFile(t, "ids.npy", npyFile("<i8", "(2,)", []int64{7, 3}))
+
+		records := readAll(t, name, vecfile.NPY, vecfile.Options{IDsFile: ids})
+		assert.Equal(t, []vecfile.Record{
+			{Vector: sampleVectors[0], ID: 7, HasID: true},
+			{Vector: sampleVectors[1], ID: 3, HasID: true},
+		}, records)
+	})
+
+	t.Run("uint8", func(t *testing.T) {
+		t.Parallel()
+		name := writeFile(t, "v.npy", npyFile("|u1", "(1, 2)", []uint8{4, 255}))
+
+		records := readAll(t, name, vecfile.NPY, vecfile.Options{})
+		assert.Equal(t, []vecfile.Record{{Vector: []float32{4, 255}}}, records)
+	})
+
+	t.Run("mismatching IDs file", func(t *testing.T) {
+		t.Parallel()
+		name := writeFile(t, "v.npy", npyFile("<f4", "(2, 3)", float32s(sampleVectors)))
+		ids := writeFile(t, "ids.npy", npyFile("<u4", "(1,)", []uint32{1}))
+
+		_, err := vecfile.Open(name, vecfile.NPY, vecfile.Options{IDsFile: ids})
+		assert.Error(t, err)
+	})
+
+	t.Run("negative IDs", func(t *testing.T) {
+		t.Parallel()
+		name := writeFile(t, "v.npy", npyFile("<f4", "(2, 3)", float32s(sampleVectors)))
+		ids := writeFile(t, "ids.npy", npyFile("<i4", "(2,)", []int32{1, -1}))
+
+		_, err := vecfile.Open(name, vecfile.NPY, vecfile.Options{IDsFile: ids})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid arrays", func(t *testing.T) {
+		t.Parallel()
+		for _, data := range [][]byte{
+			[]byte("not a npy file"),
+			npyFile("<f4", "(6,)", float32s(sampleVectors)),
+			npyFile("<c8", "(1, 1)", []float32{1, 2}),
+		} {
+			name := writeFile(t, "v.npy", data)
+			_, err := vecfile.Open(name, vecfile.NPY, vecfile.Options{})
+			assert.Error(t, err)
+		}
+	})
+
+	t.Run("truncated data", func(t *testing.T) {
+		t.Parallel()
+		name := writeFile(t, "v.npy", npyFile("<f4", "(3, 3)", float32s(sampleVectors)))
+		r, err := vecfile.Open(name, vecfile.NPY, vecfile.Options{})
+		require.NoError(t, err)
+		defer func() { assert.NoError(t, r.Close()) }()
+
+		records, err := vecfile.ReadAll(r)
+		assert.Error(t, err)
+		assert.Len(t, records, 2)
+	})
+}
+
+func TestOpen_Vecs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fvecs", func(t *testing.T) {
+		t.Parallel()
+		buf := new(bytes.Buffer)
+		for _, v := range sampleVectors {
+			writeLE(buf, int32(len(v)))
+			writeLE(buf, v)
+		}
+		name := writeFile(t, "v.fvecs", buf.Bytes())
+
+		r, err := vecfile.Open(name, vecfile.FVecs, vecfile.Options{})
+		require.NoError(t, err)
+		defer func() { assert.NoError(t, r.Close()) }()
+
+		n, err := r.Len()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+
+		records, err := vecfile.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, []vecfile.Record{
+			{Vector: sampleVectors[0]},
+			{Vector: sampleVectors[1]},
+		}, records)
+	})
+
+	t.Run("bvecs with IDs file", func(t *testing.T) {
+		t.Parallel()
+		buf := new(bytes.Buffer)
+		writeLE(buf, int32(2))
+		writeLE(buf, []uint8{1, 2})
+		writeLE(buf, int32(2))
+		writeLE(buf, []uint8{3, 4})
+		name := writeFile(t, "v.bvecs", buf.Bytes())
+		ids := writeFile(t, "ids.npy", npyFile("<u8", "(2,)", []uint64{10, 20}))
+
+		records := readAll(t, name, vecfile.BVecs, vecfile.Options{IDsFile: ids})
+		assert.Equal(t, []vecfile.Record{
+			{Vector: []float32{1, 2}, ID: 10, HasID: true},
+			{Vector: []float32{3, 4}, ID: 20, HasID: true},
+		}, records)
+	})
+
+	t.Run("columns are not allowed", func(t *testing.T) {
+		t.Parallel()
+		name := writeFile(t, "v.fvecs", nil)
+		_, err := vecfile.Open(name, vecfile.FVecs, vecfile.Options{IDColumn: "id"})
+		assert.Error(t, err)
+	})
+}
+
+func TestOpen_CSV(t *testing.T) {
+	t.Parallel()
+
+	t.Run("component columns", func(t *testing.T) {
+		t.Parallel()
+		name := writeFile(t, "v.csv", []byte(
+			"x,id,y,title,z\n"+
+				"0.5,4,1,foo,1.5\n"+
+				"2,5,2.5,\"bar, baz\",3\n"))
+
+		r, err := vecfile.Open(name, vecfile.CSV, vecfile.Options{
+			IDColumn:       "id",
+			PayloadColumns: []string{"title"},
+		})
+		require.NoError(t, err)
+		defer func() { assert.NoError(t, r.Close()) }()
+
+		n, err := r.Len()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+
+		records, err := vecfile.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, []vecfile.Record{
+			{Vector: sampleVectors[0], ID: 4, HasID: true},
+			{Vector: sampleVectors[1], ID: 5, HasID: true},
+		}, records)
+	})
+
+	t.Run("vector column", func(t *testing.T) {
+		t.Parallel()
+		name := writeFile(t, "v.csv", []byte(
+			"embedding,title\n"+
+				"\"[0.5, 1, 1.5]\",foo\n"+
+				"2 2.5 3,bar\n"))
+
+		records := readAll(t, name, vecfile.CSV, vecfile.Options{VectorColumn: "embedding"})
+		assert.Equal(t, []vecfile.Record{
+			{Vector: sampleVectors[0]},
+			{Vector: sampleVectors[1]},
+		}, records)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		t.Parallel()
+		for _, content := range []string{
+			"id,x\n1,foo\n",
+			"id,x\n-1,1\n",
+			"id,x\n,1\n",
+		} {
+			name := writeFile(t, "v.csv", []byte(content))
+			r, err := vecfile.Open(name, vecfile.CSV, vecfile.Options{IDColumn: "id"})
+			if err == nil {
+				_, err = vecfile.ReadAll(r)
+				assert.NoError(t, r.Close())
+			}
+			assert.Error(t, err, content)
+		}
+	})
+
+	t.Run("missing column", func(t *testing.T) {
+		t.Parallel()
+		name := writeFile(t, "v.csv", []byte("x,y\n1,2\n"))
+		_, err := vecfile.Open(name, vecfile.CSV, vecfile.Options{IDColumn: "id"})
+		assert.Error(t, err)
+	})
+}
+
+type parquetRow struct {
+	ID      int64     `parquet:"name=id, type=INT64"`
+	Title   string    `parquet:"name=title, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Vector  []float32 `parquet:"name=vector, type=LIST, valuetype=FLOAT"`
+	Vector2 []float64 `parquet:"name=vector2, type=LIST, valuetype=DOUBLE"`
+}
+
+func TestOpen_Parquet(t *testing.T) {
+	t.Parallel()
+
+	// More rows than a single reading batch
+	rows := make([]parquetRow, 2500)
+	for i := range rows {
+		rows[i] = parquetRow{
+			ID:      int64(i * 2),
+			Title:   fmt.Sprintf("title %d", i),
+			Vector:  []float32{float32(i), float32(i) + 0.5},
+			Vector2: []float64{float64(i)},
+		}
+	}
+	name := path.Join(t.TempDir(), "v.parquet")
+	writeParquet(t, name, rows)
+
+	t.Run("default vector column", func(t *testing.T) {
+		t.Parallel()
+		r, err := vecfile.Open(name, vecfile.Parquet, vecfile.Options{IDColumn: "id"})
+		require.NoError(t, err)
+		defer func() { assert.NoError(t, r.Close()) }()
+
+		n, err := r.Len()
+		assert.NoError(t, err)
+		assert.Equal(t, len(rows), n)
+
+		records, err := vecfile.ReadAll(r)
+		require.NoError(t, err)
+		require.Len(t, records, len(rows))
+		for i, rec := range records {
+			assert.Equal(t, rows[i].Vector, rec.Vector)
+			assert.Equal(t, uint32(rows[i].ID), rec.ID)
+			assert.True(t, rec.HasID)
+		}
+	})
+
+	t.Run("custom vector column", func(t *testing.T) {
+		t.Parallel()
+		records := readAll(t, name, vecfile.Parquet, vecfile.Options{VectorColumn: "vector2"})
+		require.Len(t, records, len(rows))
+		assert.Equal(t, vecfile.Record{Vector: []float32{1}}, records[1])
+	})
+
+	t.Run("invalid columns", func(t *testing.T) {
+		t.Parallel()
+		for _, opts := range []vecfile.Options{
+			{VectorColumn: "foo"},
+			{IDColumn: "foo"},
+			{PayloadColumns: []string{"title"}},
+		} {
+			_, err := vecfile.Open(name, vecfile.Parquet, opts)
+			assert.Error(t, err)
+		}
+	})
+
+	t.Run("not a parquet file", func(t *testing.T) {
+		t.Parallel()
+		name := writeFile(t, "v.parquet", []byte("foo"))
+		_, err := vecfile.Open(name, vecfile.Parquet, vecfile.Options{})
+		assert.Error(t, err)
+	})
+}
+
+func readAll(t *testing.T, name string, format vecfile.Format, opts vecfile.Options) []vecfile.Record {
+	t.Helper()
+	r, err := vecfile.Open(name, format, opts)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, r.Close()) }()
+	records, err := vecfile.ReadAll(r)
+	require.NoError(t, err)
+	return records
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	name = path.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(name, data, 0666))
+	return name
+}
+
+func writeParquet(t *testing.T, name string, rows []parquetRow) {
+	t.Helper()
+	fw, err := local.NewLocalFileWriter(name)
+	require.NoError(t, err)
+	pw, err := writer.NewParquetWriter(fw, new(parquetRow), 1)
+	require.NoError(t, err)
+	for _, row := range rows {
+		require.NoError(t, pw.Write(row))
+	}
+	require.NoError(t, pw.WriteStop())
+	require.NoError(t, fw.Close())
+}
+
+// npyFile returns the content of a version 1.0 ".npy" file.
+func npyFile(descr, shape string, data interface{}) []byte {
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': %s, }", descr, shape)
+	// Header is padded with spaces and terminated by a newline, so that
+	// data is 64-byte aligned.
+	total := 10 + len(header) + 1
+	header += strings.Repeat(" ", (64-total%64)%64) + "\n"
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("\x93NUMPY\x01\x00")
+	writeLE(buf, uint16(len(header)))
+	buf.WriteString(header)
+	writeLE(buf, data)
+	return buf.Bytes()
+}
+
+func float32s(vectors [][]float32) []float32 {
+	var data []float32
+	for _, v := range vectors {
+		data = append(data, v...)
+	}
+	return data
+}
+
+func writeLE(buf *bytes.Buffer, data interface{}) {
+	if err := binary.Write(buf, binary.LittleEndian, data); err != nil {
+		panic(err)
+	}
+}
+
+func TestOpen_JSONL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default keys", func(t *testing.T) {
+		t.Parallel()
+		name := writeFile(t, "v.jsonl", []byte(
+			`{"id": 1, "vector": [0.5, 1, 1.5], "title": "foo"}`+"\n\n"+
+				`{"vector": [2, 2.5, 3]}`+"\n"))
+
+		r, err := vecfile.Open(name, vecfile.JSONL, vecfile.Options{})
+		require.NoError(t, err)
+		defer func() { assert.NoError(t, r.Close()) }()
+
+		n, err := r.Len()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+
+		records, err := vecfile.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, []vecfile.Record{
+			{Vector: sampleVectors[0], ID: 1, HasID: true},
+			{Vector: sampleVectors[1]},
+		}, records)
+	})
+
+	t.Run("custom keys", func(t *testing.T) {
+		t.Parallel()
+		name := writeFile(t, "v.jsonl", []byte(`{"key": 3, "id": 2, "emb": [0.5, 1, 1.5]}`))
+
+		records := readAll(t, name, vecfile.JSONL, vecfile.Options{IDColumn: "key", VectorColumn: "emb"})
+		assert.Equal(t, []vecfile.Record{{Vector: sampleVectors[0], ID: 3, HasID: true}}, records)
+	})
+
+	t.Run("invalid lines", func(t *testing.T) {
+		t.Parallel()
+		for _, content := range []string{
+			`foo`,
+			`{"id": 1}`,
+			`{"vector": []}`,
+			`{"vector": ["a"]}`,
+			`{"id": -1, "vector": [1]}`,
+			`{"id": 1.5, "vector": [1]}`,
+		} {
+			name := writeFile(t, "v.jsonl", []byte(content))
+			r, err := vecfile.Open(name, vecfile.JSONL, vecfile.Options{})
+			require.NoError(t, err)
+			_, err = vecfile.ReadAll(r)
+			assert.Error(t, err, content)
+			assert.NoError(t, r.Close())
+		}
+	})
+}
+
+func TestCreate(t *testing.T) {
+	t.Parallel()
+
+	records := []vecfile.Record{
+		{Vector: sampleVectors[0], ID: 7, HasID: true},
+		{Vector: sampleVectors[1], ID: 3, HasID: true},
+	}
+
+	for _, format := range []vecfile.Format{vecfile.NPY, vecfile.FVecs} {
+		format := format
+		t.Run(string(format), func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			opts := vecfile.Options{IDsFile: path.Join(dir, "ids.npy")}
+			name := path.Join(dir, "v."+string(format))
+			writeAll(t, name, format, opts, records)
+
+			assert.Equal(t, records, readAll(t, name, format, opts))
+
+			// IDs can be ignored
+			assert.Equal(t, []vecfile.Record{
+				{Vector: sampleVectors[0]},
+				{Vector: sampleVectors[1]},
+			}, readAll(t, name, format, vecfile.Options{}))
+		})
+	}
+
+	t.Run("jsonl", func(t *testing.T) {
+		t.Parallel()
+		name := path.Join(t.TempDir(), "v.jsonl")
+		input := append(records, vecfile.Record{Vector: []float32{1, 2, 3}})
+		writeAll(t, name, vecfile.JSONL, vecfile.Options{}, input)
+
+		assert.Equal(t, input, readAll(t, name, vecfile.JSONL, vecfile.Options{}))
+	})
+
+	t.Run("empty npy", func(t *testing.T) {
+		t.Parallel()
+		name := path.Join(t.TempDir(), "v.npy")
+		writeAll(t, name, vecfile.NPY, vecfile.Options{}, nil)
+
+		assert.Empty(t, readAll(t, name, vecfile.NPY, vecfile.Options{}))
+	})
+
+	t.Run("invalid records", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+
+		w, err := vecfile.Create(path.Join(dir, "v.npy"), vecfile.NPY, vecfile.Options{})
+		require.NoError(t, err)
+		assert.NoError(t, w.Write(vecfile.Record{Vector: []float32{1, 2}}))
+		assert.Error(t, w.Write(vecfile.Record{Vector: []float32{1}}))
+		assert.NoError(t, w.Close())
+
+		w, err = vecfile.Create(path.Join(dir, "v.fvecs"), vecfile.FVecs, vecfile.Options{IDsFile: path.Join(dir, "ids.npy")})
+		require.NoError(t, err)
+		assert.Error(t, w.Write(vecfile.Record{Vector: []float32{1, 2}}))
+		assert.NoError(t, w.Close())
+	})
+
+	t.Run("unsupported formats", func(t *testing.T) {
+		t.Parallel()
+		for _, format := range []vecfile.Format{vecfile.BVecs, vecfile.CSV, vecfile.Parquet} {
+			_, err := vecfile.Create(path.Join(t.TempDir(), "v"), format, vecfile.Options{})
+			assert.Error(t, err)
+		}
+	})
+}
+
+func writeAll(t *testing.T, name string, format vecfile.Format, opts vecfile.Options, records []vecfile.Record) {
+	t.Helper()
+	w, err := vecfile.Create(name, format, opts)
+	require.NoError(t, err)
+	for _, rec := range records {
+		require.NoError(t, w.Write(rec))
+	}
+	require.NoError(t, w.Close())
+}
@@ -0,0 +1,160 @@
+// Copyright 2021 SpecializedGeneralist
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vecfile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// vecsComponent describes how vector components are stored in ".fvecs"
+// and ".bvecs" files.
+type vecsComponent struct {
+	size    int
+	float32 func(b []byte) float32
+}
+
+var fvecsComponent = vecsComponent{
+	size: 4,
+	float32: func(b []byte) float32 {
+		return math.Float32frombits(binary.LittleEndian.Uint32(b))
+	},
+}
+
+var bvecsComponent = vecsComponent{
+	size: 1,
+	float32: func(b []byte) float32 {
+		return float32(b[0])
+	},
+}
+
+// vecsReader reads vectors from ".fvecs" and ".bvecs" files.
+type vecsReader struct {
+	name      string
+	file      *os.File
+	r         *bufio.Reader
+	component vecsComponent
+	n         int
+	buf       []byte
+}
+
+func openVecs(name string, component vecsComponent) (*vecsReader, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file %#v: %w", name, err)
+	}
+	return &vecsReader{
+		name:      name,
+		file:      file,
+		r:         bufio.NewReader(file),
+		component: component,
+	}, nil
+}
+
+func (r *vecsReader) Read() (Record, error) {
+	var dim int32
+	err := binary.Read(r.r, binary.LittleEndian, &dim)
+	if err == io.EOF {
+		return Record{}, io.EOF
+	}
+	if err != nil {
+		return Record{}, fmt.Errorf("error reading vector %d from %#v: %w", r.n, r.name, err)
+	}
+	if dim <= 0 {
+		return Record{}, fmt.Errorf("error reading vector %d from %#v: invalid dimension %d", r.n, r.name, dim)
+	}
+
+	size := int(dim) * r.component.size
+	if cap(r.buf) < size {
+		r.buf = make([]byte, size)
+	}
+	r.buf = r.buf[:size]
+	if _, err = io.ReadFull(r.r, r.buf); err != nil {
+		return Record{}, fmt.Errorf("error reading vector %d from %#v: %w", r.n, r.name, err)
+	}
+	r.n++
+
+	vector := make([]float32, dim)
+	for i := range vector {
+		vector[i] = r.component.float32(r.buf[i*r.component.size:])
+	}
+	return Record{Vector: vector}, nil
+}
+
+// Len returns the amount of vectors, computed from the file size and the
+// dimension of the first vector.
+func (r *vecsReader) Len() (int, error) {
+	var dim int32
+	if err := binary.Read(io.NewSectionReader(r.file, 0, 4), binary.LittleEndian, &dim); err != nil {
+		if err == io.EOF {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("error reading file %#v: %w", r.name, err)
+	}
+	info, err := r.file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("error reading file %#v: %w", r.name, err)
+	}
+	rowSize := 4 + int64(dim)*int64(r.component.size)
+	if dim <= 0 || info.Size()%rowSize != 0 {
+		return 0, fmt.Errorf("error reading file %#v: unexpected file size for dimension %d", r.name, dim)
+	}
+	return int(info.Size() / rowSize), nil
+}
+
+func (r *vecsReader) Close() error {
+	return r.file.Close()
+}
+
+// fvecsWriter writes vectors to a ".fvecs" file.
+type fvecsWriter struct {
+	name string
+	file *os.File
+	w    *bufio.Writer
+}
+
+func createFVecs(name string) (*fvecsWriter, error) {
+	file, err := os.Create(name)
+	if err != nil {
+		return nil, fmt.Errorf("error creating file %#v: %w", name, err)
+	}
+	return &fvecsWriter{name: name, file: file, w: bufio.NewWriter(file)}, nil
+}
+
+func (w *fvecsWriter) Write(rec Record) error {
+	err := binary.Write(w.w, binary.LittleEndian, int32(len(rec.Vector)))
+	if err == nil {
+		err = binary.Write(w.w, binary.LittleEndian, rec.Vector)
+	}
+	if err != nil {
+		return fmt.Errorf("error writing file %#v: %w", w.name, err)
+	}
+	return nil
+}
+
+func (w *fvecsWriter) Close() error {
+	err := w.w.Flush()
+	if e := w.file.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return fmt.Errorf("error writing file %#v: %w", w.name, err)
+	}
+	return nil
+}
@@ -17,7 +17,9 @@
 package wal
 
 import (
+	"bufio"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"github.com/SpecializedGeneralist/hnsw-grpc-server/pkg/osutils"
 	"io"
@@ -30,14 +32,20 @@ import (
 type Log struct {
 	filename string
 	file     *os.File
-	encoder  *gob.Encoder
-	mx       sync.Mutex
+	// writer buffers the encoded entries, so that a batch of entries is
+	// written to the file at once.
+	writer  *bufio.Writer
+	encoder *gob.Encoder
+	mx      sync.Mutex
 }
 
 // PointAddition is a log entry representing the operation of adding new data.
 type PointAddition struct {
 	Vector []float32
 	ID     uint32
+	// ExpiresAt is the expiration time of the data, as Unix time in
+	// nanoseconds, or zero if it does not expire.
+	ExpiresAt int64
 }
 
 // DeletionMark is a log entry representing the operation of marking data
@@ -46,6 +54,12 @@ type DeletionMark struct {
 	ID uint32
 }
 
+// DeletionUnmark is a log entry representing the operation of removing the
+// deletion mark from data.
+type DeletionUnmark struct {
+	ID uint32
+}
+
 // EfSetting is a log entry representing the operation of setting the
 // "ef" parameter.
 type EfSetting struct {
@@ -55,6 +69,7 @@ type EfSetting struct {
 func init() {
 	gob.Register(PointAddition{})
 	gob.Register(DeletionMark{})
+	gob.Register(DeletionUnmark{})
 	gob.Register(EfSetting{})
 }
 
@@ -66,14 +81,48 @@ func NewLog(filename string) *Log {
 	}
 }
 
-// WritePointAddition appends a new PointAddition entry to the log.
+// WritePointAddition appends a new PointAddition entry to the log, for data
+// which does not expire.
 func (log *Log) WritePointAddition(vector []float32, id uint32) error {
+	return log.WriteExpiringPointAddition(vector, id, 0)
+}
+
+// WriteExpiringPointAddition appends a new PointAddition entry to the log,
+// with the given expiration time (see PointAddition.ExpiresAt).
+func (log *Log) WriteExpiringPointAddition(vector []float32, id uint32, expiresAt int64) error {
 	return log.write(PointAddition{
-		Vector: vector,
-		ID:     id,
+		Vector:    vector,
+		ID:        id,
+		ExpiresAt: expiresAt,
 	})
 }
 
+// WritePointAdditions appends a new PointAddition entry to the log for each
+// vector/ID pair. All entries are written and synced to the file at once.
+//
+// The expiration times of the entries are optional: if expirations is nil,
+// no entry expires.
+func (log *Log) WritePointAdditions(vectors [][]float32, ids []uint32, expirations []int64) error {
+	if len(vectors) != len(ids) {
+		return fmt.Errorf("mismatching amount of vectors (%d) and IDs (%d)", len(vectors), len(ids))
+	}
+	if expirations != nil && len(expirations) != len(ids) {
+		return fmt.Errorf("mismatching amount of expirations (%d) and IDs (%d)", len(expirations), len(ids))
+	}
+	entries := make([]interface{}, len(vectors))
+	for i, vector := range vectors {
+		e := PointAddition{
+			Vector: vector,
+			ID:     ids[i],
+		}
+		if expirations != nil {
+			e.ExpiresAt = expirations[i]
+		}
+		entries[i] = e
+	}
+	return log.write(entries...)
+}
+
 // WriteDeletionMark appends a new DeletionMark entry to the log.
 func (log *Log) WriteDeletionMark(id uint32) error {
 	return log.write(DeletionMark{
@@ -81,6 +130,25 @@ func (log *Log) WriteDeletionMark(id uint32) error {
 	})
 }
 
+// WriteDeletionMarks appends a new DeletionMark entry to the log for each
+// ID. All entries are written and synced to the file at once.
+func (log *Log) WriteDeletionMarks(ids []uint32) error {
+	entries := make([]interface{}, len(ids))
+	for i, id := range ids {
+		entries[i] = DeletionMark{
+			ID: id,
+		}
+	}
+	return log.write(entries...)
+}
+
+// WriteDeletionUnmark appends a new DeletionUnmark entry to the log.
+func (log *Log) WriteDeletionUnmark(id uint32) error {
+	return log.write(DeletionUnmark{
+		ID: id,
+	})
+}
+
 // WriteEfSetting appends a new EfSetting entry to the log.
 func (log *Log) WriteEfSetting(ef int) error {
 	return log.write(EfSetting{
@@ -123,26 +191,175 @@ func (log *Log) Read(fn func(e interface{}) error) (err error) {
 		}
 	}()
 
-	return log.readFile(file, fn)
+	_, err = log.readFile(file, fn)
+	return err
+}
+
+// LogCheck is the result of Log.Check.
+type LogCheck struct {
+	// Entries is the number of entries decoded.
+	Entries int
+	// Size is the size of the log file, in bytes.
+	Size int64
+	// ValidSize is the size of the leading part of the log file which was
+	// decoded. If it is less than Size, the rest of the file is corrupted,
+	// for example because an entry was only partially written, and all the
+	// entries following it are lost (see Log.Truncate).
+	ValidSize int64
+	// Err is the error which stopped decoding at ValidSize, if any.
+	Err error
+}
+
+// Check reads all entries from the log file, like Read, and reports how
+// many of them could be decoded. The given function is called for each of
+// them, and it can be nil.
+//
+// Unlike Read, an error decoding the entries is not returned, but reported
+// by LogCheck.Err, along with the size of the part of the file which was
+// decoded. Only the errors reading the file, and the errors returned by the
+// callback function, are returned.
+func (log *Log) Check(fn func(e interface{}) error) (check LogCheck, err error) {
+	log.mx.Lock()
+	defer log.mx.Unlock()
+
+	if log.file != nil {
+		err = log.removeEncoderAndCloseFile()
+		if err != nil {
+			return check, err
+		}
+	}
+
+	file, err := os.Open(log.filename)
+	if os.IsNotExist(err) {
+		return check, nil
+	}
+	if err != nil {
+		return check, fmt.Errorf("error opening log file %#v for reading: %w", log.filename, err)
+	}
+	defer func() {
+		if e := file.Close(); e != nil && err == nil {
+			err = e
+		}
+	}()
+	info, err := file.Stat()
+	if err != nil {
+		return check, fmt.Errorf("error reading log file %#v: %w", log.filename, err)
+	}
+	check.Size = info.Size()
+
+	check.ValidSize, err = log.readFile(file, func(e interface{}) error {
+		check.Entries++
+		if fn == nil {
+			return nil
+		}
+		return fn(e)
+	})
+	var de *decodeError
+	if errors.As(err, &de) {
+		check.Err = de.err
+		return check, nil
+	}
+	return check, err
 }
 
-func (log *Log) readFile(file *os.File, fn func(e interface{}) error) (err error) {
-	decoder := gob.NewDecoder(file)
+// Truncate truncates the log file to the given size, discarding the
+// entries following it. It is meant to discard the corrupted part of the
+// file reported by Check, at LogCheck.ValidSize: otherwise, the entries
+// which are appended to the file afterwards would be lost as well.
+//
+// If the log file was still open for writing, it is first closed, so that
+// the next entries are appended as a new stream.
+func (log *Log) Truncate(size int64) error {
+	log.mx.Lock()
+	defer log.mx.Unlock()
+
+	if log.file != nil {
+		err := log.removeEncoderAndCloseFile()
+		if err != nil {
+			return err
+		}
+	}
+	err := os.Truncate(log.filename, size)
+	if err != nil {
+		return fmt.Errorf("error truncating log file %#v: %w", log.filename, err)
+	}
+	return nil
+}
+
+// decodeError is returned by readFile if an entry cannot be decoded.
+type decodeError struct {
+	err error
+}
+
+func (e *decodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *decodeError) Unwrap() error {
+	return e.err
+}
+
+// readFile decodes all entries of the file, and returns the size of the
+// part which was decoded.
+//
+// Each time the file is opened for writing, a new gob stream is appended,
+// starting with its own type definitions. When decoding fails, a new
+// decoder is therefore created at the same position: only if it fails too,
+// the file is considered corrupted, and a *decodeError is returned.
+func (log *Log) readFile(file *os.File, fn func(e interface{}) error) (_ int64, err error) {
+	r := &countingReader{r: bufio.NewReader(file)}
+	decoder := gob.NewDecoder(r)
+	newStream := true
 	for {
+		offset := r.n
 		var e interface{}
 		err = decoder.Decode(&e)
 		if err == io.EOF {
 			break
 		}
+		if err != nil && !newStream {
+			if _, err = file.Seek(offset, io.SeekStart); err != nil {
+				return offset, fmt.Errorf("error reading log file %#v: %w", log.filename, err)
+			}
+			r = &countingReader{r: bufio.NewReader(file), n: offset}
+			decoder = gob.NewDecoder(r)
+			newStream = true
+			continue
+		}
 		if err != nil {
-			return fmt.Errorf("error decoding entry from log file %#v: %w", log.filename, err)
+			return offset, &decodeError{
+				err: fmt.Errorf("error decoding entry from log file %#v at offset %d: %w", log.filename, offset, err),
+			}
 		}
+		newStream = false
 		err = fn(e)
 		if err != nil {
-			return err
+			return offset, err
 		}
 	}
-	return nil
+	return r.n, nil
+}
+
+// countingReader counts the bytes read from a bufio.Reader. Since it is an
+// io.ByteReader, a gob.Decoder reading from it does not read ahead, so the
+// count is the exact position of the next message.
+type countingReader struct {
+	r *bufio.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+func (cr *countingReader) ReadByte() (byte, error) {
+	b, err := cr.r.ReadByte()
+	if err == nil {
+		cr.n++
+	}
+	return b, err
 }
 
 // Delete removes the log file, virtually emptying the log.
@@ -182,7 +399,7 @@ func (log *Log) Close() error {
 	return log.removeEncoderAndCloseFile()
 }
 
-func (log *Log) write(e interface{}) error {
+func (log *Log) write(entries ...interface{}) error {
 	log.mx.Lock()
 	defer log.mx.Unlock()
 
@@ -193,9 +410,17 @@ func (log *Log) write(e interface{}) error {
 		}
 	}
 
-	err := log.encoder.Encode(&e)
+	for _, e := range entries {
+		err := log.encoder.Encode(&e)
+		if err != nil {
+			_ = log.removeEncoderAndCloseFile()
+			return fmt.Errorf("error encoding value %#v to log file %#v: %w", e, log.filename, err)
+		}
+	}
+	err := log.writer.Flush()
 	if err != nil {
-		return fmt.Errorf("error encoding value %#v to log file %#v: %w", e, log.filename, err)
+		_ = log.removeEncoderAndCloseFile()
+		return fmt.Errorf("error writing to log file %#v: %w", log.filename, err)
 	}
 	err = log.file.Sync()
 	if err != nil {
@@ -210,13 +435,15 @@ func (log *Log) openFileAndCreateEncoder() error {
 		return fmt.Errorf("error opening log file %#v: %w", log.filename, err)
 	}
 	log.file = file
-	log.encoder = gob.NewEncoder(log.file)
+	log.writer = bufio.NewWriter(file)
+	log.encoder = gob.NewEncoder(log.writer)
 	return nil
 }
 
 func (log *Log) removeEncoderAndCloseFile() error {
 	err := log.file.Close()
 	log.file = nil
+	log.writer = nil
 	log.encoder = nil
 	if err != nil {
 		return fmt.Errorf("error closing log file %#v: %w", log.filename, err)
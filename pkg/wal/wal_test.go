@@ -37,6 +37,7 @@ func TestLog(t *testing.T) {
 		require.NoError(t, log.WritePointAddition([]float32{1, 2, 3}, 10))
 		require.NoError(t, log.WritePointAddition([]float32{4, 5, 6}, 20))
 		require.NoError(t, log.WriteDeletionMark(10))
+		require.NoError(t, log.WriteDeletionUnmark(10))
 		require.NoError(t, log.WriteEfSetting(42))
 
 		actualEntries := make([]interface{}, 0)
@@ -50,11 +51,95 @@ func TestLog(t *testing.T) {
 			wal.PointAddition{Vector: []float32{1, 2, 3}, ID: 10},
 			wal.PointAddition{Vector: []float32{4, 5, 6}, ID: 20},
 			wal.DeletionMark{ID: 10},
+			wal.DeletionUnmark{ID: 10},
 			wal.EfSetting{Ef: 42},
 		}
 		assert.Equal(t, expectedEntries, actualEntries)
 	})
 
+	t.Run("write point additions in batch", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		log := wal.NewLog(path.Join(dir, "log"))
+		defer mustCloseLog(t, log)
+
+		require.NoError(t, log.WriteEfSetting(42))
+		require.NoError(t, log.WritePointAdditions(
+			[][]float32{{1, 2, 3}, {4, 5, 6}},
+			[]uint32{10, 20},
+			nil,
+		))
+
+		actualEntries := make([]interface{}, 0)
+		err := log.Read(func(e interface{}) error {
+			actualEntries = append(actualEntries, e)
+			return nil
+		})
+		assert.NoError(t, err)
+
+		expectedEntries := []interface{}{
+			wal.EfSetting{Ef: 42},
+			wal.PointAddition{Vector: []float32{1, 2, 3}, ID: 10},
+			wal.PointAddition{Vector: []float32{4, 5, 6}, ID: 20},
+		}
+		assert.Equal(t, expectedEntries, actualEntries)
+	})
+
+	t.Run("write expiring point additions and deletion marks", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		log := wal.NewLog(path.Join(dir, "log"))
+		defer mustCloseLog(t, log)
+
+		require.NoError(t, log.WriteExpiringPointAddition([]float32{1, 2, 3}, 10, 1000))
+		require.NoError(t, log.WritePointAdditions(
+			[][]float32{{4, 5, 6}, {7, 8, 9}},
+			[]uint32{20, 30},
+			[]int64{0, 2000},
+		))
+		require.NoError(t, log.WriteDeletionMarks([]uint32{10, 30}))
+
+		actualEntries := make([]interface{}, 0)
+		err := log.Read(func(e interface{}) error {
+			actualEntries = append(actualEntries, e)
+			return nil
+		})
+		assert.NoError(t, err)
+
+		expectedEntries := []interface{}{
+			wal.PointAddition{Vector: []float32{1, 2, 3}, ID: 10, ExpiresAt: 1000},
+			wal.PointAddition{Vector: []float32{4, 5, 6}, ID: 20},
+			wal.PointAddition{Vector: []float32{7, 8, 9}, ID: 30, ExpiresAt: 2000},
+			wal.DeletionMark{ID: 10},
+			wal.DeletionMark{ID: 30},
+		}
+		assert.Equal(t, expectedEntries, actualEntries)
+	})
+
+	t.Run("mismatching expiration times", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		log := wal.NewLog(path.Join(dir, "log"))
+		defer mustCloseLog(t, log)
+
+		assert.Error(t, log.WritePointAdditions([][]float32{{1, 2, 3}}, []uint32{1}, []int64{1, 2}))
+		assert.NoFileExists(t, path.Join(dir, "log"))
+	})
+
+	t.Run("mismatching point additions", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		log := wal.NewLog(path.Join(dir, "log"))
+		defer mustCloseLog(t, log)
+
+		assert.Error(t, log.WritePointAdditions([][]float32{{1, 2, 3}}, []uint32{1, 2}, nil))
+		assert.NoFileExists(t, path.Join(dir, "log"))
+	})
+
 	t.Run("error creating file", func(t *testing.T) {
 		t.Parallel()
 		dir := createTempDir(t)
@@ -104,6 +189,41 @@ func TestLog_Read(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("read entries appended after closing the file", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		log := wal.NewLog(path.Join(dir, "log"))
+		defer mustCloseLog(t, log)
+
+		require.NoError(t, log.WriteEfSetting(1))
+		require.NoError(t, log.WritePointAddition([]float32{1, 2, 3}, 10))
+		require.NoError(t, log.Close())
+		require.NoError(t, log.WriteEfSetting(2))
+		require.NoError(t, log.WriteDeletionMark(10))
+
+		actualEntries := make([]interface{}, 0)
+		readEntries := func(e interface{}) error {
+			actualEntries = append(actualEntries, e)
+			return nil
+		}
+		require.NoError(t, log.Read(readEntries))
+
+		// Reading closes the file, so a new stream is appended again
+		require.NoError(t, log.WriteEfSetting(3))
+		actualEntries = actualEntries[:0]
+		assert.NoError(t, log.Read(readEntries))
+
+		expectedEntries := []interface{}{
+			wal.EfSetting{Ef: 1},
+			wal.PointAddition{Vector: []float32{1, 2, 3}, ID: 10},
+			wal.EfSetting{Ef: 2},
+			wal.DeletionMark{ID: 10},
+			wal.EfSetting{Ef: 3},
+		}
+		assert.Equal(t, expectedEntries, actualEntries)
+	})
+
 	t.Run("read partially corrupted file", func(t *testing.T) {
 		t.Parallel()
 		dir := createTempDir(t)
@@ -192,6 +312,99 @@ func TestLog_Read(t *testing.T) {
 	})
 }
 
+func TestLog_Check(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check nonexistent file", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		log := wal.NewLog(path.Join(dir, "log"))
+
+		check, err := log.Check(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, wal.LogCheck{}, check)
+	})
+
+	t.Run("check valid file", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		filename := path.Join(dir, "log")
+		log := wal.NewLog(filename)
+		defer mustCloseLog(t, log)
+
+		require.NoError(t, log.WriteEfSetting(1))
+		require.NoError(t, log.Close())
+		require.NoError(t, log.WriteDeletionMark(10))
+
+		var entries []interface{}
+		check, err := log.Check(func(e interface{}) error {
+			entries = append(entries, e)
+			return nil
+		})
+		require.NoError(t, err)
+		info, err := os.Stat(filename)
+		require.NoError(t, err)
+		assert.Equal(t, wal.LogCheck{Entries: 2, Size: info.Size(), ValidSize: info.Size()}, check)
+		assert.Equal(t, []interface{}{wal.EfSetting{Ef: 1}, wal.DeletionMark{ID: 10}}, entries)
+	})
+
+	t.Run("check and truncate torn file", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		filename := path.Join(dir, "log")
+		log := wal.NewLog(filename)
+		defer mustCloseLog(t, log)
+
+		require.NoError(t, log.WriteEfSetting(1))
+		require.NoError(t, log.WriteEfSetting(2))
+		info, err := os.Stat(filename)
+		require.NoError(t, err)
+		validSize := info.Size()
+
+		// The last entry is only partially written.
+		require.NoError(t, log.WritePointAddition([]float32{1, 2, 3}, 10))
+		require.NoError(t, log.Close())
+		info, err = os.Stat(filename)
+		require.NoError(t, err)
+		require.NoError(t, os.Truncate(filename, info.Size()-3))
+
+		check, err := log.Check(nil)
+		require.NoError(t, err)
+		assert.Equal(t, 2, check.Entries)
+		assert.Equal(t, info.Size()-3, check.Size)
+		assert.Equal(t, validSize, check.ValidSize)
+		assert.Error(t, check.Err)
+
+		require.NoError(t, log.Truncate(check.ValidSize))
+		require.NoError(t, log.WriteEfSetting(3))
+
+		var entries []interface{}
+		require.NoError(t, log.Read(func(e interface{}) error {
+			entries = append(entries, e)
+			return nil
+		}))
+		assert.Equal(t, []interface{}{wal.EfSetting{Ef: 1}, wal.EfSetting{Ef: 2}, wal.EfSetting{Ef: 3}}, entries)
+	})
+
+	t.Run("a callback error is returned", func(t *testing.T) {
+		t.Parallel()
+		dir := createTempDir(t)
+		defer deleteDir(t, dir)
+		log := wal.NewLog(path.Join(dir, "log"))
+		defer mustCloseLog(t, log)
+
+		require.NoError(t, log.WriteEfSetting(1))
+		myError := fmt.Errorf("my error")
+		_, err := log.Check(func(e interface{}) error {
+			return myError
+		})
+		assert.ErrorIs(t, err, myError)
+	})
+}
+
 func TestLog_Close(t *testing.T) {
 	t.Parallel()
 